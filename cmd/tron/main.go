@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"net/mail"
 	"os"
 	"os/signal"
 	"strconv"
@@ -15,9 +17,15 @@ import (
 	"github.com/everydev1618/tron/internal/callback"
 	"github.com/everydev1618/tron/internal/config"
 	"github.com/everydev1618/tron/internal/email"
+	"github.com/everydev1618/tron/internal/httpclient"
 	"github.com/everydev1618/tron/internal/life"
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/persist"
 	"github.com/everydev1618/tron/internal/server"
 	"github.com/everydev1618/tron/internal/slack"
+	"github.com/everydev1618/tron/internal/sms"
+	"github.com/everydev1618/tron/internal/subdomain"
 	"github.com/everydev1618/tron/internal/tools"
 	"github.com/everydev1618/tron/internal/vapi"
 	"github.com/everydev1618/tron/internal/voice/elevenlabs"
@@ -27,6 +35,9 @@ import (
 	"github.com/everydev1618/govega/llm"
 )
 
+// shutdownTimeout bounds how long a graceful shutdown waits for in-flight work
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
@@ -92,6 +103,14 @@ func runServe(args []string) {
 		log.Fatal("ANTHROPIC_API_KEY environment variable required")
 	}
 
+	// Route outbound API clients through an explicit proxy if configured
+	if proxy := os.Getenv(httpclient.ProxyEnv); proxy != "" {
+		if err := httpclient.SetProxyURL(proxy); err != nil {
+			log.Fatalf("Invalid %s: %v", httpclient.ProxyEnv, err)
+		}
+		log.Printf("Using outbound proxy from %s", httpclient.ProxyEnv)
+	}
+
 	log.Printf("Using config: %s", *configPath)
 	log.Printf("Working directory: %s", tronCfg.WorkingDir)
 	log.Printf("Agents directory: %s", tronCfg.AgentsDir)
@@ -102,6 +121,19 @@ func runServe(args []string) {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := tools.ValidateSupervision(cfg); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if extra := os.Getenv("TRON_EXTRA_MODELS"); extra != "" {
+		tools.AddKnownModels(strings.Split(extra, ",")...)
+	}
+	modelWarnings, err := tools.ValidateModels(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	for _, w := range modelWarnings {
+		log.Printf("Warning: %s", w)
+	}
 
 	// Create LLM backend
 	anthropic := llm.NewAnthropic(
@@ -126,16 +158,84 @@ func runServe(args []string) {
 		vega.WithContainerManager(cm, tronCfg.WorkingDir),
 	)
 
+	// Long task results are condensed by a summarizer agent before delivery
+	resultSummarizer := newResultSummarizer(orch, cfg)
+
+	// Slack completion notices truncate unless summarizing is turned on,
+	// since it holds up each notice for an extra LLM call
+	var slackSummarizer memory.Summarizer
+	if enabled, _ := strconv.ParseBool(os.Getenv("TRON_SLACK_SUMMARIZE_RESULTS")); enabled {
+		slackSummarizer = resultSummarizer
+	}
+
+	// Reworded notifications (slack-complete.tmpl, email-complete.tmpl, ...)
+	var notifyTemplates *notification.Templates
+	if dir := os.Getenv("TRON_NOTIFICATION_TEMPLATES"); dir != "" {
+		t, err := notification.LoadTemplates(dir)
+		if err != nil {
+			log.Fatalf("Invalid TRON_NOTIFICATION_TEMPLATES: %v", err)
+		}
+		notifyTemplates = t
+		log.Printf("Notification templates loaded from %s: %s", dir, strings.Join(t.Overrides(), ", "))
+	}
+
 	// Register custom tools with container support
-	customTools := tools.NewPersonaTools(orch, cfg, tronCfg.WorkingDir, tronCfg.TronDir, cm)
+	customTools := tools.NewPersonaToolsWithOptions(orch, cfg,
+		tools.WithWorkingDir(tronCfg.WorkingDir),
+		tools.WithTronDir(tronCfg.TronDir),
+		tools.WithContainerManager(cm),
+		tools.WithSummarizer(slackSummarizer),
+		tools.WithNotificationTemplates(notifyTemplates),
+	)
 
 	// Create and start server
-	srv := server.New(orch, cfg, customTools, *port, tronCfg.WorkingDir)
+	srv := server.New(orch, cfg, customTools, *port, tronCfg.WorkingDir,
+		subdomain.WithDomain(os.Getenv("TRON_DOMAIN")))
 	srv.SetBaseDir(tronCfg.TronDir)
 
 	// Wire up process manager for subdomain routing
 	customTools.SetProcessManager(srv.GetProcessManager())
-	log.Printf("Subdomain routing enabled (*.hellotron.com)")
+
+	// Keep more or less of each project server's output
+	if v := os.Getenv("TRON_SERVER_LOG_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid TRON_SERVER_LOG_BYTES %q, use a byte count like 65536", v)
+		}
+		srv.GetProcessManager().SetLogBufferSize(n)
+	}
+
+	// Stop project servers nobody has used for a while, if configured
+	if v := os.Getenv("TRON_SERVER_IDLE_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatalf("Invalid TRON_SERVER_IDLE_TIMEOUT %q, use a duration like 2h (0 disables)", v)
+		}
+		srv.GetProcessManager().SetIdleTimeout(d)
+		if d > 0 {
+			log.Printf("Project servers idle for %s are stopped", d)
+		}
+	}
+
+	// Restart project servers that crash, if configured
+	if v := os.Getenv("TRON_SERVER_MAX_RESTARTS"); v != "" {
+		policy := subdomain.RestartPolicy{Window: 10 * time.Minute}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid TRON_SERVER_MAX_RESTARTS %q, use a count (0 disables)", v)
+		}
+		policy.MaxRestarts = n
+		if w := os.Getenv("TRON_SERVER_RESTART_WINDOW"); w != "" {
+			if policy.Window, err = time.ParseDuration(w); err != nil || policy.Window <= 0 {
+				log.Fatalf("Invalid TRON_SERVER_RESTART_WINDOW %q, use a duration like 10m", w)
+			}
+		}
+		srv.GetProcessManager().SetRestartPolicy(policy)
+		if n > 0 {
+			log.Printf("Crashed project servers restart up to %d times per %s", n, policy.Window)
+		}
+	}
+	log.Printf("Subdomain routing enabled (*.%s)", srv.GetSubdomainRegistry().Domain())
 
 	// Initialize VAPI client if configured
 	vapiAPIKey := os.Getenv("VAPI_API_KEY")
@@ -144,6 +244,15 @@ func runServe(args []string) {
 	var vapiClient *vapi.Client
 	if vapiAPIKey != "" && vapiPhoneID != "" {
 		vapiClient = vapi.NewClient(vapiAPIKey, vapiPhoneID, vapiAssistantID)
+		vapiClient.SetTemplates(notifyTemplates)
+		if v := os.Getenv("VAPI_PERSONA_ASSISTANTS"); v != "" {
+			assistants, err := vapi.ParsePersonaAssistants(v)
+			if err != nil {
+				log.Fatalf("Invalid VAPI_PERSONA_ASSISTANTS: %v", err)
+			}
+			vapiClient.SetPersonaAssistants(assistants)
+			log.Printf("VAPI persona assistants: %d", len(assistants))
+		}
 		log.Printf("VAPI integration enabled")
 	}
 
@@ -152,12 +261,30 @@ func runServe(args []string) {
 	elevenLabsAgentID := os.Getenv("ELEVENLABS_AGENT_ID")
 	if elevenLabsAPIKey != "" && elevenLabsAgentID != "" {
 		elClient := elevenlabs.NewClient(elevenLabsAPIKey, elevenLabsAgentID)
+		if v := os.Getenv("ELEVENLABS_INTERIM_INTERVAL"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				log.Fatalf("Invalid ELEVENLABS_INTERIM_INTERVAL %q, use a duration like 250ms (0 sends every interim)", v)
+			}
+			elClient.SetInterimInterval(d)
+		}
 		srv.SetElevenLabsClient(elClient)
 		log.Printf("ElevenLabs integration enabled")
 	}
+	if msg := os.Getenv("TRON_BLOCKED_CALLER_MESSAGE"); msg != "" {
+		srv.SetBlockedCallerMessage(msg)
+	}
+	if enabled, _ := strconv.ParseBool(os.Getenv("TRON_VOICE_CALLER_CONTEXT")); enabled {
+		srv.SetVoiceCallerContext(true)
+		log.Printf("Voice caller context enabled")
+	}
+	if secret := os.Getenv("TRON_VOICE_CALLER_SECRET"); secret != "" {
+		srv.SetVoiceCallerSecret(secret)
+	}
 
 	// Initialize email client if configured
 	var emailClient *email.Client
+	var emailQueue *email.Queue
 	smtpHost := os.Getenv("SMTP_HOST")
 	smtpFrom := os.Getenv("SMTP_FROM")
 	if smtpHost != "" && smtpFrom != "" {
@@ -174,12 +301,106 @@ func runServe(args []string) {
 			os.Getenv("SMTP_PASSWORD"),
 			smtpFrom,
 		)
+		emailClient.SetTemplates(notifyTemplates)
+
+		// Optional per-persona From addresses (SMTP_FROM_MAYA, ...), one
+		// for each agent in the config
+		for persona := range cfg.Agents {
+			if from := os.Getenv("SMTP_FROM_" + strings.ToUpper(persona)); from != "" {
+				if err := emailClient.SetPersonaFrom(persona, from); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+			}
+		}
+		customTools.SetEmailClient(emailClient)
 		log.Printf("Email notifications enabled")
+
+		// Queue completion emails on disk and send them in the background
+		if enabled, _ := strconv.ParseBool(os.Getenv("TRON_EMAIL_QUEUE")); enabled {
+			emailQueue = emailClient.EnableQueue(persist.NewFileStore(tronCfg.TronDir))
+			emailQueue.Start()
+			log.Printf("Email queue enabled (%d waiting)", emailQueue.Waiting())
+		}
+	}
+
+	// Initialize SMS notifications if configured
+	var smsNotifier *sms.Notifier
+	twilio := sms.NewTwilioClient(os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_FROM_NUMBER"))
+	if twilio.IsConfigured() {
+		smsNotifier = sms.NewNotifier(twilio)
+		if window := os.Getenv("TRON_SMS_QUIET_HOURS"); window != "" {
+			loc := time.Local
+			if tz := os.Getenv("TRON_SMS_TIMEZONE"); tz != "" {
+				var err error
+				if loc, err = time.LoadLocation(tz); err != nil {
+					log.Fatalf("Invalid TRON_SMS_TIMEZONE: %v", err)
+				}
+			}
+			quiet, err := sms.ParseQuietHours(window, loc)
+			if err != nil {
+				log.Fatalf("Invalid TRON_SMS_QUIET_HOURS: %v", err)
+			}
+			smsNotifier.SetQuietHours(quiet)
+		}
+		if err := smsNotifier.SetStore(persist.NewFileStore(tronCfg.TronDir)); err != nil {
+			log.Printf("Warning: failed to load held texts: %v", err)
+		}
+		customTools.SetSMSNotifier(smsNotifier)
+		if enabled, _ := strconv.ParseBool(os.Getenv("TRON_SMS_VOICE_CALLERS")); enabled {
+			customTools.SetTextVoiceCallers(true)
+		}
+		log.Printf("SMS notifications enabled")
 	}
 
 	// Initialize callback registry
 	callbackRegistry := callback.NewRegistry(vapiClient, emailClient, tronCfg.TronDir, "Tony", smtpFrom)
+	if smsNotifier != nil {
+		callbackRegistry.SetSMSNotifier(smsNotifier)
+	}
+	if token := os.Getenv("TRON_CALLBACK_WEBHOOK_TOKEN"); token != "" {
+		callbackRegistry.SetWebhookToken(token)
+		srv.SetAdminToken(token)
+	}
+	if secret := os.Getenv("TRON_CALLBACK_WEBHOOK_SECRET"); secret != "" {
+		callbackRegistry.SetWebhookSecret(secret)
+	}
+	if routing, err := callbackEmailRouting(); err != nil {
+		log.Fatalf("%v", err)
+	} else {
+		callbackRegistry.SetEmailRouting(routing)
+	}
+	if trackingURL := os.Getenv("TRON_CALLBACK_TRACKING_URL"); trackingURL != "" {
+		callbackRegistry.SetTrackingURL(trackingURL)
+		log.Printf("Callback engagement tracking enabled")
+	}
+	if retries, backoff := os.Getenv("TRON_CALLBACK_MAX_RETRIES"), os.Getenv("TRON_CALLBACK_RETRY_BACKOFF"); retries != "" || backoff != "" {
+		n, d := callback.DefaultMaxRetries, callback.DefaultRetryBackoff
+		var err error
+		if retries != "" {
+			if n, err = strconv.Atoi(retries); err != nil || n < 0 {
+				log.Fatalf("Invalid TRON_CALLBACK_MAX_RETRIES %q, use a count (0 disables retries)", retries)
+			}
+		}
+		if backoff != "" {
+			if d, err = time.ParseDuration(backoff); err != nil || d <= 0 {
+				log.Fatalf("Invalid TRON_CALLBACK_RETRY_BACKOFF %q, use a duration like 1m", backoff)
+			}
+		}
+		callbackRegistry.SetRetryPolicy(n, d)
+	}
+	if ttl := os.Getenv("TRON_CALLBACK_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < 0 {
+			log.Fatalf("Invalid TRON_CALLBACK_TTL %q, use a duration like 24h (0 never expires)", ttl)
+		}
+		callbackRegistry.SetCallbackTTL(d)
+	}
+	callbackRegistry.SetSummarizer(resultSummarizer)
+	callbackRegistry.SetTemplates(notifyTemplates)
+	callbackRegistry.SetResultLookup(customTools.FullResult)
 	srv.SetCallbackRegistry(callbackRegistry)
+	customTools.SetCallbackRegistry(callbackRegistry)
+	callbackRegistry.Start()
 
 	// Initialize Slack handlers
 	// Check for per-persona Slack apps first (preferred)
@@ -195,6 +416,7 @@ func runServe(args []string) {
 		if botToken != "" {
 			client := slack.NewClient(botToken)
 			handler := slack.NewPersonaHandler(client, signingSecret, orch, cfg, tronCfg.TronDir, persona)
+			addSlackWorkspaces(handler, "SLACK_WORKSPACE_TOKENS_"+envKey)
 
 			// Build and set tools for this handler
 			vegaTools := vega.NewTools(vega.WithSandbox(tronCfg.WorkingDir))
@@ -230,6 +452,7 @@ func runServe(args []string) {
 		if slackBotToken != "" {
 			slackClient = slack.NewClient(slackBotToken)
 			slackHandler := slack.NewHandler(slackClient, slackSigningSecret, orch, cfg, tronCfg.TronDir)
+			addSlackWorkspaces(slackHandler, "SLACK_WORKSPACE_TOKENS")
 
 			// Build and set tools for handler
 			vegaTools := vega.NewTools(vega.WithSandbox(tronCfg.WorkingDir))
@@ -251,6 +474,131 @@ func runServe(args []string) {
 	// Wire Slack client to PersonaTools for spawn completion notifications
 	srv.WireSlackNotifications()
 
+	// Post raw tool failures to an ops channel if configured
+	if opsChannel := os.Getenv("TRON_OPS_SLACK_CHANNEL"); opsChannel != "" {
+		customTools.SetOpsChannel(opsChannel)
+		log.Printf("Tool errors will be reported to Slack channel: %s", opsChannel)
+	}
+
+	// Describe execute calls instead of running them
+	if enabled, _ := strconv.ParseBool(os.Getenv("TRON_EXEC_DRY_RUN")); enabled {
+		customTools.SetExecDryRun(true)
+		log.Printf("Execute dry-run mode enabled: commands will not run")
+	}
+
+	// Extra commands execute refuses, and optionally the only binaries it runs
+	execBlocked, execAllowed := splitList(os.Getenv("TRON_EXEC_BLOCKLIST")), splitList(os.Getenv("TRON_EXEC_ALLOWLIST"))
+	if len(execBlocked) > 0 || len(execAllowed) > 0 {
+		customTools.SetExecPolicy(tools.ExecPolicy{
+			Blocked: append(append([]string{}, tools.DefaultExecBlocklist...), execBlocked...),
+			Allowed: execAllowed,
+		})
+		if len(execAllowed) > 0 {
+			log.Printf("Execute allowlist mode: only %s may run", strings.Join(execAllowed, ", "))
+		}
+	}
+
+	// Whether project commands may run on the host if Docker dies mid-session
+	if v := os.Getenv("TRON_CONTAINER_FALLBACK"); v != "" {
+		policy, err := tools.ParseContainerFallback(v)
+		if err != nil {
+			log.Fatalf("Invalid TRON_CONTAINER_FALLBACK: %v", err)
+		}
+		customTools.SetContainerFallback(policy)
+	}
+
+	// Keep spawned agents' scratch directories around after they finish
+	if v := os.Getenv("TRON_AGENT_DIR_RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid TRON_AGENT_DIR_RETENTION %q, use a duration like 1h (0 removes right away, -1s keeps them)", v)
+		}
+		customTools.SetAgentDirRetention(d)
+	}
+
+	// Post "still running" updates for long execute calls to the agent's channel
+	if v := os.Getenv("TRON_EXEC_PROGRESS_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid TRON_EXEC_PROGRESS_INTERVAL %q, use a duration like 30s (0 disables)", v)
+		}
+		if d > 0 {
+			customTools.SetExecProgress(customTools.ExecProgressToChannel, d)
+			log.Printf("Execute progress updates every %s", d)
+		}
+	}
+
+	// Override the supervision for agents whose config doesn't set one
+	if strategy, window := os.Getenv("TRON_SPAWN_STRATEGY"), os.Getenv("TRON_SPAWN_RESTART_WINDOW"); strategy != "" || window != "" || os.Getenv("TRON_SPAWN_MAX_RESTARTS") != "" {
+		def := dsl.SupervisionDef{Strategy: strategy, MaxRestarts: tools.DefaultSupervision.MaxRestarts, Window: window}
+		if v := os.Getenv("TRON_SPAWN_MAX_RESTARTS"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				log.Fatalf("Invalid TRON_SPAWN_MAX_RESTARTS %q: %v", v, err)
+			}
+			def.MaxRestarts = n
+		}
+		if err := customTools.SetDefaultSupervision(def); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	// Cap concurrently running spawned agents if configured
+	if v := os.Getenv("TRON_MAX_CONCURRENT_SPAWNS"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid TRON_MAX_CONCURRENT_SPAWNS %q: %v", v, err)
+		}
+		mode := tools.ParseSpawnLimitMode(os.Getenv("TRON_SPAWN_LIMIT_MODE"))
+		customTools.SetMaxConcurrentSpawns(limit, mode)
+		if limit > 0 {
+			log.Printf("Spawned agents capped at %d (%s when full)", limit, mode)
+		}
+	}
+
+	// Per-tool concurrency limits for heavy tools (execute, start_server, ...)
+	if v := os.Getenv("TRON_TOOL_CONCURRENCY"); v != "" {
+		limits, err := tools.ParseToolConcurrency(v)
+		if err != nil {
+			log.Fatalf("Invalid TRON_TOOL_CONCURRENCY: %v", err)
+		}
+		customTools.SetToolConcurrency(limits)
+	}
+	if v := os.Getenv("TRON_TOOL_QUEUE_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			log.Fatalf("Invalid TRON_TOOL_QUEUE_TIMEOUT %q, use a duration like 1m", v)
+		}
+		customTools.SetToolQueueTimeout(timeout)
+	}
+
+	// Window in which repeated share_knowledge entries are skipped
+	if v := os.Getenv("TRON_KNOWLEDGE_DEDUP_WINDOW"); v != "" {
+		window, err := time.ParseDuration(v)
+		if err != nil || window < 0 {
+			log.Fatalf("Invalid TRON_KNOWLEDGE_DEDUP_WINDOW %q, use a duration like 24h (0 disables)", v)
+		}
+		customTools.SetKnowledgeDedupWindow(window)
+	}
+
+	// Bounds on the knowledge store; older entries are archived
+	var retention tools.KnowledgeRetention
+	if v := os.Getenv("TRON_KNOWLEDGE_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid TRON_KNOWLEDGE_MAX_ENTRIES %q, use a whole number (0 for no limit)", v)
+		}
+		retention.MaxEntries = n
+	}
+	if v := os.Getenv("TRON_KNOWLEDGE_MAX_AGE"); v != "" {
+		age, err := time.ParseDuration(v)
+		if err != nil || age < 0 {
+			log.Fatalf("Invalid TRON_KNOWLEDGE_MAX_AGE %q, use a duration like 480h (0 for no limit)", v)
+		}
+		retention.MaxAge = age
+	}
+	customTools.SetKnowledgeRetention(retention)
+
 	// Initialize life manager for all C-suite personas
 	lifeConfig := life.DefaultConfig(tronCfg.TronDir)
 	if apiURL := os.Getenv("TRON_SOCIAL_API_URL"); apiURL != "" {
@@ -304,26 +652,40 @@ func runServe(args []string) {
 	lifeManager.Start()
 	log.Printf("Life manager started for personas: %v", lifeManager.Personas())
 
-	// Graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Only now that the orchestrator has recovered its processes can their
+	// completion notifications be matched back up
+	customTools.RestoreSpawnCallbacks()
 
+	// Graceful shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	shutdownDone := make(chan struct{})
 	go func() {
 		<-sigCh
-		log.Println("Shutting down...")
-		cancel()
-		lifeManager.Stop()
-		srv.Shutdown(ctx)
-		orch.Shutdown(ctx)
+		log.Printf("Shutting down (deadline %s)...", shutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		report := srv.Shutdown(ctx)
+		if emailQueue != nil {
+			if err := emailQueue.Close(ctx); err != nil {
+				log.Printf("Email queue did not stop cleanly: %v", err)
+			}
+		}
+		if report.Clean() {
+			log.Printf("Shutdown complete: %s", report)
+		} else {
+			log.Printf("Shutdown finished with forced stops: %s", report)
+		}
+		close(shutdownDone)
 	}()
 
 	log.Printf("Tron server starting on port %d", *port)
-	if err := srv.ListenAndServe(); err != nil {
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server error: %v", err)
 	}
+	<-shutdownDone
 }
 
 func runChat(args []string) {
@@ -350,6 +712,9 @@ func runChat(args []string) {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := tools.ValidateSupervision(cfg); err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
 
 	// Determine which agent to use
 	selectedAgent := *agentName
@@ -394,7 +759,11 @@ func runChat(args []string) {
 	defer orch.Shutdown(context.Background())
 
 	// Register custom tools with container support
-	customTools := tools.NewPersonaTools(orch, cfg, tronCfg.WorkingDir, tronCfg.TronDir, cm)
+	customTools := tools.NewPersonaToolsWithOptions(orch, cfg,
+		tools.WithWorkingDir(tronCfg.WorkingDir),
+		tools.WithTronDir(tronCfg.TronDir),
+		tools.WithContainerManager(cm),
+	)
 
 	// Create agent
 	agent := buildAgent(agentDef, customTools, tronCfg.WorkingDir)
@@ -506,3 +875,81 @@ func parseWindow(s string) time.Duration {
 	d, _ := time.ParseDuration(s)
 	return d
 }
+
+// newResultSummarizer returns a summarizer that condenses task results using Tony's model
+func newResultSummarizer(orch *vega.Orchestrator, cfg *dsl.Document) memory.Summarizer {
+	return memory.SummarizerFunc(func(ctx context.Context, text string) (string, error) {
+		tonyDef, ok := cfg.Agents["Tony"]
+		if !ok {
+			return "", fmt.Errorf("Tony agent not found in config")
+		}
+
+		agent := vega.Agent{
+			Name:   "Summarizer",
+			Model:  tonyDef.Model,
+			System: vega.StaticPrompt(memory.ResultSummaryPrompt()),
+		}
+
+		proc, err := orch.Spawn(agent, vega.WithTask("Summarizing result"))
+		if err != nil {
+			return "", fmt.Errorf("failed to spawn summarizer: %w", err)
+		}
+
+		summary, err := proc.Send(ctx, text)
+		if err != nil {
+			proc.Fail(err)
+			return "", err
+		}
+
+		proc.Complete(summary)
+		return summary, nil
+	})
+}
+
+// addSlackWorkspaces registers extra workspace tokens from an env var of the
+// form "T123:xoxb-...,T456:xoxb-..." with a Slack handler
+func addSlackWorkspaces(handler *slack.Handler, envVar string) {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return
+	}
+	tokens, err := slack.ParseWorkspaceTokens(raw)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", envVar, err)
+	}
+	for teamID, token := range tokens {
+		handler.AddWorkspace(teamID, token)
+	}
+	log.Printf("Slack workspaces configured from %s: %d", envVar, len(tokens))
+}
+
+// callbackEmailRouting reads the Cc, Bcc and Reply-To for callback emails
+func callbackEmailRouting() (email.Routing, error) {
+	var routing email.Routing
+	var err error
+	if routing.Cc, err = email.ParseAddresses(os.Getenv("TRON_CALLBACK_EMAIL_CC")); err != nil {
+		return routing, fmt.Errorf("invalid TRON_CALLBACK_EMAIL_CC: %w", err)
+	}
+	if routing.Bcc, err = email.ParseAddresses(os.Getenv("TRON_CALLBACK_EMAIL_BCC")); err != nil {
+		return routing, fmt.Errorf("invalid TRON_CALLBACK_EMAIL_BCC: %w", err)
+	}
+	if v := os.Getenv("TRON_CALLBACK_EMAIL_REPLY_TO"); v != "" {
+		addr, err := mail.ParseAddress(v)
+		if err != nil {
+			return routing, fmt.Errorf("invalid TRON_CALLBACK_EMAIL_REPLY_TO: %w", err)
+		}
+		routing.ReplyTo = addr.String()
+	}
+	return routing, nil
+}
+
+// splitList splits a comma-separated environment value, dropping blanks
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
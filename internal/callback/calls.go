@@ -0,0 +1,60 @@
+package callback
+
+import "time"
+
+// Call statuses reported when a callback call is placed. Once VAPI reports
+// how the call ended, the status becomes one of the call engagement values
+// (EngagementAnswered, EngagementVoicemail or EngagementMissed).
+const (
+	CallPlaced = "placed" // VAPI accepted the call
+	CallFailed = "failed" // The call couldn't be placed
+)
+
+// CallRecord describes a callback call being placed or ending
+type CallRecord struct {
+	CallID      string // VAPI's call ID; empty if the call wasn't placed
+	AgentID     string // The agent whose completion triggered the call
+	AgentName   string
+	PersonaName string // Who made the call
+	TaskSummary string
+	Recipient   string // Masked phone number
+	Status      string
+	Duration    time.Duration // Set once the call has ended, if VAPI reports it
+	Error       string
+}
+
+// SetCallRecorder sets a function called when a callback call is placed and
+// again when it ends, e.g. to record it in history. It may be called with
+// the registry locked, so it must not call back into the registry. A nil
+// recorder disables recording.
+func (r *Registry) SetCallRecorder(record func(CallRecord)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recordCall = record
+}
+
+// emitCall passes a record of cb's call to the call recorder, if any
+func (r *Registry) emitCall(cb *Callback, status, errMsg string, duration time.Duration) {
+	if r.recordCall == nil {
+		return
+	}
+	r.recordCall(CallRecord{
+		CallID:      cb.CallID,
+		AgentID:     cb.AgentID,
+		AgentName:   cb.AgentName,
+		PersonaName: cb.PersonaName,
+		TaskSummary: cb.TaskSummary,
+		Recipient:   maskPhone(cb.CustomerPhone),
+		Status:      status,
+		Duration:    duration,
+		Error:       errMsg,
+	})
+}
+
+// maskPhone hides all but the start and end of a phone number
+func maskPhone(phone string) string {
+	if len(phone) <= 6 {
+		return "***"
+	}
+	return phone[:3] + "***" + phone[len(phone)-4:]
+}
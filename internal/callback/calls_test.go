@@ -0,0 +1,52 @@
+package callback
+
+import (
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func TestCallRecorder(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	var got []CallRecord
+	r.SetCallRecorder(func(rec CallRecord) { got = append(got, rec) })
+
+	cb := &Callback{ID: "cb-1", AgentID: "agent-1", AgentName: "Gary", PersonaName: "Tony", Method: "call", CustomerPhone: "+15550102000", CallID: "call-1"}
+	r.history = append(r.history, cb)
+
+	// Outcomes reach the recorder even with engagement tracking off
+	if r.RecordCallEnd("call-1", "customer-ended-call", 90*time.Second) {
+		t.Error("engagement should not be recorded while tracking is off")
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d records, want 1", len(got))
+	}
+	rec := got[0]
+	if rec.CallID != "call-1" || rec.Status != EngagementAnswered || rec.Duration != 90*time.Second || rec.AgentName != "Gary" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.Recipient != "+15***2000" {
+		t.Errorf("Recipient = %q, want the phone masked", rec.Recipient)
+	}
+
+	r.RecordCallEnd("call-2", "voicemail", 0)
+	r.RecordCallEnd("call-1", "", 0)
+	if len(got) != 1 {
+		t.Errorf("unknown calls and reasons should not be recorded, got %+v", got[1:])
+	}
+}
+
+func TestMaskPhone(t *testing.T) {
+	tests := map[string]string{
+		"":             "***",
+		"555010":       "***",
+		"+15550102000": "+15***2000",
+	}
+	for phone, want := range tests {
+		if got := maskPhone(phone); got != want {
+			t.Errorf("maskPhone(%q) = %q, want %q", phone, got, want)
+		}
+	}
+}
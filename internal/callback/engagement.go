@@ -0,0 +1,155 @@
+package callback
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Engagement values recorded on a delivered callback
+const (
+	EngagementViewed    = "viewed"    // The email's view link was opened
+	EngagementAnswered  = "answered"  // The call was picked up
+	EngagementVoicemail = "voicemail" // The call went to voicemail
+	EngagementMissed    = "missed"    // The call wasn't answered
+)
+
+// engagementRank orders engagement so weaker signals don't replace stronger
+// ones (e.g. a missed call after the email was viewed)
+var engagementRank = map[string]int{
+	"":                  0,
+	EngagementMissed:    1,
+	EngagementVoicemail: 2,
+	EngagementAnswered:  3,
+	EngagementViewed:    3,
+}
+
+// trackPath is where tracked view links point
+const trackPath = "/callbacks/track/"
+
+// SetTrackingURL turns on engagement tracking. Email view links are routed
+// through baseURL (Tron's public URL) so opening them marks the callback
+// viewed, and call outcomes from VAPI are recorded. Tracking is off by
+// default; an empty baseURL turns it off.
+func (r *Registry) SetTrackingURL(baseURL string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trackingURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// trackedViewURL returns the link to put in cb's email: viewURL itself, or
+// a tracking link that redirects to it. It sets cb's tracking token, so cb
+// must be a delivery attempt's copy.
+func (r *Registry) trackedViewURL(cb *Callback, viewURL string) string {
+	if r.trackingURL == "" || viewURL == "" {
+		return viewURL
+	}
+	if cb.TrackingToken == "" {
+		token, err := newTrackingToken()
+		if err != nil {
+			r.logger.Warnf("Failed to create tracking token: %v", err)
+			return viewURL
+		}
+		cb.TrackingToken = token
+	}
+	cb.ViewURL = viewURL
+	return r.trackingURL + trackPath + cb.TrackingToken
+}
+
+// newTrackingToken returns an unguessable token for a tracking link
+func newTrackingToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// engage records engagement on cb if it's stronger than what's there.
+// Caller must hold the lock.
+func (r *Registry) engage(cb *Callback, engagement string) {
+	if engagementRank[engagement] <= engagementRank[cb.Engagement] {
+		return
+	}
+	cb.Engagement = engagement
+	cb.EngagedAt = time.Now()
+	r.persist()
+}
+
+// HandleTrack marks a callback viewed and redirects to its view link
+func (r *Registry) HandleTrack(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(req.URL.Path, trackPath)
+
+	r.mu.Lock()
+	var target string
+	if token != "" {
+		for _, cb := range r.history {
+			if cb.TrackingToken == token {
+				r.engage(cb, EngagementViewed)
+				target = cb.ViewURL
+				break
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	if target == "" {
+		http.NotFound(w, req)
+		return
+	}
+	http.Redirect(w, req, target, http.StatusFound)
+}
+
+// RecordCallOutcome records how a callback call ended, from VAPI's
+// endedReason. It reports whether callID belonged to a callback and
+// engagement tracking is on.
+func (r *Registry) RecordCallOutcome(callID, endedReason string) bool {
+	return r.RecordCallEnd(callID, endedReason, 0)
+}
+
+// RecordCallEnd is RecordCallOutcome with the call's length, if known. The
+// outcome is also passed to the call recorder, whether or not engagement
+// tracking is on.
+func (r *Registry) RecordCallEnd(callID, endedReason string, duration time.Duration) bool {
+	outcome := callOutcome(endedReason)
+	if callID == "" || outcome == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cb := range r.history {
+		if cb.CallID != callID {
+			continue
+		}
+		r.emitCall(cb, outcome, "", duration)
+		if r.trackingURL == "" {
+			return false
+		}
+		r.engage(cb, outcome)
+		return true
+	}
+	return false
+}
+
+// callOutcome maps a VAPI endedReason to an engagement value, or "" if
+// it's unknown
+func callOutcome(endedReason string) string {
+	reason := strings.ToLower(endedReason)
+	switch {
+	case reason == "":
+		return ""
+	case strings.Contains(reason, "voicemail"):
+		return EngagementVoicemail
+	case strings.Contains(reason, "did-not-answer"), strings.Contains(reason, "no-answer"), strings.Contains(reason, "busy"):
+		return EngagementMissed
+	default:
+		return EngagementAnswered
+	}
+}
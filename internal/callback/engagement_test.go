@@ -0,0 +1,86 @@
+package callback
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func TestTrackedViewLink(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+
+	cb := &Callback{ID: "cb-1", AgentID: "agent-1", Method: "email", Status: "completed"}
+	r.history = append(r.history, cb)
+
+	// Off by default: the link is left alone
+	if got := r.trackedViewURL(cb, "https://site.example.com"); got != "https://site.example.com" {
+		t.Fatalf("untracked link = %q", got)
+	}
+
+	r.SetTrackingURL("https://tron.example.com/")
+	link := r.trackedViewURL(cb, "https://site.example.com")
+	if !strings.HasPrefix(link, "https://tron.example.com/callbacks/track/") || cb.TrackingToken == "" {
+		t.Fatalf("tracked link = %q", link)
+	}
+
+	w := httptest.NewRecorder()
+	r.HandleTrack(w, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(link, "https://tron.example.com"), nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://site.example.com" {
+		t.Errorf("track = %d to %q, want a redirect to the view URL", w.Code, w.Header().Get("Location"))
+	}
+	if h := r.ListHistory(); h[0].Engagement != EngagementViewed || h[0].EngagedAt.IsZero() {
+		t.Errorf("engagement = %q at %v, want viewed", h[0].Engagement, h[0].EngagedAt)
+	}
+
+	w = httptest.NewRecorder()
+	r.HandleTrack(w, httptest.NewRequest(http.MethodGet, "/callbacks/track/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown token status = %d, want 404", w.Code)
+	}
+}
+
+func TestRecordCallOutcome(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	cb := &Callback{ID: "cb-1", AgentID: "agent-1", Method: "both", Status: "completed", CallID: "call-1"}
+	r.history = append(r.history, cb)
+
+	if r.RecordCallOutcome("call-1", "voicemail") {
+		t.Fatal("call outcomes should be ignored while tracking is off")
+	}
+
+	r.SetTrackingURL("https://tron.example.com")
+	if !r.RecordCallOutcome("call-1", "voicemail") || cb.Engagement != EngagementVoicemail {
+		t.Fatalf("engagement = %q, want voicemail", cb.Engagement)
+	}
+	if r.RecordCallOutcome("call-2", "customer-ended-call") {
+		t.Error("unknown call ID should not match")
+	}
+
+	// A stronger signal replaces a weaker one, never the reverse
+	r.engage(cb, EngagementViewed)
+	r.RecordCallOutcome("call-1", "customer-did-not-answer")
+	if cb.Engagement != EngagementViewed {
+		t.Errorf("engagement = %q, want viewed to stick", cb.Engagement)
+	}
+}
+
+func TestCallOutcome(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"customer-ended-call":     EngagementAnswered,
+		"assistant-ended-call":    EngagementAnswered,
+		"voicemail":               EngagementVoicemail,
+		"customer-did-not-answer": EngagementMissed,
+		"customer-busy":           EngagementMissed,
+	}
+	for reason, want := range tests {
+		if got := callOutcome(reason); got != want {
+			t.Errorf("callOutcome(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
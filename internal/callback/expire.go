@@ -0,0 +1,115 @@
+package callback
+
+import "time"
+
+// DefaultCallbackTTL is how long a callback waits for its agent before it
+// expires
+const DefaultCallbackTTL = 24 * time.Hour
+
+// SetCallbackTTL sets how long callbacks registered from now on wait for
+// their agent to complete. Zero or less never expires them.
+func (r *Registry) SetCallbackTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callbackTTL = ttl
+}
+
+// expiry returns when a callback requested at requestedAt expires, or the
+// zero time if it never does. Caller must hold the lock.
+func (r *Registry) expiry(requestedAt time.Time) time.Time {
+	if r.callbackTTL <= 0 {
+		return time.Time{}
+	}
+	return requestedAt.Add(r.callbackTTL)
+}
+
+// expireStale moves pending callbacks whose agent never completed in time
+// to history as "expired", so a killed agent's callback doesn't linger
+// forever. Groups lose the expired members: one left empty expires too,
+// and one whose other members have all finished is delivered.
+func (r *Registry) expireStale(now time.Time) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+
+	expired := false
+	var due attempts
+	for agentID, cb := range r.callbacks {
+		if cb.Status != "pending" || cb.delivering {
+			continue
+		}
+		// Callbacks saved before expiry existed get the current TTL
+		expiresAt := cb.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt = r.expiry(cb.RequestedAt)
+		}
+		if expiresAt.IsZero() || now.Before(expiresAt) {
+			continue
+		}
+		group, inGroup := r.groups[cb.GroupID]
+		if inGroup {
+			if _, finished := group.Results[agentID]; finished {
+				continue // Done, waiting on the rest of its group
+			}
+		}
+
+		cb.Status = "expired"
+		cb.CompletedAt = now
+		cb.Error = "agent never completed"
+		delete(r.callbacks, agentID)
+		r.history = append(r.history, cb)
+		r.logger.Warnf("Callback for agent %s expired after waiting since %s", agentID, cb.RequestedAt.Format(time.RFC3339))
+
+		if inGroup {
+			if a := r.dropFromGroup(group, agentID, now); a != nil {
+				due.groups = append(due.groups, a)
+			}
+		}
+		expired = true
+	}
+	if !expired {
+		r.mu.Unlock()
+		return
+	}
+
+	if len(r.history) > 100 {
+		r.history = r.history[len(r.history)-100:]
+	}
+	r.persist()
+	r.mu.Unlock()
+
+	r.deliver(due)
+}
+
+// dropFromGroup removes an expired member from a pending group, returning
+// the group's delivery if everyone left has finished. Caller must hold the
+// lock.
+func (r *Registry) dropFromGroup(group *CallbackGroup, agentID string, now time.Time) *groupAttempt {
+	kept := group.AgentIDs[:0]
+	for _, id := range group.AgentIDs {
+		if id != agentID {
+			kept = append(kept, id)
+		}
+	}
+	group.AgentIDs = kept
+	if group.Status != "pending" || group.delivering {
+		return nil
+	}
+
+	switch {
+	case len(group.AgentIDs) == 0:
+		group.Status = "expired"
+		group.CompletedAt = now
+		delete(r.groups, group.ID)
+		r.groupHistory = append(r.groupHistory, group)
+		if len(r.groupHistory) > 50 {
+			r.groupHistory = r.groupHistory[1:]
+		}
+	case len(group.Results) >= len(group.AgentIDs):
+		// Everyone left has finished
+		return r.startGroup(group)
+	}
+	return nil
+}
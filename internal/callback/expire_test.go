@@ -0,0 +1,134 @@
+package callback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/sms"
+	"github.com/everydev1618/tron/internal/vapi"
+)
+
+func TestPendingCallbackExpires(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(nil, nil, dir, "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetCallbackTTL(time.Hour)
+	r.SetSMSNotifier(sms.NewNotifier(&flakySMS{}))
+
+	cb, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.expireStale(cb.ExpiresAt.Add(-time.Second))
+	if r.Get("agent-1") == nil {
+		t.Fatal("expired before ExpiresAt")
+	}
+
+	r.expireStale(cb.ExpiresAt)
+	if r.Get("agent-1") != nil {
+		t.Fatal("still pending after ExpiresAt")
+	}
+	history := r.ListHistory()
+	if len(history) != 1 || history[0].Status != "expired" {
+		t.Fatalf("history = %+v, want one expired callback", history)
+	}
+
+	// Expiry is persisted
+	reloaded := NewRegistry(nil, nil, dir, "Tony", "")
+	reloaded.SetLogger(logging.Discard())
+	if reloaded.Get("agent-1") != nil {
+		t.Error("expired callback pending again after reload")
+	}
+}
+
+func TestStaleCallbacksExpireOnStart(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(nil, nil, dir, "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetCallbackTTL(time.Nanosecond)
+	r.SetSMSNotifier(sms.NewNotifier(&flakySMS{}))
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing expires until main has configured the registry and started it
+	reloaded := NewRegistry(nil, nil, dir, "Tony", "")
+	reloaded.SetLogger(logging.Discard())
+	defer reloaded.Close(context.Background())
+	if reloaded.Get("agent-1") == nil {
+		t.Fatal("callback expired at construction")
+	}
+
+	reloaded.Start()
+	if reloaded.Get("agent-1") != nil {
+		t.Error("stale callback still pending after Start")
+	}
+}
+
+func TestCallbackTTLDisabled(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetCallbackTTL(0)
+	r.SetSMSNotifier(sms.NewNotifier(&flakySMS{}))
+
+	cb, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cb.ExpiresAt.IsZero() {
+		t.Errorf("ExpiresAt = %v, want none", cb.ExpiresAt)
+	}
+	r.expireStale(time.Now().Add(365 * 24 * time.Hour))
+	if r.Get("agent-1") == nil {
+		t.Error("callback expired with expiry disabled")
+	}
+}
+
+func TestExpiredMemberReleasesGroup(t *testing.T) {
+	r := NewRegistry(vapi.NewClient("key", "phone", "asst"), nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(0, 0)
+	r.SetCallbackTTL(time.Hour)
+
+	group, err := r.RegisterBatch("", []AgentInfo{{ID: "a1", Name: "Gary"}, {ID: "a2", Name: "Maya"}}, "call", "+15550102000", "", "Sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A client with no phone number can't place the call, so delivery
+	// shows up as a failure
+	r.vapiClient = vapi.NewClient("key", "", "asst")
+	r.OnAgentComplete(CompletionInfo{AgentID: "a1", AgentName: "Gary", Result: "done"})
+	if group.Status != "pending" {
+		t.Fatalf("group status = %s, want pending on Maya", group.Status)
+	}
+
+	r.expireStale(time.Now().Add(2 * time.Hour))
+	if group.Status != "failed" {
+		t.Errorf("group status = %s, want delivered once Maya expired", group.Status)
+	}
+	if len(group.AgentIDs) != 1 || group.AgentIDs[0] != "a1" {
+		t.Errorf("group members = %v, want only a1", group.AgentIDs)
+	}
+}
+
+func TestFullyExpiredGroupExpires(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetCallbackTTL(time.Hour)
+	r.SetSMSNotifier(sms.NewNotifier(&flakySMS{}))
+
+	group, err := r.RegisterBatch("", []AgentInfo{{ID: "a1"}, {ID: "a2"}}, "sms", "+15551234567", "", "Sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.expireStale(time.Now().Add(2 * time.Hour))
+	if group.Status != "expired" {
+		t.Errorf("group status = %s, want expired", group.Status)
+	}
+	if len(r.ListPending()) != 0 {
+		t.Errorf("pending = %d, want none", len(r.ListPending()))
+	}
+}
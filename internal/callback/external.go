@@ -0,0 +1,107 @@
+package callback
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnknownAgent is returned when no pending callback exists for an agent
+var ErrUnknownAgent = errors.New("no pending callback for agent")
+
+// SetWebhookToken sets the shared secret external systems must present to
+// complete callbacks. An empty token disables the webhook.
+func (r *Registry) SetWebhookToken(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhookToken = token
+}
+
+// CompleteExternal completes a callback for work that finished outside the
+// orchestrator, firing the same notifications as OnAgentComplete.
+func (r *Registry) CompleteExternal(agentID string, info CompletionInfo) error {
+	condensed := r.condenseFor(agentID, info.Result)
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+
+	cb, ok := r.callbacks[agentID]
+	if !ok || cb.Status != "pending" || cb.delivering {
+		r.mu.Unlock()
+		return ErrUnknownAgent
+	}
+
+	info.AgentID = agentID
+	if info.AgentName == "" {
+		info.AgentName = cb.AgentName
+	}
+	if info.ProjectName == "" {
+		info.ProjectName = cb.ProjectName
+	}
+
+	r.logger.Infof("External completion received for agent %s", agentID)
+	due := r.complete(cb, info, condensed)
+	r.mu.Unlock()
+
+	r.deliver(due)
+	return nil
+}
+
+// validWebhookToken reports whether token matches the configured secret
+func (r *Registry) validWebhookToken(token string) bool {
+	r.mu.RLock()
+	expected := r.webhookToken
+	r.mu.RUnlock()
+
+	if expected == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// HandleExternalComplete accepts completion webhooks from external systems.
+// Requests authenticate with "Authorization: Bearer <token>" and send a JSON
+// body of the form {"agent_id": "...", "result": "...", "error": "..."}.
+func (r *Registry) HandleExternalComplete(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !r.validWebhookToken(token) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var info CompletionInfo
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(&info); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if info.AgentID == "" {
+		http.Error(w, "agent_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.CompleteExternal(info.AgentID, info); err != nil {
+		if errors.Is(err, ErrUnknownAgent) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrClosed) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"success": true})
+}
@@ -0,0 +1,60 @@
+package callback
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func TestHandleExternalComplete(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetWebhookToken("secret")
+	r.SetRetryPolicy(0, 0) // Email isn't configured, so delivery fails at once
+	r.callbacks["ci-build-42"] = &Callback{AgentID: "ci-build-42", AgentName: "CI", Method: "email", Status: "pending"}
+
+	post := func(token, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/callbacks/complete", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.HandleExternalComplete(w, req)
+		return w.Code
+	}
+
+	body := `{"agent_id": "ci-build-42", "result": "build passed"}`
+
+	if code := post("", body); code != http.StatusUnauthorized {
+		t.Errorf("missing token status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := post("wrong", body); code != http.StatusUnauthorized {
+		t.Errorf("wrong token status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := post("secret", `{"agent_id": "unknown"}`); code != http.StatusNotFound {
+		t.Errorf("unknown agent status = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := post("secret", body); code != http.StatusOK {
+		t.Fatalf("valid completion status = %d, want %d", code, http.StatusOK)
+	}
+
+	if r.Get("ci-build-42") != nil {
+		t.Error("callback should no longer be pending")
+	}
+	history := r.ListHistory()
+	if len(history) != 1 || history[0].AgentID != "ci-build-42" {
+		t.Errorf("expected completed callback in history, got %v", history)
+	}
+}
+
+func TestWebhookDisabledWithoutToken(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+
+	if r.validWebhookToken("") {
+		t.Error("webhook should reject requests when no token is configured")
+	}
+}
@@ -0,0 +1,56 @@
+package callback
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CompletionMetrics is what an agent's task cost to run
+type CompletionMetrics struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	LLMCalls     int     `json:"llm_calls"`
+	ToolCalls    int     `json:"tool_calls"`
+	CostUSD      float64 `json:"cost_usd"`
+	DurationMs   int64   `json:"duration_ms"`
+}
+
+// TotalTokens returns input plus output tokens
+func (m *CompletionMetrics) TotalTokens() int {
+	return m.InputTokens + m.OutputTokens
+}
+
+// Summary renders the metrics as one line, e.g.
+// "took 4m12s, cost $0.12, 12,345 tokens, 8 tool calls".
+// It returns "" for nil metrics.
+func (m *CompletionMetrics) Summary() string {
+	if m == nil {
+		return ""
+	}
+	var parts []string
+	if m.DurationMs > 0 {
+		d := time.Duration(m.DurationMs) * time.Millisecond
+		parts = append(parts, "took "+d.Round(time.Second).String())
+	}
+	if m.CostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("cost $%.2f", m.CostUSD))
+	}
+	if total := m.TotalTokens(); total > 0 {
+		parts = append(parts, groupThousands(total)+" tokens")
+	}
+	if m.ToolCalls > 0 {
+		parts = append(parts, fmt.Sprintf("%d tool calls", m.ToolCalls))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// groupThousands formats n with comma separators
+func groupThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,19 @@
+package callback
+
+import "testing"
+
+func TestCompletionMetricsSummary(t *testing.T) {
+	var none *CompletionMetrics
+	if got := none.Summary(); got != "" {
+		t.Errorf("nil Summary() = %q, want empty", got)
+	}
+
+	m := &CompletionMetrics{InputTokens: 10000, OutputTokens: 2345, ToolCalls: 8, CostUSD: 0.123, DurationMs: 252400}
+	if got, want := m.Summary(), "took 4m12s, cost $0.12, 12,345 tokens, 8 tool calls"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	if got, want := (&CompletionMetrics{InputTokens: 999}).Summary(), "999 tokens"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
@@ -1,15 +1,22 @@
 package callback
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/everydev1618/tron/internal/email"
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/persist"
+	"github.com/everydev1618/tron/internal/sms"
+	"github.com/everydev1618/tron/internal/textutil"
 	"github.com/everydev1618/tron/internal/vapi"
 )
 
@@ -20,16 +27,40 @@ type Callback struct {
 	AgentName     string    `json:"agent_name"`
 	TaskSummary   string    `json:"task_summary"`
 	ProjectName   string    `json:"project_name"`
-	Method        string    `json:"method"` // "call", "email", or "both"
+	Method        string    `json:"method"` // "call", "email", "both" (call and email), "sms", "all", or "webhook"
 	CustomerPhone string    `json:"customer_phone,omitempty"`
 	CustomerEmail string    `json:"customer_email,omitempty"`
 	CustomerName  string    `json:"customer_name,omitempty"`
+	WebhookURL    string    `json:"webhook_url,omitempty"` // Where "webhook" callbacks are POSTed
 	PersonaName   string    `json:"persona_name"`
 	RequestedAt   time.Time `json:"requested_at"`
+	ExpiresAt     time.Time `json:"expires_at,omitempty"` // When it's given up on if the agent never completes
 	CompletedAt   time.Time `json:"completed_at,omitempty"`
-	Status        string    `json:"status"` // "pending", "completed", "failed", "orphaned"
+	Status        string    `json:"status"` // "pending", "retrying", "completed", "failed", "orphaned", "expired"
 	Error         string    `json:"error,omitempty"`
 	GroupID       string    `json:"group_id,omitempty"`
+	Summarize     bool      `json:"summarize,omitempty"` // condense long results before delivery
+	CallID        string    `json:"call_id,omitempty"`   // VAPI's ID for the callback call
+
+	// Delivery retries (see SetRetryPolicy). Completion is how the agent
+	// finished, kept while a retry is due so it survives a restart; the
+	// retry sends Condensed, so the full result text isn't kept.
+	Attempts    int             `json:"attempts,omitempty"`
+	NextRetryAt time.Time       `json:"next_retry_at,omitempty"`
+	Delivered   []string        `json:"delivered,omitempty"` // Legs that went through, e.g. "email"
+	Completion  *CompletionInfo `json:"completion,omitempty"`
+
+	// The result as sent to people, condensed before the first attempt
+	Condensed *CondensedResult `json:"condensed,omitempty"`
+
+	// Engagement tracking (see SetTrackingURL)
+	TrackingToken string    `json:"tracking_token,omitempty"`
+	ViewURL       string    `json:"view_url,omitempty"`
+	Engagement    string    `json:"engagement,omitempty"` // "viewed", "answered", "voicemail", "missed"
+	EngagedAt     time.Time `json:"engaged_at,omitempty"`
+
+	// Set while an attempt is being delivered
+	delivering bool
 }
 
 // CallbackGroup represents a batch of callbacks that complete together
@@ -46,6 +77,15 @@ type CallbackGroup struct {
 	CompletedAt   time.Time                 `json:"completed_at,omitempty"`
 	Status        string                    `json:"status"`
 	Error         string                    `json:"error,omitempty"`
+	CallID        string                    `json:"call_id,omitempty"`
+
+	// Delivery retries, as for Callback
+	Attempts    int       `json:"attempts,omitempty"`
+	NextRetryAt time.Time `json:"next_retry_at,omitempty"`
+	Delivered   []string  `json:"delivered,omitempty"`
+
+	// Set while an attempt is being delivered
+	delivering bool
 }
 
 // CompletionInfo contains the result of a completed agent
@@ -55,6 +95,8 @@ type CompletionInfo struct {
 	Result      string `json:"result"`
 	ProjectName string `json:"project_name"`
 	Error       string `json:"error,omitempty"`
+
+	Metrics *CompletionMetrics `json:"metrics,omitempty"`
 }
 
 // AgentInfo contains info for batch registration
@@ -75,15 +117,71 @@ type Registry struct {
 
 	vapiClient     *vapi.Client
 	emailClient    *email.Client
+	smsNotifier    *sms.Notifier
 	getServerURL   func(projectName string) string
 	agentValidator func(agentID string) bool
 	baseDir        string
-	personaName    string
+	store          persist.Store
+	personaName    string // Stamped on callbacks registered without a persona
 	personaEmail   string
+	logger         logging.Logger
+
+	// Shared secret for the external completion webhook
+	webhookToken string
+
+	// Signs webhook callbacks ("" = unsigned)
+	webhookSecret string
+
+	// Public base URL for engagement tracking ("" = off)
+	trackingURL string
+
+	// Cc, Bcc and Reply-To added to every callback email
+	emailRouting email.Routing
+
+	// Condenses long results for callbacks that opt in
+	summarizer memory.Summarizer
+
+	// Reads an agent's full result from where it's stored (see SetResultLookup)
+	lookupResult func(agentID string) (string, bool)
+
+	// Wording of SMS callbacks (nil uses the built-ins)
+	templates *notification.Templates
+
+	// Told about callback calls (see SetCallRecorder)
+	recordCall func(CallRecord)
+
+	// Failed deliveries are retried this many times, waiting retryBackoff
+	// and doubling (see SetRetryPolicy)
+	maxRetries   int
+	retryBackoff time.Duration
+	stopSweep    chan struct{}
+	startOnce    sync.Once
+
+	// Attempts being delivered without the lock; Close waits for them
+	deliveries sync.WaitGroup
+
+	// Pending callbacks expire this long after they're requested
+	callbackTTL time.Duration
+
+	// Set by Close; pending callbacks are kept for the next start
+	closed    bool
+	closeOnce sync.Once
+	closeDone chan struct{}
 }
 
-// NewRegistry creates a new callback registry
+// callbacksKey is where the registry's state is saved
+const callbacksKey = "tron.work/callbacks.json"
+
+// NewRegistry creates a new callback registry that saves its state in
+// files under baseDir
 func NewRegistry(vapiClient *vapi.Client, emailClient *email.Client, baseDir, personaName, personaEmail string) *Registry {
+	return NewRegistryWithStore(vapiClient, emailClient, baseDir, persist.NewFileStore(baseDir), personaName, personaEmail)
+}
+
+// NewRegistryWithStore creates a new callback registry that saves its state
+// in store. Full results of condensed callbacks are still written as files
+// under baseDir, since their paths are sent to the recipient.
+func NewRegistryWithStore(vapiClient *vapi.Client, emailClient *email.Client, baseDir string, store persist.Store, personaName, personaEmail string) *Registry {
 	r := &Registry{
 		callbacks:    make(map[string]*Callback),
 		groups:       make(map[string]*CallbackGroup),
@@ -92,16 +190,38 @@ func NewRegistry(vapiClient *vapi.Client, emailClient *email.Client, baseDir, pe
 		vapiClient:   vapiClient,
 		emailClient:  emailClient,
 		baseDir:      baseDir,
+		store:        store,
 		personaName:  personaName,
 		personaEmail: personaEmail,
+		logger:       logging.New("callback"),
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+		stopSweep:    make(chan struct{}),
+		callbackTTL:  DefaultCallbackTTL,
 	}
 
-	// Load persisted callbacks
+	// Load persisted callbacks; stale ones are expired by Start, once the
+	// TTL and delivery settings are in place
 	r.load()
 
 	return r
 }
 
+// Start expires callbacks a previous run left stuck and begins retrying
+// failed deliveries and expiring stale callbacks in the background. Call it
+// once the registry is configured; until then nothing expires or retries.
+func (r *Registry) Start() {
+	r.startOnce.Do(func() {
+		r.expireStale(time.Now())
+		go r.sweepLoop(r.stopSweep)
+	})
+}
+
+// SetLogger replaces the registry's logger
+func (r *Registry) SetLogger(l logging.Logger) {
+	r.logger = l
+}
+
 // SetServerURLFunc sets the function to get server URLs for projects
 func (r *Registry) SetServerURLFunc(fn func(projectName string) string) {
 	r.getServerURL = fn
@@ -113,31 +233,28 @@ func (r *Registry) SetAgentValidator(fn func(agentID string) bool) {
 	r.cleanupOrphaned()
 }
 
-// Register creates a new callback request
-func (r *Registry) Register(agentID, agentName, taskSummary, projectName, method, phone, emailAddr, customerName string) (*Callback, error) {
+// Register creates a new callback request made by persona, who the
+// callback call, email and text come from ("" uses the registry's default
+// persona). webhookURL is where "webhook" callbacks are POSTed and is
+// ignored by the other methods.
+func (r *Registry) Register(persona, agentID, agentName, taskSummary, projectName, method, phone, emailAddr, customerName, webhookURL string) (*Callback, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Validate method requirements
-	if method == "call" || method == "both" {
-		if phone == "" {
-			return nil, fmt.Errorf("phone number required for call callback")
-		}
-		if r.vapiClient == nil || !r.vapiClient.IsConfigured() {
-			return nil, fmt.Errorf("VAPI not configured for call callbacks")
-		}
+	if r.closed {
+		return nil, ErrClosed
 	}
-	if method == "email" || method == "both" {
-		if emailAddr == "" {
-			return nil, fmt.Errorf("email address required for email callback")
-		}
-		if r.emailClient == nil || !r.emailClient.IsConfigured() {
-			return nil, fmt.Errorf("email not configured for email callbacks")
-		}
+
+	if method != "webhook" {
+		webhookURL = ""
+	}
+	if err := r.validateMethod(method, phone, emailAddr, webhookURL); err != nil {
+		return nil, err
 	}
 
+	now := time.Now()
 	cb := &Callback{
-		ID:            fmt.Sprintf("cb-%s-%d", agentID, time.Now().UnixNano()),
+		ID:            fmt.Sprintf("cb-%s-%d", agentID, now.UnixNano()),
 		AgentID:       agentID,
 		AgentName:     agentName,
 		TaskSummary:   taskSummary,
@@ -146,8 +263,10 @@ func (r *Registry) Register(agentID, agentName, taskSummary, projectName, method
 		CustomerPhone: phone,
 		CustomerEmail: emailAddr,
 		CustomerName:  customerName,
-		PersonaName:   r.personaName,
-		RequestedAt:   time.Now(),
+		WebhookURL:    webhookURL,
+		PersonaName:   r.personaOrDefault(persona),
+		RequestedAt:   now,
+		ExpiresAt:     r.expiry(now),
 		Status:        "pending",
 	}
 
@@ -157,31 +276,126 @@ func (r *Registry) Register(agentID, agentName, taskSummary, projectName, method
 	return cb, nil
 }
 
-// RegisterBatch creates a group callback for multiple agents
-func (r *Registry) RegisterBatch(agents []AgentInfo, method, phone, emailAddr, customerName string) (*CallbackGroup, error) {
+// personaOrDefault returns persona, or the registry's default if it's empty
+func (r *Registry) personaOrDefault(persona string) string {
+	if persona != "" {
+		return persona
+	}
+	return r.personaName
+}
+
+// validateMethod checks that a method has the recipient details it needs
+// and that its channel is configured. Caller must hold the lock.
+func (r *Registry) validateMethod(method, phone, emailAddr, webhookURL string) error {
+	for _, leg := range methodLegs(method) {
+		switch leg {
+		case "call":
+			if phone == "" {
+				return fmt.Errorf("phone number required for call callback")
+			}
+			if r.vapiClient == nil || !r.vapiClient.IsConfigured() {
+				return fmt.Errorf("VAPI not configured for call callbacks")
+			}
+		case "email":
+			if emailAddr == "" {
+				return fmt.Errorf("email address required for email callback")
+			}
+			if r.emailClient == nil || !r.emailClient.IsConfigured() {
+				return fmt.Errorf("email not configured for email callbacks")
+			}
+		case "sms":
+			if phone == "" {
+				return fmt.Errorf("phone number required for SMS callback")
+			}
+			if !r.smsNotifier.IsConfigured() {
+				return fmt.Errorf("SMS not configured for SMS callbacks")
+			}
+		case "webhook":
+			if err := validWebhookURL(webhookURL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Update changes how a pending callback is delivered, keeping its link to
+// the running agent. Empty arguments keep the current value. The result is
+// validated like Register; callbacks that already fired can't be changed.
+func (r *Registry) Update(agentID, method, phone, emailAddr, customerName string) (*Callback, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Validate method requirements (same as single)
-	if method == "call" || method == "both" {
-		if phone == "" {
-			return nil, fmt.Errorf("phone number required for call callback")
-		}
+	if r.closed {
+		return nil, ErrClosed
 	}
-	if method == "email" || method == "both" {
-		if emailAddr == "" {
-			return nil, fmt.Errorf("email address required for email callback")
+
+	cb, ok := r.callbacks[agentID]
+	if !ok {
+		for _, done := range r.history {
+			if done.AgentID == agentID {
+				return nil, fmt.Errorf("callback for agent %s already %s", agentID, done.Status)
+			}
 		}
+		return nil, fmt.Errorf("%w %s", ErrUnknownAgent, agentID)
+	}
+	if cb.GroupID != "" {
+		return nil, fmt.Errorf("callback for agent %s is part of batch %s and can't be changed on its own", agentID, cb.GroupID)
+	}
+	if cb.delivering {
+		return nil, fmt.Errorf("callback for agent %s is being delivered", agentID)
+	}
+
+	updated := *cb
+	if method != "" {
+		updated.Method = method
+	}
+	if phone != "" {
+		updated.CustomerPhone = phone
+	}
+	if emailAddr != "" {
+		updated.CustomerEmail = emailAddr
+	}
+	if customerName != "" {
+		updated.CustomerName = customerName
+	}
+	if err := r.validateMethod(updated.Method, updated.CustomerPhone, updated.CustomerEmail, updated.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	*cb = updated
+	r.persist()
+	return cb, nil
+}
+
+// RegisterBatch creates a group callback for multiple agents, made by
+// persona as for Register
+func (r *Registry) RegisterBatch(persona string, agents []AgentInfo, method, phone, emailAddr, customerName string) (*CallbackGroup, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, ErrClosed
 	}
 
-	groupID := fmt.Sprintf("grp-%d", time.Now().UnixNano())
+	// Validate method requirements (same as single)
+	if slices.Contains(methodLegs(method), "webhook") {
+		return nil, fmt.Errorf("webhook callbacks can't be batched; register one per agent")
+	}
+	if err := r.validateMethod(method, phone, emailAddr, ""); err != nil {
+		return nil, err
+	}
+
+	persona = r.personaOrDefault(persona)
+	now := time.Now()
+	groupID := fmt.Sprintf("grp-%d", now.UnixNano())
 	agentIDs := make([]string, len(agents))
 
 	// Create individual callbacks pointing to group
 	for i, agent := range agents {
 		agentIDs[i] = agent.ID
 		cb := &Callback{
-			ID:            fmt.Sprintf("cb-%s-%d", agent.ID, time.Now().UnixNano()),
+			ID:            fmt.Sprintf("cb-%s-%d", agent.ID, now.UnixNano()),
 			AgentID:       agent.ID,
 			AgentName:     agent.Name,
 			TaskSummary:   agent.TaskSummary,
@@ -190,8 +404,9 @@ func (r *Registry) RegisterBatch(agents []AgentInfo, method, phone, emailAddr, c
 			CustomerPhone: phone,
 			CustomerEmail: emailAddr,
 			CustomerName:  customerName,
-			PersonaName:   r.personaName,
-			RequestedAt:   time.Now(),
+			PersonaName:   persona,
+			RequestedAt:   now,
+			ExpiresAt:     r.expiry(now),
 			Status:        "pending",
 			GroupID:       groupID,
 		}
@@ -206,8 +421,8 @@ func (r *Registry) RegisterBatch(agents []AgentInfo, method, phone, emailAddr, c
 		CustomerPhone: phone,
 		CustomerEmail: emailAddr,
 		CustomerName:  customerName,
-		PersonaName:   r.personaName,
-		RequestedAt:   time.Now(),
+		PersonaName:   persona,
+		RequestedAt:   now,
 		Status:        "pending",
 	}
 
@@ -219,64 +434,165 @@ func (r *Registry) RegisterBatch(agents []AgentInfo, method, phone, emailAddr, c
 
 // OnAgentComplete is called when an agent finishes
 func (r *Registry) OnAgentComplete(info CompletionInfo) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	condensed := r.condenseFor(info.AgentID, info.Result)
 
+	r.mu.Lock()
 	cb, ok := r.callbacks[info.AgentID]
-	if !ok {
-		return // No callback registered
+	if !ok || r.closed || cb.Status != "pending" || cb.delivering {
+		r.mu.Unlock()
+		return // No callback registered, left pending for the next start, or already delivering
 	}
+	due := r.complete(cb, info, condensed)
+	r.mu.Unlock()
 
+	r.deliver(due)
+}
+
+// complete records a finished agent and starts its callback or, for groups,
+// the group callback once every member is done. condensed is the result
+// from condenseFor, if any. The attempt started is returned for deliver.
+// Caller must hold the lock.
+func (r *Registry) complete(cb *Callback, info CompletionInfo, condensed *CondensedResult) attempts {
+	var due attempts
 	if cb.GroupID != "" {
 		// Part of a group - record result
 		group, ok := r.groups[cb.GroupID]
-		if !ok {
-			return
+		if !ok || group.delivering {
+			return due
 		}
 
-		group.Results[info.AgentID] = info
+		group.Results[info.AgentID] = r.withoutStoredResult(info)
 
 		// Check if all agents in group are done
 		if len(group.Results) == len(group.AgentIDs) {
-			r.executeGroupCallback(group)
+			due.groups = append(due.groups, r.startGroup(group))
 		}
 	} else {
 		// Single callback
-		r.executeCallback(cb, info)
+		if cb.Condensed == nil {
+			cb.Condensed = condensed
+		}
+		due.callbacks = append(due.callbacks, r.startCallback(cb, info))
 	}
 
 	r.persist()
+	return due
+}
+
+// attempts are delivery attempts started with the lock held
+type attempts struct {
+	callbacks []*callbackAttempt
+	groups    []*groupAttempt
+}
+
+// deliver makes each attempt without the lock, so a slow recipient doesn't
+// block the registry, and records how it went under the lock again
+func (r *Registry) deliver(due attempts) {
+	for _, a := range due.callbacks {
+		r.deliverCallback(a)
+
+		r.mu.Lock()
+		r.finishCallback(a)
+		r.persist()
+		r.mu.Unlock()
+	}
+	for _, a := range due.groups {
+		r.deliverGroup(a)
+
+		r.mu.Lock()
+		r.finishGroup(a)
+		r.persist()
+		r.mu.Unlock()
+	}
+}
+
+// callbackAttempt is one try at delivering a single callback. It's started
+// and recorded with the lock held, but delivers on a copy of the callback
+// so it can run without the lock.
+type callbackAttempt struct {
+	cb   *Callback      // The registered callback
+	snap Callback       // Copy the delivery works on
+	info CompletionInfo // The agent's full result
+	err  error
 }
 
-func (r *Registry) executeCallback(cb *Callback, info CompletionInfo) {
+// startCallback claims cb for a delivery attempt. Caller must hold the lock.
+func (r *Registry) startCallback(cb *Callback, info CompletionInfo) *callbackAttempt {
 	cb.CompletedAt = time.Now()
+	cb.Attempts++
+	if cb.Condensed == nil {
+		// Not condensed up front, e.g. saved by an older version.
+		// Summarizing here would hold the lock, so truncate.
+		cb.Condensed = r.condenseResult(cb.ID, cb.AgentID, nil, info.Result)
+	}
+	cb.delivering = true
+	r.deliveries.Add(1)
+
+	a := &callbackAttempt{cb: cb, snap: *cb, info: info}
+	a.snap.Delivered = slices.Clone(cb.Delivered)
+	return a
+}
 
-	var execErr error
-	switch cb.Method {
-	case "call":
-		execErr = r.executeCall(cb, info)
-	case "email":
-		execErr = r.executeEmail(cb, info)
-	case "both":
-		if err := r.executeCall(cb, info); err != nil {
-			execErr = err
-		}
-		if err := r.executeEmail(cb, info); err != nil {
-			if execErr != nil {
-				execErr = fmt.Errorf("call: %v; email: %v", execErr, err)
-			} else {
-				execErr = err
-			}
+// deliverCallback runs the legs of an attempt that haven't gone through
+// yet. It doesn't need the lock.
+func (r *Registry) deliverCallback(a *callbackAttempt) {
+	cb := &a.snap
+	info := a.info
+	info.Result = cb.Condensed.Result
+
+	a.err = deliverLegs(cb.Method, &cb.Delivered, func(leg string) error {
+		switch leg {
+		case "call":
+			return r.executeCall(cb, info)
+		case "email":
+			return r.executeEmail(cb, info, cb.Condensed.FullPath != "")
+		case "sms":
+			return r.executeSMS(cb.CustomerPhone, r.smsBody(cb, info))
+		case "webhook":
+			// Machines get the whole result, not the condensed one
+			return r.executeWebhook(cb, a.info)
 		}
-	}
+		return nil
+	})
+}
 
-	if execErr != nil {
+// finishCallback records how an attempt went. A failed delivery is retried
+// later while the retry policy allows, and only then marked failed. Caller
+// must hold the lock.
+func (r *Registry) finishCallback(a *callbackAttempt) {
+	defer r.deliveries.Done()
+
+	cb := a.cb
+	cb.delivering = false
+	cb.Delivered = a.snap.Delivered
+	cb.CallID = a.snap.CallID
+	cb.TrackingToken = a.snap.TrackingToken
+	cb.ViewURL = a.snap.ViewURL
+	if r.callbacks[cb.AgentID] != cb {
+		return // Cancelled while it was being delivered
+	}
+
+	if a.err != nil {
+		cb.Error = a.err.Error()
+		if next, ok := r.nextRetry(cb.Attempts, time.Now()); ok {
+			// Retries send the condensed result, so the full text isn't kept
+			completion := a.info
+			completion.Result = ""
+			cb.Status = "retrying"
+			cb.NextRetryAt = next
+			cb.Completion = &completion
+			r.logger.Warnf("Callback for agent %s failed (attempt %d), retrying at %s: %v", cb.AgentID, cb.Attempts, next.Format(time.RFC3339), a.err)
+			return
+		}
 		cb.Status = "failed"
-		cb.Error = execErr.Error()
-		log.Printf("Callback failed for agent %s: %v", cb.AgentID, execErr)
+		r.logger.Errorf("Callback failed for agent %s after %d attempts: %v", cb.AgentID, cb.Attempts, a.err)
 	} else {
 		cb.Status = "completed"
+		cb.Error = ""
 	}
+	cb.NextRetryAt = time.Time{}
+	cb.Completion = nil
+	cb.Condensed = nil
 
 	// Move to history
 	delete(r.callbacks, cb.AgentID)
@@ -286,35 +602,84 @@ func (r *Registry) executeCallback(cb *Callback, info CompletionInfo) {
 	}
 }
 
-func (r *Registry) executeGroupCallback(group *CallbackGroup) {
+// groupAttempt is one try at delivering a group, like callbackAttempt
+type groupAttempt struct {
+	group *CallbackGroup
+	snap  CallbackGroup
+	err   error
+}
+
+// startGroup claims a group for a delivery attempt. Caller must hold the lock.
+func (r *Registry) startGroup(group *CallbackGroup) *groupAttempt {
 	group.CompletedAt = time.Now()
+	group.Attempts++
+	group.delivering = true
+	r.deliveries.Add(1)
+
+	a := &groupAttempt{group: group, snap: *group}
+	a.snap.AgentIDs = slices.Clone(group.AgentIDs)
+	a.snap.Results = maps.Clone(group.Results)
+	for agentID, info := range a.snap.Results {
+		a.snap.Results[agentID] = r.withStoredResult(info)
+	}
+	a.snap.Delivered = slices.Clone(group.Delivered)
+	return a
+}
 
-	var execErr error
-	switch group.Method {
-	case "call":
-		execErr = r.executeBatchCall(group)
-	case "email":
-		execErr = r.executeBatchEmail(group)
-	case "both":
-		if err := r.executeBatchCall(group); err != nil {
-			execErr = err
+// deliverGroup runs the legs of a group attempt that haven't gone through
+// yet. It doesn't need the lock.
+func (r *Registry) deliverGroup(a *groupAttempt) {
+	group := &a.snap
+	a.err = deliverLegs(group.Method, &group.Delivered, func(leg string) error {
+		switch leg {
+		case "call":
+			return r.executeBatchCall(group)
+		case "email":
+			return r.executeBatchEmail(group)
+		case "sms":
+			return r.executeSMS(group.CustomerPhone, batchSMSBody(group))
 		}
-		if err := r.executeBatchEmail(group); err != nil {
-			if execErr != nil {
-				execErr = fmt.Errorf("call: %v; email: %v", execErr, err)
-			} else {
-				execErr = err
+		return nil
+	})
+}
+
+// finishGroup records how a group attempt went, retrying like
+// finishCallback. Caller must hold the lock.
+func (r *Registry) finishGroup(a *groupAttempt) {
+	defer r.deliveries.Done()
+
+	group := a.group
+	group.delivering = false
+	group.Delivered = a.snap.Delivered
+	if a.snap.CallID != group.CallID {
+		// Members carry the call ID so its outcome can be matched later
+		group.CallID = a.snap.CallID
+		for _, agentID := range group.AgentIDs {
+			if cb, ok := r.callbacks[agentID]; ok {
+				cb.CallID = group.CallID
 			}
 		}
 	}
-
-	if execErr != nil {
+	if a.err != nil {
+		group.Error = a.err.Error()
+		if next, ok := r.nextRetry(group.Attempts, time.Now()); ok {
+			group.Status = "retrying"
+			group.NextRetryAt = next
+			for _, agentID := range group.AgentIDs {
+				if cb, ok := r.callbacks[agentID]; ok {
+					cb.Status = "retrying"
+				}
+			}
+			r.logger.Warnf("Group callback %s failed (attempt %d), retrying at %s: %v", group.ID, group.Attempts, next.Format(time.RFC3339), a.err)
+			return
+		}
 		group.Status = "failed"
-		group.Error = execErr.Error()
-		log.Printf("Group callback failed: %v", execErr)
+		r.logger.Errorf("Group callback %s failed after %d attempts: %v", group.ID, group.Attempts, a.err)
 	} else {
 		group.Status = "completed"
+		group.Error = ""
 	}
+	group.NextRetryAt = time.Time{}
 
 	// Clean up individual callbacks
 	for _, agentID := range group.AgentIDs {
@@ -343,53 +708,138 @@ func (r *Registry) executeCall(cb *Callback, info CompletionInfo) error {
 	}
 
 	ctx := &vapi.CallbackContext{
+		PersonaName: cb.PersonaName,
 		AgentName:   cb.AgentName,
 		TaskSummary: cb.TaskSummary,
 		Result:      info.Result,
 		ProjectName: cb.ProjectName,
+		Stats:       info.Metrics.Summary(),
 	}
 
-	// Truncate phone for logging
-	phone := cb.CustomerPhone
-	if len(phone) > 6 {
-		phone = phone[:3] + "***" + phone[len(phone)-4:]
-	}
-	log.Printf("Initiating callback call to %s for agent %s", phone, cb.AgentID)
+	r.logger.Infof("Initiating callback call to %s for agent %s", maskPhone(cb.CustomerPhone), cb.AgentID)
 
-	_, err := r.vapiClient.Call(nil, cb.CustomerPhone, cb.CustomerName, ctx)
-	return err
+	resp, err := r.vapiClient.Call(nil, cb.CustomerPhone, cb.CustomerName, ctx)
+	if err != nil {
+		r.emitCall(cb, CallFailed, err.Error(), 0)
+		return err
+	}
+	if resp != nil {
+		cb.CallID = resp.ID
+	}
+	r.emitCall(cb, CallPlaced, "", 0)
+	return nil
 }
 
-func (r *Registry) executeEmail(cb *Callback, info CompletionInfo) error {
+func (r *Registry) executeEmail(cb *Callback, info CompletionInfo, shortened bool) error {
 	if r.emailClient == nil || !r.emailClient.IsConfigured() {
 		return fmt.Errorf("email client not configured")
 	}
 
 	var viewURL string
 	if r.getServerURL != nil && cb.ProjectName != "" {
-		viewURL = r.getServerURL(cb.ProjectName)
+		viewURL = r.trackedViewURL(cb, r.getServerURL(cb.ProjectName))
 	}
 
 	ctx := &email.CallbackContext{
-		RecipientName:  cb.CustomerName,
-		RecipientEmail: cb.CustomerEmail,
-		AgentID:        cb.AgentID,
-		AgentName:      cb.AgentName,
-		TaskSummary:    cb.TaskSummary,
-		ProjectName:    cb.ProjectName,
-		Result:         info.Result,
-		Error:          info.Error,
-		ViewURL:        viewURL,
-		Success:        info.Error == "",
+		PersonaName:     cb.PersonaName,
+		RecipientName:   cb.CustomerName,
+		RecipientEmail:  cb.CustomerEmail,
+		AgentID:         cb.AgentID,
+		AgentName:       cb.AgentName,
+		TaskSummary:     cb.TaskSummary,
+		ProjectName:     cb.ProjectName,
+		Result:          info.Result,
+		Error:           info.Error,
+		ViewURL:         viewURL,
+		ResultShortened: shortened,
+		Stats:           info.Metrics.Summary(),
+		Success:         info.Error == "",
+		Routing:         r.emailRouting,
 	}
 
 	return r.emailClient.SendTaskComplete(ctx)
 }
 
+// executeBatchCall makes one call summarizing every agent in a group,
+// failed ones included
 func (r *Registry) executeBatchCall(group *CallbackGroup) error {
-	// For batch calls, we make a single call summarizing all results
-	// This is a simplification - could be expanded to make individual calls
-	return fmt.Errorf("batch calls not yet implemented")
+	if r.vapiClient == nil || !r.vapiClient.IsConfigured() {
+		return fmt.Errorf("VAPI client not configured")
+	}
+
+	rollup := newBatchRollup(group)
+	ctx := &vapi.CallbackContext{
+		PersonaName: group.PersonaName,
+		AgentName:   "your team",
+		TaskSummary: fmt.Sprintf("%d tasks", len(group.AgentIDs)),
+		Result:      strings.Join(rollup.lines, "\n"),
+		Stats:       rollup.counts(),
+		Variables: map[string]string{
+			"succeeded": strconv.Itoa(rollup.succeeded),
+			"failed":    strconv.Itoa(rollup.failed),
+			"rollup":    textutil.Shorten(rollup.counts()+". "+strings.Join(rollup.lines, " "), batchRollupLength),
+		},
+	}
+
+	// Stands in for the group in the call recorder
+	record := &Callback{
+		AgentID:       group.ID,
+		AgentName:     fmt.Sprintf("%d agents", len(group.AgentIDs)),
+		PersonaName:   group.PersonaName,
+		TaskSummary:   ctx.TaskSummary,
+		CustomerPhone: group.CustomerPhone,
+	}
+
+	r.logger.Infof("Initiating batch callback call to %s for group %s", maskPhone(group.CustomerPhone), group.ID)
+
+	resp, err := r.vapiClient.Call(context.Background(), group.CustomerPhone, group.CustomerName, ctx)
+	if err != nil {
+		r.emitCall(record, CallFailed, err.Error(), 0)
+		return err
+	}
+	if resp != nil {
+		record.CallID = resp.ID
+		group.CallID = resp.ID
+	}
+	r.emitCall(record, CallPlaced, "", 0)
+	return nil
+}
+
+const (
+	// batchLineLength caps each agent's line in a batch call
+	batchLineLength = 80
+
+	// batchRollupLength caps the rollup variable read out on a batch call
+	batchRollupLength = 500
+)
+
+// batchRollup tallies a group's results for a batch call
+type batchRollup struct {
+	succeeded, failed int
+	lines             []string // One per agent, in registration order
+}
+
+func newBatchRollup(group *CallbackGroup) batchRollup {
+	var b batchRollup
+	for _, id := range group.AgentIDs {
+		info, ok := group.Results[id]
+		if !ok {
+			continue
+		}
+		if info.Error != "" {
+			b.failed++
+			b.lines = append(b.lines, fmt.Sprintf("%s failed: %s", info.AgentName, textutil.Shorten(info.Error, batchLineLength)))
+		} else {
+			b.succeeded++
+			b.lines = append(b.lines, fmt.Sprintf("%s finished: %s", info.AgentName, textutil.Shorten(info.Result, batchLineLength)))
+		}
+	}
+	return b
+}
+
+// counts summarizes the tally, e.g. "2 succeeded, 1 failed"
+func (b batchRollup) counts() string {
+	return fmt.Sprintf("%d succeeded, %d failed", b.succeeded, b.failed)
 }
 
 func (r *Registry) executeBatchEmail(group *CallbackGroup) error {
@@ -411,24 +861,35 @@ func (r *Registry) executeBatchEmail(group *CallbackGroup) error {
 	results := make([]email.AgentResult, 0, len(group.Results))
 	for _, info := range group.Results {
 		results = append(results, email.AgentResult{
-			AgentID:     info.AgentID,
-			AgentName:   info.AgentName,
-			Result:      info.Result,
-			Error:       info.Error,
-			Success:     info.Error == "",
+			AgentID:   info.AgentID,
+			AgentName: info.AgentName,
+			Result:    info.Result,
+			Error:     info.Error,
+			Stats:     info.Metrics.Summary(),
+			Success:   info.Error == "",
 		})
 	}
 
 	ctx := &email.BatchCallbackContext{
+		PersonaName:    group.PersonaName,
 		RecipientName:  group.CustomerName,
 		RecipientEmail: group.CustomerEmail,
 		Results:        results,
 		ViewURL:        viewURL,
+		Routing:        r.emailRouting,
 	}
 
 	return r.emailClient.SendBatchComplete(ctx)
 }
 
+// SetEmailRouting sets the Cc, Bcc and Reply-To used on callback emails,
+// e.g. to copy a team inbox and route customer replies to a monitored one
+func (r *Registry) SetEmailRouting(routing email.Routing) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emailRouting = routing
+}
+
 // Get returns a pending callback by agent ID
 func (r *Registry) Get(agentID string) *Callback {
 	r.mu.RLock()
@@ -481,56 +942,37 @@ func (r *Registry) CanEmail() bool {
 	return r.emailClient != nil && r.emailClient.IsConfigured()
 }
 
-func (r *Registry) persist() {
-	path := filepath.Join(r.baseDir, "tron.work", "callbacks.json")
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Printf("Failed to create callbacks directory: %v", err)
-		return
-	}
+// CanSMS returns true if SMS callbacks are available
+func (r *Registry) CanSMS() bool {
+	return r.smsNotifier.IsConfigured()
+}
 
-	data := struct {
-		Callbacks    map[string]*Callback      `json:"callbacks"`
-		Groups       map[string]*CallbackGroup `json:"groups"`
-		History      []*Callback               `json:"history"`
-		GroupHistory []*CallbackGroup          `json:"group_history"`
-	}{
+// registryState is the registry's saved state
+type registryState struct {
+	Callbacks    map[string]*Callback      `json:"callbacks"`
+	Groups       map[string]*CallbackGroup `json:"groups"`
+	History      []*Callback               `json:"history"`
+	GroupHistory []*CallbackGroup          `json:"group_history"`
+}
+
+func (r *Registry) persist() {
+	data := registryState{
 		Callbacks:    r.callbacks,
 		Groups:       r.groups,
 		History:      r.history,
 		GroupHistory: r.groupHistory,
 	}
-
-	content, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		log.Printf("Failed to marshal callbacks: %v", err)
-		return
-	}
-
-	if err := os.WriteFile(path, content, 0644); err != nil {
-		log.Printf("Failed to persist callbacks: %v", err)
+	if err := persist.SaveJSON(r.store, callbacksKey, data); err != nil {
+		r.logger.Errorf("Failed to persist callbacks: %v", err)
 	}
 }
 
 func (r *Registry) load() {
-	path := filepath.Join(r.baseDir, "tron.work", "callbacks.json")
-	content, err := os.ReadFile(path)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			log.Printf("Failed to load callbacks: %v", err)
-		}
+	var data registryState
+	if ok, err := persist.LoadJSON(r.store, callbacksKey, &data); err != nil {
+		r.logger.Errorf("Failed to load callbacks: %v", err)
 		return
-	}
-
-	var data struct {
-		Callbacks    map[string]*Callback      `json:"callbacks"`
-		Groups       map[string]*CallbackGroup `json:"groups"`
-		History      []*Callback               `json:"history"`
-		GroupHistory []*CallbackGroup          `json:"group_history"`
-	}
-
-	if err := json.Unmarshal(content, &data); err != nil {
-		log.Printf("Failed to parse callbacks: %v", err)
+	} else if !ok {
 		return
 	}
 
@@ -557,7 +999,8 @@ func (r *Registry) cleanupOrphaned() {
 	defer r.mu.Unlock()
 
 	for agentID, cb := range r.callbacks {
-		if !r.agentValidator(agentID) {
+		// Callbacks awaiting a retry have outlived their agents on purpose
+		if cb.Status == "pending" && !r.agentValidator(agentID) {
 			cb.Status = "orphaned"
 			r.history = append(r.history, cb)
 			delete(r.callbacks, agentID)
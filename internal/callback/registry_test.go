@@ -0,0 +1,72 @@
+package callback
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/vapi"
+)
+
+func TestBatchRollup(t *testing.T) {
+	group := &CallbackGroup{
+		AgentIDs: []string{"a1", "a2", "a3"},
+		Results: map[string]CompletionInfo{
+			"a3": {AgentName: "Maya", Error: "timed out"},
+			"a1": {AgentName: "Gary", Result: "Deployed the site. " + strings.Repeat("details ", 50)},
+		},
+	}
+
+	rollup := newBatchRollup(group)
+	if rollup.counts() != "1 succeeded, 1 failed" {
+		t.Errorf("counts() = %q", rollup.counts())
+	}
+	if len(rollup.lines) != 2 || !strings.HasPrefix(rollup.lines[0], "Gary finished: Deployed the site.") || rollup.lines[1] != "Maya failed: timed out" {
+		t.Errorf("lines = %q, want one per finished agent in order", rollup.lines)
+	}
+	if len(rollup.lines[0]) > len("Gary finished: ")+batchLineLength {
+		t.Errorf("line not shortened: %d bytes", len(rollup.lines[0]))
+	}
+}
+
+func TestBatchCallFailureIsRecorded(t *testing.T) {
+	r := NewRegistry(vapi.NewClient("key", "phone", "asst"), nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(0, 0)
+
+	group, err := r.RegisterBatch("", []AgentInfo{{ID: "a1", Name: "Gary"}, {ID: "a2", Name: "Maya"}}, "call", "+15550102000", "", "Sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A client with no phone number can't place the call
+	r.vapiClient = vapi.NewClient("key", "", "asst")
+	r.OnAgentComplete(CompletionInfo{AgentID: "a1", AgentName: "Gary", Result: "done"})
+	r.OnAgentComplete(CompletionInfo{AgentID: "a2", AgentName: "Maya", Error: "boom"})
+
+	if group.Status != "failed" || !strings.Contains(group.Error, "VAPI client not configured") {
+		t.Errorf("group = %s %q, want failed for the unconfigured client", group.Status, group.Error)
+	}
+	if strings.Contains(group.Error, "not yet implemented") {
+		t.Error("batch calls still unimplemented")
+	}
+}
+
+func TestRegisterStampsPersona(t *testing.T) {
+	r := NewRegistry(vapi.NewClient("key", "phone", "asst"), nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+
+	cb, err := r.Register("Maya", "agent-1", "Gary", "deploy", "", "call", "+15550102000", "", "Sam", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cb.PersonaName != "Maya" {
+		t.Errorf("PersonaName = %q, want the registering persona Maya", cb.PersonaName)
+	}
+	group, err := r.RegisterBatch("", []AgentInfo{{ID: "a1"}, {ID: "a2"}}, "call", "+15550102000", "", "Sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.PersonaName != "Tony" || r.callbacks["a1"].PersonaName != "Tony" {
+		t.Errorf("batch persona = %q, want the default Tony", group.PersonaName)
+	}
+}
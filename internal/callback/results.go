@@ -0,0 +1,35 @@
+package callback
+
+// SetResultLookup lets the registry read agents' full results from where
+// they're already stored, such as the spawn result store, rather than keep
+// its own copy while a batch waits for its other agents or a retry. lookup
+// returns an agent's full result, or false if it doesn't have all of it.
+func (r *Registry) SetResultLookup(lookup func(agentID string) (string, bool)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookupResult = lookup
+}
+
+// withoutStoredResult drops info's result if the lookup can supply it
+// again. Caller must hold the lock.
+func (r *Registry) withoutStoredResult(info CompletionInfo) CompletionInfo {
+	if r.lookupResult == nil || info.Result == "" {
+		return info
+	}
+	if stored, ok := r.lookupResult(info.AgentID); ok && stored == info.Result {
+		info.Result = ""
+	}
+	return info
+}
+
+// withStoredResult fills in a result dropped by withoutStoredResult.
+// Caller must hold the lock.
+func (r *Registry) withStoredResult(info CompletionInfo) CompletionInfo {
+	if r.lookupResult == nil || info.Result != "" || info.Error != "" {
+		return info
+	}
+	if stored, ok := r.lookupResult(info.AgentID); ok {
+		info.Result = stored
+	}
+	return info
+}
@@ -0,0 +1,36 @@
+package callback
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/sms"
+)
+
+func TestBatchReadsResultsFromLookup(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	provider := &stubSMS{}
+	r.SetSMSNotifier(sms.NewNotifier(provider))
+	stored := map[string]string{"a1": "Deployed the site."}
+	r.SetResultLookup(func(agentID string) (string, bool) {
+		result, ok := stored[agentID]
+		return result, ok
+	})
+
+	group, err := r.RegisterBatch("", []AgentInfo{{ID: "a1", Name: "Gary"}, {ID: "a2", Name: "Maya"}}, "sms", "+15551234567", "", "Sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "a1", AgentName: "Gary", Result: "Deployed the site."})
+	if got := group.Results["a1"].Result; got != "" {
+		t.Errorf("waiting group kept the stored result %q", got)
+	}
+
+	// A result the lookup doesn't have is kept as is
+	r.OnAgentComplete(CompletionInfo{AgentID: "a2", AgentName: "Maya", Result: "Wrote the copy."})
+	if !strings.Contains(provider.body, "Gary: Deployed the site.") || !strings.Contains(provider.body, "Maya: Wrote the copy.") {
+		t.Errorf("SMS body = %q, want both results", provider.body)
+	}
+}
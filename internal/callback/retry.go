@@ -0,0 +1,140 @@
+package callback
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is how many times a failed delivery is retried
+	DefaultMaxRetries = 3
+
+	// DefaultRetryBackoff is the wait before the first retry; it doubles
+	// with each further attempt
+	DefaultRetryBackoff = time.Minute
+
+	// maxRetryBackoff caps the wait between attempts
+	maxRetryBackoff = time.Hour
+
+	// sweepInterval is how often due retries and stale callbacks are handled
+	sweepInterval = 15 * time.Second
+)
+
+// SetRetryPolicy sets how many times a failed delivery is retried and the
+// wait before the first retry, which doubles with each attempt. Zero
+// retries marks a callback failed on its first error.
+func (r *Registry) SetRetryPolicy(maxRetries int, backoff time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	r.maxRetries = max(maxRetries, 0)
+	r.retryBackoff = backoff
+}
+
+// nextRetry returns when to try again after a delivery's attempts-th
+// failure, or false once its retries are used up. Caller must hold the lock.
+func (r *Registry) nextRetry(attempts int, now time.Time) (time.Time, bool) {
+	if attempts > r.maxRetries {
+		return time.Time{}, false
+	}
+	delay := r.retryBackoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return now.Add(min(delay, maxRetryBackoff)), true
+}
+
+// sweepLoop retries due deliveries and expires stale callbacks until stop
+// is closed
+func (r *Registry) sweepLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			r.retryDue(now)
+			r.expireStale(now)
+		}
+	}
+}
+
+// retryDue makes another attempt at every delivery whose retry time has come.
+// The attempts are started with the lock held and delivered without it.
+func (r *Registry) retryDue(now time.Time) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+
+	var due attempts
+	for _, cb := range r.callbacks {
+		if cb.Status == "retrying" && cb.GroupID == "" && cb.Completion != nil && !cb.delivering && !now.Before(cb.NextRetryAt) {
+			r.logger.Infof("Retrying callback for agent %s (attempt %d)", cb.AgentID, cb.Attempts+1)
+			due.callbacks = append(due.callbacks, r.startCallback(cb, *cb.Completion))
+		}
+	}
+	for _, group := range r.groups {
+		if group.Status == "retrying" && !group.delivering && !now.Before(group.NextRetryAt) {
+			r.logger.Infof("Retrying group callback %s (attempt %d)", group.ID, group.Attempts+1)
+			due.groups = append(due.groups, r.startGroup(group))
+		}
+	}
+	r.mu.Unlock()
+
+	r.deliver(due)
+}
+
+// methodLegs lists the deliveries a callback method makes
+func methodLegs(method string) []string {
+	switch method {
+	case "both":
+		return []string{"call", "email"}
+	case "all":
+		return []string{"call", "email", "sms"}
+	}
+	return []string{method}
+}
+
+// deliverLegs runs each leg of a method not already delivered, recording
+// the ones that succeed in delivered so a retry doesn't repeat them. It
+// returns the failures combined, or nil if every leg went through.
+func deliverLegs(method string, delivered *[]string, deliver func(leg string) error) error {
+	var failures []string
+	var lastErr error
+	for _, leg := range methodLegs(method) {
+		if containsLeg(*delivered, leg) {
+			continue
+		}
+		if err := deliver(leg); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", leg, err))
+			lastErr = err
+			continue
+		}
+		*delivered = append(*delivered, leg)
+	}
+
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return lastErr
+	default:
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+}
+
+func containsLeg(legs []string, leg string) bool {
+	for _, l := range legs {
+		if l == leg {
+			return true
+		}
+	}
+	return false
+}
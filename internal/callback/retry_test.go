@@ -0,0 +1,186 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/sms"
+)
+
+// flakySMS fails its first sends
+type flakySMS struct {
+	failures int
+	sent     int
+}
+
+func (s *flakySMS) IsConfigured() bool { return true }
+
+func (s *flakySMS) Send(ctx context.Context, to, body string) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("provider returned 500")
+	}
+	s.sent++
+	return nil
+}
+
+func TestFailedCallbackIsRetried(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(nil, nil, dir, "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(3, time.Minute)
+	provider := &flakySMS{failures: 2}
+	r.SetSMSNotifier(sms.NewNotifier(provider))
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "done"})
+
+	cb := r.Get("agent-1")
+	if cb == nil || cb.Status != "retrying" || cb.Attempts != 1 {
+		t.Fatalf("after first failure: %+v, want retrying after 1 attempt", cb)
+	}
+	first := cb.NextRetryAt
+
+	// Not due yet
+	r.retryDue(first.Add(-time.Second))
+	if cb.Attempts != 1 {
+		t.Errorf("retried before NextRetryAt")
+	}
+
+	// The attempt count and what's sent survive a restart; the full result
+	// isn't kept alongside
+	reloaded := NewRegistry(nil, nil, dir, "Tony", "")
+	reloaded.SetLogger(logging.Discard())
+	got := reloaded.Get("agent-1")
+	if got == nil || got.Attempts != 1 || got.Completion == nil || got.Condensed == nil || got.Condensed.Result != "done" {
+		t.Fatalf("reloaded callback = %+v", got)
+	}
+	if got.Completion.Result != "" {
+		t.Errorf("retrying callback kept the full result %q", got.Completion.Result)
+	}
+
+	r.retryDue(first)
+	if cb.Attempts != 2 || cb.Status != "retrying" {
+		t.Fatalf("after second failure: status %s attempts %d", cb.Status, cb.Attempts)
+	}
+	if backoff := time.Until(cb.NextRetryAt); backoff < 2*time.Minute-time.Second {
+		t.Errorf("second backoff = %s, want it doubled", backoff)
+	}
+
+	r.retryDue(cb.NextRetryAt)
+	if r.Get("agent-1") != nil || provider.sent != 1 {
+		t.Fatalf("third attempt should deliver: sent %d", provider.sent)
+	}
+	if h := r.ListHistory(); len(h) != 1 || h[0].Status != "completed" || h[0].Attempts != 3 || h[0].Completion != nil {
+		t.Errorf("history = %+v", h[0])
+	}
+}
+
+func TestCallbackFailsAfterMaxRetries(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(1, time.Minute)
+	r.SetSMSNotifier(sms.NewNotifier(&flakySMS{failures: 10}))
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "done"})
+	r.retryDue(time.Now().Add(time.Hour))
+
+	if h := r.ListHistory(); len(h) != 1 || h[0].Status != "failed" || h[0].Attempts != 2 {
+		t.Errorf("history = %+v, want failed after 2 attempts", h)
+	}
+}
+
+func TestDeliverLegsSkipsDelivered(t *testing.T) {
+	var delivered []string
+	var calls []string
+	fail := map[string]bool{"call": true}
+	deliver := func(leg string) error {
+		calls = append(calls, leg)
+		if fail[leg] {
+			return errors.New("busy")
+		}
+		return nil
+	}
+
+	if err := deliverLegs("both", &delivered, deliver); err == nil || err.Error() != "busy" {
+		t.Errorf("first attempt err = %v", err)
+	}
+	fail["call"] = false
+	if err := deliverLegs("both", &delivered, deliver); err != nil {
+		t.Errorf("retry err = %v", err)
+	}
+	if want := []string{"call", "email", "call"}; len(calls) != 3 || calls[2] != "call" {
+		t.Errorf("legs run = %v, want %v (email not resent)", calls, want)
+	}
+}
+
+// blockingSMS fails its first send and holds the next until released
+type blockingSMS struct {
+	sends   int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSMS) IsConfigured() bool { return true }
+
+func (s *blockingSMS) Send(ctx context.Context, to, body string) error {
+	s.sends++
+	if s.sends == 1 {
+		return errors.New("provider returned 500")
+	}
+	close(s.started)
+	<-s.release
+	return nil
+}
+
+func TestRetryDeliversWithoutLock(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(3, time.Minute)
+	provider := &blockingSMS{started: make(chan struct{}), release: make(chan struct{})}
+	r.SetSMSNotifier(sms.NewNotifier(provider))
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "done"})
+	due := r.Get("agent-1").NextRetryAt
+
+	retried := make(chan struct{})
+	go func() {
+		r.retryDue(due)
+		close(retried)
+	}()
+	<-provider.started
+
+	// The registry stays usable while the retry is in flight, and a second
+	// sweep doesn't start it again
+	if got := r.Pending(); got != 1 {
+		t.Errorf("Pending() = %d during retry, want 1", got)
+	}
+	r.retryDue(due)
+
+	// Close waits for the delivery
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := r.Close(ctx); err == nil {
+		t.Error("Close returned while a retry was being delivered")
+	}
+
+	close(provider.release)
+	<-retried
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if h := r.ListHistory(); len(h) != 1 || h[0].Status != "completed" || provider.sends != 2 {
+		t.Errorf("history = %+v after %d sends, want completed after 2", h, provider.sends)
+	}
+}
@@ -0,0 +1,48 @@
+package callback
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned once the registry has been closed for shutdown
+var ErrClosed = errors.New("callback registry is shutting down")
+
+// Close stops accepting callbacks and retries, waits for a delivery in
+// progress to finish and persists what is still pending, including
+// deliveries awaiting a retry, so it survives a restart. Completions
+// arriving after Close are not delivered. It returns ctx.Err() if a delivery
+// is still running when ctx is done.
+func (r *Registry) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() {
+		r.closeDone = make(chan struct{})
+		close(r.stopSweep)
+		go func() {
+			r.mu.Lock()
+			r.closed = true
+			r.mu.Unlock()
+
+			// No attempts start once closed is set
+			r.deliveries.Wait()
+
+			r.mu.Lock()
+			r.persist()
+			r.mu.Unlock()
+			close(r.closeDone)
+		}()
+	})
+
+	select {
+	case <-r.closeDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pending returns the number of callbacks still waiting on their agents
+func (r *Registry) Pending() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.callbacks)
+}
@@ -0,0 +1,53 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func TestCloseKeepsPendingCallbacks(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(nil, nil, dir, "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.callbacks["agent-1"] = &Callback{AgentID: "agent-1", AgentName: "Maya", Method: "email", Status: "pending"}
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if _, err := r.Register("", "agent-2", "Alex", "task", "", "email", "", "a@example.com", "", ""); !errors.Is(err, ErrClosed) {
+		t.Errorf("Register() after Close = %v, want ErrClosed", err)
+	}
+	if err := r.CompleteExternal("agent-1", CompletionInfo{Result: "done"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("CompleteExternal() after Close = %v, want ErrClosed", err)
+	}
+
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", Result: "done"})
+	if r.Pending() != 1 {
+		t.Errorf("Pending() = %d, want the callback left pending", r.Pending())
+	}
+}
+
+func TestCloseTimesOutDuringDelivery(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+
+	// Simulate a delivery holding the lock
+	r.mu.Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The registry still closes once the delivery finishes
+	r.mu.Unlock()
+	if err := r.Close(context.Background()); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+}
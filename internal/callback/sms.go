@@ -0,0 +1,95 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/sms"
+)
+
+// smsTimeout bounds a single SMS send
+const smsTimeout = 30 * time.Second
+
+// SetSMSNotifier enables "sms" callbacks
+func (r *Registry) SetSMSNotifier(n *sms.Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.smsNotifier = n
+}
+
+// executeSMS texts a callback result. Messages held for quiet hours count
+// as delivered.
+func (r *Registry) executeSMS(phone, body string) error {
+	if !r.smsNotifier.IsConfigured() {
+		return fmt.Errorf("SMS not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), smsTimeout)
+	defer cancel()
+
+	deferred, err := r.smsNotifier.Notify(ctx, phone, body)
+	if err != nil {
+		return err
+	}
+	if deferred {
+		r.logger.Infof("SMS callback held until quiet hours end")
+	}
+	return nil
+}
+
+// SetTemplates sets the templates used for SMS callback texts
+func (r *Registry) SetTemplates(t *notification.Templates) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates = t
+}
+
+// smsBody is the text for a single agent's result, with a link to the
+// project if it has a server. Stats and the link go before the result,
+// which may be cut to fit the message. cb must be a delivery attempt's copy
+// (see trackedViewURL).
+func (r *Registry) smsBody(cb *Callback, info CompletionInfo) string {
+	var viewURL string
+	if r.getServerURL != nil && cb.ProjectName != "" {
+		viewURL = r.trackedViewURL(cb, r.getServerURL(cb.ProjectName))
+	}
+
+	body, err := r.templates.Render(notification.TemplateSMSComplete, notification.CompletionData{
+		Persona:       cb.PersonaName,
+		RecipientName: cb.CustomerName,
+		AgentName:     cb.AgentName,
+		AgentID:       cb.AgentID,
+		Task:          cb.TaskSummary,
+		Project:       cb.ProjectName,
+		Success:       info.Error == "",
+		Result:        info.Result,
+		Error:         info.Error,
+		Stats:         info.Metrics.Summary(),
+		ViewURL:       viewURL,
+	})
+	if err != nil {
+		r.logger.Warnf("%v", err)
+	}
+	return body
+}
+
+// batchSMSBody is the text for a completed group, one line per agent
+func batchSMSBody(group *CallbackGroup) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Your team finished %d tasks.", len(group.Results)))
+	for _, id := range group.AgentIDs {
+		info, ok := group.Results[id]
+		if !ok {
+			continue
+		}
+		if info.Error != "" {
+			sb.WriteString(fmt.Sprintf("\n%s: failed (%s)", info.AgentName, info.Error))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n%s: %s", info.AgentName, info.Result))
+		}
+	}
+	return sb.String()
+}
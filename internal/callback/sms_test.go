@@ -0,0 +1,108 @@
+package callback
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/sms"
+)
+
+// stubSMS records sent texts
+type stubSMS struct {
+	to, body string
+}
+
+func (s *stubSMS) IsConfigured() bool { return true }
+
+func (s *stubSMS) Send(ctx context.Context, to, body string) error {
+	s.to, s.body = to, body
+	return nil
+}
+
+func TestSMSCallback(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy the site", "", "sms", "+15551234567", "", "Sam", ""); err == nil {
+		t.Fatal("expected an error registering sms without a provider")
+	}
+	if _, err := r.RegisterBatch("", []AgentInfo{{ID: "a1"}}, "sms", "+15551234567", "", "Sam"); err == nil || !strings.Contains(err.Error(), "SMS not configured") {
+		t.Fatalf("RegisterBatch(sms) err = %v, want the missing provider rejected", err)
+	}
+
+	provider := &stubSMS{}
+	r.SetSMSNotifier(sms.NewNotifier(provider))
+	if _, err := r.Register("", "agent-1", "Gary", "deploy the site", "", "sms", "", "", "Sam", ""); err == nil {
+		t.Fatal("expected an error registering sms without a phone")
+	}
+	if _, err := r.Register("", "agent-1", "Gary", "deploy the site", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: strings.Repeat("all good ", 100)})
+
+	if provider.to != "+15551234567" {
+		t.Errorf("texted %q, want the customer phone", provider.to)
+	}
+	if !strings.HasPrefix(provider.body, `Gary finished "deploy the site"`) || len(provider.body) > sms.MaxLength {
+		t.Errorf("body = %q (%d chars)", provider.body, len(provider.body))
+	}
+	if h := r.ListHistory(); len(h) != 1 || h[0].Status != "completed" {
+		t.Errorf("history = %+v, want one completed callback", h)
+	}
+}
+
+func TestSMSCallbackIncludesMetrics(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	provider := &stubSMS{}
+	r.SetSMSNotifier(sms.NewNotifier(provider))
+	if _, err := r.Register("", "agent-1", "Gary", "deploy the site", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	r.OnAgentComplete(CompletionInfo{
+		AgentID:   "agent-1",
+		AgentName: "Gary",
+		Result:    "deployed",
+		Metrics:   &CompletionMetrics{InputTokens: 1000, ToolCalls: 2, CostUSD: 0.05, DurationMs: 60000},
+	})
+
+	if !strings.Contains(provider.body, "took 1m0s, cost $0.05") {
+		t.Errorf("body = %q, want the run's stats", provider.body)
+	}
+}
+
+func TestSMSCallbackLinksProject(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	provider := &stubSMS{}
+	r.SetSMSNotifier(sms.NewNotifier(provider))
+	r.SetServerURLFunc(func(project string) string { return "https://" + project + ".example.com" })
+	if _, err := r.Register("", "agent-1", "Gary", "deploy the site", "blog", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "deployed"})
+
+	if want := `Gary finished "deploy the site" https://blog.example.com: deployed`; provider.body != want {
+		t.Errorf("body = %q, want %q", provider.body, want)
+	}
+}
+
+func TestAllMethodNeedsEveryChannel(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetSMSNotifier(sms.NewNotifier(&stubSMS{}))
+
+	// SMS alone is configured, so the call leg of "all" can't go out
+	_, err := r.Register("", "agent-1", "Gary", "deploy", "", "all", "+15551234567", "sam@example.com", "Sam", "")
+	if err == nil || !strings.Contains(err.Error(), "VAPI not configured") {
+		t.Errorf("Register(all) err = %v, want the call leg rejected", err)
+	}
+	if _, err := r.RegisterBatch("", []AgentInfo{{ID: "a1"}}, "all", "+15551234567", "sam@example.com", "Sam"); err == nil || !strings.Contains(err.Error(), "VAPI not configured") {
+		t.Errorf("RegisterBatch(all) err = %v, want the call leg rejected", err)
+	}
+}
@@ -0,0 +1,97 @@
+package callback
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/persist"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+const (
+	// summaryThreshold is the result length above which results are condensed
+	summaryThreshold = 1500
+
+	// summaryTimeout bounds a single summarizer call
+	summaryTimeout = 30 * time.Second
+)
+
+// SetSummarizer sets the summarizer used for long results on callbacks that opt in
+func (r *Registry) SetSummarizer(s memory.Summarizer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summarizer = s
+}
+
+// SetSummarize opts a pending callback in or out of result summarization.
+// Returns false if no callback is pending for the agent.
+func (r *Registry) SetSummarize(agentID string, enabled bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cb, ok := r.callbacks[agentID]
+	if !ok {
+		return false
+	}
+	cb.Summarize = enabled
+	r.persist()
+	return true
+}
+
+// CondensedResult is a callback's result as sent to people, worked out once
+// before the first delivery so retries reuse it
+type CondensedResult struct {
+	Result   string `json:"result"`
+	FullPath string `json:"full_path,omitempty"` // Where the full text was saved, if it was cut
+}
+
+// condenseFor condenses the result for agentID's pending callback ahead of
+// completing it. It runs without the lock, since summarizing can take a
+// while. Returns nil if the agent has no pending single callback.
+func (r *Registry) condenseFor(agentID, result string) *CondensedResult {
+	r.mu.RLock()
+	cb, ok := r.callbacks[agentID]
+	if !ok || cb.Status != "pending" || cb.GroupID != "" {
+		r.mu.RUnlock()
+		return nil
+	}
+	id := cb.ID
+	var summarizer memory.Summarizer
+	if cb.Summarize {
+		summarizer = r.summarizer
+	}
+	r.mu.RUnlock()
+
+	return r.condenseResult(id, agentID, summarizer, result)
+}
+
+// condenseResult shortens a long result for delivery and saves the full text
+// under the callback's ID. It summarizes with summarizer if one is given,
+// and truncates otherwise.
+func (r *Registry) condenseResult(id, agentID string, summarizer memory.Summarizer, result string) *CondensedResult {
+	if len(result) <= summaryThreshold {
+		return &CondensedResult{Result: result}
+	}
+
+	path := filepath.Join(r.baseDir, "tron.work", "results", id+".md")
+	if err := persist.WriteFile(path, []byte(result)); err != nil {
+		r.logger.Errorf("Failed to save full result: %v", err)
+		path = ""
+	}
+
+	if summarizer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+		defer cancel()
+
+		summary, err := summarizer.Summarize(ctx, result)
+		if err == nil && strings.TrimSpace(summary) != "" {
+			return &CondensedResult{Result: strings.TrimSpace(summary), FullPath: path}
+		}
+		r.logger.Warnf("Summarizer failed for agent %s, truncating instead: %v", agentID, err)
+	}
+
+	return &CondensedResult{Result: textutil.Shorten(result, summaryThreshold), FullPath: path}
+}
@@ -0,0 +1,95 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/sms"
+)
+
+func TestCondenseResult(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetSummarizer(memory.SummarizerFunc(func(ctx context.Context, text string) (string, error) {
+		return "Deployed the site.", nil
+	}))
+
+	long := strings.Repeat("log line\n", 500)
+
+	// Short results pass through untouched
+	if got := r.condenseResult("cb-1", "agent-1", nil, "done"); got.Result != "done" || got.FullPath != "" {
+		t.Errorf("short result = %+v", got)
+	}
+
+	// Without a summarizer, long results are truncated
+	got := r.condenseResult("cb-2", "agent-2", nil, long)
+	if len(got.Result) > summaryThreshold || !strings.HasSuffix(got.Result, "...") {
+		t.Errorf("expected truncated result, got %d bytes", len(got.Result))
+	}
+	if data, err := os.ReadFile(got.FullPath); err != nil || string(data) != long {
+		t.Errorf("full result should be saved to %q: %v", got.FullPath, err)
+	}
+
+	// With opt-in, the summarizer is used
+	r.callbacks["agent-3"] = &Callback{ID: "cb-3", AgentID: "agent-3", Status: "pending", Summarize: true}
+	if got := r.condenseFor("agent-3", long); got == nil || got.Result != "Deployed the site." {
+		t.Errorf("expected summary, got %+v", got)
+	}
+
+	// A failing summarizer falls back to truncation
+	r.SetSummarizer(memory.SummarizerFunc(func(ctx context.Context, text string) (string, error) {
+		return "", errors.New("model unavailable")
+	}))
+	if got := r.condenseFor("agent-3", long); got == nil || !strings.HasSuffix(got.Result, "...") {
+		t.Errorf("expected truncation fallback, got %+v", got)
+	}
+}
+
+func TestSummarizeOnceWithoutLock(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(3, time.Minute)
+	r.SetSMSNotifier(sms.NewNotifier(&flakySMS{failures: 1}))
+
+	calls := 0
+	r.SetSummarizer(memory.SummarizerFunc(func(ctx context.Context, text string) (string, error) {
+		calls++
+		// The registry stays usable while the summarizer runs
+		done := make(chan struct{})
+		go func() {
+			r.ListPending()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("registry locked while summarizing")
+		}
+		return "Deployed the site.", nil
+	}))
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+	r.SetSummarize("agent-1", true)
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: strings.Repeat("log line\n", 500)})
+
+	cb := r.Get("agent-1")
+	if cb == nil || cb.Status != "retrying" || cb.Condensed == nil || cb.Condensed.Result != "Deployed the site." {
+		t.Fatalf("after failed first attempt: %+v", cb)
+	}
+
+	r.retryDue(cb.NextRetryAt)
+	if h := r.ListHistory(); len(h) != 1 || h[0].Status != "completed" {
+		t.Fatalf("history = %+v, want the retry delivered", h)
+	}
+	if calls != 1 {
+		t.Errorf("summarizer called %d times, want once", calls)
+	}
+}
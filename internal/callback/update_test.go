@@ -0,0 +1,54 @@
+package callback
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/email"
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/sms"
+)
+
+func TestUpdatePendingCallback(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(nil, email.NewClient("smtp.example.com", 587, "", "", "tony@example.com"), dir, "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetSMSNotifier(sms.NewNotifier(&stubSMS{}))
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy the site", "", "sms", "+15551234567", "", "Sam", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Switching to email needs an address
+	if _, err := r.Update("agent-1", "email", "", "", ""); err == nil {
+		t.Fatal("expected an error switching to email without an address")
+	}
+	if cb := r.Get("agent-1"); cb.Method != "sms" {
+		t.Fatalf("failed update changed the method to %q", cb.Method)
+	}
+
+	cb, err := r.Update("agent-1", "email", "", "sam@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cb.Method != "email" || cb.CustomerEmail != "sam@example.com" || cb.CustomerPhone != "+15551234567" || cb.CustomerName != "Sam" {
+		t.Errorf("updated callback = %+v", cb)
+	}
+
+	// Persisted across a restart
+	reloaded := NewRegistry(nil, nil, dir, "Tony", "")
+	reloaded.SetLogger(logging.Discard())
+	if got := reloaded.Get("agent-1"); got == nil || got.Method != "email" {
+		t.Errorf("reloaded callback = %+v", got)
+	}
+
+	if _, err := r.Update("agent-2", "email", "", "sam@example.com", ""); !errors.Is(err, ErrUnknownAgent) {
+		t.Errorf("unknown agent error = %v", err)
+	}
+
+	r.history = append(r.history, &Callback{AgentID: "agent-3", Status: "completed"})
+	if _, err := r.Update("agent-3", "sms", "+15551234567", "", ""); err == nil || !strings.Contains(err.Error(), "already completed") {
+		t.Errorf("completed callback error = %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+	"github.com/everydev1618/tron/internal/webhook"
+)
+
+// webhookTimeout bounds a single webhook delivery
+const webhookTimeout = 30 * time.Second
+
+// Headers sent with webhook callbacks. The signature is webhook.Default's:
+// a hex HMAC-SHA256 of "<timestamp>.<body>" under the webhook secret.
+const (
+	WebhookSignatureHeader = "X-Tron-Signature"
+	WebhookTimestampHeader = "X-Tron-Timestamp"
+)
+
+// WebhookPayload is the JSON body POSTed to a webhook callback's URL. It
+// describes the agent's work and leaves out the callback's recipient
+// details and tracking token.
+type WebhookPayload struct {
+	CallbackID  string             `json:"callback_id"`
+	AgentID     string             `json:"agent_id"`
+	AgentName   string             `json:"agent_name"`
+	TaskSummary string             `json:"task_summary,omitempty"`
+	ProjectName string             `json:"project_name,omitempty"`
+	Success     bool               `json:"success"`
+	Result      string             `json:"result,omitempty"` // In full, never condensed
+	Error       string             `json:"error,omitempty"`
+	Metrics     *CompletionMetrics `json:"metrics,omitempty"`
+	Attempt     int                `json:"attempt"`
+	CompletedAt time.Time          `json:"completed_at"`
+}
+
+// newWebhookPayload builds the payload for cb's delivery of info
+func newWebhookPayload(cb *Callback, info CompletionInfo) WebhookPayload {
+	return WebhookPayload{
+		CallbackID:  cb.ID,
+		AgentID:     cb.AgentID,
+		AgentName:   cb.AgentName,
+		TaskSummary: cb.TaskSummary,
+		ProjectName: cb.ProjectName,
+		Success:     info.Error == "",
+		Result:      info.Result,
+		Error:       info.Error,
+		Metrics:     info.Metrics,
+		Attempt:     cb.Attempts,
+		CompletedAt: cb.CompletedAt,
+	}
+}
+
+// blockedWebhookAddr reports whether webhooks must not be sent to addr.
+// Replaced in tests, which receive them on a loopback server.
+var blockedWebhookAddr = httpclient.BlockedAddr
+
+// SetWebhookSecret sets the secret webhook callbacks are signed with. An
+// empty secret sends them unsigned.
+func (r *Registry) SetWebhookSecret(secret string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhookSecret = secret
+}
+
+// validWebhookURL checks that a webhook callback target is an absolute
+// http or https URL that isn't an internal address. Hosts are resolved and
+// checked again when the webhook is sent.
+func validWebhookURL(target string) error {
+	if target == "" {
+		return fmt.Errorf("webhook URL required for webhook callback")
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid webhook URL %q: use an http or https URL", target)
+	}
+	if err := httpclient.CheckLiteralHost(u.Hostname(), blockedWebhookAddr); err != nil {
+		return fmt.Errorf("invalid webhook URL %q: %w", target, err)
+	}
+	return nil
+}
+
+// newWebhookClient returns the client for sending a webhook to u. Redirects
+// aren't followed, so a webhook can't be bounced to an internal address.
+func newWebhookClient(u *url.URL) *http.Client {
+	client := httpclient.New(webhookTimeout)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	client.Transport = httpclient.GuardedTransport(u, blockedWebhookAddr)
+	return client
+}
+
+// executeWebhook POSTs an agent's result to the callback's URL, refusing
+// URLs that resolve to internal addresses. Any response other than 2xx is a
+// failed delivery.
+func (r *Registry) executeWebhook(cb *Callback, info CompletionInfo) error {
+	u, err := url.Parse(cb.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	body, err := json.Marshal(newWebhookPayload(cb, info))
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	if err := httpclient.CheckHost(ctx, u.Hostname(), blockedWebhookAddr); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r.mu.RLock()
+	secret := r.webhookSecret
+	r.mu.RUnlock()
+	if secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, webhook.Default.Sign(body, timestamp, secret))
+	}
+
+	resp, err := newWebhookClient(u).Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package callback
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/webhook"
+)
+
+// allowLoopbackWebhooks lets webhooks reach httptest servers for the rest
+// of the test
+func allowLoopbackWebhooks(t *testing.T) {
+	orig := blockedWebhookAddr
+	blockedWebhookAddr = func(addr netip.Addr) bool {
+		return !addr.Unmap().IsLoopback() && orig(addr)
+	}
+	t.Cleanup(func() { blockedWebhookAddr = orig })
+}
+
+func TestWebhookCallbackIsSigned(t *testing.T) {
+	allowLoopbackWebhooks(t)
+
+	var r *Registry
+	var body []byte
+	var verifyErr error
+	unlocked := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ = io.ReadAll(req.Body)
+		verifyErr = webhook.Verify(body, req.Header.Get(WebhookTimestampHeader), req.Header.Get(WebhookSignatureHeader), "s3cret", time.Minute)
+
+		// The registry isn't locked while the webhook is sent
+		done := make(chan struct{})
+		go func() {
+			r.Pending()
+			close(done)
+		}()
+		select {
+		case <-done:
+			unlocked = true
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	r = NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetWebhookSecret("s3cret")
+	r.SetTrackingURL("https://tron.example.com")
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "blog", "webhook", "+15551234567", "sam@example.com", "Sam", srv.URL+"/hook"); err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "done", Metrics: &CompletionMetrics{DurationMs: 1500}})
+
+	if verifyErr != nil {
+		t.Errorf("signature didn't verify: %v", verifyErr)
+	}
+	if !unlocked {
+		t.Error("registry locked while the webhook was sent")
+	}
+
+	var got WebhookPayload
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.AgentID != "agent-1" || got.ProjectName != "blog" || !got.Success || got.Result != "done" || got.Attempt != 1 {
+		t.Errorf("payload = %+v", got)
+	}
+	if got.Metrics == nil || got.Metrics.DurationMs != 1500 {
+		t.Errorf("payload metrics = %+v", got.Metrics)
+	}
+	for _, private := range []string{"5551234567", "sam@example.com", "Sam", "tracking"} {
+		if strings.Contains(string(body), private) {
+			t.Errorf("payload leaks %q: %s", private, body)
+		}
+	}
+	if history := r.ListHistory(); len(history) != 1 || history[0].Status != "completed" {
+		t.Errorf("history = %+v, want completed", history)
+	}
+}
+
+func TestWebhookErrorStatusIsRetried(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	status := http.StatusBadGateway
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(3, time.Minute)
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "webhook", "", "", "", srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "done"})
+
+	cb := r.Get("agent-1")
+	if cb == nil || cb.Status != "retrying" || cb.Error != "webhook returned 502 Bad Gateway" {
+		t.Fatalf("after 502: %+v, want retrying", cb)
+	}
+
+	status = http.StatusNoContent
+	r.retryDue(cb.NextRetryAt)
+	if cb.Status != "completed" {
+		t.Errorf("after retry: status %s, want completed", cb.Status)
+	}
+}
+
+func TestWebhookNeedsURL(t *testing.T) {
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+
+	internal := []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data/", "http://10.0.0.5/hook", "http://[::1]/hook", "http://localhost/hook"}
+	for _, target := range append([]string{"", "ftp://example.com/hook", "/relative"}, internal...) {
+		if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "webhook", "", "", "", target); err == nil {
+			t.Errorf("Register(webhook, %q) succeeded, want an invalid URL error", target)
+		}
+	}
+	if _, err := r.RegisterBatch("", []AgentInfo{{ID: "a1"}}, "webhook", "", "", ""); err == nil {
+		t.Error("RegisterBatch(webhook) succeeded, want it refused")
+	}
+}
+
+func TestWebhookRefusesInternalRedirect(t *testing.T) {
+	allowLoopbackWebhooks(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "http://169.254.169.254/latest/meta-data/", http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	r := NewRegistry(nil, nil, t.TempDir(), "Tony", "")
+	r.SetLogger(logging.Discard())
+	r.SetRetryPolicy(0, time.Minute)
+
+	if _, err := r.Register("", "agent-1", "Gary", "deploy", "", "webhook", "", "", "", srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	r.OnAgentComplete(CompletionInfo{AgentID: "agent-1", AgentName: "Gary", Result: "done"})
+
+	if h := r.ListHistory(); len(h) != 1 || h[0].Status != "failed" || !strings.Contains(h[0].Error, "307") {
+		t.Errorf("history = %+v, want the redirect not followed", h)
+	}
+}
@@ -2,9 +2,15 @@ package email
 
 import (
 	"fmt"
+	"net/mail"
 	"net/smtp"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/textutil"
 )
 
 // Client handles email sending for callback notifications
@@ -14,6 +20,17 @@ type Client struct {
 	user     string
 	password string
 	from     string
+
+	// Per-persona From addresses
+	senders   map[string]*mail.Address
+	sendersMu sync.RWMutex
+
+	// Message layouts; nil uses the built-in wording
+	templates *notification.Templates
+	logger    logging.Logger
+
+	// Outbound queue; nil sends inline
+	queue *Queue
 }
 
 // NewClient creates a new email client
@@ -24,9 +41,44 @@ func NewClient(host string, port int, user, password, from string) *Client {
 		user:     user,
 		password: password,
 		from:     from,
+		senders:  make(map[string]*mail.Address),
+		logger:   logging.New("email"),
 	}
 }
 
+// SetTemplates sets the templates used for notification bodies
+func (c *Client) SetTemplates(t *notification.Templates) {
+	c.templates = t
+}
+
+// SetPersonaFrom sets the From address used for a persona's notifications.
+// The address may include a display name ("Maya <maya@example.com>"); if it
+// doesn't, the persona name is used.
+func (c *Client) SetPersonaFrom(persona, from string) error {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid From address for %s: %w", persona, err)
+	}
+	if addr.Name == "" {
+		addr.Name = persona
+	}
+
+	c.sendersMu.Lock()
+	defer c.sendersMu.Unlock()
+	c.senders[strings.ToLower(persona)] = addr
+	return nil
+}
+
+// fromFor returns the From header for a persona, or "" to use the default
+func (c *Client) fromFor(persona string) string {
+	c.sendersMu.RLock()
+	defer c.sendersMu.RUnlock()
+	if addr, ok := c.senders[strings.ToLower(persona)]; ok {
+		return addr.String()
+	}
+	return ""
+}
+
 // IsConfigured returns true if the client has required settings
 func (c *Client) IsConfigured() bool {
 	return c.host != "" && c.from != ""
@@ -34,16 +86,20 @@ func (c *Client) IsConfigured() bool {
 
 // CallbackContext contains data for single callback emails
 type CallbackContext struct {
-	RecipientName string
-	RecipientEmail string
-	AgentID       string
-	AgentName     string
-	TaskSummary   string
-	ProjectName   string
-	Result        string
-	Error         string
-	ViewURL       string
-	Success       bool
+	PersonaName     string
+	RecipientName   string
+	RecipientEmail  string
+	AgentID         string
+	AgentName       string
+	TaskSummary     string
+	ProjectName     string
+	Result          string
+	Error           string
+	ViewURL         string
+	ResultShortened bool   // Whether Result was cut short or summarized
+	Stats           string // One-line duration/cost/token summary, if known
+	Success         bool
+	Routing
 }
 
 // AgentResult contains result for a single agent in batch callbacks
@@ -54,39 +110,117 @@ type AgentResult struct {
 	ProjectName string
 	Result      string
 	Error       string
+	Stats       string
 	Success     bool
 }
 
 // BatchCallbackContext contains data for batch callback emails
 type BatchCallbackContext struct {
+	PersonaName    string
 	RecipientName  string
 	RecipientEmail string
 	Results        []AgentResult
 	ViewURL        string
+	Routing
+}
+
+// Routing holds optional extra recipients and a Reply-To for an email
+type Routing struct {
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+}
+
+// Message is an outgoing email
+type Message struct {
+	To      string   `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Bcc     []string `json:"bcc,omitempty"`
+	ReplyTo string   `json:"reply_to,omitempty"`
+	From    string   `json:"from,omitempty"` // "" uses the client default
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+}
+
+// ParseAddresses parses a comma-separated address list, as used for Cc and
+// Bcc settings. An empty string is an empty list.
+func ParseAddresses(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	addrs, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address list %q: %w", list, err)
+	}
+	out := make([]string, len(addrs))
+	for i, a := range addrs {
+		out[i] = a.String()
+	}
+	return out, nil
 }
 
-// SendTaskComplete sends an email notification for a completed task
+// Send sends a plain notification email from the default address
+func (c *Client) Send(to, subject, body string) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("email client not configured")
+	}
+	return c.send(Message{To: to, Subject: subject, Body: body})
+}
+
+// SendTaskComplete sends an email notification for a completed task. With
+// a queue enabled it returns once the mail is queued.
 func (c *Client) SendTaskComplete(ctx *CallbackContext) error {
 	if !c.IsConfigured() {
 		return fmt.Errorf("email client not configured")
 	}
+	return c.deliver(c.taskCompleteMessage(ctx), false)
+}
 
-	subject := c.buildSubject(ctx)
-	body := c.buildEmailBody(ctx)
+// SendTaskCompleteNow is SendTaskComplete, but always sends inline and
+// returns the SMTP result
+func (c *Client) SendTaskCompleteNow(ctx *CallbackContext) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("email client not configured")
+	}
+	return c.deliver(c.taskCompleteMessage(ctx), true)
+}
 
-	return c.send(ctx.RecipientEmail, subject, body, "")
+func (c *Client) taskCompleteMessage(ctx *CallbackContext) Message {
+	return Message{
+		To:      ctx.RecipientEmail,
+		Cc:      ctx.Cc,
+		Bcc:     ctx.Bcc,
+		ReplyTo: ctx.ReplyTo,
+		From:    c.fromFor(ctx.PersonaName),
+		Subject: c.buildSubject(ctx),
+		Body:    c.buildEmailBody(ctx),
+	}
 }
 
-// SendBatchComplete sends an email notification for multiple completed tasks
+// SendBatchComplete sends an email notification for multiple completed
+// tasks. With a queue enabled it returns once the mail is queued.
 func (c *Client) SendBatchComplete(ctx *BatchCallbackContext) error {
 	if !c.IsConfigured() {
 		return fmt.Errorf("email client not configured")
 	}
+	return c.deliver(Message{
+		To:      ctx.RecipientEmail,
+		Cc:      ctx.Cc,
+		Bcc:     ctx.Bcc,
+		ReplyTo: ctx.ReplyTo,
+		From:    c.fromFor(ctx.PersonaName),
+		Subject: c.buildBatchSubject(ctx),
+		Body:    c.buildBatchEmailBody(ctx),
+	}, false)
+}
 
-	subject := c.buildBatchSubject(ctx)
-	body := c.buildBatchEmailBody(ctx)
-
-	return c.send(ctx.RecipientEmail, subject, body, "")
+// deliver queues a message if a queue is enabled, or sends it inline
+func (c *Client) deliver(m Message, now bool) error {
+	if c.queue == nil || now {
+		return c.send(m)
+	}
+	_, err := c.queue.Enqueue(m)
+	return err
 }
 
 func (c *Client) buildSubject(ctx *CallbackContext) string {
@@ -97,11 +231,7 @@ func (c *Client) buildSubject(ctx *CallbackContext) string {
 		subject = fmt.Sprintf("%s failed: %s", ctx.AgentName, ctx.TaskSummary)
 	}
 
-	// Truncate if too long
-	if len(subject) > 50 {
-		subject = subject[:47] + "..."
-	}
-	return subject
+	return textutil.Shorten(subject, 50)
 }
 
 func (c *Client) buildBatchSubject(ctx *BatchCallbackContext) string {
@@ -122,116 +252,139 @@ func (c *Client) buildBatchSubject(ctx *BatchCallbackContext) string {
 }
 
 func (c *Client) buildEmailBody(ctx *CallbackContext) string {
-	var sb strings.Builder
+	return c.render(notification.TemplateEmailComplete, notification.CompletionData{
+		Persona:       signer(ctx.PersonaName),
+		RecipientName: ctx.RecipientName,
+		AgentName:     ctx.AgentName,
+		AgentID:       ctx.AgentID,
+		Task:          ctx.TaskSummary,
+		Project:       ctx.ProjectName,
+		Success:       ctx.Success,
+		Result:        ctx.Result,
+		Error:         ctx.Error,
+		Shortened:     ctx.ResultShortened,
+		Stats:         ctx.Stats,
+		ViewURL:       ctx.ViewURL,
+	})
+}
 
-	// Greeting
-	if ctx.RecipientName != "" {
-		sb.WriteString(fmt.Sprintf("Hey %s,\n\n", ctx.RecipientName))
-	} else {
-		sb.WriteString("Hey,\n\n")
+func (c *Client) buildBatchEmailBody(ctx *BatchCallbackContext) string {
+	data := notification.BatchData{
+		Persona:       signer(ctx.PersonaName),
+		RecipientName: ctx.RecipientName,
+		ViewURL:       ctx.ViewURL,
 	}
-
-	// Status
-	if ctx.Success {
-		sb.WriteString(fmt.Sprintf("%s has finished working on your task.\n\n", ctx.AgentName))
-	} else {
-		sb.WriteString(fmt.Sprintf("%s encountered an issue with your task.\n\n", ctx.AgentName))
+	for _, r := range ctx.Results {
+		data.Results = append(data.Results, notification.CompletionData{
+			Persona:       data.Persona,
+			RecipientName: ctx.RecipientName,
+			AgentName:     r.AgentName,
+			AgentID:       r.AgentID,
+			Task:          r.TaskSummary,
+			Project:       r.ProjectName,
+			Success:       r.Success,
+			Result:        r.Result,
+			Error:         r.Error,
+			Stats:         r.Stats,
+		})
 	}
+	return c.render(notification.TemplateBatchEmail, data)
+}
 
-	// Task details
-	sb.WriteString(fmt.Sprintf("**Task:** %s\n", ctx.TaskSummary))
-	if ctx.ProjectName != "" {
-		sb.WriteString(fmt.Sprintf("**Project:** %s\n", ctx.ProjectName))
+// render renders a notification body, logging a broken override (the
+// built-in template's text is used in its place)
+func (c *Client) render(name string, data any) string {
+	body, err := c.templates.Render(name, data)
+	if err != nil {
+		c.logger.Warnf("%v", err)
 	}
+	return body
+}
 
-	// Result or error
-	if ctx.Success && ctx.Result != "" {
-		sb.WriteString(fmt.Sprintf("\n**Result:**\n%s\n", ctx.Result))
-	} else if !ctx.Success && ctx.Error != "" {
-		sb.WriteString(fmt.Sprintf("\n**Error:**\n%s\n", ctx.Error))
+func (c *Client) send(m Message) error {
+	msg, recipients, envelopeFrom, err := c.compose(m, time.Now())
+	if err != nil {
+		return err
 	}
 
-	// View URL
-	if ctx.ViewURL != "" {
-		sb.WriteString(fmt.Sprintf("\nView the project: %s\n", ctx.ViewURL))
-	}
+	addr := fmt.Sprintf("%s:%d", c.host, c.port)
 
-	// Footer
-	sb.WriteString(fmt.Sprintf("\n---\nAgent ID: %s\nThis is an automated notification from Tony.\n", ctx.AgentID))
+	// Use auth if credentials provided
+	var auth smtp.Auth
+	if c.user != "" && c.password != "" {
+		auth = smtp.PlainAuth("", c.user, c.password, c.host)
+	}
 
-	return sb.String()
+	return smtp.SendMail(addr, auth, envelopeFrom, recipients, msg)
 }
 
-func (c *Client) buildBatchEmailBody(ctx *BatchCallbackContext) string {
-	var sb strings.Builder
+// compose builds the raw message and SMTP envelope. Every address is
+// parsed and re-encoded, and newlines are stripped from the subject, so
+// caller-supplied values can't inject headers. Bcc recipients get the mail
+// but aren't listed in the headers.
+func (c *Client) compose(m Message, date time.Time) (msg []byte, recipients []string, envelopeFrom string, err error) {
+	from := c.from
+	if m.From != "" {
+		from = m.From
+	}
 
-	// Greeting
-	if ctx.RecipientName != "" {
-		sb.WriteString(fmt.Sprintf("Hey %s,\n\n", ctx.RecipientName))
-	} else {
-		sb.WriteString("Hey,\n\n")
+	// The header keeps any display name; SMTP needs the bare address
+	envelopeFrom = from
+	if addr, err := mail.ParseAddress(from); err == nil {
+		envelopeFrom = addr.Address
 	}
 
-	sb.WriteString("Your tasks have been completed. Here's a summary:\n\n")
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid To address %q: %w", m.To, err)
+	}
+	recipients = append(recipients, to.Address)
 
-	// List each result
-	for _, r := range ctx.Results {
-		if r.Success {
-			sb.WriteString(fmt.Sprintf("✓ **%s** - %s\n", r.AgentName, r.TaskSummary))
-			if r.Result != "" {
-				sb.WriteString(fmt.Sprintf("  Result: %s\n", truncate(r.Result, 100)))
-			}
-		} else {
-			sb.WriteString(fmt.Sprintf("✗ **%s** - %s\n", r.AgentName, r.TaskSummary))
-			if r.Error != "" {
-				sb.WriteString(fmt.Sprintf("  Error: %s\n", truncate(r.Error, 100)))
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	sb.WriteString(fmt.Sprintf("To: %s\r\n", to))
+
+	for _, list := range []struct {
+		header string
+		addrs  []string
+	}{{"Cc", m.Cc}, {"Bcc", m.Bcc}} {
+		var shown []string
+		for _, raw := range list.addrs {
+			a, err := mail.ParseAddress(raw)
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("invalid %s address %q: %w", list.header, raw, err)
 			}
+			recipients = append(recipients, a.Address)
+			shown = append(shown, a.String())
+		}
+		if list.header == "Cc" && len(shown) > 0 {
+			sb.WriteString(fmt.Sprintf("Cc: %s\r\n", strings.Join(shown, ", ")))
 		}
-		sb.WriteString("\n")
-	}
-
-	// View URL
-	if ctx.ViewURL != "" {
-		sb.WriteString(fmt.Sprintf("View the project: %s\n", ctx.ViewURL))
 	}
 
-	// Footer
-	sb.WriteString("\n---\nThis is an automated notification from Tony.\n")
-
-	return sb.String()
-}
-
-func (c *Client) send(to, subject, body, fromOverride string) error {
-	from := c.from
-	if fromOverride != "" {
-		from = fromOverride
+	if m.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(m.ReplyTo)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("invalid Reply-To address %q: %w", m.ReplyTo, err)
+		}
+		sb.WriteString(fmt.Sprintf("Reply-To: %s\r\n", replyTo))
 	}
 
-	// Build email message
-	msg := fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"Date: %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/plain; charset=utf-8\r\n"+
-		"\r\n"+
-		"%s",
-		from, to, subject, time.Now().Format(time.RFC1123Z), body)
-
-	addr := fmt.Sprintf("%s:%d", c.host, c.port)
-
-	// Use auth if credentials provided
-	var auth smtp.Auth
-	if c.user != "" && c.password != "" {
-		auth = smtp.PlainAuth("", c.user, c.password, c.host)
-	}
+	subject := strings.Join(strings.Fields(m.Subject), " ")
+	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	sb.WriteString(fmt.Sprintf("Date: %s\r\n", date.Format(time.RFC1123Z)))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(m.Body)
 
-	return smtp.SendMail(addr, auth, from, []string{to}, []byte(msg))
+	return []byte(sb.String()), recipients, envelopeFrom, nil
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// signer returns the persona name used in email footers
+func signer(persona string) string {
+	if persona == "" {
+		return notification.DefaultPersona
 	}
-	return s[:maxLen-3] + "..."
+	return persona
 }
@@ -0,0 +1,108 @@
+package email
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComposeRouting(t *testing.T) {
+	c := NewClient("smtp.example.com", 587, "", "", "Tony <noreply@example.com>")
+	msg, recipients, envelopeFrom, err := c.compose(Message{
+		To:      "sam@example.com",
+		Cc:      []string{"Team <team@example.com>"},
+		Bcc:     []string{"archive@example.com"},
+		ReplyTo: "support@example.com",
+		Subject: "Gary completed: site",
+		Body:    "hello",
+	}, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if envelopeFrom != "noreply@example.com" {
+		t.Errorf("envelope from = %q", envelopeFrom)
+	}
+	if got := strings.Join(recipients, ","); got != "sam@example.com,team@example.com,archive@example.com" {
+		t.Errorf("recipients = %s", got)
+	}
+
+	headers, body, _ := strings.Cut(string(msg), "\r\n\r\n")
+	for _, want := range []string{
+		"To: <sam@example.com>",
+		`Cc: "Team" <team@example.com>`,
+		"Reply-To: <support@example.com>",
+		"Subject: Gary completed: site",
+	} {
+		if !strings.Contains(headers, want+"\r\n") {
+			t.Errorf("headers missing %q:\n%s", want, headers)
+		}
+	}
+	if strings.Contains(headers, "archive@example.com") {
+		t.Error("Bcc recipient leaked into the headers")
+	}
+	if body != "hello" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestComposeRejectsHeaderInjection(t *testing.T) {
+	c := NewClient("smtp.example.com", 587, "", "", "noreply@example.com")
+
+	bad := []Message{
+		{To: "sam@example.com\r\nBcc: evil@example.com", Subject: "s"},
+		{To: "sam@example.com", Cc: []string{"team@example.com\nBcc: evil@example.com"}, Subject: "s"},
+		{To: "sam@example.com", ReplyTo: "x@example.com\r\nX-Evil: 1", Subject: "s"},
+	}
+	for _, m := range bad {
+		if _, _, _, err := c.compose(m, time.Now()); err == nil {
+			t.Errorf("compose(%+v) accepted an injected header", m)
+		}
+	}
+
+	msg, _, _, err := c.compose(Message{To: "sam@example.com", Subject: "hi\r\nBcc: evil@example.com"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers, _, _ := strings.Cut(string(msg), "\r\n\r\n")
+	if strings.Contains(headers, "\r\nBcc:") {
+		t.Errorf("subject newline produced a header:\n%s", headers)
+	}
+}
+
+func TestParseAddresses(t *testing.T) {
+	got, err := ParseAddresses("team@example.com, Ops <ops@example.com>")
+	if err != nil || len(got) != 2 || got[1] != `"Ops" <ops@example.com>` {
+		t.Errorf("ParseAddresses = %v, %v", got, err)
+	}
+	if got, err := ParseAddresses(" "); got != nil || err != nil {
+		t.Errorf("empty list = %v, %v", got, err)
+	}
+	if _, err := ParseAddresses("not an address"); err == nil {
+		t.Error("expected an invalid list to fail")
+	}
+}
+
+func TestEmailBodyLinksShortenedResult(t *testing.T) {
+	c := NewClient("smtp.example.com", 587, "", "", "noreply@example.com")
+	ctx := &CallbackContext{
+		AgentName:   "Gary",
+		TaskSummary: "build the site",
+		Result:      "summary",
+		ViewURL:     "https://site.example.com/t/abc",
+		Success:     true,
+	}
+
+	if body := c.buildEmailBody(ctx); !strings.Contains(body, "View the project: https://site.example.com/t/abc") {
+		t.Errorf("full result should link the project:\n%s", body)
+	}
+
+	ctx.ResultShortened = true
+	body := c.buildEmailBody(ctx)
+	if !strings.Contains(body, "The full result is available at: https://site.example.com/t/abc") {
+		t.Errorf("shortened result should link the full one:\n%s", body)
+	}
+	if strings.Contains(body, "View the project") {
+		t.Errorf("view link repeated:\n%s", body)
+	}
+}
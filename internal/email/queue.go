@@ -0,0 +1,254 @@
+package email
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+// Queued message states
+const (
+	StatusQueued    = "queued"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed" // Gave up after DefaultMaxAttempts
+)
+
+// Queue defaults
+const (
+	DefaultMaxAttempts = 6
+	queueKey           = "tron.work/email_queue.json"
+	queueRetryBase     = 30 * time.Second
+	queueRetryMax      = 30 * time.Minute
+	queueKeepFinished  = 7 * 24 * time.Hour
+	queuePollInterval  = time.Minute
+)
+
+// QueuedMessage is an email waiting for, or done with, delivery
+type QueuedMessage struct {
+	Message
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextAttempt time.Time `json:"next_attempt"`
+	CreatedAt   time.Time `json:"created_at"`
+	FinishedAt  time.Time `json:"finished_at,omitempty"`
+}
+
+// Queue is a persistent outbound queue. Enqueued mail is saved before it
+// is sent and retried with backoff, so a slow or failing SMTP server
+// doesn't hold up the caller and mail survives a restart.
+type Queue struct {
+	store       persist.Store
+	send        func(Message) error
+	maxAttempts int
+	logger      logging.Logger
+
+	mu       sync.Mutex
+	messages []*QueuedMessage
+
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// EnableQueue makes SendTaskComplete and SendBatchComplete queue their mail
+// in store instead of sending inline. Mail queued before a restart is
+// loaded and retried. Call Start on the returned queue to begin delivery.
+func (c *Client) EnableQueue(store persist.Store) *Queue {
+	q := newQueue(store, c.send)
+	q.logger = c.logger
+	if ok, err := persist.LoadJSON(store, queueKey, &q.messages); err != nil {
+		c.logger.Errorf("Failed to load email queue: %v", err)
+	} else if ok {
+		c.logger.Infof("Loaded email queue: %d waiting", q.Waiting())
+	}
+	c.queue = q
+	return q
+}
+
+func newQueue(store persist.Store, send func(Message) error) *Queue {
+	return &Queue{
+		store:       store,
+		send:        send,
+		maxAttempts: DefaultMaxAttempts,
+		logger:      logging.Discard(),
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Enqueue saves a message for delivery and returns its ID
+func (q *Queue) Enqueue(m Message) (string, error) {
+	now := time.Now()
+	msg := &QueuedMessage{
+		Message:     m,
+		ID:          newMessageID(),
+		Status:      StatusQueued,
+		NextAttempt: now,
+		CreatedAt:   now,
+	}
+
+	q.mu.Lock()
+	q.messages = append(q.messages, msg)
+	err := q.saveLocked()
+	if err != nil {
+		q.messages = q.messages[:len(q.messages)-1]
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return msg.ID, nil
+}
+
+// Start runs the delivery worker until Close
+func (q *Queue) Start() {
+	q.startOnce.Do(func() {
+		go q.run()
+	})
+}
+
+// Close stops the worker, waiting for a send in progress to finish. Mail
+// still queued is delivered after the next start.
+func (q *Queue) Close(ctx context.Context) error {
+	q.closeOnce.Do(func() { close(q.stop) })
+	q.startOnce.Do(func() { close(q.done) }) // Never started: nothing to wait for
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Waiting returns the number of messages not yet delivered or given up on
+func (q *Queue) Waiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, m := range q.messages {
+		if m.Status == StatusQueued {
+			n++
+		}
+	}
+	return n
+}
+
+// Messages returns a copy of every message in the queue
+func (q *Queue) Messages() []QueuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]QueuedMessage, len(q.messages))
+	for i, m := range q.messages {
+		out[i] = *m
+	}
+	return out
+}
+
+func (q *Queue) run() {
+	defer close(q.done)
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.deliverDue(time.Now())
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue sends every queued message whose retry time has come
+func (q *Queue) deliverDue(now time.Time) {
+	q.mu.Lock()
+	var due []*QueuedMessage
+	for _, m := range q.messages {
+		if m.Status == StatusQueued && !m.NextAttempt.After(now) {
+			due = append(due, m)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, m := range due {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		// Send outside the lock so Enqueue never waits on SMTP
+		err := q.send(m.Message)
+
+		q.mu.Lock()
+		m.Attempts++
+		switch {
+		case err == nil:
+			m.Status, m.LastError, m.FinishedAt = StatusDelivered, "", time.Now()
+		case m.Attempts >= q.maxAttempts:
+			m.Status, m.LastError, m.FinishedAt = StatusFailed, err.Error(), time.Now()
+			q.logger.Errorf("Giving up on email %s to %s after %d attempts: %v", m.ID, m.To, m.Attempts, err)
+		default:
+			m.LastError = err.Error()
+			m.NextAttempt = time.Now().Add(retryDelay(m.Attempts))
+			q.logger.Warnf("Email %s to %s failed (attempt %d), retrying at %s: %v",
+				m.ID, m.To, m.Attempts, m.NextAttempt.Format(time.Kitchen), err)
+		}
+		q.pruneLocked(time.Now())
+		if err := q.saveLocked(); err != nil {
+			q.logger.Errorf("Failed to save email queue: %v", err)
+		}
+		q.mu.Unlock()
+	}
+}
+
+// retryDelay doubles from queueRetryBase per attempt up to queueRetryMax
+func retryDelay(attempts int) time.Duration {
+	d := queueRetryBase
+	for i := 1; i < attempts && d < queueRetryMax; i++ {
+		d *= 2
+	}
+	if d > queueRetryMax {
+		d = queueRetryMax
+	}
+	return d
+}
+
+// pruneLocked drops delivered and failed messages after a week
+func (q *Queue) pruneLocked(now time.Time) {
+	kept := q.messages[:0]
+	for _, m := range q.messages {
+		if m.Status != StatusQueued && now.Sub(m.FinishedAt) > queueKeepFinished {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	q.messages = kept
+}
+
+func (q *Queue) saveLocked() error {
+	return persist.SaveJSON(q.store, queueKey, q.messages)
+}
+
+func newMessageID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,101 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+func TestQueueRetriesAndSurvivesRestart(t *testing.T) {
+	store := persist.NewFileStore(t.TempDir())
+
+	failing := true
+	var sent []string
+	send := func(m Message) error {
+		if failing {
+			return errors.New("smtp down")
+		}
+		sent = append(sent, m.To)
+		return nil
+	}
+
+	q := newQueue(store, send)
+	if _, err := q.Enqueue(Message{To: "sam@example.com", Subject: "Done", Body: "body"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed send is kept with a backoff
+	q.deliverDue(time.Now())
+	msgs := q.Messages()
+	if len(msgs) != 1 || msgs[0].Status != StatusQueued || msgs[0].Attempts != 1 || msgs[0].LastError != "smtp down" {
+		t.Fatalf("after failure: %+v", msgs)
+	}
+	if !msgs[0].NextAttempt.After(time.Now()) {
+		t.Error("expected the retry to be scheduled in the future")
+	}
+
+	// A new queue on the same store (a restart) picks the message up
+	failing = false
+	c := NewClient("smtp.example.com", 587, "", "", "tony@example.com")
+	restarted := c.EnableQueue(store)
+	restarted.send = send
+	if restarted.Waiting() != 1 {
+		t.Fatalf("restarted queue has %d waiting, want 1", restarted.Waiting())
+	}
+	restarted.deliverDue(time.Now().Add(queueRetryMax))
+	if len(sent) != 1 || restarted.Messages()[0].Status != StatusDelivered {
+		t.Errorf("sent = %v, messages = %+v", sent, restarted.Messages())
+	}
+}
+
+func TestQueueGivesUp(t *testing.T) {
+	q := newQueue(persist.NewFileStore(t.TempDir()), func(m Message) error {
+		return errors.New("rejected")
+	})
+	q.maxAttempts = 2
+	q.Enqueue(Message{To: "sam@example.com", Subject: "Done", Body: "body"})
+
+	q.deliverDue(time.Now())
+	q.deliverDue(time.Now().Add(queueRetryMax))
+	if m := q.Messages()[0]; m.Status != StatusFailed || m.Attempts != 2 {
+		t.Errorf("message = %+v, want failed after 2 attempts", m)
+	}
+	if q.Waiting() != 0 {
+		t.Errorf("Waiting() = %d, want 0", q.Waiting())
+	}
+}
+
+func TestQueueWorkerDeliversOnEnqueue(t *testing.T) {
+	delivered := make(chan string, 1)
+	q := newQueue(persist.NewFileStore(t.TempDir()), func(m Message) error {
+		delivered <- m.To
+		return nil
+	})
+	q.Start()
+	defer q.Close(context.Background())
+
+	q.Enqueue(Message{To: "sam@example.com", Subject: "Done", Body: "body"})
+	select {
+	case to := <-delivered:
+		if to != "sam@example.com" {
+			t.Errorf("delivered to %q", to)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker did not deliver the queued message")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if d := retryDelay(1); d != queueRetryBase {
+		t.Errorf("retryDelay(1) = %v", d)
+	}
+	if d := retryDelay(2); d != 2*queueRetryBase {
+		t.Errorf("retryDelay(2) = %v", d)
+	}
+	if d := retryDelay(50); d != queueRetryMax {
+		t.Errorf("retryDelay(50) = %v, want the cap", d)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
 )
 
 // CLevel contains the list of C-level executives who get governance context
@@ -40,3 +44,66 @@ func GetPromptSection(content string, agentName string) string {
 	}
 	return "\n\n## Company Operating Framework\n" + content
 }
+
+// Store caches the operating framework so each spawn doesn't reread it.
+// The file is stat'ed on each use and only read again when its size or
+// modification time changes; a missing file is cached as empty until it
+// appears.
+type Store struct {
+	path   string
+	logger logging.Logger
+
+	mu      sync.Mutex
+	loaded  bool
+	content string
+	modTime time.Time
+	size    int64
+}
+
+// NewStore creates a store for the operating framework under knowledgeDir.
+// Nothing is read until the content is first needed.
+func NewStore(knowledgeDir string) *Store {
+	return &Store{
+		path:   filepath.Join(knowledgeDir, "governance", "operating-framework.md"),
+		logger: logging.New("governance"),
+	}
+}
+
+// Content returns the operating framework, rereading it if it changed
+func (s *Store) Content() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.loaded, s.content, s.modTime, s.size = true, "", time.Time{}, 0
+		return "", nil
+	}
+	if err != nil {
+		return s.content, err
+	}
+	if s.loaded && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.content, nil
+	}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return s.content, err
+	}
+	s.loaded, s.content, s.modTime, s.size = true, string(content), info.ModTime(), info.Size()
+	return s.content, nil
+}
+
+// PromptSection returns the framework section for an agent's system prompt.
+// Agents outside the C-level get nothing, without touching the disk. If
+// the file can't be read the last good copy is used.
+func (s *Store) PromptSection(agentName string) string {
+	if s == nil || !IsCLevel(agentName) {
+		return ""
+	}
+	content, err := s.Content()
+	if err != nil {
+		s.logger.Warnf("Failed to read %s: %v", s.path, err)
+	}
+	return GetPromptSection(content, agentName)
+}
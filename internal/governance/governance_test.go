@@ -0,0 +1,68 @@
+package governance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreCachesUntilFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+
+	// Missing file: empty, no error
+	if got, err := s.Content(); got != "" || err != nil {
+		t.Fatalf("Content on a missing file = %q, %v", got, err)
+	}
+
+	path := filepath.Join(dir, "governance", "operating-framework.md")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte("Ship weekly."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Content(); got != "Ship weekly." {
+		t.Fatalf("Content after the file appeared = %q", got)
+	}
+
+	// Same size and mtime: served from the cache, not reread
+	stamp := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(path, stamp, stamp)
+	s.Content()
+	os.WriteFile(path, []byte("Ship yearly."), 0644)
+	os.Chtimes(path, stamp, stamp)
+	if got, _ := s.Content(); got != "Ship weekly." {
+		t.Errorf("unchanged stat should hit the cache, got %q", got)
+	}
+
+	// A new mtime invalidates it
+	os.Chtimes(path, stamp.Add(time.Minute), stamp.Add(time.Minute))
+	if got, _ := s.Content(); got != "Ship yearly." {
+		t.Errorf("Content after a change = %q", got)
+	}
+
+	os.Remove(path)
+	if got, err := s.Content(); got != "" || err != nil {
+		t.Errorf("Content after removal = %q, %v", got, err)
+	}
+}
+
+func TestStorePromptSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "governance", "operating-framework.md")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("Ship weekly."), 0644)
+	s := NewStore(dir)
+
+	if got := s.PromptSection("maya"); !strings.Contains(got, "## Company Operating Framework\nShip weekly.") {
+		t.Errorf("C-level section = %q", got)
+	}
+	if got := s.PromptSection("Gary"); got != "" {
+		t.Errorf("non-C-level agent got %q", got)
+	}
+	var nilStore *Store
+	if got := nilStore.PromptSection("Tony"); got != "" {
+		t.Errorf("nil store = %q", got)
+	}
+}
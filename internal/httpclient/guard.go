@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned for URLs that resolve to internal addresses
+var ErrBlockedAddress = errors.New("address is internal or reserved")
+
+// blockedPrefixes are ranges BlockedAddr refuses on top of the loopback,
+// private, link-local and multicast ones netip reports
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which can reach any IPv4 address
+}
+
+// BlockedAddr reports whether requests to user-supplied URLs must not reach
+// addr: loopback, private networks, link-local (including the
+// 169.254.169.254 cloud metadata service) and other reserved ranges
+func BlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckLiteralHost fails if host is a blocked IP address or a localhost
+// name, without resolving it
+func CheckLiteralHost(host string, blocked func(netip.Addr) bool) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if blocked(addr) {
+			return fmt.Errorf("refusing to connect to %s: %w", host, ErrBlockedAddress)
+		}
+		return nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("refusing to connect to %s: %w", host, ErrBlockedAddress)
+	}
+	return nil
+}
+
+// CheckHost resolves host and fails if blocked reports any of its addresses
+func CheckHost(ctx context.Context, host string, blocked func(netip.Addr) bool) error {
+	if err := CheckLiteralHost(host, blocked); err != nil {
+		return err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if blocked(addr) {
+			return fmt.Errorf("refusing to connect to %s (%s): %w", host, addr, ErrBlockedAddress)
+		}
+	}
+	return nil
+}
+
+// GuardedTransport returns a transport for requests to u that refuses
+// connections to addresses blocked reports as they're dialed, catching
+// hosts that resolve differently between CheckHost and the dial. Through a
+// proxy, the proxy makes the connection, so the shared transport is
+// returned and the up-front checks are all that apply.
+func GuardedTransport(u *url.URL, blocked func(netip.Addr) bool) http.RoundTripper {
+	if proxy, err := Proxy(&http.Request{URL: u}); err != nil || proxy != nil {
+		return Transport()
+	}
+
+	guard := func(network, address string, _ syscall.RawConn) error {
+		ap, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("unexpected dial address %q", address)
+		}
+		if blocked(ap.Addr()) {
+			return fmt.Errorf("refusing to connect to %s: %w", ap.Addr(), ErrBlockedAddress)
+		}
+		return nil
+	}
+
+	transport := Transport().Clone()
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second, Control: guard}
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,34 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCheckHostRefusesInternalAddresses(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "169.254.169.254", "10.0.0.5", "::1", "localhost", "api.localhost"} {
+		if err := CheckHost(context.Background(), host, BlockedAddr); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("CheckHost(%q) = %v, want a blocked address error", host, err)
+		}
+	}
+	if err := CheckHost(context.Background(), "93.184.216.34", BlockedAddr); err != nil {
+		t.Errorf("CheckHost(public address) = %v, want nil", err)
+	}
+}
+
+func TestGuardedTransportRefusesDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	client := &http.Client{Transport: GuardedTransport(u, BlockedAddr)}
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("dial to loopback = %v, want it refused", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package httpclient builds the HTTP clients used for outbound API calls.
+//
+// All clients share one transport that routes through a proxy when one is
+// configured. An explicit proxy set with SetProxyURL takes precedence over
+// the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyEnv is the environment variable holding an explicit proxy URL
+const ProxyEnv = "TRON_PROXY_URL"
+
+var (
+	proxyURL atomic.Pointer[url.URL]
+
+	transportOnce sync.Once
+	transport     *http.Transport
+)
+
+// SetProxyURL configures an explicit proxy for all clients. Supported schemes
+// are http, https, socks5 and socks5h. An empty string clears the explicit
+// proxy so the environment variables apply again.
+func SetProxyURL(raw string) error {
+	if raw == "" {
+		proxyURL.Store(nil)
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy URL %q has no host", raw)
+	}
+
+	proxyURL.Store(u)
+	return nil
+}
+
+// Proxy picks the proxy for a request: the explicit proxy if set, otherwise
+// the one from the environment. It matches the signature of http.Transport.Proxy
+// so it can also be used by websocket dialers.
+func Proxy(req *http.Request) (*url.URL, error) {
+	if u := proxyURL.Load(); u != nil {
+		return u, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// Transport returns the shared proxy-aware transport
+func Transport() *http.Transport {
+	transportOnce.Do(func() {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = Proxy
+	})
+	return transport
+}
+
+// New creates a client with the given timeout on the shared transport
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: Transport(),
+	}
+}
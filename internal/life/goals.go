@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/everydev1618/tron/internal/persist"
 	"gopkg.in/yaml.v3"
 )
 
@@ -176,7 +177,7 @@ func (g *GoalTracker) persist() {
 		return
 	}
 
-	os.WriteFile(filepath.Join(dir, "goals.yaml"), data, 0644)
+	persist.WriteFile(filepath.Join(dir, "goals.yaml"), data)
 }
 
 // createDefault creates a default goals file.
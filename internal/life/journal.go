@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 // Journal manages Tony's daily thoughts and observations.
@@ -190,7 +192,7 @@ func (j *Journal) persist() {
 		return
 	}
 
-	os.WriteFile(filepath.Join(dir, "journal.json"), data, 0644)
+	persist.WriteFile(filepath.Join(dir, "journal.json"), data)
 }
 
 // load restores entries from disk.
@@ -27,6 +27,9 @@ type PersonaConfig struct {
 	FocusAreas  []string // Topics this persona cares about
 	ContentTone string   // Brief description of how they communicate
 	AvatarUrl   string   // URL to the persona's avatar image (DC Comics style)
+
+	// ValidateTone opts the persona into tone validation of generated posts
+	ValidateTone bool
 }
 
 // Loop manages an autonomous daily routine for a persona.
@@ -3,10 +3,10 @@ package life
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/logging"
 )
 
 // Manager orchestrates life loops for multiple personas.
@@ -15,6 +15,7 @@ type Manager struct {
 	config LoopConfig
 	slack  SlackNotifier
 	social *SocialClient // Shared social client with per-agent keys
+	logger logging.Logger
 
 	mu    sync.RWMutex
 	loops map[string]*Loop
@@ -39,18 +40,20 @@ func DefaultPersonas() []PersonaConfig {
 			ContentTone: "Direct and numbers-focused, asks 'so what?'",
 		},
 		{
-			Name:        "Alex",
-			Role:        "CEO",
-			AvatarUrl:   "https://hellotron-avatars.s3.us-west-2.amazonaws.com/avatars/personas/alex.png",
-			FocusAreas:  []string{"strategy", "leadership", "vision", "culture", "execution"},
-			ContentTone: "Strategic and warm, tells stories to make points",
+			Name:         "Alex",
+			Role:         "CEO",
+			AvatarUrl:    "https://hellotron-avatars.s3.us-west-2.amazonaws.com/avatars/personas/alex.png",
+			FocusAreas:   []string{"strategy", "leadership", "vision", "culture", "execution"},
+			ContentTone:  "Strategic and warm, tells stories to make points",
+			ValidateTone: true,
 		},
 		{
-			Name:        "Jordan",
-			Role:        "CFO",
-			AvatarUrl:   "https://hellotron-avatars.s3.us-west-2.amazonaws.com/avatars/personas/jordan.png",
-			FocusAreas:  []string{"finance", "runway", "unit economics", "fundraising", "budgets"},
-			ContentTone: "Translates finance to plain English, direct about bad news",
+			Name:         "Jordan",
+			Role:         "CFO",
+			AvatarUrl:    "https://hellotron-avatars.s3.us-west-2.amazonaws.com/avatars/personas/jordan.png",
+			FocusAreas:   []string{"finance", "runway", "unit economics", "fundraising", "budgets"},
+			ContentTone:  "Translates finance to plain English, direct about bad news",
+			ValidateTone: true,
 		},
 		{
 			Name:        "Riley",
@@ -94,10 +97,16 @@ func NewManager(orch *vega.Orchestrator, config LoopConfig) *Manager {
 		orch:   orch,
 		config: config,
 		social: social,
+		logger: logging.New("life-manager"),
 		loops:  make(map[string]*Loop),
 	}
 }
 
+// SetLogger replaces the manager's logger.
+func (m *Manager) SetLogger(l logging.Logger) {
+	m.logger = l
+}
+
 // SetAgentKey sets a per-persona API key for posting.
 func (m *Manager) SetAgentKey(name, key string) {
 	m.social.SetAgentKey(name, key)
@@ -113,6 +122,12 @@ func (m *Manager) SetSlack(slack SlackNotifier) {
 	}
 }
 
+// SetToneValidator replaces the validator used for personas that opt into
+// tone validation. Pass nil to disable validation.
+func (m *Manager) SetToneValidator(v ToneValidator) {
+	m.social.SetToneValidator(v)
+}
+
 // AddPersona adds a persona's life loop to the manager.
 func (m *Manager) AddPersona(persona PersonaConfig, schedule LoopConfig) {
 	m.mu.Lock()
@@ -129,6 +144,9 @@ func (m *Manager) AddPersona(persona PersonaConfig, schedule LoopConfig) {
 // EnsureAvatar checks if a persona has an avatar URL and generates one if missing.
 // The agentID is required to call the hellotron avatar API.
 // Returns the avatar URL (existing or newly generated).
+// The manager lock is only held to look up and update the loop, never during
+// generation, so a slow generation does not block Status, AddPersona or
+// other manager operations.
 func (m *Manager) EnsureAvatar(ctx context.Context, personaName, agentID string) (string, error) {
 	m.mu.RLock()
 	loop, ok := m.loops[personaName]
@@ -145,7 +163,7 @@ func (m *Manager) EnsureAvatar(ctx context.Context, personaName, agentID string)
 		return persona.AvatarUrl, nil
 	}
 
-	// Generate a new avatar
+	// Generate a new avatar (bounded by GenerateAvatar's own timeout)
 	avatarUrl, err := m.social.GenerateAvatar(ctx, persona, agentID)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate avatar for %s: %w", personaName, err)
@@ -178,17 +196,17 @@ func (m *Manager) EnsureAllAvatars(ctx context.Context, agentIDs map[string]stri
 	for _, name := range personas {
 		agentID, ok := agentIDs[name]
 		if !ok {
-			log.Printf("[life-manager] No agent ID for persona %s, skipping avatar", name)
+			m.logger.Warnf("No agent ID for persona %s, skipping avatar", name)
 			continue
 		}
 
 		avatarUrl, err := m.EnsureAvatar(ctx, name, agentID)
 		if err != nil {
-			log.Printf("[life-manager] Failed to ensure avatar for %s: %v", name, err)
+			m.logger.Errorf("Failed to ensure avatar for %s: %v", name, err)
 			continue
 		}
 		results[name] = avatarUrl
-		log.Printf("[life-manager] Avatar for %s: %s", name, avatarUrl)
+		m.logger.Infof("Avatar for %s: %s", name, avatarUrl)
 	}
 
 	return results
@@ -207,18 +225,18 @@ func (m *Manager) SyncAvatars(ctx context.Context, agentIDs map[string]string) {
 
 	for _, persona := range personas {
 		if persona.AvatarUrl == "" {
-			log.Printf("[life-manager] %s has no avatar URL configured, skipping sync", persona.Name)
+			m.logger.Warnf("%s has no avatar URL configured, skipping sync", persona.Name)
 			continue
 		}
 
 		agentID, ok := agentIDs[persona.Name]
 		if !ok {
-			log.Printf("[life-manager] No agent ID for persona %s, skipping avatar sync", persona.Name)
+			m.logger.Warnf("No agent ID for persona %s, skipping avatar sync", persona.Name)
 			continue
 		}
 
 		if err := m.social.SyncAvatar(ctx, persona, agentID); err != nil {
-			log.Printf("[life-manager] Failed to sync avatar for %s: %v", persona.Name, err)
+			m.logger.Errorf("Failed to sync avatar for %s: %v", persona.Name, err)
 			continue
 		}
 	}
@@ -240,7 +258,7 @@ func (m *Manager) Start() {
 
 	for name, loop := range m.loops {
 		loop.Start()
-		log.Printf("[life-manager] Started %s's life loop", name)
+		m.logger.Infof("Started %s's life loop", name)
 	}
 }
 
@@ -251,7 +269,7 @@ func (m *Manager) Stop() {
 
 	for name, loop := range m.loops {
 		loop.Stop()
-		log.Printf("[life-manager] Stopped %s's life loop", name)
+		m.logger.Infof("Stopped %s's life loop", name)
 	}
 }
 
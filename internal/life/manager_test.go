@@ -0,0 +1,83 @@
+package life
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestSlowAvatarGenerationDoesNotBlockManager(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	avatarAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`{"avatarUrl": "https://example.com/tony.png"}`))
+	}))
+	defer avatarAPI.Close()
+
+	m := NewManager(vega.NewOrchestrator(), LoopConfig{
+		BaseDir:      t.TempDir(),
+		SocialAPIURL: avatarAPI.URL + "/api/posts",
+	})
+	m.SetAgentKey("Tony", "key")
+	m.AddPersona(PersonaConfig{Name: "Tony", Role: "CTO"}, LoopConfig{BaseDir: t.TempDir()})
+
+	type result struct {
+		url string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		url, err := m.EnsureAvatar(context.Background(), "Tony", "agent-1")
+		done <- result{url, err}
+	}()
+	<-started
+
+	// Generation is in flight; manager operations must not wait on it
+	statusDone := make(chan struct{})
+	go func() {
+		m.Status()
+		m.AddPersona(PersonaConfig{Name: "Maya", Role: "CMO"}, LoopConfig{BaseDir: t.TempDir()})
+		close(statusDone)
+	}()
+	select {
+	case <-statusDone:
+	case <-time.After(time.Second):
+		t.Fatal("Status/AddPersona blocked while avatar generation was in flight")
+	}
+
+	close(release)
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("EnsureAvatar() error = %v", res.err)
+	}
+	if got := m.GetLoop("Tony").Persona().AvatarUrl; got != res.url || got == "" {
+		t.Errorf("persona avatar = %q, want %q", got, res.url)
+	}
+}
+
+func TestGenerateAvatarHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	avatarAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release // never answers while the test runs
+	}))
+	defer avatarAPI.Close()
+	defer close(release)
+
+	s := NewSocialClient(avatarAPI.URL+"/api/posts", "key")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := s.GenerateAvatar(ctx, PersonaConfig{Name: "Tony"}, "agent-1"); err == nil {
+		t.Fatal("expected an error from a hung avatar call")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GenerateAvatar returned after %s, want it cut off by the context", elapsed)
+	}
+}
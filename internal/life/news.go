@@ -12,6 +12,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 // NewsReader fetches and filters news from various sources.
@@ -118,7 +121,7 @@ type ProductHuntPost struct {
 func NewNewsReader(baseDir string) *NewsReader {
 	nr := &NewsReader{
 		baseDir:  baseDir,
-		client:   &http.Client{Timeout: 30 * time.Second},
+		client:   httpclient.New(30 * time.Second),
 		saved:    make([]Article, 0),
 		lastRead: make(map[string]time.Time),
 	}
@@ -569,7 +572,7 @@ func (n *NewsReader) persist() {
 		return
 	}
 
-	os.WriteFile(filepath.Join(dir, "news.json"), jsonData, 0644)
+	persist.WriteFile(filepath.Join(dir, "news.json"), jsonData)
 }
 
 // load restores state from disk.
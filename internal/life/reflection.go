@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 // Reflector handles Tony's self-improvement and learning.
@@ -225,7 +226,7 @@ func (r *Reflector) persist() {
 	}
 
 	jsonData, _ := json.MarshalIndent(data, "", "  ")
-	os.WriteFile(filepath.Join(dir, "reflection.json"), jsonData, 0644)
+	persist.WriteFile(filepath.Join(dir, "reflection.json"), jsonData)
 }
 
 // load restores state from disk.
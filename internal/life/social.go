@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+	"github.com/everydev1618/tron/internal/textutil"
 )
 
 // SocialClient handles posting to the Tron social feed.
@@ -18,6 +21,16 @@ type SocialClient struct {
 	agentKeys  map[string]string // Per-persona API keys (name -> key)
 	client     *http.Client
 
+	// Used for avatar generation, which outlasts client's timeout;
+	// GenerateAvatar bounds each call with its own deadline instead
+	avatarClient *http.Client
+
+	// Checks posts against the persona's voice (personas opt in)
+	toneValidator ToneValidator
+
+	// Picks the day's wisdom line
+	now func() time.Time
+
 	// Safety settings
 	blockedTerms   []string
 	maxPostLen     int
@@ -40,10 +53,13 @@ type SocialPost struct {
 // NewSocialClient creates a new social feed client.
 func NewSocialClient(apiURL, defaultKey string) *SocialClient {
 	return &SocialClient{
-		apiURL:     apiURL,
-		defaultKey: defaultKey,
-		agentKeys:  make(map[string]string),
-		client:     &http.Client{Timeout: 30 * time.Second},
+		apiURL:        apiURL,
+		defaultKey:    defaultKey,
+		agentKeys:     make(map[string]string),
+		client:        httpclient.New(30 * time.Second),
+		avatarClient:  httpclient.New(0),
+		toneValidator: KeywordToneValidator{Rules: DefaultToneRules()},
+		now:           time.Now,
 		blockedTerms: []string{
 			// Client/business info
 			"client", "customer name", "contract", "deal", "revenue",
@@ -69,6 +85,11 @@ func (s *SocialClient) SetAgentKey(name, key string) {
 	s.agentKeys[name] = key
 }
 
+// SetToneValidator sets the validator for personas that opt into tone checks.
+func (s *SocialClient) SetToneValidator(v ToneValidator) {
+	s.toneValidator = v
+}
+
 // getKeyForAgent returns the API key for a given agent.
 func (s *SocialClient) getKeyForAgent(name string) string {
 	if key, ok := s.agentKeys[name]; ok {
@@ -89,6 +110,10 @@ func (s *SocialClient) ComposeForPersona(ctx context.Context, persona PersonaCon
 	// 2. A general insight from work
 	// 3. Role-appropriate wisdom
 
+	// Off-brand candidates are skipped in favor of the next one, up to
+	// maxToneRejections before giving up for this round
+	rejected := 0
+
 	// Try to compose from articles first (skip already-posted articles)
 	if len(articles) > 0 {
 		for _, article := range articles {
@@ -100,7 +125,7 @@ func (s *SocialClient) ComposeForPersona(ctx context.Context, persona PersonaCon
 			}
 
 			content := s.composeFromArticleForPersona(article, persona.Name)
-			if content != "" && s.isSafe(content) {
+			if content != "" && s.isSafe(content) && s.fitsTone(ctx, persona, content, &rejected) {
 				return &SocialPost{
 					Author:    persona.Name,
 					Content:   content,
@@ -109,6 +134,9 @@ func (s *SocialClient) ComposeForPersona(ctx context.Context, persona PersonaCon
 					Timestamp: time.Now(),
 				}
 			}
+			if rejected >= maxToneRejections {
+				return nil
+			}
 		}
 	}
 
@@ -116,7 +144,7 @@ func (s *SocialClient) ComposeForPersona(ctx context.Context, persona PersonaCon
 	for _, entry := range journal {
 		if entry.Type == "reflection" {
 			content := s.composeFromReflection(entry)
-			if content != "" && s.isSafe(content) {
+			if content != "" && s.isSafe(content) && s.fitsTone(ctx, persona, content, &rejected) {
 				return &SocialPost{
 					Author:    persona.Name,
 					Content:   content,
@@ -124,17 +152,24 @@ func (s *SocialClient) ComposeForPersona(ctx context.Context, persona PersonaCon
 					Timestamp: time.Now(),
 				}
 			}
+			if rejected >= maxToneRejections {
+				return nil
+			}
 		}
 	}
 
-	// Fall back to persona-appropriate wisdom
-	content := composeWisdomForPersona(persona.Name)
-	if content != "" {
-		return &SocialPost{
-			Author:    persona.Name,
-			Content:   content,
-			Type:      "thought",
-			Timestamp: time.Now(),
+	// Fall back to persona-appropriate wisdom, moving on to the next line
+	// if today's pick is off-brand
+	now := s.now()
+	for offset := 0; offset < len(wisdomFor(persona.Name)) && rejected < maxToneRejections; offset++ {
+		content := composeWisdomAt(persona.Name, now, offset)
+		if content != "" && s.fitsTone(ctx, persona, content, &rejected) {
+			return &SocialPost{
+				Author:    persona.Name,
+				Content:   content,
+				Type:      "thought",
+				Timestamp: time.Now(),
+			}
 		}
 	}
 
@@ -169,7 +204,7 @@ func (s *SocialClient) composeFromArticleForPersona(article Article, persona str
 			maxTopicLen := 288 - len(take) - len(article.URL) - 20 // 20 for "Reading: " + ". " + "\n\n"
 			if maxTopicLen > 10 {
 				if len(topic) > maxTopicLen {
-					topic = topic[:maxTopicLen-3] + "..."
+					topic = textutil.Shorten(topic, maxTopicLen)
 				}
 				post = fmt.Sprintf("Reading: %s. %s\n\n%s", topic, take, article.URL)
 			} else {
@@ -245,6 +280,15 @@ func (s *SocialClient) isSafe(content string) bool {
 	return true
 }
 
+const (
+	// avatarGenerateTimeout bounds an avatar generation call; image
+	// generation is slow, so this is longer than the client's default
+	avatarGenerateTimeout = 2 * time.Minute
+
+	// avatarSyncTimeout bounds setting an existing avatar URL
+	avatarSyncTimeout = 15 * time.Second
+)
+
 // AvatarRequest represents a request to generate a persona avatar.
 type AvatarRequest struct {
 	Style       string   `json:"style"`       // "persona" for DC Comics style
@@ -280,6 +324,9 @@ func (s *SocialClient) SyncAvatar(ctx context.Context, persona PersonaConfig, ag
 		return fmt.Errorf("no API key configured for persona %s", persona.Name)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, avatarSyncTimeout)
+	defer cancel()
+
 	req := SetAvatarRequest{AvatarUrl: persona.AvatarUrl}
 	jsonData, err := json.Marshal(req)
 	if err != nil {
@@ -326,6 +373,9 @@ func (s *SocialClient) GenerateAvatar(ctx context.Context, persona PersonaConfig
 		return "", fmt.Errorf("no API key configured for persona %s", persona.Name)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, avatarGenerateTimeout)
+	defer cancel()
+
 	// Build the avatar generation request
 	req := AvatarRequest{
 		Style:       "persona",
@@ -353,7 +403,7 @@ func (s *SocialClient) GenerateAvatar(ctx context.Context, persona PersonaConfig
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-API-Key", apiKey)
 
-	resp, err := s.client.Do(httpReq)
+	resp, err := s.avatarClient.Do(httpReq)
 	if err != nil {
 		return "", fmt.Errorf("failed to request avatar: %w", err)
 	}
@@ -397,7 +447,7 @@ func (s *SocialClient) Publish(ctx context.Context, post *SocialPost) error {
 	// Truncate content to 288 chars if needed
 	content := post.Content
 	if len(content) > 288 {
-		content = content[:285] + "..."
+		content = textutil.Shorten(content, 288)
 	}
 
 	// Prepare request - Hellotron feed uses simple {"content": "..."} format
@@ -558,11 +608,11 @@ func extractTopic(title string) string {
 	title = strings.TrimSpace(title)
 
 	if len(title) > 50 {
-		if idx := strings.LastIndex(title[:50], " "); idx > 20 {
-			title = title[:idx]
-		} else {
-			title = title[:50]
+		cut := textutil.Truncate(title, 50)
+		if idx := strings.LastIndex(cut, " "); idx > 20 {
+			cut = cut[:idx]
 		}
+		title = cut
 	}
 
 	return title
@@ -745,15 +795,22 @@ var personaWisdom = map[string][]string{
 	},
 }
 
-// composeWisdomForPersona returns persona-appropriate wisdom with hashtags.
-func composeWisdomForPersona(persona string) string {
+// wisdomFor returns the wisdom lines for a persona, defaulting to Tony's.
+func wisdomFor(persona string) []string {
 	wisdom, ok := personaWisdom[persona]
 	if !ok {
 		wisdom = personaWisdom["Tony"] // Default to Tony
 	}
+	return wisdom
+}
+
+// composeWisdomAt returns persona-appropriate wisdom with hashtags, offset
+// places after the pick for now.
+func composeWisdomAt(persona string, now time.Time, offset int) string {
+	wisdom := wisdomFor(persona)
 
 	// Pick based on day + hour to vary between personas
-	idx := (time.Now().YearDay() + time.Now().Hour()) % len(wisdom)
+	idx := (now.YearDay() + now.Hour() + offset) % len(wisdom)
 	content := wisdom[idx]
 
 	// Add persona-relevant hashtags
@@ -0,0 +1,109 @@
+package life
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// maxToneRejections is how many off-brand candidates a compose skips before
+// giving up on posting this round
+const maxToneRejections = 3
+
+// ToneVerdict is a validator's judgment of a candidate post.
+type ToneVerdict struct {
+	OK     bool
+	Reason string // Why the post was rejected (for tuning)
+}
+
+// ToneValidator checks generated content against a persona's tone and focus
+// areas. Implementations can be keyword heuristics or an LLM judge.
+type ToneValidator interface {
+	Validate(ctx context.Context, persona PersonaConfig, content string) ToneVerdict
+}
+
+// ToneValidatorFunc adapts a function to ToneValidator.
+type ToneValidatorFunc func(ctx context.Context, persona PersonaConfig, content string) ToneVerdict
+
+// Validate calls f.
+func (f ToneValidatorFunc) Validate(ctx context.Context, persona PersonaConfig, content string) ToneVerdict {
+	return f(ctx, persona, content)
+}
+
+// ToneRule is a keyword heuristic for one persona's voice.
+type ToneRule struct {
+	Avoid      []string // Phrases that are off-brand for the persona
+	MaxNumbers int      // Reject posts with more numeric figures than this (0 = no limit)
+}
+
+// KeywordToneValidator rejects posts that use a persona's avoided phrases or
+// lean on more raw figures than the persona's rule allows.
+type KeywordToneValidator struct {
+	Rules map[string]ToneRule // Persona name -> rule
+}
+
+// numberPattern matches numeric figures such as 40%, $2.5M or 10x
+var numberPattern = regexp.MustCompile(`[$€£]?\d[\d,.]*\s*(%|[kKmMbB]\b|x\b)?`)
+
+// Validate applies the persona's rule. Personas without a rule always pass.
+func (v KeywordToneValidator) Validate(ctx context.Context, persona PersonaConfig, content string) ToneVerdict {
+	rule, ok := v.Rules[persona.Name]
+	if !ok {
+		return ToneVerdict{OK: true}
+	}
+
+	lower := strings.ToLower(content)
+	for _, phrase := range rule.Avoid {
+		if strings.Contains(lower, strings.ToLower(phrase)) {
+			return ToneVerdict{Reason: fmt.Sprintf("uses %q, which is off-brand for %s", phrase, persona.Name)}
+		}
+	}
+
+	// Links carry numbers that aren't figures
+	text := content
+	for _, field := range strings.Fields(content) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") || strings.HasPrefix(field, "#") {
+			text = strings.Replace(text, field, "", 1)
+		}
+	}
+	if rule.MaxNumbers > 0 {
+		if n := len(numberPattern.FindAllString(text, -1)); n > rule.MaxNumbers {
+			return ToneVerdict{Reason: fmt.Sprintf("has %d figures, %s tells the story behind the numbers", n, persona.Name)}
+		}
+	}
+
+	return ToneVerdict{OK: true}
+}
+
+// DefaultToneRules returns keyword rules for the default personas.
+func DefaultToneRules() map[string]ToneRule {
+	return map[string]ToneRule{
+		"Jordan": { // CFO - plain and sober, no hype
+			Avoid: []string{"game changer", "game-changer", "revolutionary", "to the moon",
+				"skyrocket", "crushing it", "massive", "insane", "!!"},
+		},
+		"Alex": { // CEO - stories, not spreadsheets
+			MaxNumbers: 1,
+		},
+	}
+}
+
+// fitsTone reports whether content passes tone validation for the persona.
+// Personas that haven't opted in always pass. Rejections are logged and
+// counted in rejected.
+func (s *SocialClient) fitsTone(ctx context.Context, persona PersonaConfig, content string, rejected *int) bool {
+	if !persona.ValidateTone || s.toneValidator == nil {
+		return true
+	}
+
+	verdict := s.toneValidator.Validate(ctx, persona, content)
+	if verdict.OK {
+		return true
+	}
+
+	*rejected++
+	log.Printf("[social] Rejected off-brand post for %s (%d/%d): %s", persona.Name, *rejected, maxToneRejections, verdict.Reason)
+	return false
+}
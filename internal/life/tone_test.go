@@ -0,0 +1,79 @@
+package life
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestKeywordToneValidator(t *testing.T) {
+	v := KeywordToneValidator{Rules: DefaultToneRules()}
+	ctx := context.Background()
+
+	tests := []struct {
+		persona string
+		content string
+		ok      bool
+	}{
+		{"Jordan", "This new pricing model is a game changer!!", false},
+		{"Jordan", "Cash is oxygen. Know your runway.", true},
+		{"Alex", "Revenue up 40%, churn down to 2%, NPS 71.", false},
+		{"Alex", "We grew 40% because one customer told ten friends. https://example.com/2024/10/story", true},
+		{"Tony", "This is revolutionary!!", true}, // no rule for Tony
+	}
+	for _, tt := range tests {
+		verdict := v.Validate(ctx, PersonaConfig{Name: tt.persona}, tt.content)
+		if verdict.OK != tt.ok {
+			t.Errorf("Validate(%s, %q) = %+v, want OK=%v", tt.persona, tt.content, verdict, tt.ok)
+		}
+		if !verdict.OK && verdict.Reason == "" {
+			t.Errorf("Validate(%s, %q) rejected without a reason", tt.persona, tt.content)
+		}
+	}
+}
+
+func TestComposeSkipsOffBrandCandidates(t *testing.T) {
+	s := NewSocialClient("", "")
+	// The wisdom pick changes with the hour; pin it
+	now := time.Date(2026, 3, 1, 9, 59, 59, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	calls := 0
+	s.SetToneValidator(ToneValidatorFunc(func(ctx context.Context, p PersonaConfig, content string) ToneVerdict {
+		calls++
+		if calls == 1 {
+			return ToneVerdict{Reason: "off-brand"}
+		}
+		return ToneVerdict{OK: true}
+	}))
+
+	persona := PersonaConfig{Name: "Jordan", ValidateTone: true}
+	post := s.ComposeForPersona(context.Background(), persona, nil, nil)
+	if post == nil {
+		t.Fatal("expected the next candidate to be posted after a rejection")
+	}
+	if want := composeWisdomAt("Jordan", now, 1); post.Content != want {
+		t.Errorf("post = %q, want the next wisdom line %q", post.Content, want)
+	}
+}
+
+func TestComposeGivesUpAfterRejectionLimit(t *testing.T) {
+	s := NewSocialClient("", "")
+	calls := 0
+	s.SetToneValidator(ToneValidatorFunc(func(ctx context.Context, p PersonaConfig, content string) ToneVerdict {
+		calls++
+		return ToneVerdict{Reason: "off-brand"}
+	}))
+
+	if post := s.ComposeForPersona(context.Background(), PersonaConfig{Name: "Jordan", ValidateTone: true}, nil, nil); post != nil {
+		t.Errorf("expected no post, got %q", post.Content)
+	}
+	if calls != maxToneRejections {
+		t.Errorf("validator called %d times, want %d", calls, maxToneRejections)
+	}
+
+	// Personas that haven't opted in are never validated
+	calls = 0
+	if post := s.ComposeForPersona(context.Background(), PersonaConfig{Name: "Jordan"}, nil, nil); post == nil || calls != 0 {
+		t.Errorf("opted-out persona: post=%v, validator calls=%d; want a post and 0 calls", post, calls)
+	}
+}
@@ -0,0 +1,105 @@
+// Package logging provides a minimal leveled logger used across tron's
+// long-lived components. The default implementation writes through the
+// standard library logger so existing log output keeps its format.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Level is a log severity
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger is the leveled logging interface injected into components
+type Logger interface {
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a Level.
+// Unknown values fall back to info.
+func ParseLevel(s string) Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// StdLogger writes leveled messages through a *log.Logger
+type StdLogger struct {
+	prefix string
+	level  Level
+	out    *log.Logger
+}
+
+// New creates a logger that tags messages with [prefix] and honours LOG_LEVEL
+func New(prefix string) *StdLogger {
+	return &StdLogger{
+		prefix: prefix,
+		level:  ParseLevel(os.Getenv("LOG_LEVEL")),
+		out:    log.Default(),
+	}
+}
+
+// WithLevel returns a copy of the logger with a different minimum level
+func (l *StdLogger) WithLevel(level Level) *StdLogger {
+	c := *l
+	c.level = level
+	return &c
+}
+
+func (l *StdLogger) logf(level Level, tag, format string, args ...any) {
+	if level < l.level {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if tag != "" {
+		msg = tag + " " + msg
+	}
+	if l.prefix != "" {
+		msg = "[" + l.prefix + "] " + msg
+	}
+	l.out.Print(msg)
+}
+
+// Debugf logs at debug level
+func (l *StdLogger) Debugf(format string, args ...any) { l.logf(LevelDebug, "DEBUG", format, args...) }
+
+// Infof logs at info level
+func (l *StdLogger) Infof(format string, args ...any) { l.logf(LevelInfo, "", format, args...) }
+
+// Warnf logs at warn level
+func (l *StdLogger) Warnf(format string, args ...any) { l.logf(LevelWarn, "WARN", format, args...) }
+
+// Errorf logs at error level
+func (l *StdLogger) Errorf(format string, args ...any) { l.logf(LevelError, "ERROR", format, args...) }
+
+// Discard returns a logger that drops everything (useful in tests)
+func Discard() Logger {
+	return discard{}
+}
+
+type discard struct{}
+
+func (discard) Debugf(string, ...any) {}
+func (discard) Infof(string, ...any)  {}
+func (discard) Warnf(string, ...any)  {}
+func (discard) Errorf(string, ...any) {}
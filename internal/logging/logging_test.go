@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Level
+	}{
+		{"debug", LevelDebug},
+		{"INFO", LevelInfo},
+		{"warn", LevelWarn},
+		{"warning", LevelWarn},
+		{"error", LevelError},
+		{"", LevelInfo},
+		{"verbose", LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseLevel(tt.input); got != tt.want {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStdLoggerFiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := &StdLogger{prefix: "test", level: LevelWarn, out: log.New(&buf, "", 0)}
+
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.Warnf("warn %d", 3)
+	l.Errorf("error %d", 4)
+
+	out := buf.String()
+	if strings.Contains(out, "debug 1") || strings.Contains(out, "info 2") {
+		t.Errorf("messages below warn were logged: %q", out)
+	}
+	if !strings.Contains(out, "[test] WARN warn 3") {
+		t.Errorf("missing warn line in %q", out)
+	}
+	if !strings.Contains(out, "[test] ERROR error 4") {
+		t.Errorf("missing error line in %q", out)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 const (
@@ -80,7 +82,7 @@ func Append(baseDir, callerName, summary string) error {
 		}
 	}
 
-	return os.WriteFile(path, []byte(newContent), 0644)
+	return persist.WriteFile(path, []byte(newContent))
 }
 
 // filterRecentEntries removes entries older than MaxMemoryAge
@@ -125,6 +127,43 @@ func filterRecentEntries(content string) string {
 	return strings.TrimSpace(result.String())
 }
 
+// CallsWith returns the entries in recent memory content for calls with the
+// named caller, newest first, each prefixed with its date
+func CallsWith(content, callerName string) string {
+	if content == "" || callerName == "" {
+		return ""
+	}
+
+	heading := strings.ToLower("### Call with " + callerName + " at ")
+	var result strings.Builder
+	var date string
+	inCall := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			date = strings.TrimPrefix(line, "## ")
+			inCall = false
+			continue
+		case strings.HasPrefix(line, "### "):
+			inCall = strings.HasPrefix(strings.ToLower(line), heading)
+			if inCall {
+				callTime := line[strings.LastIndex(line, " at ")+len(" at "):]
+				result.WriteString(fmt.Sprintf("%s, %s:\n", date, callTime))
+			}
+			continue
+		}
+		if inCall && strings.TrimSpace(line) != "" {
+			result.WriteString(line)
+			result.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSpace(result.String())
+}
+
 // GetPromptSection formats memory content for injection into system prompt
 func GetPromptSection(content string) string {
 	if content == "" {
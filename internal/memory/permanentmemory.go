@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 const (
@@ -56,7 +58,7 @@ func SaveDirective(baseDir, directive, category string) error {
 		newContent = existingContent + entry
 	}
 
-	return os.WriteFile(path, []byte(newContent), 0644)
+	return persist.WriteFile(path, []byte(newContent))
 }
 
 // LoadPersonMemory reads the memory file for a specific person
@@ -99,7 +101,7 @@ func SavePersonMemory(baseDir, personName, memory, category string) error {
 		newContent = existingContent + entry
 	}
 
-	return os.WriteFile(path, []byte(newContent), 0644)
+	return persist.WriteFile(path, []byte(newContent))
 }
 
 // ListPeopleMemories returns a list of all people with saved memories
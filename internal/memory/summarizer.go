@@ -0,0 +1,26 @@
+package memory
+
+import "context"
+
+// Summarizer condenses text into a short human-readable summary
+type Summarizer interface {
+	Summarize(ctx context.Context, text string) (string, error)
+}
+
+// SummarizerFunc adapts a function to the Summarizer interface
+type SummarizerFunc func(ctx context.Context, text string) (string, error)
+
+// Summarize calls f(ctx, text)
+func (f SummarizerFunc) Summarize(ctx context.Context, text string) (string, error) {
+	return f(ctx, text)
+}
+
+// ResultSummaryPrompt returns the prompt template for summarizing an agent's task result
+func ResultSummaryPrompt() string {
+	return `Summarize this task result for someone who will read it in an email or hear it on a phone call.
+- Lead with the outcome: what was done, or what failed
+- Keep concrete details that matter: names, numbers, URLs, next steps
+- Drop logs, code listings, and repetition
+
+Use at most 5 short sentences. No markdown headings.`
+}
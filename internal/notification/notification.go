@@ -9,13 +9,14 @@ const (
 	ChannelSlack ChannelType = "slack"
 	ChannelVoice ChannelType = "voice"
 	ChannelAPI   ChannelType = "api"
+	ChannelSMS   ChannelType = "sms"
 )
 
 // ChannelContext holds information about the channel that initiated a request
 type ChannelContext struct {
 	Type      ChannelType
 	ChannelID string // Slack channel ID
-	UserID    string // Slack user ID or phone number
+	UserID    string // Slack user ID or phone number (SMS sends here)
 	UserName  string // Display name for messages
 	Email     string // Email for voice callbacks (optional)
 }
@@ -0,0 +1,179 @@
+package notification
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+// Notification template names. A deployment overrides one by putting
+// <name>.tmpl in its templates directory.
+const (
+	TemplateSlackComplete     = "slack-complete"      // CompletionData
+	TemplateEmailComplete     = "email-complete"      // CompletionData
+	TemplateBatchEmail        = "batch-email"         // BatchData
+	TemplateVoiceFirstMessage = "voice-first-message" // CompletionData
+	TemplateSMSComplete       = "sms-complete"        // CompletionData
+)
+
+// DefaultPersona signs notifications when no persona is given
+const DefaultPersona = "Tony"
+
+//go:embed templates/*.tmpl
+var defaultTemplateFS embed.FS
+
+// CompletionData is what single-task templates receive
+type CompletionData struct {
+	Persona       string // Who the notification is from
+	RecipientName string
+	AgentName     string
+	AgentID       string
+	Task          string
+	Project       string
+	Success       bool
+	Result        string
+	Error         string
+	Shortened     bool   // Whether Result was cut short or summarized
+	Stats         string // One-line duration/cost summary, if known
+	ViewURL       string
+}
+
+// BatchData is what the batch-email template receives
+type BatchData struct {
+	Persona       string
+	RecipientName string
+	ViewURL       string
+	Results       []CompletionData
+}
+
+// templateFuncs are available to every template. truncate takes the length
+// first so it works in pipelines: {{.Result | truncate 100}}.
+var templateFuncs = template.FuncMap{
+	"truncate": func(maxLen int, s string) string {
+		if maxLen < 4 {
+			return s
+		}
+		return textutil.Shorten(s, maxLen)
+	},
+}
+
+// sampleData is rendered through overrides at load so a typo'd field fails
+// at startup instead of on the first notification
+var sampleData = map[string]any{
+	TemplateSlackComplete:     CompletionData{},
+	TemplateEmailComplete:     CompletionData{},
+	TemplateBatchEmail:        BatchData{Results: []CompletionData{{}}},
+	TemplateVoiceFirstMessage: CompletionData{},
+	TemplateSMSComplete:       CompletionData{},
+}
+
+// Templates renders notifications, using deployment overrides where given
+// and the built-in wording otherwise. A nil *Templates uses the built-ins.
+type Templates struct {
+	overrides map[string]*template.Template
+}
+
+var defaults = mustParseDefaults()
+
+func mustParseDefaults() map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(sampleData))
+	for name := range sampleData {
+		data, err := defaultTemplateFS.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			panic(fmt.Sprintf("missing default notification template %s: %v", name, err))
+		}
+		parsed[name] = template.Must(template.New(name).Funcs(templateFuncs).Parse(string(data)))
+	}
+	return parsed
+}
+
+// LoadTemplates reads <name>.tmpl overrides from dir. Names without a file
+// keep the built-in template; an unknown .tmpl file is an error, since it's
+// most likely a misspelled name.
+func LoadTemplates(dir string) (*Templates, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{overrides: make(map[string]*template.Template)}
+	for _, path := range files {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		sample, ok := sampleData[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown notification template %q (known: %s)", name, strings.Join(TemplateNames(), ", "))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification template %s: %w", name, err)
+		}
+		if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+			return nil, fmt.Errorf("invalid notification template %s: %w", name, err)
+		}
+		t.overrides[name] = tmpl
+	}
+	return t, nil
+}
+
+// TemplateNames returns the known template names, sorted
+func TemplateNames() []string {
+	names := make([]string, 0, len(sampleData))
+	for name := range sampleData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Overrides returns the names of templates loaded from the deployment
+func (t *Templates) Overrides() []string {
+	if t == nil {
+		return nil
+	}
+	names := make([]string, 0, len(t.overrides))
+	for name := range t.overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render executes the named template. If an override fails, the built-in
+// template's output is returned along with the error, so the notification
+// can still go out.
+func (t *Templates) Render(name string, data any) (string, error) {
+	def, ok := defaults[name]
+	if !ok {
+		return "", fmt.Errorf("unknown notification template %q", name)
+	}
+
+	var sb strings.Builder
+	if t != nil {
+		if tmpl, ok := t.overrides[name]; ok {
+			err := tmpl.Execute(&sb, data)
+			if err == nil {
+				return sb.String(), nil
+			}
+			sb.Reset()
+			if defErr := def.Execute(&sb, data); defErr != nil {
+				return "", defErr
+			}
+			return sb.String(), fmt.Errorf("notification template %s failed, used the default: %w", name, err)
+		}
+	}
+
+	if err := def.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
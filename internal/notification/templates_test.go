@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTemplates(t *testing.T) {
+	var tmpl *Templates // nil uses the built-ins
+
+	email, err := tmpl.Render(TemplateEmailComplete, CompletionData{
+		Persona: "Maya", RecipientName: "Sam", AgentName: "Gary", AgentID: "proc-1",
+		Task: "Build the site", Project: "site", Success: true, Result: "Done.",
+		Stats: "took 2m", ViewURL: "https://site.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEmail := "Hey Sam,\n\nGary has finished working on your task.\n\n" +
+		"**Task:** Build the site\n**Project:** site\n\n**Result:**\nDone.\n\n" +
+		"**Stats:** took 2m\n\nView the project: https://site.example.com\n\n" +
+		"---\nAgent ID: proc-1\nThis is an automated notification from Maya.\n"
+	if email != wantEmail {
+		t.Errorf("email-complete =\n%q\nwant\n%q", email, wantEmail)
+	}
+
+	failed, _ := tmpl.Render(TemplateEmailComplete, CompletionData{Persona: "Tony", AgentName: "Gary", Task: "t", Error: "boom"})
+	if !strings.HasPrefix(failed, "Hey,\n\nGary encountered an issue") || !strings.Contains(failed, "**Error:**\nboom\n") {
+		t.Errorf("failed email-complete = %q", failed)
+	}
+
+	batch, _ := tmpl.Render(TemplateBatchEmail, BatchData{
+		Persona: "Tony",
+		Results: []CompletionData{
+			{AgentName: "Gary", Task: "a", Success: true, Result: strings.Repeat("x", 150)},
+			{AgentName: "Sarah", Task: "b", Error: "nope", Stats: "took 1m"},
+		},
+	})
+	wantBatch := "Hey,\n\nYour tasks have been completed. Here's a summary:\n\n" +
+		"✓ **Gary** - a\n  Result: " + strings.Repeat("x", 97) + "...\n\n" +
+		"✗ **Sarah** - b\n  Error: nope\n  Stats: took 1m\n\n" +
+		"\n---\nThis is an automated notification from Tony.\n"
+	if batch != wantBatch {
+		t.Errorf("batch-email =\n%q\nwant\n%q", batch, wantBatch)
+	}
+
+	voice, _ := tmpl.Render(TemplateVoiceFirstMessage, CompletionData{Persona: DefaultPersona, AgentName: "Gary", Task: "ship it"})
+	if want := "Hey, this is Tony. I'm calling to let you know that Gary has finished working on ship it."; voice != want {
+		t.Errorf("voice-first-message = %q, want %q", voice, want)
+	}
+
+	text, _ := tmpl.Render(TemplateSMSComplete, CompletionData{AgentName: "Gary", Task: "ship it", Success: true, Result: "Live.", Stats: "took 2m", ViewURL: "https://site.example.com"})
+	if want := `Gary finished "ship it" (took 2m) https://site.example.com: Live.`; text != want {
+		t.Errorf("sms-complete = %q, want %q", text, want)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("slack-complete.tmpl", "{{.Persona}} says {{.AgentName}} is done")
+	tmpl, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.Overrides(); len(got) != 1 || got[0] != TemplateSlackComplete {
+		t.Errorf("Overrides() = %v", got)
+	}
+	if got, _ := tmpl.Render(TemplateSlackComplete, CompletionData{Persona: "Acme", AgentName: "Gary"}); got != "Acme says Gary is done" {
+		t.Errorf("override rendered %q", got)
+	}
+	if got, _ := tmpl.Render(TemplateVoiceFirstMessage, CompletionData{Persona: "Acme", AgentName: "Gary"}); !strings.HasPrefix(got, "Hey, this is Acme.") {
+		t.Errorf("templates without an override should use the default, got %q", got)
+	}
+
+	// Bad field names and unknown template files fail at load
+	write("slack-complete.tmpl", "{{.Agent}}")
+	if _, err := LoadTemplates(dir); err == nil {
+		t.Error("expected an unknown field to fail at load")
+	}
+	os.Remove(filepath.Join(dir, "slack-complete.tmpl"))
+	write("slack-done.tmpl", "hi")
+	if _, err := LoadTemplates(dir); err == nil || !strings.Contains(err.Error(), "slack-done") {
+		t.Errorf("err = %v, want unknown template slack-done", err)
+	}
+}
@@ -0,0 +1,51 @@
+package persist
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// syncFile flushes a temp file to disk; tests swap it to simulate a crash
+var syncFile = (*os.File).Sync
+
+// WriteFile replaces path with data atomically. It writes a temp file in the
+// same directory, fsyncs it and renames it over path, so a crash or a
+// concurrent writer leaves either the old file or the new one, never a
+// truncated mix. Missing parent directories are created.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := syncFile(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself; not every platform can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package persist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileReplacesContents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	if err := WriteFile(path, []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("two")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "two" {
+		t.Errorf("contents = %q, want two", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected only the target file, found %d entries", len(entries))
+	}
+}
+
+func TestWriteFileInterruptedKeepsPreviousFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := WriteFile(path, []byte(`{"good": true}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fail after the new data is partly on disk, as a crash would
+	crash := errors.New("power lost")
+	syncFile = func(f *os.File) error {
+		f.Truncate(3)
+		return crash
+	}
+	defer func() { syncFile = (*os.File).Sync }()
+
+	if err := WriteFile(path, []byte(`{"good": false, "padding": "xxxxxxxx"}`)); !errors.Is(err, crash) {
+		t.Fatalf("WriteFile = %v, want the simulated crash", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"good": true}` {
+		t.Errorf("previous file was clobbered: %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("interrupted write left %d entries, want just the original", len(entries))
+	}
+}
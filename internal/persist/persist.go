@@ -0,0 +1,100 @@
+// Package persist is the storage backend for Tron's saved state. Stores
+// save whole JSON documents under slash-separated keys, so the default
+// file backend keeps the existing on-disk layout and another backend
+// (SQLite, object storage) only has to implement Store.
+package persist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get for a key that has never been saved
+var ErrNotFound = errors.New("persist: not found")
+
+// Store saves and loads blobs by key, e.g. "tron.work/callbacks.json".
+// Put must replace a key's value atomically: a reader sees the old value
+// or the new one, never a partial write.
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, data []byte) error
+	Delete(key string) error
+}
+
+// FileStore keeps each key as a file under a base directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Path returns the file a key is stored in
+func (s *FileStore) Path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Get reads a key's file
+func (s *FileStore) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(s.Path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put writes a key's file atomically with WriteFile
+func (s *FileStore) Put(key string, data []byte) error {
+	return WriteFile(s.Path(key), data)
+}
+
+// Delete removes a key's file. Deleting a missing key is not an error.
+func (s *FileStore) Delete(key string) error {
+	if err := os.Remove(s.Path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SaveJSON writes v as indented JSON under key
+func SaveJSON(s Store, key string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return s.Put(key, data)
+}
+
+// LoadJSON reads key into v. It returns false with no error if the key was
+// never saved. Data that doesn't parse is moved to "<key>.corrupt-<time>"
+// so the next save doesn't overwrite what's left of it, and an error is
+// returned; v is left as it was so the caller can start fresh.
+func LoadJSON(s Store, key string, v any) (bool, error) {
+	data, err := s.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		aside := fmt.Sprintf("%s.corrupt-%s", key, time.Now().UTC().Format("20060102T150405"))
+		if putErr := s.Put(aside, data); putErr == nil {
+			s.Delete(key)
+			return false, fmt.Errorf("%s is corrupt, moved to %s: %w", key, aside, err)
+		}
+		return false, fmt.Errorf("%s is corrupt: %w", key, err)
+	}
+	return true, nil
+}
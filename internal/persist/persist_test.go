@@ -0,0 +1,68 @@
+package persist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStoreJSON(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+
+	var got map[string]int
+	if ok, err := LoadJSON(s, "tron.work/state.json", &got); ok || err != nil {
+		t.Fatalf("LoadJSON on a missing key = %v, %v; want false, nil", ok, err)
+	}
+	if _, err := s.Get("tron.work/state.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get on a missing key = %v, want ErrNotFound", err)
+	}
+
+	if err := SaveJSON(s, "tron.work/state.json", map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	// Keys map onto the existing file layout
+	if _, err := os.Stat(filepath.Join(dir, "tron.work", "state.json")); err != nil {
+		t.Errorf("expected the key's file on disk: %v", err)
+	}
+	if ok, err := LoadJSON(s, "tron.work/state.json", &got); !ok || err != nil || got["a"] != 1 {
+		t.Errorf("LoadJSON = %v, %v, %v", ok, err, got)
+	}
+
+	if err := s.Delete("tron.work/state.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("tron.work/state.json"); err != nil {
+		t.Errorf("deleting a missing key should succeed, got %v", err)
+	}
+}
+
+func TestLoadJSONMovesCorruptDataAside(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+	if err := s.Put("state.json", []byte(`{"a": 1`)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{"kept": 1}
+	ok, err := LoadJSON(s, "state.json", &got)
+	if ok || err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("LoadJSON = %v, %v; want a corrupt error", ok, err)
+	}
+	if got["kept"] != 1 {
+		t.Errorf("v was modified: %v", got)
+	}
+
+	if _, err := s.Get("state.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("corrupt key should be cleared, got %v", err)
+	}
+	aside, _ := filepath.Glob(filepath.Join(dir, "state.json.corrupt-*"))
+	if len(aside) != 1 {
+		t.Fatalf("expected one corrupt copy, found %v", aside)
+	}
+	if data, _ := os.ReadFile(aside[0]); string(data) != `{"a": 1` {
+		t.Errorf("corrupt copy = %q", data)
+	}
+}
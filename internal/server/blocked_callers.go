@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultBlockedCallerMessage is said to blocked callers before hanging up
+const defaultBlockedCallerMessage = "Sorry, this number can't be connected. Goodbye."
+
+// SetBlockedCallerMessage sets what blocked callers hear
+func (s *Server) SetBlockedCallerMessage(msg string) {
+	s.blockedCallerMessage = msg
+}
+
+// blockedMessage returns the configured rejection message
+func (s *Server) blockedMessage() string {
+	if s.blockedCallerMessage != "" {
+		return s.blockedCallerMessage
+	}
+	return defaultBlockedCallerMessage
+}
+
+// isBlockedCaller reports whether the caller's number is on the blocklist.
+// phone must come from the telephony provider or a signed session; a number
+// the client supplies itself could simply be left off to dodge the block.
+func (s *Server) isBlockedCaller(phone string) bool {
+	return phone != "" && s.customTools.IsBlocked(phone)
+}
+
+// writeBlockedCallerResponse answers a chat completion with the rejection
+// message instead of running the agent
+func (s *Server) writeBlockedCallerResponse(w http.ResponseWriter, stream bool) {
+	finishReason := "stop"
+	response := ChatCompletionResponse{
+		ID:      fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano()),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   "tony",
+		Choices: []Choice{{
+			Index:        0,
+			Message:      &ChatMessage{Role: "assistant", Content: s.blockedMessage()},
+			FinishReason: &finishReason,
+		}},
+	}
+
+	if !stream {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	response.Object = "chat.completion.chunk"
+	response.Choices[0].Message = nil
+	response.Choices[0].Delta = &ChatDelta{Role: "assistant", Content: s.blockedMessage()}
+	s.writeSSE(w, flusher, response)
+	fmt.Fprintf(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
@@ -53,6 +53,19 @@ func (s *Server) handleElevenLabsWS(w http.ResponseWriter, r *http.Request) {
 	}
 	defer clientConn.Close()
 
+	// Reject blocked numbers before opening a session. A browser session
+	// only has a number when it's signed (see verifiedCaller), so unsigned
+	// sessions aren't screened: the block is advisory for them
+	phone := s.verifiedCaller(r)
+	if s.isBlockedCaller(phone) {
+		log.Printf("Rejecting blocked caller %s", maskPhone(phone))
+		clientConn.WriteJSON(map[string]string{
+			"type":    "rejected",
+			"message": s.blockedMessage(),
+		})
+		return
+	}
+
 	// Connect to ElevenLabs
 	ctx := r.Context()
 	elSession, err := s.elevenLabsClient.Connect(ctx)
@@ -66,12 +79,22 @@ func (s *Server) handleElevenLabsWS(w http.ResponseWriter, r *http.Request) {
 	}
 	defer elSession.Close()
 
+	// Greet known callers with what we remember about them, but only when
+	// the session's number is signed: otherwise anyone could claim it
+	if init, ok := s.callerConversationInit(phone); ok {
+		if err := elSession.SendInitiation(init); err != nil {
+			log.Printf("Failed to send caller context to ElevenLabs: %v", err)
+		}
+	}
+
 	session := &ElevenLabsSession{
 		ClientConn:     clientConn,
 		ElevenLabsConn: elSession,
 		ConversationID: elSession.ConversationID(),
 		StartTime:      time.Now(),
 	}
+	s.trackVoiceSession(session)
+	defer s.untrackVoiceSession(session)
 
 	// Send session info to client
 	clientConn.WriteJSON(map[string]string{
@@ -81,7 +104,7 @@ func (s *Server) handleElevenLabsWS(w http.ResponseWriter, r *http.Request) {
 
 	// Start forwarding goroutines
 	var wg sync.WaitGroup
-	wg.Add(3)
+	wg.Add(4)
 
 	go func() {
 		defer wg.Done()
@@ -98,6 +121,11 @@ func (s *Server) handleElevenLabsWS(w http.ResponseWriter, r *http.Request) {
 		s.forwardTranscriptsToClient(session)
 	}()
 
+	go func() {
+		defer wg.Done()
+		s.forwardInterruptionsToClient(session)
+	}()
+
 	wg.Wait()
 }
 
@@ -145,6 +173,11 @@ func (s *Server) forwardClientToElevenLabs(session *ElevenLabsSession) {
 				log.Printf("Failed to send text: %v", err)
 			}
 
+		case "interrupt":
+			if err := session.ElevenLabsConn.Interrupt(); err != nil {
+				log.Printf("Failed to interrupt agent: %v", err)
+			}
+
 		case "end":
 			session.ElevenLabsConn.Close()
 			return
@@ -173,13 +206,17 @@ func (s *Server) forwardElevenLabsAudioToClient(session *ElevenLabsSession) {
 
 func (s *Server) forwardTranscriptsToClient(session *ElevenLabsSession) {
 	for transcript := range session.ElevenLabsConn.Transcripts() {
-		session.mu.Lock()
-		err := session.ClientConn.WriteJSON(map[string]interface{}{
+		msg := map[string]interface{}{
 			"type":     "transcript",
 			"role":     transcript.Role,
 			"text":     transcript.Text,
 			"is_final": transcript.IsFinal,
-		})
+		}
+		if transcript.Confidence != elevenlabs.NoConfidence {
+			msg["confidence"] = transcript.Confidence
+		}
+		session.mu.Lock()
+		err := session.ClientConn.WriteJSON(msg)
 		session.mu.Unlock()
 
 		if err != nil {
@@ -189,6 +226,22 @@ func (s *Server) forwardTranscriptsToClient(session *ElevenLabsSession) {
 	}
 }
 
+// forwardInterruptionsToClient tells the client to stop playing queued agent audio
+func (s *Server) forwardInterruptionsToClient(session *ElevenLabsSession) {
+	for range session.ElevenLabsConn.Interruptions() {
+		session.mu.Lock()
+		err := session.ClientConn.WriteJSON(map[string]string{
+			"type": "interrupt",
+		})
+		session.mu.Unlock()
+
+		if err != nil {
+			log.Printf("Failed to send interrupt to client: %v", err)
+			return
+		}
+	}
+}
+
 // OpenAI-compatible types for ElevenLabs LLM endpoint
 type openAIChatRequest struct {
 	Model       string          `json:"model"`
@@ -260,6 +313,12 @@ func (s *Server) handleElevenLabsLLM(w http.ResponseWriter, r *http.Request) {
 		conversationID = hex.EncodeToString(hash[:8])
 	}
 
+	if s.isBlockedCaller(userID) {
+		log.Printf("Rejecting blocked caller %s", maskPhone(userID))
+		s.writeBlockedCallerResponse(w, req.Stream)
+		return
+	}
+
 	// Get Tony definition
 	tonyDef, ok := s.config.Agents["Tony"]
 	if !ok {
@@ -275,10 +334,8 @@ func (s *Server) handleElevenLabsLLM(w http.ResponseWriter, r *http.Request) {
 
 	// Add caller context if available
 	if userID != "" {
-		callerInfo := s.customTools.IdentifyCaller(userID)
-		if callerInfo != "" {
-			systemPrompt += fmt.Sprintf("\n\n## Current Caller\n%s", callerInfo)
-		}
+		systemPrompt += s.customTools.CallerPromptSection(userID)
+		systemPrompt += s.callerMemoryPromptSection(userID)
 	}
 
 	// Load memory
@@ -1,13 +1,14 @@
 package server
 
 import (
-	"encoding/json"
+	"log"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 const (
@@ -26,6 +27,8 @@ const (
 	HistorySessionStart  HistoryEntryType = "session_start"
 	HistorySessionEnd    HistoryEntryType = "session_end"
 	HistoryError         HistoryEntryType = "error"
+	HistoryCallbackCall  HistoryEntryType = "callback_call"
+	HistoryProcessSpawn  HistoryEntryType = "process_spawn"
 )
 
 // HistoryEntry represents a single historical event
@@ -40,6 +43,12 @@ type HistoryEntry struct {
 	DurationMs int64             `json:"duration_ms,omitempty"`
 	Metrics    *HistoryMetrics   `json:"metrics,omitempty"`
 	Error      string            `json:"error,omitempty"`
+	Tool       string            `json:"tool,omitempty"`
+	ErrorType  string            `json:"error_type,omitempty"`
+	CallID     string            `json:"call_id,omitempty"`
+	Recipient  string            `json:"recipient,omitempty"` // Masked phone number
+	Parent     string            `json:"parent,omitempty"`    // Agent that spawned this one
+	ParentID   string            `json:"parent_id,omitempty"` // Its process ID
 }
 
 // HistoryMetrics contains metrics for a completed process
@@ -50,6 +59,16 @@ type HistoryMetrics struct {
 	LLMCalls      int     `json:"llm_calls,omitempty"`
 	ToolCalls     int     `json:"tool_calls,omitempty"`
 	EstimatedCost float64 `json:"estimated_cost,omitempty"`
+	Model         string  `json:"model,omitempty"`
+}
+
+// tokens returns the total tokens used, working it out from input and
+// output for entries that didn't record a total
+func (m *HistoryMetrics) tokens() int {
+	if m.TotalTokens > 0 {
+		return m.TotalTokens
+	}
+	return m.InputTokens + m.OutputTokens
 }
 
 // HistorySummary contains aggregate statistics
@@ -63,6 +82,11 @@ type HistorySummary struct {
 	ByStatus        map[string]int            `json:"by_status"`
 	AvgDurationMs   int64                     `json:"avg_duration_ms"`
 	TotalCost       float64                   `json:"total_cost"`
+	CostByAgent     map[string]float64        `json:"cost_by_agent"`
+	TokensByAgent   map[string]int            `json:"tokens_by_agent"`
+	CostByModel     map[string]float64        `json:"cost_by_model"`
+	ErrorsByTool    map[string]int            `json:"errors_by_tool"`
+	ErrorsByType    map[string]int            `json:"errors_by_type"`
 }
 
 // HistoryResponse is the API response for /api/history
@@ -75,17 +99,30 @@ type HistoryResponse struct {
 type HistoryStore struct {
 	entries []HistoryEntry
 	mu      sync.RWMutex
-	baseDir string
+	store   persist.Store
 }
 
-// NewHistoryStore creates a new history store
+// NewHistoryStore creates a new history store saved under baseDir, or the
+// home directory if baseDir is empty
 func NewHistoryStore(baseDir string) *HistoryStore {
-	store := &HistoryStore{
+	if baseDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = "."
+		}
+		baseDir = home
+	}
+	return NewHistoryStoreWithBackend(persist.NewFileStore(baseDir))
+}
+
+// NewHistoryStoreWithBackend creates a new history store saved in store
+func NewHistoryStoreWithBackend(store persist.Store) *HistoryStore {
+	h := &HistoryStore{
 		entries: make([]HistoryEntry, 0),
-		baseDir: baseDir,
+		store:   store,
 	}
-	store.load()
-	return store
+	h.load()
+	return h
 }
 
 // Record adds a new history entry
@@ -108,16 +145,83 @@ func (h *HistoryStore) Record(entry HistoryEntry) {
 	h.save()
 }
 
+// RecordCall records a callback call, updating the entry already recorded
+// for entry.CallID (when it was placed) with its final status and length
+func (h *HistoryStore) RecordCall(entry HistoryEntry) {
+	entry.Type = HistoryCallbackCall
+	if entry.CallID != "" {
+		h.mu.Lock()
+		for i := len(h.entries) - 1; i >= 0; i-- {
+			e := &h.entries[i]
+			if e.Type == HistoryCallbackCall && e.CallID == entry.CallID {
+				e.Status = entry.Status
+				if entry.DurationMs > 0 {
+					e.DurationMs = entry.DurationMs
+				}
+				h.save()
+				h.mu.Unlock()
+				return
+			}
+		}
+		h.mu.Unlock()
+	}
+	h.Record(entry)
+}
+
+// QueryFilter selects history entries. Zero fields match everything.
+type QueryFilter struct {
+	Since        time.Time        // Only entries after this time
+	Agent        string           // Exact agent name
+	Type         HistoryEntryType // Exact entry type
+	Status       string           // Exact status
+	MinDuration  time.Duration    // Only entries that took at least this long
+	TextContains string           // Case-insensitive match on task, error or tool
+}
+
+// matches reports whether an entry passes the filter
+func (f QueryFilter) matches(entry HistoryEntry) bool {
+	if !f.Since.IsZero() && !entry.Timestamp.After(f.Since) {
+		return false
+	}
+	if f.Agent != "" && entry.Agent != f.Agent {
+		return false
+	}
+	if f.Type != "" && entry.Type != f.Type {
+		return false
+	}
+	if f.Status != "" && entry.Status != f.Status {
+		return false
+	}
+	if f.MinDuration > 0 && time.Duration(entry.DurationMs)*time.Millisecond < f.MinDuration {
+		return false
+	}
+	if f.TextContains != "" {
+		text := strings.ToLower(f.TextContains)
+		if !strings.Contains(strings.ToLower(entry.Task), text) &&
+			!strings.Contains(strings.ToLower(entry.Error), text) &&
+			!strings.Contains(strings.ToLower(entry.Tool), text) {
+			return false
+		}
+	}
+	return true
+}
+
 // Query returns entries within the specified number of days
 func (h *HistoryStore) Query(days int) HistoryResponse {
+	return h.QueryFiltered(QueryFilter{
+		Since: time.Now().Add(-time.Duration(days) * 24 * time.Hour),
+	})
+}
+
+// QueryFiltered returns the entries matching filter, newest first, with a
+// summary of just those entries
+func (h *HistoryStore) QueryFiltered(filter QueryFilter) HistoryResponse {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
 	filtered := make([]HistoryEntry, 0)
-
 	for _, entry := range h.entries {
-		if entry.Timestamp.After(cutoff) {
+		if filter.matches(entry) {
 			filtered = append(filtered, entry)
 		}
 	}
@@ -140,6 +244,11 @@ func (h *HistoryStore) buildSummary(entries []HistoryEntry) HistorySummary {
 		ByAgent:      make(map[string]int),
 		ByDay:        make(map[string]int),
 		ByStatus:     make(map[string]int),
+		ErrorsByTool: make(map[string]int),
+		ErrorsByType: make(map[string]int),
+		CostByAgent:   make(map[string]float64),
+		TokensByAgent: make(map[string]int),
+		CostByModel:   make(map[string]float64),
 	}
 
 	var totalDuration int64
@@ -168,17 +277,31 @@ func (h *HistoryStore) buildSummary(entries []HistoryEntry) HistorySummary {
 			summary.TotalSessions++
 		case HistoryError:
 			summary.TotalErrors++
+			if entry.Tool != "" {
+				summary.ErrorsByTool[entry.Tool]++
+			}
+			if entry.ErrorType != "" {
+				summary.ErrorsByType[entry.ErrorType]++
+			}
 		}
 
-		// Track duration
-		if entry.DurationMs > 0 {
+		// Track duration (calls last much less than processes, so they'd
+		// skew the average)
+		if entry.DurationMs > 0 && entry.Type != HistoryCallbackCall {
 			totalDuration += entry.DurationMs
 			durationCount++
 		}
 
-		// Track cost
-		if entry.Metrics != nil {
-			summary.TotalCost += entry.Metrics.EstimatedCost
+		// Track cost and tokens
+		if m := entry.Metrics; m != nil {
+			summary.TotalCost += m.EstimatedCost
+			if entry.Agent != "" {
+				summary.CostByAgent[entry.Agent] += m.EstimatedCost
+				summary.TokensByAgent[entry.Agent] += m.tokens()
+			}
+			if m.Model != "" {
+				summary.CostByModel[m.Model] += m.EstimatedCost
+			}
 		}
 	}
 
@@ -208,19 +331,16 @@ func (h *HistoryStore) prune() {
 	h.entries = filtered
 }
 
-// load reads history from disk
-func (h *HistoryStore) load() {
-	path := h.filePath()
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			// Log error but continue with empty history
-		}
-		return
-	}
+// historyKey is where history is saved
+const historyKey = ".tronvega/" + historyFileName
 
+// load reads saved history
+func (h *HistoryStore) load() {
 	var entries []HistoryEntry
-	if err := json.Unmarshal(data, &entries); err != nil {
+	if ok, err := persist.LoadJSON(h.store, historyKey, &entries); err != nil {
+		log.Printf("[history] Failed to load history: %v", err)
+		return
+	} else if !ok {
 		return
 	}
 
@@ -228,36 +348,11 @@ func (h *HistoryStore) load() {
 	h.prune()
 }
 
-// save writes history to disk
+// save writes history to the store
 func (h *HistoryStore) save() {
-	path := h.filePath()
-
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return
-	}
-
-	data, err := json.MarshalIndent(h.entries, "", "  ")
-	if err != nil {
-		return
+	if err := persist.SaveJSON(h.store, historyKey, h.entries); err != nil {
+		log.Printf("[history] Failed to save history: %v", err)
 	}
-
-	os.WriteFile(path, data, 0644)
-}
-
-// filePath returns the path to the history file
-func (h *HistoryStore) filePath() string {
-	if h.baseDir != "" {
-		return filepath.Join(h.baseDir, ".tronvega", historyFileName)
-	}
-
-	// Fall back to home directory
-	home, err := os.UserHomeDir()
-	if err != nil {
-		home = "."
-	}
-	return filepath.Join(home, ".tronvega", historyFileName)
 }
 
 // generateHistoryID creates a unique ID for a history entry
@@ -281,9 +376,8 @@ type SpawnPatternSummary struct {
 	CommonPatterns []SpawnPattern `json:"common_patterns"`  // Parent→Child frequencies
 }
 
-// BuildSpawnPatterns analyzes spawn history and returns pattern summary
-// Note: This is a simplified implementation that tracks spawn events.
-// For full accuracy, spawn events should be recorded in history.
+// BuildSpawnPatterns analyzes the process_spawn events within the specified
+// number of days: who spawns whom, and how deep spawn chains go
 func (h *HistoryStore) BuildSpawnPatterns(days int) SpawnPatternSummary {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -295,34 +389,41 @@ func (h *HistoryStore) BuildSpawnPatterns(days int) SpawnPatternSummary {
 		CommonPatterns: make([]SpawnPattern, 0),
 	}
 
-	// Track spawn patterns from process start events
-	// We infer spawns from process_start events - the spawner is typically
-	// a persona (Tony, Maya, etc.) and the spawned is a team member
 	patternCounts := make(map[string]int)
-
-	// Known personas (spawners)
-	personas := map[string]bool{
-		"Tony": true, "Maya": true, "Alex": true, "Jordan": true, "Riley": true,
-	}
+	parentOf := make(map[string]string) // Process ID -> parent process ID
 
 	for _, entry := range h.entries {
-		if entry.Timestamp.Before(cutoff) {
+		if entry.Type != HistoryProcessSpawn || entry.Timestamp.Before(cutoff) {
 			continue
 		}
 
-		if entry.Type == HistoryProcessStart && entry.Agent != "" {
-			// Track who gets spawned
-			summary.SpawnedByAgent[entry.Agent]++
-			summary.TotalSpawns++
-
-			// If this is a team member being spawned, attribute to their manager
-			// This is a heuristic - ideally we'd track parent in history
-			if !personas[entry.Agent] {
-				// Find which persona likely spawned this agent
-				// For now, count all spawned agents
-				// In a full implementation, we'd track the parent ID
+		summary.TotalSpawns++
+		summary.SpawnedByAgent[entry.Agent]++
+		if entry.Parent != "" {
+			summary.SpawnsByAgent[entry.Parent]++
+			patternCounts[entry.Parent+"→"+entry.Agent]++
+		}
+		if entry.ProcessID != "" && entry.ParentID != "" {
+			parentOf[entry.ProcessID] = entry.ParentID
+		}
+	}
+
+	// A spawned process's depth is how many spawns led to it, found by
+	// walking up its parents until one that wasn't spawned itself. The
+	// bound guards against a cycle in corrupt history.
+	if summary.TotalSpawns > 0 {
+		summary.MaxDepth = 1
+	}
+	for id := range parentOf {
+		depth := 1
+		for parent := parentOf[id]; depth <= len(parentOf); depth++ {
+			grandparent, spawned := parentOf[parent]
+			if !spawned {
+				break
 			}
+			parent = grandparent
 		}
+		summary.MaxDepth = max(summary.MaxDepth, depth)
 	}
 
 	// Convert pattern counts to sorted list
@@ -353,9 +454,17 @@ func splitPattern(pattern string) []string {
 	return strings.Split(pattern, "→")
 }
 
-// sortPatterns sorts patterns by count descending
+// sortPatterns sorts patterns by count descending, then by name so the
+// top 10 is stable
 func sortPatterns(patterns []SpawnPattern) {
 	sort.Slice(patterns, func(i, j int) bool {
-		return patterns[i].Count > patterns[j].Count
+		a, b := patterns[i], patterns[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Parent != b.Parent {
+			return a.Parent < b.Parent
+		}
+		return a.Child < b.Child
 	})
 }
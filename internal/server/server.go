@@ -1,7 +1,9 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -44,6 +46,18 @@ type Server struct {
 	// ElevenLabs client for voice
 	elevenLabsClient *elevenlabs.Client
 
+	// Inject known callers' memory into voice calls (opt-in)
+	voiceCallerContext bool
+
+	// Secret a browser voice session's ?phone= must be signed with
+	voiceCallerSecret string
+
+	// What blocked callers hear (defaults to defaultBlockedCallerMessage)
+	blockedCallerMessage string
+
+	// Bearer token the admin data endpoints require (empty refuses them all)
+	adminToken string
+
 	// Slack handlers (legacy single handler or per-persona handlers)
 	slackHandler  *slack.Handler            // Legacy single handler
 	slackHandlers map[string]*slack.Handler // Per-persona handlers (persona -> handler)
@@ -60,6 +74,10 @@ type Server struct {
 
 	// History store for activity logging
 	historyStore *HistoryStore
+
+	// Open ElevenLabs voice sessions, closed on shutdown
+	voiceSessions   map[*ElevenLabsSession]struct{}
+	voiceSessionsMu sync.Mutex
 }
 
 // LifeManager interface for managing multiple persona life loops (to avoid circular imports)
@@ -67,14 +85,17 @@ type LifeManager interface {
 	TriggerActivity(persona, activity string) string
 	TriggerActivityAll(activity string) map[string]string
 	Personas() []string
+	Stop()
 }
 
-// New creates a new server instance
-func New(orch *vega.Orchestrator, config *dsl.Document, customTools *tools.PersonaTools, port int, workingDir string) *Server {
+// New creates a new server instance. subdomainOpts configure project server
+// routing, e.g. subdomain.WithDomain for a self-hosted base domain.
+func New(orch *vega.Orchestrator, config *dsl.Document, customTools *tools.PersonaTools, port int, workingDir string, subdomainOpts ...subdomain.Option) *Server {
 	// Initialize subdomain routing with persistence
 	dataDir := filepath.Join(workingDir, "vega.work", "data")
-	subdomainReg := subdomain.NewRegistry(dataDir)
+	subdomainReg := subdomain.NewRegistryWithOptions(append([]subdomain.Option{subdomain.WithDataDir(dataDir)}, subdomainOpts...)...)
 	procManager := subdomain.NewProcessManager(subdomainReg)
+	subdomainReg.SetRouteHook(procManager.Touch)
 
 	s := &Server{
 		orch:              orch,
@@ -89,6 +110,7 @@ func New(orch *vega.Orchestrator, config *dsl.Document, customTools *tools.Perso
 		processManager:    procManager,
 		historyStore:      NewHistoryStore(""),
 		slackHandlers:     make(map[string]*slack.Handler),
+		voiceSessions:     make(map[*ElevenLabsSession]struct{}),
 	}
 
 	mux := http.NewServeMux()
@@ -114,13 +136,34 @@ func New(orch *vega.Orchestrator, config *dsl.Document, customTools *tools.Perso
 	mux.HandleFunc("/slack/events/jordan", s.handleSlackEventsPersona("Jordan"))
 	mux.HandleFunc("/slack/events/riley", s.handleSlackEventsPersona("Riley"))
 
+	// Slack interactive components (button clicks)
+	mux.HandleFunc("/slack/interactions", s.handleSlackInteractions)
+	mux.HandleFunc("/slack/interactions/tony", s.handleSlackInteractionsPersona("Tony"))
+	mux.HandleFunc("/slack/interactions/maya", s.handleSlackInteractionsPersona("Maya"))
+	mux.HandleFunc("/slack/interactions/alex", s.handleSlackInteractionsPersona("Alex"))
+	mux.HandleFunc("/slack/interactions/jordan", s.handleSlackInteractionsPersona("Jordan"))
+	mux.HandleFunc("/slack/interactions/riley", s.handleSlackInteractionsPersona("Riley"))
+
 	// Caddy on-demand TLS verification endpoint
 	mux.HandleFunc("/internal/caddy-ask", s.subdomainRegistry.HandleCaddyAsk)
+	mux.HandleFunc("/internal/caddy-ask/stats", s.subdomainRegistry.HandleCaddyAskStats)
 
 	// Subdomain management endpoints
 	mux.HandleFunc("/internal/subdomains", s.handleSubdomainList)
 	mux.HandleFunc("/internal/subdomains/register", s.handleSubdomainRegister)
 
+	// Contact import (CSV body)
+	mux.HandleFunc("/internal/contacts/import", s.requireAdminToken(s.handleContactsImport))
+
+	// Knowledge export/import (JSON bundle)
+	mux.HandleFunc("/internal/knowledge/export", s.requireAdminToken(s.handleKnowledgeExport))
+	mux.HandleFunc("/internal/knowledge/import", s.requireAdminToken(s.handleKnowledgeImport))
+	mux.HandleFunc("/internal/knowledge/compact", s.requireAdminToken(s.handleKnowledgeCompact))
+
+	// External callback completion webhook
+	mux.HandleFunc("/callbacks/complete", s.handleCallbackComplete)
+	mux.HandleFunc("/callbacks/track/", s.handleCallbackTrack)
+
 	// Health check
 	mux.HandleFunc("/health", s.handleHealth)
 
@@ -148,6 +191,12 @@ func New(orch *vega.Orchestrator, config *dsl.Document, customTools *tools.Perso
 		WriteTimeout: 5 * time.Minute, // Long timeout for streaming
 	}
 
+	// Record tool failures and spawned agents in history
+	if customTools != nil {
+		customTools.SetToolErrorRecorder(s.RecordToolError)
+		customTools.SetProcessRecorder(s.RecordProcessEvent)
+	}
+
 	return s
 }
 
@@ -176,14 +225,15 @@ func (s *Server) AddSlackHandler(persona string, handler *slack.Handler) {
 // WireSlackNotifications wires the Slack client to PersonaTools for notifications
 // Call this after adding Slack handlers
 func (s *Server) WireSlackNotifications() {
-	// Use the legacy handler's client, or any per-persona handler's client
+	// Use the legacy handler's workspaces, or any per-persona handler's, so
+	// notifications go out with the token of the channel's workspace
 	if s.slackHandler != nil {
-		s.customTools.SetSlackClient(s.slackHandler.Client())
+		s.customTools.SetSlackClient(s.slackHandler.Workspaces())
 		return
 	}
 	// Fallback to any per-persona handler
 	for _, handler := range s.slackHandlers {
-		s.customTools.SetSlackClient(handler.Client())
+		s.customTools.SetSlackClient(handler.Workspaces())
 		return
 	}
 }
@@ -191,6 +241,15 @@ func (s *Server) WireSlackNotifications() {
 // SetCallbackRegistry sets the callback registry
 func (s *Server) SetCallbackRegistry(registry *callback.Registry) {
 	s.callbackRegistry = registry
+	if registry != nil {
+		registry.SetCallRecorder(s.RecordCallbackCall)
+	}
+}
+
+// SetAdminToken sets the bearer token the admin data endpoints (contact
+// import and the like) require. They refuse every request until it's set.
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
 }
 
 // GetProcessManager returns the process manager for starting project servers
@@ -213,22 +272,6 @@ func (s *Server) ListenAndServe() error {
 	return s.httpServer.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the server
-func (s *Server) Shutdown(ctx context.Context) error {
-	// Shutdown legacy slack handler
-	if s.slackHandler != nil {
-		s.slackHandler.Shutdown()
-	}
-	// Shutdown per-persona slack handlers
-	for _, handler := range s.slackHandlers {
-		handler.Shutdown()
-	}
-	if s.processManager != nil {
-		s.processManager.Shutdown()
-	}
-	return s.httpServer.Shutdown(ctx)
-}
-
 // handleSlackEvents delegates to the Slack handler if configured (legacy endpoint)
 func (s *Server) handleSlackEvents(w http.ResponseWriter, r *http.Request) {
 	if s.slackHandler == nil {
@@ -250,6 +293,27 @@ func (s *Server) handleSlackEventsPersona(persona string) http.HandlerFunc {
 	}
 }
 
+// handleSlackInteractions handles button clicks for the legacy Slack app
+func (s *Server) handleSlackInteractions(w http.ResponseWriter, r *http.Request) {
+	if s.slackHandler == nil {
+		http.Error(w, "Slack not configured", http.StatusServiceUnavailable)
+		return
+	}
+	s.slackHandler.HandleInteractions(w, r)
+}
+
+// handleSlackInteractionsPersona returns a handler for a specific persona's button clicks
+func (s *Server) handleSlackInteractionsPersona(persona string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := s.slackHandlers[persona]
+		if !ok {
+			http.Error(w, fmt.Sprintf("Slack not configured for %s", persona), http.StatusServiceUnavailable)
+			return
+		}
+		handler.HandleInteractions(w, r)
+	}
+}
+
 // OpenAI-compatible request/response structures
 type ChatCompletionRequest struct {
 	Model       string        `json:"model"`
@@ -373,6 +437,12 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		callerPhone, _ = s.getCallerFromVAPI(callID)
 	}
 
+	if s.isBlockedCaller(callerPhone) {
+		log.Printf("[chat] Rejecting blocked caller %s", maskPhone(callerPhone))
+		s.writeBlockedCallerResponse(w, req.Stream)
+		return
+	}
+
 	callerID := callerPhone
 	if callerID == "" {
 		callerID = r.Header.Get("X-Request-ID")
@@ -451,12 +521,13 @@ func (s *Server) getOrCreateSession(ctx context.Context, callerID, callerPhone s
 
 	// Add caller context if we have phone number
 	if callerPhone != "" {
-		callerInfo := s.customTools.IdentifyCaller(callerPhone)
-		if callerInfo != "" {
-			systemPrompt = fmt.Sprintf("%s\n\n## Current Caller\n%s", systemPrompt, callerInfo)
-		}
+		systemPrompt += s.customTools.CallerPromptSection(callerPhone)
+		systemPrompt += s.callerMemoryPromptSection(callerPhone)
 	}
 
+	// Inject global directives
+	systemPrompt += s.customTools.DirectivesPromptSection("")
+
 	// Inject knowledge feed
 	if ks := s.customTools.GetKnowledgeStore(); ks != nil {
 		feedSection := knowledge.GetFeedPromptSection(ks)
@@ -727,6 +798,8 @@ type APIStatusResponse struct {
 	SessionCount   int                 `json:"session_count"`
 	Personas       []string            `json:"personas,omitempty"`
 	ActivePersonas []string            `json:"active_personas,omitempty"`
+	SpawnsInUse    int                 `json:"spawns_in_use"`
+	SpawnLimit     int                 `json:"spawn_limit,omitempty"`
 }
 
 // APIProcessResponse represents a single process in the API
@@ -777,6 +850,10 @@ func (s *Server) handleAPIStatus(w http.ResponseWriter, r *http.Request) {
 		SessionCount: sessionCount,
 	}
 
+	if s.customTools != nil {
+		response.SpawnsInUse, response.SpawnLimit = s.customTools.SpawnCapacity()
+	}
+
 	// Add personas if life manager is available
 	if s.lifeManager != nil {
 		response.Personas = s.lifeManager.Personas()
@@ -894,7 +971,25 @@ func (s *Server) handleAPIHistory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	response := s.historyStore.Query(days)
+	// Narrow the entries, and the summary with them, before they're sent
+	query := r.URL.Query()
+	filter := QueryFilter{
+		Since:        time.Now().Add(-time.Duration(days) * 24 * time.Hour),
+		Agent:        query.Get("agent"),
+		Type:         HistoryEntryType(query.Get("type")),
+		Status:       query.Get("status"),
+		TextContains: query.Get("q"),
+	}
+	if minDuration := query.Get("min_duration"); minDuration != "" {
+		d, err := time.ParseDuration(minDuration)
+		if err != nil || d < 0 {
+			http.Error(w, "Invalid min_duration, use a duration like 30s", http.StatusBadRequest)
+			return
+		}
+		filter.MinDuration = d
+	}
+
+	response := s.historyStore.QueryFiltered(filter)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -912,6 +1007,19 @@ func (s *Server) RecordProcessStart(agent, processID, task string) {
 	})
 }
 
+// RecordProcessSpawn records which agent spawned a process in history. The
+// parent is empty for processes started outside any agent.
+func (s *Server) RecordProcessSpawn(parent, parentID, agent, processID, task string) {
+	s.historyStore.Record(HistoryEntry{
+		Type:      HistoryProcessSpawn,
+		Agent:     agent,
+		ProcessID: processID,
+		Task:      task,
+		Parent:    parent,
+		ParentID:  parentID,
+	})
+}
+
 // RecordProcessEnd records a process end event in history
 func (s *Server) RecordProcessEnd(agent, processID, task, status string, durationMs int64, metrics *HistoryMetrics) {
 	s.historyStore.Record(HistoryEntry{
@@ -936,6 +1044,79 @@ func (s *Server) RecordError(agent, processID, errorMsg string) {
 	})
 }
 
+// RecordToolError records a failed tool call in history, tagged with the
+// tool name and error category
+func (s *Server) RecordToolError(te tools.ToolError) {
+	s.historyStore.Record(HistoryEntry{
+		Type:      HistoryError,
+		Agent:     te.Agent,
+		ProcessID: te.ProcessID,
+		Tool:      te.Tool,
+		ErrorType: te.Category,
+		Error:     te.Err.Error(),
+		Status:    "error",
+	})
+}
+
+// RecordProcessEvent records a spawned agent starting or finishing in
+// history and, on finish, fires any callback registered for it with the
+// agent's real token, tool-call and cost metrics
+func (s *Server) RecordProcessEvent(ev tools.ProcessEvent) {
+	if !ev.Done {
+		s.RecordProcessStart(ev.Agent, ev.ProcessID, ev.Task)
+		s.RecordProcessSpawn(ev.ParentAgent, ev.ParentID, ev.Agent, ev.ProcessID, ev.Task)
+		return
+	}
+
+	status, errMsg := "completed", ""
+	if ev.Err != nil {
+		status, errMsg = "failed", ev.Err.Error()
+	}
+
+	m := ev.Metrics
+	s.RecordProcessEnd(ev.Agent, ev.ProcessID, ev.Task, status, ev.Duration.Milliseconds(), &HistoryMetrics{
+		InputTokens:   m.InputTokens,
+		OutputTokens:  m.OutputTokens,
+		TotalTokens:   m.InputTokens + m.OutputTokens,
+		LLMCalls:      m.Iterations,
+		ToolCalls:     m.ToolCalls,
+		EstimatedCost: m.CostUSD,
+		Model:         ev.Model,
+	})
+
+	if s.callbackRegistry != nil {
+		s.callbackRegistry.OnAgentComplete(callback.CompletionInfo{
+			AgentID:     ev.ProcessID,
+			AgentName:   ev.Agent,
+			Result:      ev.Result,
+			ProjectName: ev.Project,
+			Error:       errMsg,
+			Metrics: &callback.CompletionMetrics{
+				InputTokens:  m.InputTokens,
+				OutputTokens: m.OutputTokens,
+				LLMCalls:     m.Iterations,
+				ToolCalls:    m.ToolCalls,
+				CostUSD:      m.CostUSD,
+				DurationMs:   ev.Duration.Milliseconds(),
+			},
+		})
+	}
+}
+
+// RecordCallbackCall records a callback call in history
+func (s *Server) RecordCallbackCall(rec callback.CallRecord) {
+	s.historyStore.RecordCall(HistoryEntry{
+		Agent:      rec.PersonaName,
+		ProcessID:  rec.AgentID,
+		Task:       fmt.Sprintf("Called %s about %s's task: %s", rec.Recipient, rec.AgentName, rec.TaskSummary),
+		Status:     rec.Status,
+		DurationMs: rec.Duration.Milliseconds(),
+		Error:      rec.Error,
+		CallID:     rec.CallID,
+		Recipient:  rec.Recipient,
+	})
+}
+
 // handleAPISpawnTree returns the hierarchical spawn tree of all processes
 func (s *Server) handleAPISpawnTree(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -1013,10 +1194,134 @@ func (s *Server) handleSubdomainRegister(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
 		"success": true,
-		"url":     fmt.Sprintf("https://%s.hellotron.com", req.Subdomain),
+		"url":     s.subdomainRegistry.URL(req.Subdomain),
 	})
 }
 
+// handleCallbackComplete lets external systems complete a pending callback
+func (s *Server) handleCallbackComplete(w http.ResponseWriter, r *http.Request) {
+	if s.callbackRegistry == nil {
+		http.Error(w, "Callbacks not configured", http.StatusServiceUnavailable)
+		return
+	}
+	s.callbackRegistry.HandleExternalComplete(w, r)
+}
+
+// handleCallbackTrack records a callback email's view link being opened
+func (s *Server) handleCallbackTrack(w http.ResponseWriter, r *http.Request) {
+	if s.callbackRegistry == nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.callbackRegistry.HandleTrack(w, r)
+}
+
+// requireAdminToken wraps an admin endpoint so it only serves requests that
+// send "Authorization: Bearer <token>" with the admin token. These routes are
+// reachable through the public reverse proxy, so they can't rely on being
+// internal.
+func (s *Server) requireAdminToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleContactsImport imports contacts from a CSV request body.
+// Pass ?overwrite=true to replace contacts whose phone already exists.
+func (s *Server) handleContactsImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	overwrite := r.URL.Query().Get("overwrite") == "true"
+	summary, err := s.customTools.ImportContactsCSV(http.MaxBytesReader(w, r.Body, 10<<20), overwrite)
+	if err != nil && summary == nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// handleKnowledgeExport writes the knowledge base as a JSON bundle
+func (s *Server) handleKnowledgeExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.customTools == nil {
+		http.Error(w, "Knowledge not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := s.customTools.ExportKnowledge(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="knowledge.json"`)
+	w.Write(buf.Bytes())
+}
+
+// handleKnowledgeImport loads a JSON bundle from handleKnowledgeExport into
+// the knowledge base and returns the per-entry results. ?mode=merge (the
+// default) keeps the existing entries; ?mode=replace swaps them for the bundle.
+func (s *Server) handleKnowledgeImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.customTools == nil {
+		http.Error(w, "Knowledge not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	mode := tools.KnowledgeImportMode(r.URL.Query().Get("mode"))
+	report, err := s.customTools.ImportKnowledge(http.MaxBytesReader(w, r.Body, 32<<20), mode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleKnowledgeCompact archives the knowledge entries outside the
+// retention now, rather than waiting for the sweep
+func (s *Server) handleKnowledgeCompact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.customTools == nil {
+		http.Error(w, "Knowledge not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	archived, err := s.customTools.CompactKnowledge()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"archived": archived})
+}
+
 // handleClearSessions clears all Slack sessions to force prompt refresh
 func (s *Server) handleClearSessions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/everydev1618/tron/internal/callback"
+	"github.com/everydev1618/tron/internal/memory"
 	"github.com/everydev1618/tron/internal/tools"
+	"github.com/everydev1618/tron/internal/webhook"
 	"github.com/everydev1618/govega"
 	"github.com/everydev1618/govega/dsl"
 )
@@ -448,3 +453,348 @@ func TestChatCompletionResponseFormat(t *testing.T) {
 		t.Errorf("Object = %q, want %q", decoded.Object, resp.Object)
 	}
 }
+
+func TestHistorySummaryBreaksDownToolErrors(t *testing.T) {
+	store := NewHistoryStore(t.TempDir())
+	store.Record(HistoryEntry{Type: HistoryError, Agent: "Tony", Tool: "web_search", ErrorType: "timeout", Error: "deadline exceeded"})
+	store.Record(HistoryEntry{Type: HistoryError, Agent: "Tony", Tool: "web_search", ErrorType: "api", Error: "status 502"})
+	store.Record(HistoryEntry{Type: HistoryError, Agent: "Gary", Tool: "execute", ErrorType: "blocked", Error: "blocked command"})
+	store.Record(HistoryEntry{Type: HistoryError, Agent: "Tony", Error: "process failed"})
+
+	summary := store.Query(1).Summary
+	if summary.TotalErrors != 4 {
+		t.Errorf("TotalErrors = %d, want 4", summary.TotalErrors)
+	}
+	if summary.ErrorsByTool["web_search"] != 2 || summary.ErrorsByTool["execute"] != 1 {
+		t.Errorf("ErrorsByTool = %v", summary.ErrorsByTool)
+	}
+	if summary.ErrorsByType["timeout"] != 1 || summary.ErrorsByType["api"] != 1 || summary.ErrorsByType["blocked"] != 1 {
+		t.Errorf("ErrorsByType = %v", summary.ErrorsByType)
+	}
+}
+
+func TestHistoryQueryFiltered(t *testing.T) {
+	store := NewHistoryStore(t.TempDir())
+	store.Record(HistoryEntry{Type: HistoryProcessEnd, Agent: "Gary", Task: "Deploy the blog", Status: "completed", DurationMs: 120000, Metrics: &HistoryMetrics{EstimatedCost: 0.10}})
+	store.Record(HistoryEntry{Type: HistoryProcessEnd, Agent: "Gary", Task: "fix typo", Status: "completed", DurationMs: 5000, Metrics: &HistoryMetrics{EstimatedCost: 0.01}})
+	store.Record(HistoryEntry{Type: HistoryProcessEnd, Agent: "Maya", Task: "deploy the shop", Status: "failed", DurationMs: 300000})
+	store.Record(HistoryEntry{Type: HistoryError, Agent: "Gary", Tool: "deploy_site", Error: "timeout", Status: "error"})
+	store.Record(HistoryEntry{Type: HistoryProcessEnd, Agent: "Gary", Task: "old deploy", Timestamp: time.Now().Add(-48 * time.Hour)})
+
+	resp := store.QueryFiltered(QueryFilter{
+		Since:        time.Now().Add(-24 * time.Hour),
+		Agent:        "Gary",
+		Type:         HistoryProcessEnd,
+		MinDuration:  time.Minute,
+		TextContains: "DEPLOY",
+	})
+	if len(resp.Entries) != 1 || resp.Entries[0].Task != "Deploy the blog" {
+		t.Fatalf("entries = %+v, want only Gary's long deploy", resp.Entries)
+	}
+	if resp.Summary.TotalEntries != 1 || resp.Summary.TotalCost != 0.10 {
+		t.Errorf("summary = %+v, want it to cover only the filtered entry", resp.Summary)
+	}
+
+	// Text matches errors and tools too
+	if got := store.QueryFiltered(QueryFilter{TextContains: "deploy"}).Entries; len(got) != 4 {
+		t.Errorf("text filter matched %d entries, want 4", len(got))
+	}
+	if got := store.QueryFiltered(QueryFilter{Status: "failed"}).Entries; len(got) != 1 || got[0].Agent != "Maya" {
+		t.Errorf("status filter = %+v", got)
+	}
+	if got := store.Query(1).Entries; len(got) != 4 {
+		t.Errorf("Query(1) returned %d entries, want the 4 recent ones", len(got))
+	}
+}
+
+func TestRecordProcessEventRollsUpCost(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	t.Cleanup(func() { orch.Shutdown(context.Background()) })
+	srv := New(orch, createTestConfig(), nil, 0, dir)
+	srv.SetBaseDir(dir)
+
+	srv.RecordProcessEvent(tools.ProcessEvent{ProcessID: "p1", Agent: "Gary", Task: "build it"})
+	srv.RecordProcessEvent(tools.ProcessEvent{
+		ProcessID: "p1",
+		Agent:     "Gary",
+		Task:      "build it",
+		Model:     "claude-sonnet-4",
+		Done:      true,
+		Duration:  4 * time.Minute,
+		Metrics:   vega.ProcessMetrics{InputTokens: 1000, OutputTokens: 234, Iterations: 3, ToolCalls: 8, CostUSD: 0.12},
+	})
+	srv.RecordProcessEvent(tools.ProcessEvent{ProcessID: "p2", Agent: "Maya", Task: "design it"})
+	srv.RecordProcessEvent(tools.ProcessEvent{ProcessID: "p2", Agent: "Maya", Task: "design it", Model: "claude-haiku-4", Done: true, Err: errors.New("boom"), Metrics: vega.ProcessMetrics{CostUSD: 0.03, InputTokens: 100}})
+
+	resp := srv.historyStore.Query(1)
+	if resp.Summary.TotalProcesses != 2 {
+		t.Errorf("TotalProcesses = %d, want 2", resp.Summary.TotalProcesses)
+	}
+	if got := resp.Summary.TotalCost; got < 0.149 || got > 0.151 {
+		t.Errorf("TotalCost = %v, want 0.15", got)
+	}
+	if resp.Summary.CostByAgent["Gary"] != 0.12 || resp.Summary.CostByAgent["Maya"] != 0.03 {
+		t.Errorf("CostByAgent = %v", resp.Summary.CostByAgent)
+	}
+	if resp.Summary.TokensByAgent["Gary"] != 1234 || resp.Summary.TokensByAgent["Maya"] != 100 {
+		t.Errorf("TokensByAgent = %v", resp.Summary.TokensByAgent)
+	}
+	if resp.Summary.CostByModel["claude-sonnet-4"] != 0.12 || resp.Summary.CostByModel["claude-haiku-4"] != 0.03 {
+		t.Errorf("CostByModel = %v", resp.Summary.CostByModel)
+	}
+	if resp.Summary.ByStatus["failed"] != 1 {
+		t.Errorf("ByStatus = %v, want one failed", resp.Summary.ByStatus)
+	}
+
+	for _, e := range resp.Entries {
+		if e.Type != HistoryProcessEnd || e.ProcessID != "p1" {
+			continue
+		}
+		if e.Metrics == nil || e.Metrics.TotalTokens != 1234 || e.Metrics.LLMCalls != 3 || e.DurationMs != 240000 {
+			t.Errorf("p1 end entry = %+v, metrics %+v", e, e.Metrics)
+		}
+	}
+}
+
+func TestBuildSpawnPatterns(t *testing.T) {
+	store := NewHistoryStore(t.TempDir())
+	store.Record(HistoryEntry{Type: HistoryProcessSpawn, Agent: "Gary", ProcessID: "p1", Parent: "Tony", ParentID: "tony"})
+	store.Record(HistoryEntry{Type: HistoryProcessSpawn, Agent: "Maya", ProcessID: "p2", Parent: "Tony", ParentID: "tony"})
+	store.Record(HistoryEntry{Type: HistoryProcessSpawn, Agent: "Gary", ProcessID: "p3", Parent: "Tony", ParentID: "tony"})
+	store.Record(HistoryEntry{Type: HistoryProcessSpawn, Agent: "Derek", ProcessID: "p4", Parent: "Gary", ParentID: "p1"})
+	store.Record(HistoryEntry{Type: HistoryProcessSpawn, Agent: "Sam", ProcessID: "p5", Parent: "Derek", ParentID: "p4"})
+	store.Record(HistoryEntry{Type: HistoryProcessStart, Agent: "Gary", ProcessID: "p1"})
+
+	summary := store.BuildSpawnPatterns(1)
+	if summary.TotalSpawns != 5 {
+		t.Errorf("TotalSpawns = %d, want 5", summary.TotalSpawns)
+	}
+	if summary.MaxDepth != 3 {
+		t.Errorf("MaxDepth = %d, want 3 for Tony→Gary→Derek→Sam", summary.MaxDepth)
+	}
+	if summary.SpawnsByAgent["Tony"] != 3 || summary.SpawnsByAgent["Gary"] != 1 || summary.SpawnedByAgent["Gary"] != 2 {
+		t.Errorf("SpawnsByAgent = %v, SpawnedByAgent = %v", summary.SpawnsByAgent, summary.SpawnedByAgent)
+	}
+	if len(summary.CommonPatterns) != 4 || summary.CommonPatterns[0] != (SpawnPattern{Parent: "Tony", Child: "Gary", Count: 2}) {
+		t.Errorf("CommonPatterns = %+v, want Tony→Gary first", summary.CommonPatterns)
+	}
+}
+
+func TestRecordProcessEventRecordsSpawnParent(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	t.Cleanup(func() { orch.Shutdown(context.Background()) })
+	srv := New(orch, createTestConfig(), nil, 0, dir)
+	srv.SetBaseDir(dir)
+
+	srv.RecordProcessEvent(tools.ProcessEvent{ProcessID: "p1", Agent: "Gary", Task: "build it", ParentID: "tony", ParentAgent: "Tony"})
+
+	var spawn *HistoryEntry
+	for _, e := range srv.historyStore.Query(1).Entries {
+		if e.Type == HistoryProcessSpawn {
+			spawn = &e
+		}
+	}
+	if spawn == nil || spawn.Parent != "Tony" || spawn.ParentID != "tony" || spawn.Agent != "Gary" || spawn.ProcessID != "p1" {
+		t.Errorf("spawn entry = %+v", spawn)
+	}
+}
+
+func TestRecordCallbackCall(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	t.Cleanup(func() { orch.Shutdown(context.Background()) })
+	srv := New(orch, createTestConfig(), nil, 0, dir)
+	srv.SetBaseDir(dir)
+
+	placed := callback.CallRecord{CallID: "call-1", AgentID: "p1", AgentName: "Gary", PersonaName: "Tony", TaskSummary: "build it", Recipient: "+15***2000", Status: callback.CallPlaced}
+	srv.RecordCallbackCall(placed)
+	ended := placed
+	ended.Status, ended.Duration = callback.EngagementAnswered, 90*time.Second
+	srv.RecordCallbackCall(ended)
+	srv.RecordCallbackCall(callback.CallRecord{AgentID: "p2", PersonaName: "Tony", Recipient: "***", Status: callback.CallFailed, Error: "VAPI down"})
+
+	resp := srv.historyStore.Query(1)
+	if len(resp.Entries) != 2 {
+		t.Fatalf("got %d entries, want the placed call updated in place plus the failed one", len(resp.Entries))
+	}
+	for _, e := range resp.Entries {
+		if e.Type != HistoryCallbackCall || e.Agent != "Tony" {
+			t.Errorf("entry = %+v", e)
+		}
+		if e.CallID == "call-1" && (e.Status != "answered" || e.DurationMs != 90000 || e.Recipient != "+15***2000" || e.ProcessID != "p1") {
+			t.Errorf("call-1 entry = %+v", e)
+		}
+	}
+	if resp.Summary.AvgDurationMs != 0 {
+		t.Errorf("AvgDurationMs = %d, calls should not count toward it", resp.Summary.AvgDurationMs)
+	}
+}
+
+func TestCallDuration(t *testing.T) {
+	tests := []struct {
+		event map[string]interface{}
+		want  time.Duration
+	}{
+		{map[string]interface{}{"durationSeconds": 90.5}, 90500 * time.Millisecond},
+		{map[string]interface{}{"call": map[string]interface{}{"startedAt": "2026-01-02T14:05:00Z", "endedAt": "2026-01-02T14:06:30Z"}}, 90 * time.Second},
+		{map[string]interface{}{"call": map[string]interface{}{"startedAt": "2026-01-02T14:05:00Z"}}, 0},
+		{map[string]interface{}{}, 0},
+	}
+	for _, tt := range tests {
+		if got := callDuration(tt.event); got != tt.want {
+			t.Errorf("callDuration(%v) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestCallerContext(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	t.Cleanup(func() { orch.Shutdown(context.Background()) })
+	customTools := tools.NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+	srv := New(orch, createTestConfig(), customTools, 0, dir)
+	srv.SetBaseDir(dir)
+
+	// Contacts may fail to persist in tests; the in-memory update is what matters
+	customTools.UpdateCaller(tools.Contact{Name: "Sarah Chen", Phone: "+1 555 010 2000"})
+	if err := memory.SavePersonMemory(dir, "Sarah Chen", "Prefers email over Slack", "preference"); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.Append(dir, "Sarah Chen", "- Discussed the pricing page"); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.Append(dir, "Bob", "- Talked about hiring"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := srv.callerConversationInit("+15550102000"); ok {
+		t.Fatal("caller context should be off by default")
+	}
+
+	srv.SetVoiceCallerContext(true)
+	init, ok := srv.callerConversationInit("+15550102000")
+	if !ok {
+		t.Fatal("expected caller context for a known caller")
+	}
+	ctx := init.DynamicVariables["caller_context"]
+	if !strings.Contains(ctx, "Prefers email over Slack") || !strings.Contains(ctx, "pricing page") {
+		t.Errorf("caller_context = %q, want person memory and recent call", ctx)
+	}
+	if strings.Contains(ctx, "hiring") {
+		t.Errorf("caller_context = %q, should not include other callers' calls", ctx)
+	}
+	if init.FirstMessage != "Hey Sarah, good to hear from you again." {
+		t.Errorf("FirstMessage = %q", init.FirstMessage)
+	}
+
+	if _, ok := srv.callerConversationInit("+15559999999"); ok {
+		t.Error("unknown caller should get no context")
+	}
+}
+
+func TestVerifiedCaller(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := webhook.Default.Sign([]byte("+15550102000"), ts, "s3cret")
+	request := func(query string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/ws/elevenlabs?"+query, nil)
+	}
+	signed := "phone=%2B15550102000&ts=" + ts + "&sig=" + sig
+
+	// Without a secret nothing is trusted, signed or not
+	if got := srv.verifiedCaller(request(signed)); got != "" {
+		t.Errorf("no secret: verifiedCaller = %q, want empty", got)
+	}
+
+	srv.SetVoiceCallerSecret("s3cret")
+	if got := srv.verifiedCaller(request(signed)); got != "+15550102000" {
+		t.Errorf("signed: verifiedCaller = %q, want +15550102000", got)
+	}
+	for _, query := range []string{
+		"phone=%2B15550102000",
+		"phone=%2B15559999999&ts=" + ts + "&sig=" + sig,
+		"phone=%2B15550102000&ts=1&sig=" + webhook.Default.Sign([]byte("+15550102000"), "1", "s3cret"),
+	} {
+		if got := srv.verifiedCaller(request(query)); got != "" {
+			t.Errorf("%s: verifiedCaller = %q, want empty", query, got)
+		}
+	}
+}
+
+func TestTruncateContext(t *testing.T) {
+	text := strings.Repeat("line of memory\n", 200)
+	got := truncateContext(text, maxCallerContextBytes)
+	if len(got) > maxCallerContextBytes+len("\n...") {
+		t.Errorf("truncateContext() length = %d, want <= %d", len(got), maxCallerContextBytes+4)
+	}
+	if !strings.HasSuffix(got, "line of memory\n...") {
+		t.Errorf("truncateContext() should cut at a line boundary, got suffix %q", got[len(got)-20:])
+	}
+}
+
+func TestChatCompletionsRejectsBlockedCaller(t *testing.T) {
+	dir := t.TempDir()
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	t.Cleanup(func() { orch.Shutdown(context.Background()) })
+	customTools := tools.NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+	srv := New(orch, createTestConfig(), customTools, 0, dir)
+
+	if err := customTools.BlockCaller("+15550107777", "spam"); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetBlockedCallerMessage("Not taking calls from this number.")
+
+	body := `{"model":"tony","messages":[{"role":"user","content":"hello"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat/completions", strings.NewReader(body))
+	req.Header.Set("X-Vapi-Caller-Phone", "+1 555 010 7777")
+	w := httptest.NewRecorder()
+	srv.handleChatCompletions(w, req)
+
+	var resp ChatCompletionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "Not taking calls from this number." {
+		t.Errorf("response = %+v, want the blocked caller message", resp)
+	}
+	if n := len(srv.sessions); n != 0 {
+		t.Errorf("sessions = %d, want no session for a blocked caller", n)
+	}
+}
+
+func TestAdminEndpointsRequireToken(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	served := false
+	handler := srv.requireAdminToken(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})
+
+	call := func(auth string) int {
+		served = false
+		req := httptest.NewRequest(http.MethodPost, "/internal/contacts/import", strings.NewReader("name,phone\n"))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Code
+	}
+
+	// No token configured: nothing gets through, even an empty bearer
+	if code := call("Bearer "); code != http.StatusUnauthorized || served {
+		t.Errorf("unconfigured: status %d, served %v; want 401 and not served", code, served)
+	}
+
+	srv.SetAdminToken("s3cret")
+	for _, auth := range []string{"", "s3cret", "Bearer wrong", "Basic s3cret"} {
+		if code := call(auth); code != http.StatusUnauthorized || served {
+			t.Errorf("Authorization %q: status %d, served %v; want 401 and not served", auth, code, served)
+		}
+	}
+	if code := call("Bearer s3cret"); code != http.StatusOK || !served {
+		t.Errorf("valid token: status %d, served %v; want 200 and served", code, served)
+	}
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/govega"
+	"github.com/gorilla/websocket"
+)
+
+// voiceDrainPollInterval is how often Shutdown checks for closed voice sessions
+const voiceDrainPollInterval = 50 * time.Millisecond
+
+// ShutdownReport records which components stopped cleanly and which were
+// cut off when the shutdown deadline passed
+type ShutdownReport struct {
+	Stopped []string `json:"stopped"`
+	Forced  []string `json:"forced"`
+}
+
+func (r *ShutdownReport) stopped(format string, args ...any) {
+	r.Stopped = append(r.Stopped, fmt.Sprintf(format, args...))
+}
+
+func (r *ShutdownReport) forced(format string, args ...any) {
+	r.Forced = append(r.Forced, fmt.Sprintf(format, args...))
+}
+
+// Clean reports whether everything stopped before the deadline
+func (r *ShutdownReport) Clean() bool {
+	return len(r.Forced) == 0
+}
+
+// String summarizes the report on one line
+func (r *ShutdownReport) String() string {
+	s := fmt.Sprintf("stopped: %s", strings.Join(r.Stopped, ", "))
+	if len(r.Forced) > 0 {
+		s += fmt.Sprintf("; forced: %s", strings.Join(r.Forced, ", "))
+	}
+	return s
+}
+
+// Shutdown stops the server and everything it coordinates within ctx's
+// deadline. New spawns and callbacks are refused first, then open voice
+// sessions are closed, in-flight requests, spawned agents, tool calls and
+// callback deliveries are given until the deadline to finish, pending state
+// is persisted, and finally project servers and agent processes are stopped.
+// Anything still running at the deadline is force-stopped and listed in the
+// report's Forced entries.
+func (s *Server) Shutdown(ctx context.Context) *ShutdownReport {
+	report := &ShutdownReport{}
+
+	// Stop accepting new work
+	if s.customTools != nil {
+		s.customTools.StopAccepting()
+	}
+	if s.lifeManager != nil {
+		s.lifeManager.Stop()
+		report.stopped("life loops")
+	}
+
+	s.closeVoiceSessions(ctx, report)
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.httpServer.Close()
+		report.forced("http server (%v)", err)
+	} else {
+		report.stopped("http server")
+	}
+
+	// Shutdown legacy slack handler
+	if s.slackHandler != nil {
+		s.stopSlackHandler(ctx, "slack", s.slackHandler.Shutdown, report)
+	}
+	// Shutdown per-persona slack handlers
+	for persona, handler := range s.slackHandlers {
+		s.stopSlackHandler(ctx, "slack "+persona, handler.Shutdown, report)
+	}
+
+	if s.customTools != nil {
+		// Spawned agents finish first: they still need tools and callbacks
+		if n := s.customTools.AwaitSpawns(ctx); n > 0 {
+			report.forced("%d spawned agents", n)
+		} else {
+			report.stopped("spawned agents")
+		}
+		if n := s.customTools.Drain(ctx); n > 0 {
+			report.forced("%d tool calls", n)
+		} else {
+			report.stopped("tool calls")
+		}
+	}
+
+	if s.callbackRegistry != nil {
+		pending := s.callbackRegistry.Pending()
+		if err := s.callbackRegistry.Close(ctx); err != nil {
+			report.forced("callback delivery (%v)", err)
+		} else {
+			report.stopped("callbacks (%d pending saved)", pending)
+		}
+	}
+
+	if s.processManager != nil {
+		s.processManager.Shutdown()
+		report.stopped("project servers")
+	}
+
+	var running int
+	for _, proc := range s.orch.List() {
+		if proc.Status() == vega.StatusRunning {
+			running++
+		}
+	}
+	s.orch.Shutdown(ctx)
+	if running > 0 {
+		report.forced("%d agent processes", running)
+	} else {
+		report.stopped("agent processes")
+	}
+
+	return report
+}
+
+// stopSlackHandler runs a handler's Shutdown, giving up at the deadline
+func (s *Server) stopSlackHandler(ctx context.Context, name string, shutdown func(), report *ShutdownReport) {
+	done := make(chan struct{})
+	go func() {
+		shutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		report.stopped("%s", name)
+	case <-ctx.Done():
+		report.forced("%s", name)
+	}
+}
+
+// trackVoiceSession registers an open voice session for shutdown
+func (s *Server) trackVoiceSession(session *ElevenLabsSession) {
+	s.voiceSessionsMu.Lock()
+	defer s.voiceSessionsMu.Unlock()
+	s.voiceSessions[session] = struct{}{}
+}
+
+// untrackVoiceSession removes a voice session once its handler returns
+func (s *Server) untrackVoiceSession(session *ElevenLabsSession) {
+	s.voiceSessionsMu.Lock()
+	defer s.voiceSessionsMu.Unlock()
+	delete(s.voiceSessions, session)
+}
+
+// openVoiceSessions returns the voice sessions still open
+func (s *Server) openVoiceSessions() []*ElevenLabsSession {
+	s.voiceSessionsMu.Lock()
+	defer s.voiceSessionsMu.Unlock()
+
+	sessions := make([]*ElevenLabsSession, 0, len(s.voiceSessions))
+	for session := range s.voiceSessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// closeVoiceSessions sends each voice client a close frame, ends its
+// ElevenLabs conversation and waits for the handlers to return. Sessions
+// still open at the deadline have their client connection dropped.
+func (s *Server) closeVoiceSessions(ctx context.Context, report *ShutdownReport) {
+	sessions := s.openVoiceSessions()
+	if len(sessions) == 0 {
+		return
+	}
+
+	for _, session := range sessions {
+		session.mu.Lock()
+		session.ClientConn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+			time.Now().Add(time.Second))
+		session.mu.Unlock()
+		session.ElevenLabsConn.Close()
+	}
+
+	ticker := time.NewTicker(voiceDrainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		remaining := s.openVoiceSessions()
+		if len(remaining) == 0 {
+			report.stopped("%d voice sessions", len(sessions))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			for _, session := range remaining {
+				log.Printf("Dropping voice session %s at shutdown deadline", session.ConversationID)
+				session.ClientConn.Close()
+			}
+			report.forced("%d voice sessions", len(remaining))
+			return
+		case <-ticker.C:
+		}
+	}
+}
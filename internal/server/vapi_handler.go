@@ -134,6 +134,12 @@ func (s *Server) handleCallEnd(event map[string]interface{}) {
 		}
 	}
 
+	// Record how a callback call ended, in history and (if tracking is on)
+	// as the callback's engagement
+	if s.callbackRegistry != nil {
+		s.callbackRegistry.RecordCallEnd(callID, endedReason(event), callDuration(event))
+	}
+
 	// Get caller info
 	s.vapiState.mu.RLock()
 	info, ok := s.vapiState.callInfo[callID]
@@ -182,6 +188,39 @@ func (s *Server) handleCallEnd(event map[string]interface{}) {
 	s.vapiState.mu.Unlock()
 }
 
+// callDuration returns how long a call lasted, from VAPI's durationSeconds
+// or the call's start and end times, or 0 if the event doesn't say
+func callDuration(event map[string]interface{}) time.Duration {
+	if secs, ok := event["durationSeconds"].(float64); ok && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	call, _ := event["call"].(map[string]interface{})
+	startedAt, _ := call["startedAt"].(string)
+	endedAt, _ := call["endedAt"].(string)
+	start, err := time.Parse(time.RFC3339, startedAt)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(time.RFC3339, endedAt)
+	if err != nil || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// endedReason returns VAPI's reason a call ended, e.g. "customer-ended-call"
+// or "voicemail"
+func endedReason(event map[string]interface{}) string {
+	if reason, ok := event["endedReason"].(string); ok {
+		return reason
+	}
+	if call, ok := event["call"].(map[string]interface{}); ok {
+		reason, _ := call["endedReason"].(string)
+		return reason
+	}
+	return ""
+}
+
 func (s *Server) synthesizeCallMemory(callerName, transcript string) {
 	// Get Tony for summarization
 	tonyDef, ok := s.config.Agents["Tony"]
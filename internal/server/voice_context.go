@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/textutil"
+	"github.com/everydev1618/tron/internal/voice/elevenlabs"
+	"github.com/everydev1618/tron/internal/webhook"
+)
+
+// maxCallerContextBytes bounds the caller context injected into a call
+const maxCallerContextBytes = 2000
+
+// voiceCallerMaxAge is how long a signed browser caller stays valid
+const voiceCallerMaxAge = 5 * time.Minute
+
+// SetVoiceCallerContext enables greeting known callers with their person
+// memory and recent calls
+func (s *Server) SetVoiceCallerContext(enabled bool) {
+	s.voiceCallerContext = enabled
+}
+
+// SetVoiceCallerSecret sets the secret a browser voice session's caller
+// number is signed with. Without it no browser session has a known caller.
+func (s *Server) SetVoiceCallerSecret(secret string) {
+	s.voiceCallerSecret = secret
+}
+
+// verifiedCaller returns the caller number a browser voice session was
+// opened for, or "" if it can't be trusted. The browser picks ?phone=, so
+// it only counts when ?ts= and ?sig= sign it with the voice caller secret
+// (the webhook Default scheme over "<ts>.<phone>").
+func (s *Server) verifiedCaller(r *http.Request) string {
+	q := r.URL.Query()
+	phone := q.Get("phone")
+	if phone == "" {
+		return ""
+	}
+	if err := webhook.Verify([]byte(phone), q.Get("ts"), q.Get("sig"), s.voiceCallerSecret, voiceCallerMaxAge); err != nil {
+		log.Printf("Ignoring unverified voice caller %s: %v", maskPhone(phone), err)
+		return ""
+	}
+	return phone
+}
+
+// callerContext assembles what we remember about a known caller: permanent
+// person memory first, then recent calls with them. Returns the contact's
+// name and the context, bounded to maxCallerContextBytes, or empty strings
+// for unknown callers or when the feature is off.
+func (s *Server) callerContext(phone string) (name, context string) {
+	if !s.voiceCallerContext || phone == "" {
+		return "", ""
+	}
+	contact, ok := s.customTools.LookupCaller(phone)
+	if !ok || contact.Name == "" {
+		return "", ""
+	}
+
+	var sections []string
+	if person, err := memory.LoadPersonMemory(s.baseDir, contact.Name); err != nil {
+		log.Printf("Failed to load person memory for %s: %v", contact.Name, err)
+	} else if person = strings.TrimSpace(person); person != "" {
+		sections = append(sections, person)
+	}
+	if recent, err := memory.Load(s.baseDir); err != nil {
+		log.Printf("Failed to load recent memory: %v", err)
+	} else if calls := memory.CallsWith(recent, contact.Name); calls != "" {
+		sections = append(sections, "Recent calls:\n"+calls)
+	}
+
+	return contact.Name, truncateContext(strings.Join(sections, "\n\n"), maxCallerContextBytes)
+}
+
+// truncateContext cuts text to at most max bytes at a line boundary
+func truncateContext(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := textutil.Truncate(text, max)
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "\n..."
+}
+
+// callerConversationInit personalizes an ElevenLabs conversation for a known
+// caller. The agent prompt can use {{caller_name}} and {{caller_context}}.
+func (s *Server) callerConversationInit(phone string) (elevenlabs.ConversationInit, bool) {
+	name, context := s.callerContext(phone)
+	if name == "" {
+		return elevenlabs.ConversationInit{}, false
+	}
+
+	firstName := strings.Fields(name)[0]
+	return elevenlabs.ConversationInit{
+		DynamicVariables: map[string]string{
+			"caller_name":    name,
+			"caller_context": context,
+		},
+		FirstMessage: fmt.Sprintf("Hey %s, good to hear from you again.", firstName),
+	}, true
+}
+
+// callerMemoryPromptSection is the caller context for a voice system prompt
+func (s *Server) callerMemoryPromptSection(phone string) string {
+	name, context := s.callerContext(phone)
+	if context == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n## What You Remember About %s\n%s", name, context)
+}
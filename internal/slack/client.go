@@ -6,7 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
 )
 
 const slackAPIBase = "https://slack.com/api"
@@ -20,10 +25,8 @@ type Client struct {
 // NewClient creates a new Slack client
 func NewClient(botToken string) *Client {
 	return &Client{
-		botToken: botToken,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		botToken:   botToken,
+		httpClient: httpclient.New(10 * time.Second),
 	}
 }
 
@@ -42,13 +45,16 @@ type User struct {
 
 // SendMessage posts a message to a Slack channel
 func (c *Client) SendMessage(channel, text string) error {
-	if !c.IsConfigured() {
-		return fmt.Errorf("Slack client not configured")
-	}
-
-	payload := map[string]string{
+	return c.postMessage(map[string]string{
 		"channel": channel,
 		"text":    text,
+	})
+}
+
+// postMessage sends a chat.postMessage payload
+func (c *Client) postMessage(payload any) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("Slack client not configured")
 	}
 
 	body, err := json.Marshal(payload)
@@ -185,3 +191,124 @@ func (c *Client) GetChannelName(channelID string) (string, error) {
 
 	return result.Channel.Name, nil
 }
+
+// UploadFile uploads content as a file to a channel using Slack's external
+// upload flow: reserve an upload URL, send the bytes, then complete the upload
+// to share it in the channel.
+func (c *Client) UploadFile(channel, filename, content, title string) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("Slack client not configured")
+	}
+
+	uploadURL, fileID, err := c.getUploadURL(filename, len(content))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, uploadURL, strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("file upload failed with status %d", resp.StatusCode)
+	}
+
+	return c.completeUpload(channel, fileID, title)
+}
+
+// getUploadURL reserves an upload URL and file ID for a file of the given length
+func (c *Client) getUploadURL(filename string, length int) (string, string, error) {
+	form := url.Values{}
+	form.Set("filename", filename)
+	form.Set("length", strconv.Itoa(length))
+
+	req, err := http.NewRequest(http.MethodPost, slackAPIBase+"/files.getUploadURLExternal", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get upload URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result struct {
+		OK        bool   `json:"ok"`
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+		Error     string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !result.OK {
+		return "", "", fmt.Errorf("Slack API error: %s", result.Error)
+	}
+
+	return result.UploadURL, result.FileID, nil
+}
+
+// completeUpload finalizes an external upload and shares the file in a channel
+func (c *Client) completeUpload(channel, fileID, title string) error {
+	payload := map[string]any{
+		"files":      []map[string]string{{"id": fileID, "title": title}},
+		"channel_id": channel,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload completion: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slackAPIBase+"/files.completeUploadExternal", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to complete upload: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !result.OK {
+		return fmt.Errorf("Slack API error: %s", result.Error)
+	}
+
+	return nil
+}
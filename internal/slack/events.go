@@ -9,6 +9,38 @@ type EventPayload struct {
 	Event     *SlackEvent `json:"event"`      // Actual event
 	EventID   string      `json:"event_id"`   // For deduplication
 	EventTime int64       `json:"event_time"` // Unix timestamp
+
+	// Workspace the event came from, for multi-workspace routing
+	TeamID       string `json:"team_id"`
+	EnterpriseID string `json:"enterprise_id"`
+
+	// Installations the event was delivered for; the bot's own user ID is here
+	Authorizations []struct {
+		UserID string `json:"user_id"`
+		IsBot  bool   `json:"is_bot"`
+	} `json:"authorizations"`
+}
+
+// BotUserID returns the user ID of the bot the event was delivered to, or ""
+func (p *EventPayload) BotUserID() string {
+	for _, a := range p.Authorizations {
+		if a.IsBot {
+			return a.UserID
+		}
+	}
+	return ""
+}
+
+// WorkspaceID returns the team ID the event belongs to, falling back to the
+// event's own team and then the enterprise ID
+func (p *EventPayload) WorkspaceID() string {
+	if p.TeamID != "" {
+		return p.TeamID
+	}
+	if p.Event != nil && p.Event.Team != "" {
+		return p.Event.Team
+	}
+	return p.EnterpriseID
 }
 
 // SlackEvent represents an individual Slack event
@@ -20,6 +52,7 @@ type SlackEvent struct {
 	TS      string `json:"ts"`      // Timestamp (unique ID)
 	BotID   string `json:"bot_id"`  // Bot ID if from a bot
 	Subtype string `json:"subtype"` // Message subtype
+	Team    string `json:"team"`    // Team ID of the workspace
 }
 
 // IsFromBot returns true if the event was sent by a bot
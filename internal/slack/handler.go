@@ -2,16 +2,13 @@ package slack
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +16,7 @@ import (
 	"github.com/everydev1618/tron/internal/knowledge"
 	"github.com/everydev1618/tron/internal/memory"
 	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/webhook"
 	"github.com/everydev1618/govega"
 	"github.com/everydev1618/govega/dsl"
 )
@@ -65,6 +63,7 @@ type conversationMessage struct {
 // Handler handles Slack events
 type Handler struct {
 	client        *Client
+	workspaces    *Workspaces // Per-workspace clients, defaulting to client
 	signingSecret string
 	orch          *vega.Orchestrator
 	config        *dsl.Document
@@ -109,6 +108,10 @@ type Handler struct {
 	// Knowledge store for feed injection
 	knowledgeStore *knowledge.Store
 
+	// Interactive button handlers by action_id
+	actions   map[string]ActionHandler
+	actionsMu sync.RWMutex
+
 	// Lifecycle
 	stopCh chan struct{}
 	wg     sync.WaitGroup
@@ -124,6 +127,7 @@ func NewHandler(client *Client, signingSecret string, orch *vega.Orchestrator, c
 func NewPersonaHandler(client *Client, signingSecret string, orch *vega.Orchestrator, config *dsl.Document, baseDir, persona string) *Handler {
 	h := &Handler{
 		client:          client,
+		workspaces:      NewWorkspaces(client),
 		signingSecret:   signingSecret,
 		orch:            orch,
 		config:          config,
@@ -137,6 +141,7 @@ func NewPersonaHandler(client *Client, signingSecret string, orch *vega.Orchestr
 		processedEvents:   make(map[string]time.Time),
 		channelProcessing: make(map[string]bool),
 		sessions:          make(map[string]*vega.Process),
+		actions:           make(map[string]ActionHandler),
 		stopCh:            make(chan struct{}),
 	}
 
@@ -148,6 +153,17 @@ func NewPersonaHandler(client *Client, signingSecret string, orch *vega.Orchestr
 	h.wg.Add(1)
 	go h.eventCacheCleanup()
 
+	// Remember which workspace each channel is in across restarts
+	if baseDir != "" {
+		channelsFile := "slack_channels.json"
+		if persona != "" {
+			channelsFile = "slack_channels_" + strings.ToLower(persona) + ".json"
+		}
+		if err := h.workspaces.SetChannelsFile(filepath.Join(baseDir, "tron.work", channelsFile)); err != nil {
+			log.Printf("[slack] Failed to load channel workspaces: %v", err)
+		}
+	}
+
 	if persona != "" {
 		log.Printf("[slack] Handler created for persona: %s", persona)
 	}
@@ -165,6 +181,23 @@ func (h *Handler) Client() *Client {
 	return h.client
 }
 
+// AddWorkspace registers the bot token for another Slack workspace, so
+// events from that team are answered with its token
+func (h *Handler) AddWorkspace(teamID, botToken string) {
+	h.workspaces.Add(teamID, botToken)
+}
+
+// Workspaces returns the handler's workspace registry. It sends to each
+// channel with the token of the workspace the channel belongs to.
+func (h *Handler) Workspaces() *Workspaces {
+	return h.workspaces
+}
+
+// clientFor returns the Slack client for a channel's workspace
+func (h *Handler) clientFor(channel string) *Client {
+	return h.workspaces.ClientForChannel(channel)
+}
+
 // SetCustomTools sets the custom persona tools
 func (h *Handler) SetCustomTools(customTools interface{}) {
 	h.customTools = customTools
@@ -212,9 +245,17 @@ func (h *Handler) getOrCreateSession(ctx context.Context, channel, agentName, us
 		systemPrompt += memory.GetPromptSection(memContent)
 	}
 
-	// Inject knowledge feed
-	if h.knowledgeStore != nil {
-		feedSection := knowledge.GetFeedPromptSection(h.knowledgeStore)
+	// Inject knowledge feed, from the tools' current store when they have
+	// one, since compaction replaces it
+	type knowledgeSource interface {
+		GetKnowledgeStore() *knowledge.Store
+	}
+	store := h.knowledgeStore
+	if src, ok := h.customTools.(knowledgeSource); ok {
+		store = src.GetKnowledgeStore()
+	}
+	if store != nil {
+		feedSection := knowledge.GetFeedPromptSection(store)
 		if feedSection != "" {
 			systemPrompt += feedSection
 		}
@@ -285,7 +326,7 @@ func (h *Handler) handleTronCommand(channel, message string) bool {
 • *tronservers* - List all running project servers
 • *tronclearsessions* - Clear cached sessions (forces prompt refresh)
 • *tronagents* - List active agent processes`
-		h.client.SendMessage(channel, help)
+		h.clientFor(channel).SendMessage(channel, help)
 		return true
 
 	case "tronservers":
@@ -294,7 +335,7 @@ func (h *Handler) handleTronCommand(channel, message string) bool {
 
 	case "tronclearsessions":
 		count := h.ClearSessions()
-		h.client.SendMessage(channel, fmt.Sprintf("✓ Cleared %d session(s). Next message will use fresh prompts.", count))
+		h.clientFor(channel).SendMessage(channel, fmt.Sprintf("✓ Cleared %d session(s). Next message will use fresh prompts.", count))
 		return true
 
 	case "tronagents":
@@ -309,7 +350,7 @@ func (h *Handler) handleTronCommand(channel, message string) bool {
 func (h *Handler) handleTronServers(channel string) {
 	// Try to get server list from customTools (PersonaTools)
 	if h.customTools == nil {
-		h.client.SendMessage(channel, "Server management not available")
+		h.clientFor(channel).SendMessage(channel, "Server management not available")
 		return
 	}
 
@@ -320,9 +361,9 @@ func (h *Handler) handleTronServers(channel string) {
 
 	if lister, ok := h.customTools.(serverLister); ok {
 		result := lister.ListServersForDisplay()
-		h.client.SendMessage(channel, result)
+		h.clientFor(channel).SendMessage(channel, result)
 	} else {
-		h.client.SendMessage(channel, "Server listing not available")
+		h.clientFor(channel).SendMessage(channel, "Server management not available")
 	}
 }
 
@@ -330,7 +371,7 @@ func (h *Handler) handleTronServers(channel string) {
 func (h *Handler) handleTronAgents(channel string) {
 	processes := h.orch.List()
 	if len(processes) == 0 {
-		h.client.SendMessage(channel, "No active agent processes")
+		h.clientFor(channel).SendMessage(channel, "No active agent processes")
 		return
 	}
 
@@ -343,7 +384,7 @@ func (h *Handler) handleTronAgents(channel string) {
 		}
 		sb.WriteString(fmt.Sprintf("• *%s* (ID: %s) - Status: %s\n", name, p.ID[:8], p.Status()))
 	}
-	h.client.SendMessage(channel, sb.String())
+	h.clientFor(channel).SendMessage(channel, sb.String())
 }
 
 // HandleEvents is the HTTP handler for Slack events
@@ -362,8 +403,8 @@ func (h *Handler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 		timestamp := r.Header.Get("X-Slack-Request-Timestamp")
 		signature := r.Header.Get("X-Slack-Signature")
 
-		if !h.verifyRequest(timestamp, signature, body) {
-			log.Printf("[slack] Invalid signature - timestamp: %s", timestamp)
+		if err := h.verifyRequest(timestamp, signature, body); err != nil {
+			log.Printf("[slack] Invalid signature - timestamp: %s: %v", timestamp, err)
 			http.Error(w, "Invalid signature", http.StatusUnauthorized)
 			return
 		}
@@ -404,41 +445,26 @@ func (h *Handler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 
 	// Process event asynchronously
 	if payload.Event != nil {
+		h.workspaces.BindChannel(payload.Event.Channel, payload.WorkspaceID())
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
 					log.Printf("Panic processing Slack event: %v", r)
 				}
 			}()
-			h.processEvent(payload.Event)
+			h.processEvent(payload.Event, payload.BotUserID())
 		}()
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *Handler) verifyRequest(timestamp, signature string, body []byte) bool {
-	// Check timestamp is recent
-	ts, err := strconv.ParseInt(timestamp, 10, 64)
-	if err != nil {
-		return false
-	}
-
-	eventTime := time.Unix(ts, 0)
-	if time.Since(eventTime) > timestampValidityWindow {
-		return false
-	}
-
-	// Compute expected signature
-	baseString := fmt.Sprintf("v0:%s:%s", timestamp, string(body))
-	mac := hmac.New(sha256.New, []byte(h.signingSecret))
-	mac.Write([]byte(baseString))
-	expectedSig := "v0=" + hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedSig))
+// verifyRequest checks a request's Slack signature and timestamp
+func (h *Handler) verifyRequest(timestamp, signature string, body []byte) error {
+	return webhook.Slack.Verify(body, timestamp, signature, h.signingSecret, timestampValidityWindow)
 }
 
-func (h *Handler) processEvent(event *SlackEvent) {
+func (h *Handler) processEvent(event *SlackEvent, botUserID string) {
 	// Filter events
 	if event.Type != "message" && event.Type != "app_mention" {
 		return
@@ -481,7 +507,7 @@ func (h *Handler) processEvent(event *SlackEvent) {
 	h.activityMu.Unlock()
 
 	// Get user info
-	user := h.getUser(event.User)
+	user := h.getUser(event.Channel, event.User)
 	userName := "Unknown"
 	userEmail := ""
 	if user != nil {
@@ -502,9 +528,15 @@ func (h *Handler) processEvent(event *SlackEvent) {
 		Email:     userEmail,
 	})
 
+	// Drop the @mention that addressed us so it doesn't reach the agent
+	text := event.Text
+	if event.IsAppMention() {
+		text = ExtractCommand(text, botUserID)
+	}
+
 	// Resolve agent based on message prefix and channel name
 	channelName := h.getChannelName(event.Channel)
-	agentName, cleanedMessage := h.resolveAgentFromMessage(ctx, channelName, event.Text)
+	agentName, cleanedMessage := h.resolveAgentFromMessage(ctx, channelName, text)
 
 	// Validate message content is not empty
 	cleanedMessage = strings.TrimSpace(cleanedMessage)
@@ -522,7 +554,7 @@ func (h *Handler) processEvent(event *SlackEvent) {
 	proc, err := h.getOrCreateSession(ctx, event.Channel, agentName, userName)
 	if err != nil {
 		log.Printf("Error getting/creating session: %v", err)
-		h.client.SendMessage(event.Channel, "Sorry, I encountered an error processing your message.")
+		h.clientFor(event.Channel).SendMessage(event.Channel, "Sorry, I encountered an error processing your message.")
 		return
 	}
 
@@ -532,7 +564,7 @@ func (h *Handler) processEvent(event *SlackEvent) {
 		log.Printf("Error processing Slack message: %v", err)
 		// Mark the session as failed so a new one is created next time
 		proc.Fail(err)
-		h.client.SendMessage(event.Channel, "Sorry, I encountered an error processing your message.")
+		h.clientFor(event.Channel).SendMessage(event.Channel, "Sorry, I encountered an error processing your message.")
 		return
 	}
 
@@ -545,7 +577,7 @@ func (h *Handler) processEvent(event *SlackEvent) {
 	}
 
 	// Send response
-	if err := h.client.SendMessage(event.Channel, cleanResponse); err != nil {
+	if err := h.clientFor(event.Channel).SendMessage(event.Channel, cleanResponse); err != nil {
 		log.Printf("Error sending Slack response: %v", err)
 		return
 	}
@@ -635,7 +667,8 @@ func (h *Handler) processWithClaude(ctx context.Context, agentName string, syste
 	return response, nil
 }
 
-func (h *Handler) getUser(userID string) *User {
+// getUser returns a user's profile, looked up in the channel's workspace
+func (h *Handler) getUser(channel, userID string) *User {
 	// Check cache
 	h.userCacheMu.RLock()
 	user, ok := h.userCache[userID]
@@ -646,7 +679,7 @@ func (h *Handler) getUser(userID string) *User {
 	}
 
 	// Fetch from API
-	user, err := h.client.GetUserInfo(userID)
+	user, err := h.clientFor(channel).GetUserInfo(userID)
 	if err != nil {
 		log.Printf("Error getting user info for %s: %v", userID, err)
 		return nil
@@ -672,7 +705,7 @@ func (h *Handler) getChannelName(channelID string) string {
 	}
 
 	// Fetch from API
-	name, err := h.client.GetChannelName(channelID)
+	name, err := h.clientFor(channelID).GetChannelName(channelID)
 	if err != nil {
 		log.Printf("Error getting channel name for %s: %v", channelID, err)
 		return ""
@@ -0,0 +1,150 @@
+package slack
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// Button is an action button attached to a message
+type Button struct {
+	ActionID string // Dispatch key for the registered ActionHandler
+	Text     string // Button label
+	Value    string // Opaque data passed back on click (e.g. a process ID)
+	Style    string // "", "primary", or "danger"
+}
+
+// InteractionPayload is the payload Slack POSTs when a user clicks a button
+type InteractionPayload struct {
+	Type        string `json:"type"` // "block_actions"
+	TriggerID   string `json:"trigger_id"`
+	ResponseURL string `json:"response_url"`
+	Team        struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"user"`
+	Channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channel"`
+	Message struct {
+		TS   string `json:"ts"`
+		Text string `json:"text"`
+	} `json:"message"`
+	Actions []BlockAction `json:"actions"`
+}
+
+// BlockAction is a single clicked element in an interaction payload
+type BlockAction struct {
+	ActionID string `json:"action_id"`
+	BlockID  string `json:"block_id"`
+	Value    string `json:"value"`
+	ActionTS string `json:"action_ts"`
+}
+
+// ActionHandler handles a button click
+type ActionHandler func(payload *InteractionPayload, action BlockAction)
+
+// SendMessageWithButtons posts a message with a row of action buttons
+func (c *Client) SendMessageWithButtons(channel, text string, buttons []Button) error {
+	elements := make([]map[string]any, 0, len(buttons))
+	for _, b := range buttons {
+		el := map[string]any{
+			"type":      "button",
+			"action_id": b.ActionID,
+			"text":      map[string]string{"type": "plain_text", "text": b.Text},
+		}
+		if b.Value != "" {
+			el["value"] = b.Value
+		}
+		if b.Style != "" {
+			el["style"] = b.Style
+		}
+		elements = append(elements, el)
+	}
+
+	return c.postMessage(map[string]any{
+		"channel": channel,
+		"text":    text, // Fallback for notifications
+		"blocks": []map[string]any{
+			{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": text}},
+			{"type": "actions", "elements": elements},
+		},
+	})
+}
+
+// RegisterAction registers the handler for buttons with the given action_id
+func (h *Handler) RegisterAction(actionID string, fn ActionHandler) {
+	h.actionsMu.Lock()
+	defer h.actionsMu.Unlock()
+	h.actions[actionID] = fn
+}
+
+// HandleInteractions is the HTTP handler for Slack interactive payloads
+func (h *Handler) HandleInteractions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if h.signingSecret != "" {
+		timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+		signature := r.Header.Get("X-Slack-Signature")
+
+		if err := h.verifyRequest(timestamp, signature, body); err != nil {
+			log.Printf("[slack] Invalid interaction signature - timestamp: %s: %v", timestamp, err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// Interactions arrive form-encoded with the JSON in a "payload" field
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	var payload InteractionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		log.Printf("[slack] Failed to parse interaction payload: %v", err)
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if payload.Type != "block_actions" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	h.workspaces.BindChannel(payload.Channel.ID, payload.Team.ID)
+
+	// Slack expects an ack within 3 seconds, so handlers run in the background
+	for _, action := range payload.Actions {
+		h.actionsMu.RLock()
+		fn, ok := h.actions[action.ActionID]
+		h.actionsMu.RUnlock()
+
+		if !ok {
+			log.Printf("[slack] No handler for action %s", action.ActionID)
+			continue
+		}
+
+		go func(action BlockAction) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[slack] Panic handling action %s: %v", action.ActionID, r)
+				}
+			}()
+			fn(&payload, action)
+		}(action)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
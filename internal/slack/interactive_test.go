@@ -0,0 +1,115 @@
+package slack
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/webhook"
+)
+
+// interactionRequest builds a form-encoded interaction POST, signed with
+// secret unless it's empty
+func interactionRequest(payload, secret string) *http.Request {
+	body := url.Values{"payload": {payload}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Slack-Request-Timestamp", ts)
+		req.Header.Set("X-Slack-Signature", webhook.Slack.Sign([]byte(body), ts, secret))
+	}
+	return req
+}
+
+func TestHandleInteractionsRejectsBadSignature(t *testing.T) {
+	h := NewHandler(NewClient("xoxb-test"), "signing-secret", nil, nil, "")
+	defer h.Shutdown()
+	called := make(chan struct{}, 1)
+	h.RegisterAction("cancel", func(*InteractionPayload, BlockAction) { called <- struct{}{} })
+
+	payload := `{"type":"block_actions","actions":[{"action_id":"cancel"}]}`
+	for name, req := range map[string]*http.Request{
+		"unsigned":    interactionRequest(payload, ""),
+		"wrong key":   interactionRequest(payload, "other-secret"),
+		"tampered":    tamper(interactionRequest(payload, "signing-secret")),
+		"stale stamp": stale(interactionRequest(payload, "signing-secret")),
+	} {
+		rec := httptest.NewRecorder()
+		h.HandleInteractions(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, rec.Code)
+		}
+	}
+
+	select {
+	case <-called:
+		t.Error("action ran for a rejected request")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// tamper swaps a signed request's body for another one
+func tamper(req *http.Request) *http.Request {
+	body := url.Values{"payload": {`{"type":"block_actions","actions":[{"action_id":"delete_everything"}]}`}}.Encode()
+	req.Body = io.NopCloser(strings.NewReader(body))
+	return req
+}
+
+// stale re-signs a request with a timestamp outside the validity window
+func stale(req *http.Request) *http.Request {
+	body, _ := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", webhook.Slack.Sign(body, ts, "signing-secret"))
+	return req
+}
+
+func TestHandleInteractionsDispatchesButtons(t *testing.T) {
+	h := NewHandler(NewClient("xoxb-test"), "signing-secret", nil, nil, "")
+	defer h.Shutdown()
+	team := NewClient("xoxb-team")
+	h.workspaces.AddClient("T1", team)
+
+	type click struct {
+		user, channel, value string
+	}
+	clicks := make(chan click, 2)
+	h.RegisterAction("cancel_agent", func(p *InteractionPayload, a BlockAction) {
+		clicks <- click{p.User.ID, p.Channel.ID, a.Value}
+	})
+
+	payload := `{"type":"block_actions","team":{"id":"T1"},"user":{"id":"U1"},"channel":{"id":"C1"},` +
+		`"actions":[{"action_id":"unregistered","value":"x"},{"action_id":"cancel_agent","value":"proc-42"}]}`
+	rec := httptest.NewRecorder()
+	h.HandleInteractions(rec, interactionRequest(payload, "signing-secret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	select {
+	case c := <-clicks:
+		if c != (click{"U1", "C1", "proc-42"}) {
+			t.Errorf("click = %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("registered action never ran")
+	}
+	select {
+	case c := <-clicks:
+		t.Errorf("unexpected extra click %+v", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The click tells us which workspace the channel is in
+	if h.workspaces.ClientForChannel("C1") != team {
+		t.Error("channel C1 should be bound to workspace T1")
+	}
+}
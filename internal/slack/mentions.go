@@ -0,0 +1,32 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches user mention markup like <@U123456> or <@U123456|alice>
+var mentionPattern = regexp.MustCompile(`<@([A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// StripMentions removes all user mention markup from text
+func StripMentions(text string) string {
+	return strings.Join(strings.Fields(mentionPattern.ReplaceAllString(text, " ")), " ")
+}
+
+// ExtractCommand removes the leading mention of the bot from text and returns
+// the rest. With an empty botUserID any leading mention is removed. Mentions
+// of other users later in the text are kept.
+func ExtractCommand(text, botUserID string) string {
+	text = strings.TrimSpace(text)
+	loc := mentionPattern.FindStringSubmatchIndex(text)
+	if loc == nil || loc[0] != 0 {
+		return text
+	}
+	if botUserID != "" && text[loc[2]:loc[3]] != botUserID {
+		return text
+	}
+
+	// Drop separators people type after a mention, as in "@tron: deploy"
+	rest := strings.TrimLeft(text[loc[1]:], " \t:,")
+	return strings.TrimSpace(rest)
+}
@@ -0,0 +1,38 @@
+package slack
+
+import "testing"
+
+func TestStripMentions(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"<@U123456> deploy the app", "deploy the app"},
+		{"ask <@U2|alice> and <@U3> about it", "ask and about it"},
+		{"no mentions here", "no mentions here"},
+		{"<@U123456>", ""},
+	}
+	for _, tt := range tests {
+		if got := StripMentions(tt.in); got != tt.want {
+			t.Errorf("StripMentions(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExtractCommand(t *testing.T) {
+	tests := []struct {
+		text, bot, want string
+	}{
+		{"<@UBOT> build the site", "UBOT", "build the site"},
+		{"  <@UBOT|tron>: build the site", "UBOT", "build the site"},
+		{"<@UBOT> ping <@U2>", "UBOT", "ping <@U2>"},
+		{"<@UOTHER> build the site", "UBOT", "<@UOTHER> build the site"},
+		{"<@UOTHER> build the site", "", "build the site"},
+		{"hey <@UBOT> build", "UBOT", "hey <@UBOT> build"},
+		{"<@UBOT>", "UBOT", ""},
+	}
+	for _, tt := range tests {
+		if got := ExtractCommand(tt.text, tt.bot); got != tt.want {
+			t.Errorf("ExtractCommand(%q, %q) = %q, want %q", tt.text, tt.bot, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+// Workspaces holds a bot client per Slack workspace (team ID) and remembers
+// which workspace each channel belongs to, so replies and notifications go
+// out with the right token. Channels with no known workspace use the
+// default client.
+type Workspaces struct {
+	defaultClient *Client
+
+	clients      map[string]*Client // team ID -> client
+	channels     map[string]string  // channel ID -> team ID
+	channelsFile string             // Where channels is saved ("" = not saved)
+	mu           sync.RWMutex
+}
+
+// NewWorkspaces creates a registry that falls back to defaultClient
+func NewWorkspaces(defaultClient *Client) *Workspaces {
+	return &Workspaces{
+		defaultClient: defaultClient,
+		clients:       make(map[string]*Client),
+		channels:      make(map[string]string),
+	}
+}
+
+// Add registers the bot token for a workspace
+func (w *Workspaces) Add(teamID, botToken string) {
+	w.AddClient(teamID, NewClient(botToken))
+}
+
+// AddClient registers the client for a workspace
+func (w *Workspaces) AddClient(teamID string, client *Client) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clients[teamID] = client
+}
+
+// Teams returns the IDs of the registered workspaces
+func (w *Workspaces) Teams() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	teams := make([]string, 0, len(w.clients))
+	for id := range w.clients {
+		teams = append(teams, id)
+	}
+	return teams
+}
+
+// Client returns the client for a workspace, or the default client if the
+// workspace has no token registered
+func (w *Workspaces) Client(teamID string) *Client {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if c, ok := w.clients[teamID]; ok {
+		return c
+	}
+	return w.defaultClient
+}
+
+// SetChannelsFile loads the channel bindings saved at path and saves new
+// ones there, so a channel's notifications keep their workspace's token
+// after a restart, before the channel sends another event
+func (w *Workspaces) SetChannelsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var saved map[string]string
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("invalid %s: %w", path, err)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.channelsFile = path
+	for channel, teamID := range saved {
+		if _, ok := w.channels[channel]; !ok {
+			w.channels[channel] = teamID
+		}
+	}
+	return nil
+}
+
+// BindChannel records that a channel belongs to a workspace
+func (w *Workspaces) BindChannel(channel, teamID string) {
+	if channel == "" || teamID == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.channels[channel] == teamID {
+		return
+	}
+	w.channels[channel] = teamID
+
+	// Bindings rarely change, so saving them all each time is cheap
+	if w.channelsFile == "" {
+		return
+	}
+	data, err := json.MarshalIndent(w.channels, "", "  ")
+	if err == nil {
+		err = persist.WriteFile(w.channelsFile, data)
+	}
+	if err != nil {
+		log.Printf("[slack] Failed to save channel workspaces: %v", err)
+	}
+}
+
+// ClientForChannel returns the client for the workspace a channel belongs to
+func (w *Workspaces) ClientForChannel(channel string) *Client {
+	w.mu.RLock()
+	teamID := w.channels[channel]
+	w.mu.RUnlock()
+	return w.Client(teamID)
+}
+
+// SendMessage posts a message using the channel's workspace client
+func (w *Workspaces) SendMessage(channel, text string) error {
+	client := w.ClientForChannel(channel)
+	if client == nil {
+		return fmt.Errorf("no Slack workspace configured for channel %s", channel)
+	}
+	return client.SendMessage(channel, text)
+}
+
+// UploadFile uploads a file using the channel's workspace client
+func (w *Workspaces) UploadFile(channel, filename, content, title string) error {
+	client := w.ClientForChannel(channel)
+	if client == nil {
+		return fmt.Errorf("no Slack workspace configured for channel %s", channel)
+	}
+	return client.UploadFile(channel, filename, content, title)
+}
+
+// ParseWorkspaceTokens parses "T123:xoxb-...,T456:xoxb-..." into a map of
+// team ID to bot token
+func ParseWorkspaceTokens(s string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		teamID, token, ok := strings.Cut(pair, ":")
+		teamID, token = strings.TrimSpace(teamID), strings.TrimSpace(token)
+		if !ok || teamID == "" || token == "" {
+			return nil, fmt.Errorf("invalid workspace token %q, want TEAM_ID:token", pair)
+		}
+		tokens[teamID] = token
+	}
+	return tokens, nil
+}
@@ -0,0 +1,81 @@
+package slack
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspacesRouteByChannel(t *testing.T) {
+	def := NewClient("xoxb-default")
+	w := NewWorkspaces(def)
+	w.Add("T1", "xoxb-one")
+	w.Add("T2", "xoxb-two")
+
+	w.BindChannel("C1", "T1")
+	w.BindChannel("C2", "T2")
+
+	if got := w.ClientForChannel("C1").botToken; got != "xoxb-one" {
+		t.Errorf("C1 token = %q, want xoxb-one", got)
+	}
+	if got := w.ClientForChannel("C2").botToken; got != "xoxb-two" {
+		t.Errorf("C2 token = %q, want xoxb-two", got)
+	}
+	if got := w.ClientForChannel("C-unknown"); got != def {
+		t.Error("unbound channel should use the default client")
+	}
+
+	// A channel from a workspace with no token falls back too
+	w.BindChannel("C3", "T-unregistered")
+	if got := w.ClientForChannel("C3"); got != def {
+		t.Error("channel in an unregistered workspace should use the default client")
+	}
+}
+
+func TestWorkspacesChannelsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tron.work", "slack_channels.json")
+	w := NewWorkspaces(NewClient("xoxb-default"))
+	if err := w.SetChannelsFile(path); err != nil {
+		t.Fatalf("SetChannelsFile without a file: %v", err)
+	}
+	w.BindChannel("C1", "T1")
+
+	restarted := NewWorkspaces(NewClient("xoxb-default"))
+	restarted.Add("T1", "xoxb-one")
+	if err := restarted.SetChannelsFile(path); err != nil {
+		t.Fatalf("SetChannelsFile: %v", err)
+	}
+	if got := restarted.ClientForChannel("C1").botToken; got != "xoxb-one" {
+		t.Errorf("C1 token after restart = %q, want xoxb-one", got)
+	}
+}
+
+func TestEventPayloadWorkspaceID(t *testing.T) {
+	var p EventPayload
+	body := `{"type":"event_callback","team_id":"T1","enterprise_id":"E1","event":{"type":"message","channel":"C1","team":"T9"}}`
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.WorkspaceID(); got != "T1" {
+		t.Errorf("WorkspaceID() = %q, want T1", got)
+	}
+
+	p.TeamID = ""
+	if got := p.WorkspaceID(); got != "T9" {
+		t.Errorf("WorkspaceID() without team_id = %q, want the event's team T9", got)
+	}
+}
+
+func TestParseWorkspaceTokens(t *testing.T) {
+	tokens, err := ParseWorkspaceTokens(" T1:xoxb-one, T2:xoxb-two ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens["T1"] != "xoxb-one" || tokens["T2"] != "xoxb-two" {
+		t.Errorf("tokens = %v", tokens)
+	}
+
+	if _, err := ParseWorkspaceTokens("T1"); err == nil {
+		t.Error("expected an error for a pair without a token")
+	}
+}
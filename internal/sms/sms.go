@@ -0,0 +1,236 @@
+// Package sms sends short text notifications through a pluggable provider.
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+// MaxLength is the default message cap: two standard SMS segments
+const MaxLength = 320
+
+// heldKey is where messages held for quiet hours are saved
+const heldKey = "tron.work/sms_held.json"
+
+// Provider sends text messages. TwilioClient is the built-in implementation.
+type Provider interface {
+	IsConfigured() bool
+	Send(ctx context.Context, to, body string) error
+}
+
+// QuietHours is a daily window, in local hours, when texts are held back.
+// A window may wrap midnight (e.g. 21 to 8). Start == End disables it.
+type QuietHours struct {
+	Start    int // Hour the window opens, 0-23
+	End      int // Hour the window closes, 0-23
+	Location *time.Location
+}
+
+// ParseQuietHours parses a window like "21-8"
+func ParseQuietHours(s string, loc *time.Location) (QuietHours, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q, want START-END like 21-8", s)
+	}
+	start, err1 := strconv.Atoi(strings.TrimSpace(startStr))
+	end, err2 := strconv.Atoi(strings.TrimSpace(endStr))
+	if err1 != nil || err2 != nil || start < 0 || start > 23 || end < 0 || end > 23 {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q, hours must be 0-23", s)
+	}
+	return QuietHours{Start: start, End: end, Location: loc}, nil
+}
+
+// Contains reports whether t falls inside the quiet window
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	if q.Location != nil {
+		t = t.In(q.Location)
+	}
+	h := t.Hour()
+	if q.Start < q.End {
+		return h >= q.Start && h < q.End
+	}
+	return h >= q.Start || h < q.End
+}
+
+// Until returns how long after t the quiet window ends, or 0 if t is
+// outside it
+func (q QuietHours) Until(t time.Time) time.Duration {
+	if !q.Contains(t) {
+		return 0
+	}
+	if q.Location != nil {
+		t = t.In(q.Location)
+	}
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End, 0, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end.Sub(t)
+}
+
+// heldMessage is a text waiting for the quiet window to end
+type heldMessage struct {
+	To     string    `json:"to"`
+	Body   string    `json:"body"`
+	SendAt time.Time `json:"send_at"`
+}
+
+// Notifier sends capped-length texts through a provider, holding messages
+// that fall in quiet hours until the window ends. Held messages are saved
+// if a store is set (see SetStore); otherwise they're lost on restart.
+type Notifier struct {
+	provider  Provider
+	quiet     QuietHours
+	maxLength int
+	now       func() time.Time
+	after     func(time.Duration, func()) // Schedules held messages (time.AfterFunc)
+
+	mu    sync.Mutex
+	store persist.Store
+	held  []*heldMessage
+}
+
+// NewNotifier creates a notifier with the default length cap and no quiet hours
+func NewNotifier(provider Provider) *Notifier {
+	return &Notifier{
+		provider:  provider,
+		maxLength: MaxLength,
+		now:       time.Now,
+		after:     func(d time.Duration, fn func()) { time.AfterFunc(d, fn) },
+	}
+}
+
+// SetQuietHours sets the window when texts are held back
+func (n *Notifier) SetQuietHours(q QuietHours) {
+	n.quiet = q
+}
+
+// SetStore saves held messages under store so they survive a restart.
+// Messages a previous run was holding are rescheduled; any whose window has
+// already ended go out right away.
+func (n *Notifier) SetStore(store persist.Store) error {
+	var held []*heldMessage
+	_, err := persist.LoadJSON(store, heldKey, &held)
+
+	n.mu.Lock()
+	n.store = store
+	n.held = held
+	n.mu.Unlock()
+
+	now := n.now()
+	for _, m := range held {
+		n.schedule(m, m.SendAt.Sub(now))
+	}
+	if len(held) > 0 {
+		log.Printf("[sms] Rescheduled %d held messages", len(held))
+	}
+	return err
+}
+
+// SetMaxLength sets the message length cap
+func (n *Notifier) SetMaxLength(max int) {
+	if max > 0 {
+		n.maxLength = max
+	}
+}
+
+// IsConfigured returns true if the provider can send
+func (n *Notifier) IsConfigured() bool {
+	return n != nil && n.provider != nil && n.provider.IsConfigured()
+}
+
+// Notify texts body to a phone number, truncated to the length cap. During
+// quiet hours the message is held until the window ends and deferred is true.
+func (n *Notifier) Notify(ctx context.Context, to, body string) (deferred bool, err error) {
+	if !n.IsConfigured() {
+		return false, fmt.Errorf("SMS provider not configured")
+	}
+	if strings.TrimSpace(to) == "" {
+		return false, fmt.Errorf("phone number required for SMS")
+	}
+
+	body = Truncate(body, n.maxLength)
+
+	now := n.now()
+	if wait := n.quiet.Until(now); wait > 0 {
+		m := &heldMessage{To: to, Body: body, SendAt: now.Add(wait)}
+		n.mu.Lock()
+		n.held = append(n.held, m)
+		n.saveLocked()
+		n.mu.Unlock()
+
+		n.schedule(m, wait)
+		log.Printf("[sms] Quiet hours, holding message to %s for %s", maskPhone(to), wait.Round(time.Minute))
+		return true, nil
+	}
+
+	return false, n.provider.Send(ctx, to, body)
+}
+
+// schedule sends a held message after wait and forgets it once it's gone
+// out (or failed to)
+func (n *Notifier) schedule(m *heldMessage, wait time.Duration) {
+	if wait < 0 {
+		wait = 0
+	}
+	n.after(wait, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := n.provider.Send(ctx, m.To, m.Body); err != nil {
+			log.Printf("[sms] Failed to send held message to %s: %v", maskPhone(m.To), err)
+		}
+
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for i, h := range n.held {
+			if h == m {
+				n.held = append(n.held[:i], n.held[i+1:]...)
+				break
+			}
+		}
+		n.saveLocked()
+	})
+}
+
+// saveLocked writes held messages to the store, if one is set. Caller must
+// hold the lock.
+func (n *Notifier) saveLocked() {
+	if n.store == nil {
+		return
+	}
+	if err := persist.SaveJSON(n.store, heldKey, n.held); err != nil {
+		log.Printf("[sms] Failed to save held messages: %v", err)
+	}
+}
+
+// Truncate shortens text to at most max characters, ending with an ellipsis
+// when cut
+func Truncate(text string, max int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text
+	}
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return strings.TrimSpace(string(runes[:max-3])) + "..."
+}
+
+// maskPhone hides the middle of a phone number for logs
+func maskPhone(phone string) string {
+	if len(phone) > 6 {
+		return phone[:3] + "***" + phone[len(phone)-4:]
+	}
+	return phone
+}
@@ -0,0 +1,146 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+// stubProvider records sent messages
+type stubProvider struct {
+	sent []string
+}
+
+func (p *stubProvider) IsConfigured() bool { return true }
+
+func (p *stubProvider) Send(ctx context.Context, to, body string) error {
+	p.sent = append(p.sent, to+": "+body)
+	return nil
+}
+
+func TestQuietHoursWrapMidnight(t *testing.T) {
+	q, err := ParseQuietHours("21-8", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(h int) time.Time { return time.Date(2026, 3, 1, h, 30, 0, 0, time.UTC) }
+	for h, want := range map[int]bool{20: false, 21: true, 23: true, 2: true, 7: true, 8: false, 12: false} {
+		if got := q.Contains(at(h)); got != want {
+			t.Errorf("Contains(%02d:30) = %v, want %v", h, got, want)
+		}
+	}
+
+	if got := q.Until(at(23)); got != 8*time.Hour+30*time.Minute {
+		t.Errorf("Until(23:30) = %v, want 8h30m", got)
+	}
+	if got := q.Until(at(12)); got != 0 {
+		t.Errorf("Until(12:30) = %v, want 0", got)
+	}
+
+	if _, err := ParseQuietHours("25-8", time.UTC); err == nil {
+		t.Error("expected an error for hour 25")
+	}
+}
+
+func TestNotifierHoldsDuringQuietHours(t *testing.T) {
+	provider := &stubProvider{}
+	n := NewNotifier(provider)
+	n.SetQuietHours(QuietHours{Start: 21, End: 8, Location: time.UTC})
+	n.now = func() time.Time { return time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC) }
+
+	var held func()
+	var wait time.Duration
+	n.after = func(d time.Duration, fn func()) { wait, held = d, fn }
+
+	deferred, err := n.Notify(context.Background(), "+15551234567", "done")
+	if err != nil || !deferred {
+		t.Fatalf("Notify() in quiet hours = %v, %v, want deferred", deferred, err)
+	}
+	if len(provider.sent) != 0 {
+		t.Fatal("message sent during quiet hours")
+	}
+	if wait != 10*time.Hour {
+		t.Errorf("held for %v, want 10h", wait)
+	}
+
+	held()
+	if len(provider.sent) != 1 {
+		t.Errorf("held message sent %d times, want 1", len(provider.sent))
+	}
+}
+
+func TestHeldMessagesSurviveRestart(t *testing.T) {
+	store := persist.NewFileStore(t.TempDir())
+	night := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+
+	n := NewNotifier(&stubProvider{})
+	n.SetQuietHours(QuietHours{Start: 21, End: 8, Location: time.UTC})
+	n.now = func() time.Time { return night }
+	n.after = func(time.Duration, func()) {} // Restarts before the window ends
+	if err := n.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Notify(context.Background(), "+15551234567", "done"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next run starts after the window and sends it straight away
+	provider := &stubProvider{}
+	restarted := NewNotifier(provider)
+	restarted.now = func() time.Time { return night.Add(11 * time.Hour) }
+	var wait time.Duration
+	restarted.after = func(d time.Duration, fn func()) { wait = d; fn() }
+	if err := restarted.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.sent) != 1 || provider.sent[0] != "+15551234567: done" || wait != 0 {
+		t.Errorf("sent = %v after %v, want the held message right away", provider.sent, wait)
+	}
+
+	// Once sent it's gone from the store
+	again := NewNotifier(&stubProvider{})
+	if err := again.SetStore(store); err != nil || len(again.held) != 0 {
+		t.Errorf("held after send = %v, %v", again.held, err)
+	}
+}
+
+func TestNotifierCapsLength(t *testing.T) {
+	provider := &stubProvider{}
+	n := NewNotifier(provider)
+	n.SetMaxLength(20)
+
+	if _, err := n.Notify(context.Background(), "+1555", strings.Repeat("word ", 50)); err != nil {
+		t.Fatal(err)
+	}
+	body := strings.TrimPrefix(provider.sent[0], "+1555: ")
+	if len([]rune(body)) > 20 || !strings.HasSuffix(body, "...") {
+		t.Errorf("body = %q, want at most 20 chars ending in ...", body)
+	}
+}
+
+func TestTwilioSend(t *testing.T) {
+	var form map[string]string
+	var user string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ = r.BasicAuth()
+		r.ParseForm()
+		form = map[string]string{"To": r.Form.Get("To"), "From": r.Form.Get("From"), "Body": r.Form.Get("Body")}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := NewTwilioClient("AC123", "secret", "+15550000000")
+	c.baseURL = server.URL
+	if err := c.Send(context.Background(), "+15551234567", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if user != "AC123" || form["To"] != "+15551234567" || form["From"] != "+15550000000" || form["Body"] != "hello" {
+		t.Errorf("request user=%q form=%v", user, form)
+	}
+}
@@ -0,0 +1,75 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+)
+
+const (
+	twilioBaseURL = "https://api.twilio.com/2010-04-01"
+	apiTimeout    = 30 * time.Second
+)
+
+// TwilioClient sends texts through the Twilio Messages API
+type TwilioClient struct {
+	accountSID string
+	authToken  string
+	from       string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTwilioClient creates a Twilio client sending from the given number
+func NewTwilioClient(accountSID, authToken, from string) *TwilioClient {
+	return &TwilioClient{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		baseURL:    twilioBaseURL,
+		httpClient: httpclient.New(apiTimeout),
+	}
+}
+
+// IsConfigured returns true if the client has required credentials
+func (c *TwilioClient) IsConfigured() bool {
+	return c.accountSID != "" && c.authToken != "" && c.from != ""
+}
+
+// Send texts body to a phone number
+func (c *TwilioClient) Send(ctx context.Context, to, body string) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("Twilio client not configured")
+	}
+
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", c.from)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", c.baseURL, url.PathEscape(c.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("Twilio API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
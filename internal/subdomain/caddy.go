@@ -0,0 +1,96 @@
+package subdomain
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// negativeCacheTTL is how long a rejected subdomain is remembered
+	negativeCacheTTL = 30 * time.Second
+
+	// negativeCacheMaxEntries bounds memory under probing; the cache is reset when full
+	negativeCacheMaxEntries = 10000
+)
+
+// AskStats counts Caddy on-demand TLS ask requests by outcome.
+type AskStats struct {
+	Valid     uint64 `json:"valid"`
+	Invalid   uint64 `json:"invalid"`
+	Malformed uint64 `json:"malformed"`
+	CacheHits uint64 `json:"cache_hits"`
+}
+
+// askCounters holds the live counters behind AskStats
+type askCounters struct {
+	valid     atomic.Uint64
+	invalid   atomic.Uint64
+	malformed atomic.Uint64
+	cacheHits atomic.Uint64
+}
+
+// negativeCache remembers recently rejected subdomains
+type negativeCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// AskStats returns a snapshot of the Caddy ask counters.
+func (r *Registry) AskStats() AskStats {
+	return AskStats{
+		Valid:     r.asks.valid.Load(),
+		Invalid:   r.asks.invalid.Load(),
+		Malformed: r.asks.malformed.Load(),
+		CacheHits: r.asks.cacheHits.Load(),
+	}
+}
+
+// rejected reports whether subdomain was rejected within the TTL
+func (c *negativeCache) rejected(subdomain string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.entries[subdomain]
+	if !ok {
+		return false
+	}
+	if now.After(expires) {
+		delete(c.entries, subdomain)
+		return false
+	}
+	return true
+}
+
+// add remembers a rejected subdomain
+func (c *negativeCache) add(subdomain string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= negativeCacheMaxEntries {
+		c.entries = make(map[string]time.Time)
+	}
+	c.entries[subdomain] = now.Add(negativeCacheTTL)
+}
+
+// checkSubdomain reports whether subdomain is registered, adding it to the
+// negative cache if not. Both happen under r.mu, which allocations hold while
+// they forget, so an ask racing an allocation can't cache the new subdomain
+// as rejected after it was forgotten.
+func (r *Registry) checkSubdomain(subdomain string, now time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.subdomains[subdomain]; ok {
+		return true
+	}
+	r.rejected.add(subdomain, now)
+	return false
+}
+
+// forget drops a subdomain so a fresh allocation is accepted immediately
+func (c *negativeCache) forget(subdomain string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, subdomain)
+}
@@ -0,0 +1,106 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Readiness probe timing: the first probe comes quickly, then they back
+// off so a server that never binds its port costs little
+const (
+	readyProbeInterval    = 100 * time.Millisecond
+	maxReadyProbeInterval = 2 * time.Second
+	readyProbeTimeout     = 2 * time.Second
+)
+
+// StartOption configures how StartServer starts a server.
+type StartOption func(*startOptions)
+
+type startOptions struct {
+	healthTimeout time.Duration
+	healthPath    string
+	routes        []string
+}
+
+// WaitHealthy makes StartServer wait until the server accepts connections
+// on its port before returning, failing if it hasn't within timeout. If
+// path is set, the server must also answer an HTTP GET for it with a
+// status below 500.
+func WaitHealthy(timeout time.Duration, path string) StartOption {
+	return func(o *startOptions) {
+		o.healthTimeout = timeout
+		o.healthPath = path
+	}
+}
+
+// probeReady checks a server until it's ready or exits, then marks it
+// Ready. It runs for every server, so Ready is meaningful whether or not
+// anyone waited for it.
+func (pm *ProcessManager) probeReady(proc *ServerProcess) {
+	interval := readyProbeInterval
+	for {
+		select {
+		case <-proc.done:
+			return
+		case <-time.After(interval):
+		}
+
+		if checkHealthy(proc.Port, proc.healthPath) {
+			pm.mu.Lock()
+			if proc.Status == "running" {
+				proc.Ready = true
+			}
+			pm.mu.Unlock()
+			close(proc.ready)
+			pm.logger.Infof("Server for %s is ready on port %d", proc.ProjectName, proc.Port)
+			return
+		}
+		interval = min(interval*2, maxReadyProbeInterval)
+	}
+}
+
+// waitReady waits for a server's readiness probe to pass
+func (pm *ProcessManager) waitReady(ctx context.Context, proc *ServerProcess, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-proc.ready:
+		return nil
+	case <-proc.done:
+		pm.mu.RLock()
+		exit := describeExit(proc.ExitCode, proc.ExitSignal)
+		pm.mu.RUnlock()
+		return fmt.Errorf("server for %s exited before it was ready (%s)", proc.ProjectName, exit)
+	case <-timer.C:
+		return fmt.Errorf("server for %s not accepting connections on port %d after %s", proc.ProjectName, proc.Port, timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// checkHealthy reports whether something accepts connections on port and,
+// if path is set, answers a GET for it without a server error
+func checkHealthy(port int, path string) bool {
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	conn, err := net.DialTimeout("tcp", addr, readyProbeTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	if path == "" {
+		return true
+	}
+
+	client := &http.Client{Timeout: readyProbeTimeout}
+	resp, err := client.Get("http://" + addr + "/" + strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
@@ -0,0 +1,74 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitHealthy(t *testing.T) {
+	pm := newTestProcessManager(t)
+
+	// Stand in for the server: the command just idles, and the test
+	// listens on the port the project is given
+	alloc, err := pm.registry.Allocate("site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", alloc.Port))
+	if err != nil {
+		t.Skipf("can't listen on the allocated port: %v", err)
+	}
+	var checks atomic.Int32
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			t.Errorf("health check requested %s", r.URL.Path)
+		}
+		// Still warming up the first time
+		if checks.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	proc, err := pm.StartServer(context.Background(), "site", "sleep 30", t.TempDir(), os.Environ(), WaitHealthy(5*time.Second, "healthz"))
+	if err != nil {
+		t.Fatalf("StartServer() error = %v", err)
+	}
+	defer pm.StopAll()
+
+	pm.mu.RLock()
+	ready := proc.Ready
+	pm.mu.RUnlock()
+	if !ready {
+		t.Error("Ready not set once healthy")
+	}
+	if n := checks.Load(); n < 2 {
+		t.Errorf("health checks = %d, want a retry after the 503", n)
+	}
+}
+
+func TestWaitHealthyFailures(t *testing.T) {
+	pm := newTestProcessManager(t)
+
+	_, err := pm.StartServer(context.Background(), "crash", "exit 2", t.TempDir(), os.Environ(), WaitHealthy(5*time.Second, ""))
+	if err == nil || !strings.Contains(err.Error(), "exited before it was ready (exit code 2)") {
+		t.Errorf("crashing server: err = %v", err)
+	}
+
+	proc, err := pm.StartServer(context.Background(), "slow", "sleep 30", t.TempDir(), os.Environ(), WaitHealthy(200*time.Millisecond, ""))
+	if err == nil || !strings.Contains(err.Error(), "not accepting connections") {
+		t.Errorf("server that never binds: err = %v", err)
+	}
+	if proc == nil || pm.GetServer("slow") != proc || proc.Ready {
+		t.Error("server that isn't ready yet should be left running, not ready")
+	}
+	pm.StopAll()
+}
@@ -0,0 +1,66 @@
+package subdomain
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultLogBufferSize is how much of a server's output is kept by default
+const DefaultLogBufferSize = 64 << 10
+
+// logBuffer keeps the most recent output of a server process in a fixed
+// size ring. It's written by the process's output copier and read by
+// GetServerLogs, so it has its own lock.
+type logBuffer struct {
+	mu      sync.Mutex
+	data    []byte
+	next    int   // Where the next byte goes
+	full    bool  // data has wrapped, so the oldest byte is at next
+	written int64 // Total bytes written
+}
+
+func newLogBuffer(size int) *logBuffer {
+	if size <= 0 {
+		size = DefaultLogBufferSize
+	}
+	return &logBuffer{data: make([]byte, size)}
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	b.written += int64(n)
+	if len(p) >= len(b.data) {
+		// Only the tail fits
+		copy(b.data, p[len(p)-len(b.data):])
+		b.next, b.full = 0, true
+		return n, nil
+	}
+
+	copied := copy(b.data[b.next:], p)
+	if copied < len(p) {
+		b.next = copy(b.data, p[copied:])
+		b.full = true
+	} else if b.next += copied; b.next == len(b.data) {
+		b.next, b.full = 0, true
+	}
+	return n, nil
+}
+
+// String returns the kept output, oldest first, noting how much was dropped
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return string(b.data[:b.next])
+	}
+	dropped := b.written - int64(len(b.data))
+	out := string(b.data[b.next:]) + string(b.data[:b.next])
+	if dropped > 0 {
+		out = fmt.Sprintf("... (%d bytes of earlier output truncated)\n", dropped) + out
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package subdomain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogBufferKeepsTail(t *testing.T) {
+	b := newLogBuffer(8)
+	b.Write([]byte("abc"))
+	if got := b.String(); got != "abc" {
+		t.Errorf("String() = %q, want abc", got)
+	}
+
+	b.Write([]byte("defgh"))
+	if got := b.String(); got != "abcdefgh" {
+		t.Errorf("full buffer = %q", got)
+	}
+
+	b.Write([]byte("ij"))
+	if got := b.String(); got != "... (2 bytes of earlier output truncated)\ncdefghij" {
+		t.Errorf("wrapped buffer = %q", got)
+	}
+
+	b.Write([]byte(strings.Repeat("x", 10) + "12345678"))
+	if got := b.String(); !strings.HasSuffix(got, "\n12345678") || !strings.Contains(got, "(20 bytes") {
+		t.Errorf("after oversized write = %q", got)
+	}
+}
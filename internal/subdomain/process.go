@@ -2,17 +2,79 @@ package subdomain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"sort"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+// stopGracePeriod is how long a server has to exit after SIGTERM before it
+// is killed.
+const stopGracePeriod = 10 * time.Second
+
+// Restart backoff defaults, used when a RestartPolicy leaves them zero
+const (
+	defaultRestartBackoff    = time.Second
+	defaultRestartMaxBackoff = 30 * time.Second
 )
 
 // ProcessManager manages server processes for projects.
 type ProcessManager struct {
-	mu        sync.RWMutex
-	registry  *Registry
-	processes map[string]*ServerProcess
+	mu            sync.RWMutex
+	registry      *Registry
+	processes     map[string]*ServerProcess
+	logger        logging.Logger
+	restartPolicy RestartPolicy
+
+	// Output of each project's server, kept across crashes and restarts
+	// until the server is stopped
+	logs    map[string]*logBuffer
+	logSize int
+
+	// Servers idle longer than idleTimeout are stopped by a sweeper, which
+	// stopSweeper ends. Zero disables it.
+	idleTimeout time.Duration
+	stopSweeper chan struct{}
+}
+
+// RestartPolicy controls automatic restarts of servers that crash. Like
+// agent supervision, a server is restarted at most MaxRestarts times within
+// Window, after which it is left down. The zero policy never restarts.
+type RestartPolicy struct {
+	MaxRestarts int
+	Window      time.Duration
+
+	// Backoff is the delay before the first restart, doubling with each
+	// restart in the window up to MaxBackoff (defaults 1s and 30s)
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// enabled reports whether the policy restarts anything
+func (p RestartPolicy) enabled() bool {
+	return p.MaxRestarts > 0 && p.Window > 0
+}
+
+// delay returns the backoff before a restart, given how many restarts
+// already happened in the window
+func (p RestartPolicy) delay(recent int) time.Duration {
+	d, ceiling := p.Backoff, p.MaxBackoff
+	if d <= 0 {
+		d = defaultRestartBackoff
+	}
+	if ceiling <= 0 {
+		ceiling = defaultRestartMaxBackoff
+	}
+	for i := 0; i < recent && d < ceiling; i++ {
+		d *= 2
+	}
+	return min(d, ceiling)
 }
 
 // ServerProcess represents a running server process.
@@ -25,8 +87,40 @@ type ServerProcess struct {
 	WorkDir     string
 	Status      string
 	StartedAt   time.Time
-	cmd         *exec.Cmd
-	cancel      context.CancelFunc
+
+	// Ready is set once the server accepts connections on its port (and
+	// answers its health check path, if it has one)
+	Ready bool
+
+	// LastAccessedAt is when the server was last used: a request proxied
+	// to it, or its URL looked up. Idle servers are stopped when an idle
+	// timeout is set.
+	LastAccessedAt time.Time
+
+	// Set once the process exits. ExitCode is -1 when it was killed by a
+	// signal, in which case ExitSignal names it (e.g. "terminated").
+	ExitedAt   time.Time
+	ExitCode   int
+	ExitSignal string
+
+	// RestartCount is how many times the server was restarted automatically
+	// after crashing
+	RestartCount int
+
+	// Routes are the named routes the process also serves, each on its own
+	// subdomain and port (see WithRoutes)
+	Routes []Allocation
+
+	env        []string      // Environment as given, before PORT is added
+	healthPath string        // HTTP path that must answer before it's Ready
+	routeNames []string      // Named routes it was started with
+	ready      chan struct{} // Closed once it's Ready
+	restarts   []time.Time   // Automatic restarts within the policy window
+	ctx        context.Context
+	cmd        *exec.Cmd
+	cancel     context.CancelFunc
+	stopping   bool          // Set when we asked the process to stop
+	done       chan struct{} // Closed once the exit has been recorded
 }
 
 // NewProcessManager creates a new process manager.
@@ -34,26 +128,121 @@ func NewProcessManager(registry *Registry) *ProcessManager {
 	return &ProcessManager{
 		registry:  registry,
 		processes: make(map[string]*ServerProcess),
+		logger:    logging.New("subdomain"),
+		logs:      make(map[string]*logBuffer),
+		logSize:   DefaultLogBufferSize,
 	}
 }
 
-// StartServer starts a server process for a project.
-func (pm *ProcessManager) StartServer(ctx context.Context, projectName, command, workDir string, env []string) (*ServerProcess, error) {
+// SetLogBufferSize sets how many bytes of output are kept per server. It
+// applies to servers started from then on.
+func (pm *ProcessManager) SetLogBufferSize(size int) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
+	if size <= 0 {
+		size = DefaultLogBufferSize
+	}
+	pm.logSize = size
+}
 
-	// Check if already running
+// SetLogger replaces the process manager's logger.
+func (pm *ProcessManager) SetLogger(l logging.Logger) {
+	pm.logger = l
+}
+
+// SetRestartPolicy sets how servers that crash are restarted. It applies
+// to crashes from then on.
+func (pm *ProcessManager) SetRestartPolicy(p RestartPolicy) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.restartPolicy = p
+}
+
+// SetIdleTimeout stops servers that haven't been accessed for d, freeing
+// their subdomain and port. Zero disables it.
+func (pm *ProcessManager) SetIdleTimeout(d time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.stopSweeper != nil {
+		close(pm.stopSweeper)
+		pm.stopSweeper = nil
+	}
+	pm.idleTimeout = d
+	if d <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	pm.stopSweeper = stop
+	go pm.sweepIdle(d, stop)
+}
+
+// Touch records that a project's server was just used, postponing its idle
+// timeout.
+func (pm *ProcessManager) Touch(projectName string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 	if proc, exists := pm.processes[projectName]; exists {
-		if proc.Status == "running" {
-			return proc, nil
+		proc.LastAccessedAt = time.Now()
+	}
+}
+
+// WithRoutes gives the server named routes besides its main one, e.g. "api"
+// for a backend next to the frontend. Each route gets its own public
+// subdomain ("<subdomain>-api") and port, passed to the command as
+// PORT_API alongside PORT.
+func WithRoutes(names ...string) StartOption {
+	return func(o *startOptions) {
+		o.routes = names
+	}
+}
+
+// RoutePortVar is the environment variable holding a named route's port
+func RoutePortVar(route string) string {
+	return "PORT_" + strings.ToUpper(strings.ReplaceAll(route, "-", "_"))
+}
+
+// StartServer starts a server process for a project. With WaitHealthy it
+// returns once the server is ready; if it isn't in time, the server is
+// left running and returned along with the error.
+func (pm *ProcessManager) StartServer(ctx context.Context, projectName, command, workDir string, env []string, opts ...StartOption) (*ServerProcess, error) {
+	var o startOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	pm.mu.Lock()
+	// Check if already running
+	proc, exists := pm.processes[projectName]
+	if !exists || proc.Status != "running" {
+		var err error
+		proc, err = pm.launchLocked(ctx, projectName, command, workDir, env, o)
+		if err != nil {
+			pm.mu.Unlock()
+			return nil, err
 		}
 	}
+	pm.mu.Unlock()
 
-	// Allocate subdomain and port
-	alloc, err := pm.registry.Allocate(projectName)
+	if o.healthTimeout > 0 {
+		if err := pm.waitReady(ctx, proc, o.healthTimeout); err != nil {
+			return proc, err
+		}
+	}
+	return proc, nil
+}
+
+// launchLocked starts a project's server on its subdomain and port, and
+// those of any named routes, allocating them if it has none yet. Caller
+// must hold pm.mu.
+func (pm *ProcessManager) launchLocked(ctx context.Context, projectName, command, workDir string, env []string, o startOptions) (*ServerProcess, error) {
+	// Allocate subdomain and port, plus one of each per route
+	allocs, err := pm.registry.AllocateRoutes(projectName, o.routes...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate subdomain: %w", err)
 	}
+	alloc, routes := allocs[0], allocs[1:]
 
 	// Create process context
 	procCtx, cancel := context.WithCancel(ctx)
@@ -62,9 +251,26 @@ func (pm *ProcessManager) StartServer(ctx context.Context, projectName, command,
 	cmd := exec.CommandContext(procCtx, "sh", "-c", command)
 	cmd.Dir = workDir
 
-	// Set environment with PORT
-	cmdEnv := append(env, fmt.Sprintf("PORT=%d", alloc.Port))
-	cmd.Env = cmdEnv
+	// Stop with SIGTERM so servers can shut down cleanly, then kill
+	terminateProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = stopGracePeriod
+
+	// Set environment with PORT, and PORT_<ROUTE> for each named route
+	cmd.Env = append(append([]string(nil), env...), fmt.Sprintf("PORT=%d", alloc.Port))
+	for _, route := range routes {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", RoutePortVar(route.Route), route.Port))
+	}
+
+	// Capture output from the first byte; a restarted server appends to
+	// the output of the run that came before
+	logs, restarted := pm.logs[projectName]
+	if restarted {
+		fmt.Fprintf(logs, "\n--- server started %s ---\n", time.Now().Format(time.RFC3339))
+	} else {
+		logs = newLogBuffer(pm.logSize)
+	}
+	cmd.Stdout = logs
+	cmd.Stderr = logs
 
 	// Start the process
 	if err := cmd.Start(); err != nil {
@@ -72,28 +278,46 @@ func (pm *ProcessManager) StartServer(ctx context.Context, projectName, command,
 		pm.registry.Release(projectName)
 		return nil, fmt.Errorf("failed to start server: %w", err)
 	}
+	pm.logs[projectName] = logs
 
+	now := time.Now()
 	proc := &ServerProcess{
-		ProjectName: projectName,
-		Subdomain:   alloc.Subdomain,
-		Port:        alloc.Port,
-		URL:         alloc.URL,
-		Command:     command,
-		WorkDir:     workDir,
-		Status:      "running",
-		StartedAt:   time.Now(),
-		cmd:         cmd,
-		cancel:      cancel,
+		ProjectName:    projectName,
+		Subdomain:      alloc.Subdomain,
+		Port:           alloc.Port,
+		URL:            alloc.URL,
+		Command:        command,
+		WorkDir:        workDir,
+		Status:         "running",
+		StartedAt:      now,
+		LastAccessedAt: now,
+		env:            env,
+		Routes:         routes,
+		healthPath:     o.healthPath,
+		routeNames:     o.routes,
+		ready:          make(chan struct{}),
+		ctx:            procCtx,
+		cmd:            cmd,
+		cancel:         cancel,
+		done:           make(chan struct{}),
 	}
 
 	pm.processes[projectName] = proc
+	pm.logger.Infof("Started server for %s on port %d (%s)", projectName, alloc.Port, alloc.URL)
 
 	// Monitor process in background
 	go pm.monitorProcess(proc)
+	go pm.probeReady(proc)
 
 	return proc, nil
 }
 
+// startOptions returns the options a server was started with, to start it
+// the same way again
+func (proc *ServerProcess) startOptions() startOptions {
+	return startOptions{healthPath: proc.healthPath, routes: proc.routeNames}
+}
+
 // StopServer stops a server process.
 func (pm *ProcessManager) StopServer(projectName string) error {
 	pm.mu.Lock()
@@ -104,13 +328,80 @@ func (pm *ProcessManager) StopServer(projectName string) error {
 		return fmt.Errorf("server not found: %s", projectName)
 	}
 
+	pm.stopLocked(proc)
+	pm.logger.Infof("Stopped server for %s", projectName)
+
+	return nil
+}
+
+// stopLocked stops a server and releases its subdomain, port and logs.
+// Caller must hold pm.mu.
+func (pm *ProcessManager) stopLocked(proc *ServerProcess) {
+	proc.stopping = true
 	proc.cancel()
 	proc.Status = "stopped"
 
-	pm.registry.Release(projectName)
+	pm.registry.Release(proc.ProjectName)
+	delete(pm.processes, proc.ProjectName)
+	delete(pm.logs, proc.ProjectName)
+}
+
+// RestartServer stops a project's server and starts it again with the same
+// command, directory and environment. It keeps the subdomain and port, so
+// the public URL doesn't change.
+func (pm *ProcessManager) RestartServer(projectName string) (*ServerProcess, error) {
+	pm.mu.Lock()
+	old, exists := pm.processes[projectName]
+	if !exists {
+		pm.mu.Unlock()
+		return nil, fmt.Errorf("server not found: %s", projectName)
+	}
+
+	// Removing it first keeps its exit from releasing the allocation
+	old.stopping = true
+	old.cancel()
 	delete(pm.processes, projectName)
+	pm.mu.Unlock()
 
-	return nil
+	// Let the old process free the port before the new one binds it
+	select {
+	case <-old.done:
+	case <-time.After(stopGracePeriod + 5*time.Second):
+		pm.logger.Warnf("Server for %s is slow to exit, restarting anyway", projectName)
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	// Someone may have started it again while we waited
+	if proc, exists := pm.processes[projectName]; exists && proc.Status == "running" {
+		return proc, nil
+	}
+	proc, err := pm.launchLocked(context.Background(), projectName, old.Command, old.WorkDir, old.env, old.startOptions())
+	if err != nil {
+		return nil, err
+	}
+	pm.logger.Infof("Restarted server for %s", projectName)
+	return proc, nil
+}
+
+// StopAll stops every running server and returns their project names,
+// sorted.
+func (pm *ProcessManager) StopAll() []string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	names := make([]string, 0, len(pm.processes))
+	for name, proc := range pm.processes {
+		pm.stopLocked(proc)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		pm.logger.Infof("Stopped all servers: %s", strings.Join(names, ", "))
+	}
+	return names
 }
 
 // GetServer returns the server process for a project.
@@ -120,6 +411,20 @@ func (pm *ProcessManager) GetServer(projectName string) *ServerProcess {
 	return pm.processes[projectName]
 }
 
+// GetServerLogs returns the recent combined stdout and stderr of a
+// project's server. Output is kept after a crash, so it shows why the
+// server failed, and dropped when the server is stopped.
+func (pm *ProcessManager) GetServerLogs(projectName string) (string, error) {
+	pm.mu.RLock()
+	logs := pm.logs[projectName]
+	pm.mu.RUnlock()
+
+	if logs == nil {
+		return "", fmt.Errorf("no logs for server: %s", projectName)
+	}
+	return logs.String(), nil
+}
+
 // ListServers returns all running servers.
 func (pm *ProcessManager) ListServers() []*ServerProcess {
 	pm.mu.RLock()
@@ -132,36 +437,158 @@ func (pm *ProcessManager) ListServers() []*ServerProcess {
 	return servers
 }
 
-// monitorProcess watches a process and updates status when it exits.
+// sweepIdle stops idle servers every so often until stop is closed
+func (pm *ProcessManager) sweepIdle(timeout time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(min(max(timeout/4, time.Second), time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			pm.stopIdle(now, timeout)
+		}
+	}
+}
+
+// stopIdle stops running servers last accessed more than timeout before
+// now and returns their project names
+func (pm *ProcessManager) stopIdle(now time.Time, timeout time.Duration) []string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	var names []string
+	for name, proc := range pm.processes {
+		idle := now.Sub(proc.LastAccessedAt)
+		if proc.Status != "running" || idle <= timeout {
+			continue
+		}
+		pm.stopLocked(proc)
+		names = append(names, name)
+		pm.logger.Infof("Stopped server for %s after %s idle, released %s (port %d)", name, idle.Round(time.Second), proc.Subdomain, proc.Port)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// monitorProcess watches a process and updates status when it exits. A
+// process we stopped is "stopped" however it exited; otherwise a non-zero
+// exit or a signal marks it "failed".
 func (pm *ProcessManager) monitorProcess(proc *ServerProcess) {
 	if proc.cmd == nil {
 		return
 	}
+	defer close(proc.done)
 
 	err := proc.cmd.Wait()
+	code, signal := exitStatus(proc.cmd, err)
 
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
-	if err != nil {
+	proc.ExitedAt = time.Now()
+	proc.Ready = false
+	proc.ExitCode = code
+	proc.ExitSignal = signal
+
+	switch {
+	case proc.stopping:
+		proc.Status = "stopped"
+		pm.logger.Infof("Server for %s stopped (%s)", proc.ProjectName, describeExit(code, signal))
+	case err != nil && proc.ctx.Err() == nil && pm.scheduleRestartLocked(proc):
+		proc.Status = "restarting"
+		pm.logger.Warnf("Server for %s exited unexpectedly (%s): %v; restarting", proc.ProjectName, describeExit(code, signal), err)
+		return
+	case err != nil:
 		proc.Status = "failed"
-	} else {
+		pm.logger.Warnf("Server for %s exited unexpectedly (%s): %v", proc.ProjectName, describeExit(code, signal), err)
+	default:
 		proc.Status = "stopped"
+		pm.logger.Infof("Server for %s exited", proc.ProjectName)
 	}
 
-	pm.registry.Release(proc.ProjectName)
-	delete(pm.processes, proc.ProjectName)
+	// A stopped server may already have been replaced by a new one
+	if pm.processes[proc.ProjectName] == proc {
+		pm.registry.Release(proc.ProjectName)
+		delete(pm.processes, proc.ProjectName)
+	}
 }
 
-// Shutdown stops all running servers.
-func (pm *ProcessManager) Shutdown() {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
+// scheduleRestartLocked arranges for a crashed server to be started again
+// after the policy's backoff, keeping its subdomain and port in the
+// meantime. It returns false, logging why, if the policy doesn't allow
+// another restart. Caller must hold pm.mu.
+func (pm *ProcessManager) scheduleRestartLocked(proc *ServerProcess) bool {
+	policy := pm.restartPolicy
+	if !policy.enabled() || pm.processes[proc.ProjectName] != proc {
+		return false
+	}
 
-	for _, proc := range pm.processes {
-		proc.cancel()
-		pm.registry.Release(proc.ProjectName)
+	cutoff := time.Now().Add(-policy.Window)
+	var recent []time.Time
+	for _, t := range proc.restarts {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= policy.MaxRestarts {
+		pm.logger.Errorf("Server for %s crashed %d times within %s, giving up", proc.ProjectName, len(recent)+1, policy.Window)
+		return false
+	}
+
+	delay := policy.delay(len(recent))
+	time.AfterFunc(delay, func() {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+
+		// It may have been stopped, restarted or replaced while we waited
+		if pm.processes[proc.ProjectName] != proc || proc.Status != "restarting" {
+			return
+		}
+		next, err := pm.launchLocked(context.Background(), proc.ProjectName, proc.Command, proc.WorkDir, proc.env, proc.startOptions())
+		if err != nil {
+			proc.Status = "failed"
+			pm.logger.Errorf("Failed to restart server for %s: %v", proc.ProjectName, err)
+			pm.registry.Release(proc.ProjectName)
+			delete(pm.processes, proc.ProjectName)
+			return
+		}
+		next.RestartCount = proc.RestartCount + 1
+		next.LastAccessedAt = proc.LastAccessedAt
+		next.restarts = append(recent, time.Now())
+		pm.logger.Infof("Restarted server for %s after crash (restart %d)", proc.ProjectName, next.RestartCount)
+	})
+	return true
+}
+
+// exitStatus returns a finished command's exit code, or -1 and the signal
+// that killed it
+func exitStatus(cmd *exec.Cmd, err error) (code int, signal string) {
+	state := cmd.ProcessState
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		state = exitErr.ProcessState
+	}
+	if state == nil {
+		return -1, ""
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return -1, ws.Signal().String()
 	}
+	return state.ExitCode(), ""
+}
 
-	pm.processes = make(map[string]*ServerProcess)
+// describeExit formats an exit code or signal for logs
+func describeExit(code int, signal string) string {
+	if signal != "" {
+		return "signal: " + signal
+	}
+	return fmt.Sprintf("exit code %d", code)
+}
+
+// Shutdown stops all running servers.
+func (pm *ProcessManager) Shutdown() {
+	pm.SetIdleTimeout(0)
+	pm.StopAll()
 }
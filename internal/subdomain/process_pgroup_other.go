@@ -0,0 +1,14 @@
+//go:build !unix
+
+package subdomain
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// terminateProcessGroupOnCancel signals only the shell, as there are no
+// process groups to signal
+func terminateProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package subdomain
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// terminateProcessGroupOnCancel runs cmd in its own process group and sends
+// SIGTERM to the whole group when it's stopped, so a server the shell
+// started as a child (npm run dev's node, say) stops too and lets go of
+// the output pipes
+func terminateProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
@@ -0,0 +1,368 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func newTestProcessManager(t *testing.T) *ProcessManager {
+	t.Helper()
+	pm := NewProcessManager(NewRegistry(t.TempDir()))
+	pm.SetLogger(logging.Discard())
+	return pm
+}
+
+// waitExit waits for a server's exit to be recorded
+func waitExit(t *testing.T, proc *ServerProcess) {
+	t.Helper()
+	select {
+	case <-proc.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not exit")
+	}
+}
+
+// waitFor polls cond until it holds
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStopServerIsNotAFailure(t *testing.T) {
+	pm := newTestProcessManager(t)
+	proc, err := pm.StartServer(context.Background(), "site", "sleep 30", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pm.StopServer("site"); err != nil {
+		t.Fatal(err)
+	}
+	waitExit(t, proc)
+
+	if proc.Status != "stopped" {
+		t.Errorf("Status = %q, want stopped", proc.Status)
+	}
+	if proc.ExitSignal != "terminated" || proc.ExitCode != -1 {
+		t.Errorf("exit = code %d signal %q, want SIGTERM", proc.ExitCode, proc.ExitSignal)
+	}
+}
+
+func TestServerCrashIsAFailure(t *testing.T) {
+	pm := newTestProcessManager(t)
+
+	proc, err := pm.StartServer(context.Background(), "crash", "exit 3", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitExit(t, proc)
+	if proc.Status != "failed" || proc.ExitCode != 3 || proc.ExitSignal != "" {
+		t.Errorf("exit 3: status %q code %d signal %q", proc.Status, proc.ExitCode, proc.ExitSignal)
+	}
+	if pm.GetServer("crash") != nil {
+		t.Error("crashed server should be removed")
+	}
+
+	proc, err = pm.StartServer(context.Background(), "killed", "kill -KILL $$", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitExit(t, proc)
+	if proc.Status != "failed" || proc.ExitSignal != "killed" {
+		t.Errorf("self-kill: status %q signal %q", proc.Status, proc.ExitSignal)
+	}
+}
+
+func TestStoppedServerExitKeepsReplacement(t *testing.T) {
+	pm := newTestProcessManager(t)
+	old, err := pm.StartServer(context.Background(), "site", "trap '' TERM; sleep 1", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.StopServer("site"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restarted before the old process has exited
+	replacement, err := pm.StartServer(context.Background(), "site", "sleep 30", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pm.Shutdown()
+
+	waitExit(t, old)
+	if got := pm.GetServer("site"); got != replacement {
+		t.Error("the old server's exit removed its replacement")
+	}
+}
+
+func TestRestartServerKeepsAllocation(t *testing.T) {
+	pm := newTestProcessManager(t)
+	defer pm.Shutdown()
+
+	dir := t.TempDir()
+	old, err := pm.StartServer(context.Background(), "site", "echo $GREETING >> out.txt; sleep 30", dir, append(os.Environ(), "GREETING=hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out.txt")
+	waitFor(t, func() bool { data, _ := os.ReadFile(out); return len(data) > 0 })
+
+	proc, err := pm.RestartServer("site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitExit(t, old)
+	if proc == old || proc.Status != "running" {
+		t.Fatalf("restart returned %+v, want a new running process", proc)
+	}
+	if proc.Port != old.Port || proc.URL != old.URL || proc.Command != old.Command || proc.WorkDir != old.WorkDir {
+		t.Errorf("restart changed the server: %s:%d -> %s:%d", old.URL, old.Port, proc.URL, proc.Port)
+	}
+	if got := pm.GetServer("site"); got != proc {
+		t.Error("restarted server is not the current one")
+	}
+
+	// The stored environment is reused
+	waitFor(t, func() bool { data, _ := os.ReadFile(out); return string(data) == "hello\nhello\n" })
+
+	if _, err := pm.RestartServer("missing"); err == nil {
+		t.Error("expected restarting an unknown server to fail")
+	}
+}
+
+func TestStopAll(t *testing.T) {
+	pm := newTestProcessManager(t)
+	var procs []*ServerProcess
+	for _, name := range []string{"b", "a"} {
+		proc, err := pm.StartServer(context.Background(), name, "sleep 30", t.TempDir(), os.Environ())
+		if err != nil {
+			t.Fatal(err)
+		}
+		procs = append(procs, proc)
+	}
+
+	if got := pm.StopAll(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("StopAll() = %v, want [a b]", got)
+	}
+	for _, proc := range procs {
+		waitExit(t, proc)
+		if proc.Status != "stopped" {
+			t.Errorf("%s: Status = %q, want stopped", proc.ProjectName, proc.Status)
+		}
+	}
+	if len(pm.ListServers()) != 0 {
+		t.Error("servers still listed after StopAll")
+	}
+	if got := pm.StopAll(); len(got) != 0 {
+		t.Errorf("second StopAll() = %v, want nothing", got)
+	}
+}
+
+func TestCrashedServerIsRestarted(t *testing.T) {
+	pm := newTestProcessManager(t)
+	pm.SetRestartPolicy(RestartPolicy{MaxRestarts: 3, Window: time.Minute, Backoff: 10 * time.Millisecond})
+
+	// Crashes on the first run, then stays up
+	dir := t.TempDir()
+	first, err := pm.StartServer(context.Background(), "flaky", "if [ -f ran ]; then sleep 30; else touch ran; exit 1; fi", dir, os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pm.StopAll()
+
+	var restarted *ServerProcess
+	waitFor(t, func() bool {
+		restarted = pm.GetServer("flaky")
+		return restarted != nil && restarted != first
+	})
+	if restarted.RestartCount != 1 {
+		t.Errorf("RestartCount = %d, want 1", restarted.RestartCount)
+	}
+	if restarted.Port != first.Port || restarted.URL != first.URL {
+		t.Errorf("restart moved the server from %s to %s", first.URL, restarted.URL)
+	}
+}
+
+func TestRestartGivesUpAfterMaxRestarts(t *testing.T) {
+	pm := newTestProcessManager(t)
+	pm.SetRestartPolicy(RestartPolicy{MaxRestarts: 2, Window: time.Minute, Backoff: 10 * time.Millisecond})
+
+	dir := t.TempDir()
+	if _, err := pm.StartServer(context.Background(), "broken", "echo run >> runs; exit 1", dir, os.Environ()); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		data, _ := os.ReadFile(filepath.Join(dir, "runs"))
+		return string(data) == "run\nrun\nrun\n" && pm.GetServer("broken") == nil
+	})
+
+	// Gave up after the first run and two restarts
+	time.Sleep(100 * time.Millisecond)
+	if data, _ := os.ReadFile(filepath.Join(dir, "runs")); string(data) != "run\nrun\nrun\n" {
+		t.Errorf("runs = %q, want three", data)
+	}
+	if _, ok := pm.registry.GetByProject("broken"); ok {
+		t.Error("allocation kept after giving up")
+	}
+}
+
+func TestRestartPolicyDelay(t *testing.T) {
+	p := RestartPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for recent, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := p.delay(recent); got != want {
+			t.Errorf("delay(%d) = %s, want %s", recent, got, want)
+		}
+	}
+	if got := (RestartPolicy{}).delay(0); got != defaultRestartBackoff {
+		t.Errorf("default delay = %s", got)
+	}
+}
+
+func TestGetServerLogs(t *testing.T) {
+	pm := newTestProcessManager(t)
+
+	proc, err := pm.StartServer(context.Background(), "noisy", "echo listening; echo 'bad config' >&2; exit 1", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitExit(t, proc)
+
+	// Output outlives the crashed process
+	logs, err := pm.GetServerLogs("noisy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs != "listening\nbad config\n" {
+		t.Errorf("GetServerLogs() = %q", logs)
+	}
+
+	if _, err := pm.StartServer(context.Background(), "noisy", "echo again; sleep 30", t.TempDir(), os.Environ()); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		logs, _ := pm.GetServerLogs("noisy")
+		return strings.HasPrefix(logs, "listening\nbad config\n\n--- server started ") && strings.HasSuffix(logs, "---\nagain\n")
+	})
+
+	if err := pm.StopServer("noisy"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.GetServerLogs("noisy"); err == nil {
+		t.Error("logs kept after the server was stopped")
+	}
+}
+
+func TestStopIdle(t *testing.T) {
+	pm := newTestProcessManager(t)
+	for _, name := range []string{"idle", "busy"} {
+		if _, err := pm.StartServer(context.Background(), name, "sleep 30", t.TempDir(), os.Environ()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer pm.StopAll()
+
+	later := time.Now().Add(time.Hour)
+	pm.GetServer("busy").LastAccessedAt = later.Add(-time.Minute)
+
+	if got := pm.stopIdle(later, 30*time.Minute); len(got) != 1 || got[0] != "idle" {
+		t.Errorf("stopIdle() = %v, want [idle]", got)
+	}
+	if pm.GetServer("idle") != nil {
+		t.Error("idle server still running")
+	}
+	if _, ok := pm.registry.GetByProject("idle"); ok {
+		t.Error("idle server's allocation not released")
+	}
+	if pm.GetServer("busy") == nil {
+		t.Error("recently used server was stopped")
+	}
+}
+
+func TestProxiedRequestTouchesServer(t *testing.T) {
+	pm := newTestProcessManager(t)
+	pm.registry.SetRouteHook(pm.Touch)
+
+	proc, err := pm.StartServer(context.Background(), "site", "sleep 30", t.TempDir(), os.Environ())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pm.StopAll()
+	started := proc.LastAccessedAt
+
+	time.Sleep(10 * time.Millisecond)
+	req := httptest.NewRequest("GET", "http://"+proc.Subdomain+"."+DefaultDomain+"/", nil)
+	pm.registry.Middleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+
+	if !pm.GetServer("site").LastAccessedAt.After(started) {
+		t.Error("proxied request didn't update LastAccessedAt")
+	}
+}
+
+func TestStartServerWithRoutes(t *testing.T) {
+	pm := newTestProcessManager(t)
+	defer pm.StopAll()
+
+	proc, err := pm.StartServer(context.Background(), "shop", "echo $PORT $PORT_API $PORT_ADMIN_UI; sleep 30", t.TempDir(), os.Environ(), WithRoutes("api", "admin-ui"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proc.Routes) != 2 || proc.Routes[0].Route != "admin-ui" || proc.Routes[1].Route != "api" {
+		t.Fatalf("Routes = %+v, want admin-ui and api", proc.Routes)
+	}
+	admin, api := proc.Routes[0], proc.Routes[1]
+	if api.Subdomain != proc.Subdomain+"-api" {
+		t.Errorf("api subdomain = %q, want %q", api.Subdomain, proc.Subdomain+"-api")
+	}
+
+	// The command is told every port
+	want := fmt.Sprintf("%d %d %d\n", proc.Port, api.Port, admin.Port)
+	waitFor(t, func() bool {
+		logs, _ := pm.GetServerLogs("shop")
+		return logs == want
+	})
+
+	// Route subdomains are proxied and get certificates
+	if port, ok := pm.registry.GetBySubdomain(api.Subdomain); !ok || port != api.Port {
+		t.Errorf("GetBySubdomain(%q) = %d, %v", api.Subdomain, port, ok)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/internal/caddy-ask?domain="+api.Subdomain+"."+pm.registry.Domain(), nil)
+	w := httptest.NewRecorder()
+	pm.registry.HandleCaddyAsk(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Caddy ask for the api route = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// Restarting keeps the routes
+	restarted, err := pm.RestartServer("shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted.Routes) != 2 || restarted.Routes[1] != api {
+		t.Errorf("routes after restart = %+v", restarted.Routes)
+	}
+
+	// Stopping releases them
+	if err := pm.StopServer("shop"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pm.registry.GetBySubdomain(api.Subdomain); ok {
+		t.Error("api route still allocated after stop")
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"crypto/rand"
 	"encoding/base32"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -13,8 +15,12 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
 )
 
 const (
@@ -25,10 +31,18 @@ const (
 	// SubdomainLength is the length of generated subdomains
 	SubdomainLength = 8
 
-	// Domain suffix
-	Domain = "hellotron.com"
+	// DefaultDomain is the base domain subdomains are allocated under
+	DefaultDomain = "hellotron.com"
 )
 
+// ErrNoPortsAvailable is returned when every port in the range is allocated or in use.
+var ErrNoPortsAvailable = errors.New("no available ports")
+
+// Capacity returns the number of server slots in the port range.
+func Capacity() int {
+	return MaxPort - MinPort + 1
+}
+
 // Registry manages subdomain-to-port mappings for project servers.
 type Registry struct {
 	mu sync.RWMutex
@@ -42,8 +56,65 @@ type Registry struct {
 	// project → subdomain mapping (for lookup by project name)
 	projects map[string]string
 
+	// project → route name → subdomain for additional named routes
+	routes map[string]map[string]string
+
 	// dataDir for persistence
 	dataDir string
+
+	// domain is the base domain subdomains are allocated under
+	domain string
+
+	// random is the entropy source for subdomain generation
+	random io.Reader
+
+	// Caddy ask counters and recently rejected subdomains
+	asks     askCounters
+	rejected negativeCache
+
+	// onRoute is told which project each proxied request is for
+	onRoute func(projectName string)
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithDataDir enables persistence of allocations in dir.
+func WithDataDir(dir string) Option {
+	return func(r *Registry) {
+		r.dataDir = dir
+	}
+}
+
+// WithDomain sets the base domain, e.g. "apps.example.com". Defaults to
+// DefaultDomain; an empty domain keeps the default.
+func WithDomain(domain string) Option {
+	return func(r *Registry) {
+		if d := normalizeDomain(domain); d != "" {
+			r.domain = d
+		}
+	}
+}
+
+// normalizeDomain lowercases a domain and strips any scheme, path and
+// surrounding dots
+func normalizeDomain(domain string) string {
+	d := strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(d, "://"); i >= 0 {
+		d = d[i+3:]
+	}
+	if i := strings.IndexByte(d, '/'); i >= 0 {
+		d = d[:i]
+	}
+	return strings.Trim(d, ".")
+}
+
+// WithRandom sets the entropy source used to generate subdomains.
+// Defaults to crypto/rand; tests can pass a fixed reader for reproducible output.
+func WithRandom(random io.Reader) Option {
+	return func(r *Registry) {
+		r.random = random
+	}
 }
 
 // registryState is the JSON-serializable state for persistence
@@ -53,20 +124,36 @@ type registryState struct {
 
 type allocationRecord struct {
 	Project   string `json:"project"`
+	Route     string `json:"route,omitempty"`
 	Subdomain string `json:"subdomain"`
 	Port      int    `json:"port"`
 }
 
 // NewRegistry creates a new subdomain registry with optional persistence.
 func NewRegistry(dataDir ...string) *Registry {
+	var opts []Option
+	if len(dataDir) > 0 {
+		opts = append(opts, WithDataDir(dataDir[0]))
+	}
+	return NewRegistryWithOptions(opts...)
+}
+
+// NewRegistryWithOptions creates a new subdomain registry configured by opts.
+func NewRegistryWithOptions(opts ...Option) *Registry {
 	r := &Registry{
 		subdomains: make(map[string]int),
 		ports:      make(map[int]string),
 		projects:   make(map[string]string),
+		routes:     make(map[string]map[string]string),
+		domain:     DefaultDomain,
+		random:     rand.Reader,
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
 
-	if len(dataDir) > 0 && dataDir[0] != "" {
-		r.dataDir = dataDir[0]
+	if r.dataDir != "" {
 		if err := r.load(); err != nil {
 			log.Printf("[subdomain] Failed to load registry state: %v", err)
 		}
@@ -75,6 +162,23 @@ func NewRegistry(dataDir ...string) *Registry {
 	return r
 }
 
+// SetRouteHook sets a function called with the project name whenever
+// Middleware proxies a request to a project server, e.g. to track when
+// servers were last used. Set it before serving requests.
+func (r *Registry) SetRouteHook(fn func(projectName string)) {
+	r.onRoute = fn
+}
+
+// Domain returns the base domain subdomains are allocated under.
+func (r *Registry) Domain() string {
+	return r.domain
+}
+
+// URL returns the public URL for a subdomain.
+func (r *Registry) URL(subdomain string) string {
+	return fmt.Sprintf("https://%s.%s", subdomain, r.domain)
+}
+
 // load reads the registry state from disk
 func (r *Registry) load() error {
 	if r.dataDir == "" {
@@ -99,7 +203,11 @@ func (r *Registry) load() error {
 	for _, alloc := range state.Allocations {
 		r.subdomains[alloc.Subdomain] = alloc.Port
 		r.ports[alloc.Port] = alloc.Subdomain
-		r.projects[alloc.Project] = alloc.Subdomain
+		if alloc.Route == "" {
+			r.projects[alloc.Project] = alloc.Subdomain
+		} else {
+			r.setRoute(alloc.Project, alloc.Route, alloc.Subdomain)
+		}
 	}
 
 	log.Printf("[subdomain] Loaded %d allocations from disk", len(state.Allocations))
@@ -120,6 +228,14 @@ func (r *Registry) save() error {
 			Subdomain: subdomain,
 			Port:      port,
 		})
+		for route, routeSubdomain := range r.routes[project] {
+			state.Allocations = append(state.Allocations, allocationRecord{
+				Project:   project,
+				Route:     route,
+				Subdomain: routeSubdomain,
+				Port:      r.subdomains[routeSubdomain],
+			})
+		}
 	}
 
 	data, err := json.MarshalIndent(state, "", "  ")
@@ -127,16 +243,13 @@ func (r *Registry) save() error {
 		return err
 	}
 
-	if err := os.MkdirAll(r.dataDir, 0755); err != nil {
-		return err
-	}
-
-	path := filepath.Join(r.dataDir, "subdomain_registry.json")
-	return os.WriteFile(path, data, 0644)
+	return persist.WriteFile(filepath.Join(r.dataDir, "subdomain_registry.json"), data)
 }
 
 // Allocation represents an allocated subdomain and port.
+// Route is empty for a project's primary subdomain.
 type Allocation struct {
+	Route     string
 	Subdomain string
 	Port      int
 	URL       string
@@ -154,7 +267,7 @@ func (r *Registry) Allocate(projectName string) (*Allocation, error) {
 		return &Allocation{
 			Subdomain: subdomain,
 			Port:      port,
-			URL:       fmt.Sprintf("https://%s.%s", subdomain, Domain),
+			URL:       r.URL(subdomain),
 		}, nil
 	}
 
@@ -174,6 +287,7 @@ func (r *Registry) Allocate(projectName string) (*Allocation, error) {
 	r.subdomains[subdomain] = port
 	r.ports[port] = subdomain
 	r.projects[projectName] = subdomain
+	r.rejected.forget(subdomain)
 
 	// Persist to disk
 	if err := r.save(); err != nil {
@@ -183,7 +297,7 @@ func (r *Registry) Allocate(projectName string) (*Allocation, error) {
 	return &Allocation{
 		Subdomain: subdomain,
 		Port:      port,
-		URL:       fmt.Sprintf("https://%s.%s", subdomain, Domain),
+		URL:       r.URL(subdomain),
 	}, nil
 }
 
@@ -203,12 +317,165 @@ func (r *Registry) Release(projectName string) {
 	delete(r.subdomains, subdomain)
 	delete(r.ports, port)
 
+	for _, routeSubdomain := range r.routes[projectName] {
+		delete(r.ports, r.subdomains[routeSubdomain])
+		delete(r.subdomains, routeSubdomain)
+	}
+	delete(r.routes, projectName)
+
 	// Persist to disk
 	if err := r.save(); err != nil {
 		log.Printf("[subdomain] Failed to save registry state: %v", err)
 	}
 }
 
+// AllocateRoutes assigns the primary subdomain plus one subdomain and port per
+// named route, e.g. route "api" becomes "<primary>-api". Routes that already
+// exist are kept. Either every requested route is allocated or none are.
+func (r *Registry) AllocateRoutes(projectName string, routeNames ...string) ([]Allocation, error) {
+	for _, name := range routeNames {
+		if !isValidRouteName(name) {
+			return nil, fmt.Errorf("invalid route name %q", name)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Track what this call adds so a failure can be rolled back
+	var added []string
+	rollback := func() {
+		for _, sub := range added {
+			delete(r.ports, r.subdomains[sub])
+			delete(r.subdomains, sub)
+		}
+	}
+
+	primary, exists := r.projects[projectName]
+	if !exists {
+		sub, err := r.generateUniqueSubdomain()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate subdomain: %w", err)
+		}
+		if err := r.reserve(sub); err != nil {
+			return nil, err
+		}
+		primary = sub
+		added = append(added, sub)
+	}
+
+	newRoutes := make(map[string]string)
+	for _, name := range routeNames {
+		if _, exists := r.routes[projectName][name]; exists {
+			continue
+		}
+		if _, pending := newRoutes[name]; pending {
+			continue
+		}
+		sub := primary + "-" + name
+		if _, taken := r.subdomains[sub]; taken {
+			rollback()
+			return nil, fmt.Errorf("subdomain %q already allocated", sub)
+		}
+		if err := r.reserve(sub); err != nil {
+			rollback()
+			return nil, err
+		}
+		newRoutes[name] = sub
+		added = append(added, sub)
+	}
+
+	// Everything reserved; commit the project mappings
+	r.projects[projectName] = primary
+	for name, sub := range newRoutes {
+		r.setRoute(projectName, name, sub)
+	}
+	for _, sub := range added {
+		r.rejected.forget(sub)
+	}
+
+	if len(added) > 0 {
+		if err := r.save(); err != nil {
+			log.Printf("[subdomain] Failed to save registry state: %v", err)
+		}
+	}
+
+	return r.projectAllocations(projectName), nil
+}
+
+// GetRoutes returns the primary and named route allocations for a project.
+func (r *Registry) GetRoutes(projectName string) ([]Allocation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.projects[projectName]; !exists {
+		return nil, false
+	}
+	return r.projectAllocations(projectName), true
+}
+
+// projectAllocations lists a project's primary allocation followed by its
+// named routes in name order. Caller must hold the lock.
+func (r *Registry) projectAllocations(projectName string) []Allocation {
+	primary := r.projects[projectName]
+	allocations := []Allocation{r.newAllocation("", primary, r.subdomains[primary])}
+
+	names := make([]string, 0, len(r.routes[projectName]))
+	for name := range r.routes[projectName] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sub := r.routes[projectName][name]
+		allocations = append(allocations, r.newAllocation(name, sub, r.subdomains[sub]))
+	}
+	return allocations
+}
+
+// reserve allocates a port for subdomain and records the mapping.
+// Caller must hold the lock.
+func (r *Registry) reserve(subdomain string) error {
+	port, err := r.allocatePort()
+	if err != nil {
+		return fmt.Errorf("failed to allocate port: %w", err)
+	}
+	r.subdomains[subdomain] = port
+	r.ports[port] = subdomain
+	return nil
+}
+
+// setRoute records a named route for a project. Caller must hold the lock.
+func (r *Registry) setRoute(projectName, route, subdomain string) {
+	if r.routes[projectName] == nil {
+		r.routes[projectName] = make(map[string]string)
+	}
+	r.routes[projectName][route] = subdomain
+}
+
+// newAllocation builds an Allocation with its public URL
+func (r *Registry) newAllocation(route, subdomain string, port int) Allocation {
+	return Allocation{
+		Route:     route,
+		Subdomain: subdomain,
+		Port:      port,
+		URL:       r.URL(subdomain),
+	}
+}
+
+// isValidRouteName reports whether name can be used as a subdomain suffix
+func isValidRouteName(name string) bool {
+	if name == "" || len(name) > 20 || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+		return false
+	}
+	for _, c := range name {
+		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
 // RegisterExisting registers an existing subdomain/port allocation.
 // Used to recover orphaned processes or manually configure known services.
 func (r *Registry) RegisterExisting(projectName, subdomainName string, port int) error {
@@ -230,17 +497,18 @@ func (r *Registry) RegisterExisting(projectName, subdomainName string, port int)
 	r.subdomains[subdomainName] = port
 	r.ports[port] = subdomainName
 	r.projects[projectName] = subdomainName
+	r.rejected.forget(subdomainName)
 
 	// Persist to disk
 	if err := r.save(); err != nil {
 		log.Printf("[subdomain] Failed to save registry state: %v", err)
 	}
 
-	log.Printf("[subdomain] Registered existing: %s -> %s.%s:%d", projectName, subdomainName, Domain, port)
+	log.Printf("[subdomain] Registered existing: %s -> %s.%s:%d", projectName, subdomainName, r.domain, port)
 	return nil
 }
 
-// GetBySubdomain returns the port for a subdomain.
+// GetBySubdomain returns the port for a subdomain, including named routes.
 func (r *Registry) GetBySubdomain(subdomain string) (int, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -262,10 +530,31 @@ func (r *Registry) GetByProject(projectName string) (*Allocation, bool) {
 	return &Allocation{
 		Subdomain: subdomain,
 		Port:      port,
-		URL:       fmt.Sprintf("https://%s.%s", subdomain, Domain),
+		URL:       r.URL(subdomain),
 	}, true
 }
 
+// projectForSubdomain returns the project a subdomain or named route
+// belongs to, or "" if none does.
+func (r *Registry) projectForSubdomain(subdomain string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for project, sub := range r.projects {
+		if sub == subdomain {
+			return project
+		}
+	}
+	for project, routes := range r.routes {
+		for _, sub := range routes {
+			if sub == subdomain {
+				return project
+			}
+		}
+	}
+	return ""
+}
+
 // IsValidSubdomain checks if a subdomain is registered.
 func (r *Registry) IsValidSubdomain(subdomain string) bool {
 	r.mu.RLock()
@@ -284,13 +573,13 @@ func (r *Registry) List() []Allocation {
 		allocations = append(allocations, Allocation{
 			Subdomain: subdomain,
 			Port:      port,
-			URL:       fmt.Sprintf("https://%s.%s", subdomain, Domain),
+			URL:       r.URL(subdomain),
 		})
 	}
 	return allocations
 }
 
-// generateUniqueSubdomain creates a cryptographically random subdomain.
+// generateUniqueSubdomain creates a random subdomain from the registry's entropy source.
 func (r *Registry) generateUniqueSubdomain() (string, error) {
 	// Use base32 encoding (lowercase, no padding) for URL-safe subdomains
 	encoder := base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
@@ -298,7 +587,7 @@ func (r *Registry) generateUniqueSubdomain() (string, error) {
 	for attempts := 0; attempts < 100; attempts++ {
 		// Generate random bytes (5 bytes = 8 base32 chars)
 		b := make([]byte, 5)
-		if _, err := rand.Read(b); err != nil {
+		if _, err := io.ReadFull(r.random, b); err != nil {
 			return "", err
 		}
 
@@ -326,7 +615,7 @@ func (r *Registry) allocatePort() (int, error) {
 		}
 	}
 
-	return 0, fmt.Errorf("no available ports in range %d-%d", MinPort, MaxPort)
+	return 0, fmt.Errorf("%w in range %d-%d", ErrNoPortsAvailable, MinPort, MaxPort)
 }
 
 // isPortAvailable checks if a port is available for binding.
@@ -350,13 +639,13 @@ func (r *Registry) Middleware(next http.Handler) http.Handler {
 		}
 
 		// Check if this is a subdomain request
-		if !strings.HasSuffix(host, "."+Domain) {
+		if !strings.HasSuffix(host, "."+r.domain) {
 			next.ServeHTTP(w, req)
 			return
 		}
 
 		// Extract subdomain
-		subdomain := strings.TrimSuffix(host, "."+Domain)
+		subdomain := strings.TrimSuffix(host, "."+r.domain)
 
 		// Look up port - if subdomain isn't registered, pass through to main handler
 		// This allows reserved subdomains like "api" to work normally
@@ -366,6 +655,12 @@ func (r *Registry) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		if r.onRoute != nil {
+			if project := r.projectForSubdomain(subdomain); project != "" {
+				r.onRoute(project)
+			}
+		}
+
 		// Reverse proxy to the project server
 		target, _ := url.Parse(fmt.Sprintf("http://localhost:%d", port))
 		proxy := httputil.NewSingleHostReverseProxy(target)
@@ -383,23 +678,42 @@ func (r *Registry) Middleware(next http.Handler) http.Handler {
 func (r *Registry) HandleCaddyAsk(w http.ResponseWriter, req *http.Request) {
 	domain := req.URL.Query().Get("domain")
 	if domain == "" {
+		r.asks.malformed.Add(1)
 		http.Error(w, "missing domain parameter", http.StatusBadRequest)
 		return
 	}
 
-	// Check if it's a valid subdomain of hellotron.com
-	if !strings.HasSuffix(domain, "."+Domain) {
+	// Check if it's a valid subdomain of the configured domain
+	if !strings.HasSuffix(domain, "."+r.domain) {
+		r.asks.malformed.Add(1)
 		http.Error(w, "not a valid subdomain", http.StatusForbidden)
 		return
 	}
 
-	subdomain := strings.TrimSuffix(domain, "."+Domain)
+	subdomain := strings.TrimSuffix(domain, "."+r.domain)
+	now := time.Now()
+
+	// Shed repeated probes for unknown subdomains
+	if r.rejected.rejected(subdomain, now) {
+		r.asks.invalid.Add(1)
+		r.asks.cacheHits.Add(1)
+		http.Error(w, "subdomain not registered", http.StatusForbidden)
+		return
+	}
 
 	// Check if this subdomain is registered
-	if r.IsValidSubdomain(subdomain) {
+	if r.checkSubdomain(subdomain, now) {
+		r.asks.valid.Add(1)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
+	r.asks.invalid.Add(1)
 	http.Error(w, "subdomain not registered", http.StatusForbidden)
 }
+
+// HandleCaddyAskStats reports Caddy ask counters as JSON for monitoring.
+func (r *Registry) HandleCaddyAskStats(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.AskStats())
+}
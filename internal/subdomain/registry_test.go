@@ -1,6 +1,8 @@
 package subdomain
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -78,6 +80,18 @@ func TestAllocateMultipleProjects(t *testing.T) {
 	}
 }
 
+func TestAllocatePortsExhausted(t *testing.T) {
+	r := NewRegistry()
+	for port := MinPort; port <= MaxPort; port++ {
+		r.ports[port] = "taken"
+	}
+
+	_, err := r.Allocate("one-too-many")
+	if !errors.Is(err, ErrNoPortsAvailable) {
+		t.Fatalf("Allocate error = %v, want ErrNoPortsAvailable", err)
+	}
+}
+
 func TestRelease(t *testing.T) {
 	r := NewRegistry()
 
@@ -185,12 +199,12 @@ func TestHandleCaddyAsk(t *testing.T) {
 	}{
 		{
 			name:       "valid subdomain",
-			domain:     alloc.Subdomain + "." + Domain,
+			domain:     alloc.Subdomain + "." + r.Domain(),
 			wantStatus: http.StatusOK,
 		},
 		{
 			name:       "invalid subdomain",
-			domain:     "nonexistent." + Domain,
+			domain:     "nonexistent." + r.Domain(),
 			wantStatus: http.StatusForbidden,
 		},
 		{
@@ -242,3 +256,217 @@ func TestSubdomainFormat(t *testing.T) {
 		}
 	}
 }
+
+func TestAllocateDeterministicSubdomains(t *testing.T) {
+	random := bytes.NewReader(append(bytes.Repeat([]byte{0x00}, 5), bytes.Repeat([]byte{0xff}, 5)...))
+	r := NewRegistryWithOptions(WithRandom(random))
+
+	alloc1, err := r.Allocate("first")
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	alloc2, err := r.Allocate("second")
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+
+	if alloc1.Subdomain != "aaaaaaaa" {
+		t.Errorf("first subdomain = %q, want aaaaaaaa", alloc1.Subdomain)
+	}
+	if alloc2.Subdomain != "77777777" {
+		t.Errorf("second subdomain = %q, want 77777777", alloc2.Subdomain)
+	}
+}
+
+func TestAllocateRetriesOnCollision(t *testing.T) {
+	// Second allocation draws the same bytes as the first, then a unique value
+	zeros := bytes.Repeat([]byte{0x00}, 5)
+	random := bytes.NewReader(bytes.Join([][]byte{zeros, zeros, bytes.Repeat([]byte{0xff}, 5)}, nil))
+	r := NewRegistryWithOptions(WithRandom(random))
+
+	if _, err := r.Allocate("first"); err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	alloc, err := r.Allocate("second")
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	if alloc.Subdomain != "77777777" {
+		t.Errorf("subdomain after collision = %q, want 77777777", alloc.Subdomain)
+	}
+}
+
+func TestAllocateRandomSourceExhausted(t *testing.T) {
+	r := NewRegistryWithOptions(WithRandom(bytes.NewReader(nil)))
+
+	if _, err := r.Allocate("project"); err == nil {
+		t.Error("expected error when the random source is exhausted")
+	}
+}
+
+func TestHandleCaddyAskStatsAndNegativeCache(t *testing.T) {
+	r := NewRegistry()
+	alloc, _ := r.Allocate("test-project")
+
+	ask := func(domain string) int {
+		req := httptest.NewRequest(http.MethodGet, "/internal/caddy-ask?domain="+domain, nil)
+		w := httptest.NewRecorder()
+		r.HandleCaddyAsk(w, req)
+		return w.Code
+	}
+
+	ask(alloc.Subdomain + "." + r.Domain())
+	ask("probe." + r.Domain())
+	if code := ask("probe." + r.Domain()); code != http.StatusForbidden {
+		t.Errorf("cached rejection status = %d, want %d", code, http.StatusForbidden)
+	}
+	ask("example.com")
+
+	want := AskStats{Valid: 1, Invalid: 2, Malformed: 1, CacheHits: 1}
+	if got := r.AskStats(); got != want {
+		t.Errorf("AskStats() = %+v, want %+v", got, want)
+	}
+
+	// Registering a previously rejected subdomain takes effect immediately
+	if err := r.RegisterExisting("late", "probe", 3998); err != nil {
+		t.Fatalf("RegisterExisting failed: %v", err)
+	}
+	if code := ask("probe." + r.Domain()); code != http.StatusOK {
+		t.Errorf("status after registration = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestCaddyAskRacingRegistration(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		r := NewRegistry()
+		ask := func() int {
+			req := httptest.NewRequest(http.MethodGet, "/internal/caddy-ask?domain=probe."+r.Domain(), nil)
+			w := httptest.NewRecorder()
+			r.HandleCaddyAsk(w, req)
+			return w.Code
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ask()
+		}()
+		if err := r.RegisterExisting("late", "probe", 3998); err != nil {
+			t.Fatal(err)
+		}
+		<-done
+
+		// Whichever came first, the registration must win
+		if code := ask(); code != http.StatusOK {
+			t.Fatalf("iteration %d: status after registration = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+}
+
+func TestAllocateRoutes(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(dir)
+
+	allocs, err := r.AllocateRoutes("shop", "api", "admin")
+	if err != nil {
+		t.Fatalf("AllocateRoutes failed: %v", err)
+	}
+	if len(allocs) != 3 {
+		t.Fatalf("got %d allocations, want 3", len(allocs))
+	}
+
+	primary := allocs[0]
+	if primary.Route != "" {
+		t.Errorf("first allocation should be the primary, got route %q", primary.Route)
+	}
+	if allocs[1].Route != "admin" || allocs[1].Subdomain != primary.Subdomain+"-admin" {
+		t.Errorf("unexpected admin route: %+v", allocs[1])
+	}
+	if allocs[2].Route != "api" || allocs[2].Subdomain != primary.Subdomain+"-api" {
+		t.Errorf("unexpected api route: %+v", allocs[2])
+	}
+
+	ports := make(map[int]bool)
+	for _, a := range allocs {
+		port, ok := r.GetBySubdomain(a.Subdomain)
+		if !ok || port != a.Port {
+			t.Errorf("GetBySubdomain(%s) = %d, %v; want %d", a.Subdomain, port, ok, a.Port)
+		}
+		ports[a.Port] = true
+	}
+	if len(ports) != 3 {
+		t.Errorf("routes should have distinct ports, got %v", ports)
+	}
+
+	// Routes persist across restarts
+	reloaded := NewRegistry(dir)
+	if got, ok := reloaded.GetRoutes("shop"); !ok || len(got) != 3 {
+		t.Errorf("GetRoutes after reload = %v, %v; want 3 routes", got, ok)
+	}
+
+	// Release frees every route
+	r.Release("shop")
+	for _, a := range allocs {
+		if _, ok := r.GetBySubdomain(a.Subdomain); ok {
+			t.Errorf("subdomain %s still allocated after release", a.Subdomain)
+		}
+	}
+}
+
+func TestAllocateRoutesIsAtomic(t *testing.T) {
+	r := NewRegistryWithOptions(WithRandom(bytes.NewReader(bytes.Repeat([]byte{0x00}, 5))))
+
+	// Take the subdomain the api route would need
+	if err := r.RegisterExisting("other", "aaaaaaaa-api", 3998); err != nil {
+		t.Fatalf("RegisterExisting failed: %v", err)
+	}
+
+	if _, err := r.AllocateRoutes("shop", "web", "api"); err == nil {
+		t.Fatal("expected AllocateRoutes to fail on a taken route subdomain")
+	}
+	if _, ok := r.GetByProject("shop"); ok {
+		t.Error("failed AllocateRoutes should not leave a primary allocation")
+	}
+	if len(r.List()) != 1 {
+		t.Errorf("failed AllocateRoutes leaked allocations: %v", r.List())
+	}
+
+	if _, err := r.AllocateRoutes("shop", "Bad_Name"); err == nil {
+		t.Error("expected invalid route name to be rejected")
+	}
+}
+
+func TestWithDomain(t *testing.T) {
+	if got := NewRegistry().Domain(); got != DefaultDomain {
+		t.Errorf("default Domain() = %q, want %q", got, DefaultDomain)
+	}
+	if got := NewRegistryWithOptions(WithDomain("")).Domain(); got != DefaultDomain {
+		t.Errorf("empty WithDomain Domain() = %q, want %q", got, DefaultDomain)
+	}
+
+	r := NewRegistryWithOptions(WithDomain("https://Apps.Example.com/"))
+	if got := r.Domain(); got != "apps.example.com" {
+		t.Fatalf("Domain() = %q, want apps.example.com", got)
+	}
+
+	alloc, err := r.Allocate("test-project")
+	if err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	if want := "https://" + alloc.Subdomain + ".apps.example.com"; alloc.URL != want {
+		t.Errorf("URL = %q, want %q", alloc.URL, want)
+	}
+
+	ask := func(domain string) int {
+		req := httptest.NewRequest(http.MethodGet, "/internal/caddy-ask?domain="+domain, nil)
+		w := httptest.NewRecorder()
+		r.HandleCaddyAsk(w, req)
+		return w.Code
+	}
+	if code := ask(alloc.Subdomain + ".apps.example.com"); code != http.StatusOK {
+		t.Errorf("ask for configured domain = %d, want %d", code, http.StatusOK)
+	}
+	if code := ask(alloc.Subdomain + "." + DefaultDomain); code != http.StatusForbidden {
+		t.Errorf("ask for default domain = %d, want %d", code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,44 @@
+// Package textutil holds string helpers shared across packages.
+//
+// Limits here are in bytes, matching the size limits they enforce, but a
+// cut never splits a UTF-8 sequence: slicing mid-codepoint leaves invalid
+// bytes that JSON encoding replaces and clients render as mojibake.
+package textutil
+
+import "unicode/utf8"
+
+// Ellipsis is appended by Shorten
+const Ellipsis = "..."
+
+// Truncate returns the longest prefix of s that is at most maxBytes long
+// and doesn't end partway through a UTF-8 sequence
+func Truncate(s string, maxBytes int) string {
+	if maxBytes <= 0 {
+		return ""
+	}
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	// Back up over at most one sequence's continuation bytes, so invalid
+	// input can't walk the cut back to the start
+	for i := 0; i < utf8.UTFMax-1 && cut > 0 && !utf8.RuneStart(s[cut]); i++ {
+		cut--
+	}
+	if !utf8.RuneStart(s[cut]) {
+		cut = maxBytes
+	}
+	return s[:cut]
+}
+
+// Shorten truncates s so that, with Ellipsis appended, it fits in maxBytes.
+// Strings that already fit are returned unchanged.
+func Shorten(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	if maxBytes <= len(Ellipsis) {
+		return Truncate(s, maxBytes)
+	}
+	return Truncate(s, maxBytes-len(Ellipsis)) + Ellipsis
+}
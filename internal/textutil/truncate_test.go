@@ -0,0 +1,67 @@
+package textutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"hello", 0, ""},
+		{"héllo", 2, "h"}, // é is 2 bytes
+		{"héllo", 3, "hé"},
+		{"日本語", 4, "日"}, // 3 bytes each
+		{"日本語", 5, "日"},
+		{"日本語", 6, "日本"},
+		{"ok🎉!", 3, "ok"}, // 🎉 is 4 bytes
+		{"ok🎉!", 5, "ok"},
+		{"ok🎉!", 6, "ok🎉"},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.max)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) returned invalid UTF-8", tt.s, tt.max)
+		}
+	}
+}
+
+func TestTruncateInvalidInput(t *testing.T) {
+	// A run of stray continuation bytes can't be cut cleanly; keep the limit
+	s := strings.Repeat("\x80", 10)
+	if got := Truncate(s, 6); len(got) != 6 {
+		t.Errorf("Truncate on invalid input = %d bytes, want 6", len(got))
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"abcdefghij", 8, "abcde..."},
+		{"日本語テキスト", 10, "日本..."},
+		{"🎉🎉🎉", 9, "🎉..."},
+		{"abcdef", 2, "ab"},
+	}
+	for _, tt := range tests {
+		got := Shorten(tt.s, tt.max)
+		if got != tt.want {
+			t.Errorf("Shorten(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+		if len(got) > tt.max || !utf8.ValidString(got) {
+			t.Errorf("Shorten(%q, %d) = %q: over the limit or invalid UTF-8", tt.s, tt.max, got)
+		}
+	}
+}
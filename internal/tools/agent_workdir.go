@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+// agentDirsRoot is where spawned agents' own working directories are
+// created, under the working directory
+const agentDirsRoot = "agents"
+
+// SetAgentDirRetention sets how long a spawned agent's own working directory
+// is kept after it finishes, e.g. to look at its scratch files when
+// debugging. Zero (the default) removes it as soon as the agent finishes; a
+// negative retention keeps it.
+func (pt *PersonaTools) SetAgentDirRetention(d time.Duration) {
+	pt.agentDirsMu.Lock()
+	defer pt.agentDirsMu.Unlock()
+	pt.agentDirRetention = d
+}
+
+// newAgentDir creates a working directory for an agent about to be spawned.
+// The process ID isn't known until the spawn, so the directory is named
+// after the agent plus a random suffix.
+func (pt *PersonaTools) newAgentDir(agentName string) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s-%s", strings.ToLower(sanitizeProjectName(agentName)), time.Now().Format("20060102-150405"), hex.EncodeToString(b))
+	dir := filepath.Join(pt.workingDir, agentDirsRoot, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create working directory: %w", err)
+	}
+	return dir, nil
+}
+
+// setAgentDir records the working directory a process was spawned with
+func (pt *PersonaTools) setAgentDir(processID, dir string) {
+	pt.agentDirsMu.Lock()
+	defer pt.agentDirsMu.Unlock()
+	pt.agentDirs[processID] = dir
+}
+
+// agentDir returns a process's own working directory, or "" if it shares
+// the working directory
+func (pt *PersonaTools) agentDir(processID string) string {
+	pt.agentDirsMu.RLock()
+	defer pt.agentDirsMu.RUnlock()
+	return pt.agentDirs[processID]
+}
+
+// workDirFor returns where a tool call runs when no project is given: the
+// calling agent's own directory if it has one, otherwise the working
+// directory
+func (pt *PersonaTools) workDirFor(ctx context.Context) string {
+	if proc := vega.ProcessFromContext(ctx); proc != nil {
+		if dir := pt.agentDir(proc.ID); dir != "" {
+			return dir
+		}
+	}
+	return pt.workingDir
+}
+
+// releaseAgentDir forgets a finished process's working directory and
+// removes it once the retention has passed
+func (pt *PersonaTools) releaseAgentDir(processID string) {
+	pt.agentDirsMu.Lock()
+	dir, ok := pt.agentDirs[processID]
+	delete(pt.agentDirs, processID)
+	retention := pt.agentDirRetention
+	pt.agentDirsMu.Unlock()
+
+	switch {
+	case !ok || retention < 0:
+	case retention == 0:
+		pt.removeAgentDir(dir)
+	default:
+		time.AfterFunc(retention, func() { pt.removeAgentDir(dir) })
+	}
+}
+
+// discardAgentDir removes a directory made by newAgentDir for a spawn that
+// didn't happen. The shared working directory is left alone.
+func (pt *PersonaTools) discardAgentDir(dir string) {
+	if dir != pt.workingDir {
+		pt.removeAgentDir(dir)
+	}
+}
+
+// removeAgentDir deletes an agent's working directory
+func (pt *PersonaTools) removeAgentDir(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		pt.logger.Warnf("Failed to remove agent working directory %s: %v", dir, err)
+	}
+}
+
+// agentDirPromptSection tells an agent about its own working directory
+func (pt *PersonaTools) agentDirPromptSection(dir string) string {
+	rel, err := filepath.Rel(pt.workingDir, dir)
+	if err != nil {
+		rel = dir
+	}
+	return fmt.Sprintf("\n\n## Working directory\nYour scratch directory is %s (relative to the working directory); commands you execute without a project run there. Other agents don't share it, and it's cleaned up after you finish, so put anything worth keeping in a project.\n", rel)
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestAgentDirLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+
+	gary, err := pt.newAgentDir("Gary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := pt.newAgentDir("Gary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gary == other {
+		t.Fatal("two spawns of the same agent got the same directory")
+	}
+	if rel, _ := filepath.Rel(dir, gary); !strings.HasPrefix(rel, "agents"+string(filepath.Separator)+"gary-") {
+		t.Errorf("agent dir = %s, want it under agents/", rel)
+	}
+	if info, err := os.Stat(gary); err != nil || !info.IsDir() {
+		t.Fatalf("agent dir not created: %v", err)
+	}
+
+	pt.setAgentDir("proc-1", gary)
+	if got := pt.agentDir("proc-1"); got != gary {
+		t.Errorf("agentDir = %q, want %q", got, gary)
+	}
+	if got := pt.agentDir("proc-2"); got != "" {
+		t.Errorf("unknown process dir = %q, want the shared one", got)
+	}
+	if got := pt.workDirFor(context.Background()); got != dir {
+		t.Errorf("workDirFor outside an agent = %q, want %q", got, dir)
+	}
+	if section := pt.agentDirPromptSection(gary); !strings.Contains(section, filepath.Join("agents", filepath.Base(gary))) {
+		t.Errorf("prompt section = %q", section)
+	}
+
+	// Removed as soon as the agent finishes by default
+	os.WriteFile(filepath.Join(gary, "scratch.txt"), []byte("notes"), 0644)
+	pt.releaseAgentDir("proc-1")
+	if _, err := os.Stat(gary); !os.IsNotExist(err) {
+		t.Errorf("agent dir still exists after release: %v", err)
+	}
+	if pt.agentDir("proc-1") != "" {
+		t.Error("released process still has a directory")
+	}
+
+	// A negative retention keeps it
+	pt.SetAgentDirRetention(-1)
+	pt.setAgentDir("proc-2", other)
+	pt.releaseAgentDir("proc-2")
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("kept agent dir is gone: %v", err)
+	}
+
+	// A positive one removes it later
+	pt.SetAgentDirRetention(10 * time.Millisecond)
+	pt.setAgentDir("proc-3", other)
+	pt.releaseAgentDir("proc-3")
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("agent dir removed before its retention: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(other); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("agent dir not removed after its retention")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDiscardAgentDirKeepsSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+
+	pt.discardAgentDir(dir)
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("shared working dir removed: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/persist"
+	"github.com/everydev1618/govega"
+)
+
+// BlockedCaller is a phone number rejected by the voice system
+type BlockedCaller struct {
+	Phone     string    `json:"phone"`
+	Reason    string    `json:"reason,omitempty"`
+	BlockedBy string    `json:"blocked_by,omitempty"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// callerBlocklist persists blocked numbers, keyed like the contact index
+type callerBlocklist struct {
+	mu      sync.RWMutex
+	entries map[string]BlockedCaller // normalized phone -> entry
+	path    string
+}
+
+// newCallerBlocklist loads the blocklist from path, if present
+func newCallerBlocklist(path string) *callerBlocklist {
+	b := &callerBlocklist{
+		entries: make(map[string]BlockedCaller),
+		path:    path,
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		var list []BlockedCaller
+		if json.Unmarshal(data, &list) == nil {
+			for _, e := range list {
+				b.entries[normalizePhone(e.Phone)] = e
+			}
+		}
+	}
+	return b
+}
+
+// save writes the blocklist atomically. Caller must hold the lock.
+func (b *callerBlocklist) save() error {
+	list := make([]BlockedCaller, 0, len(b.entries))
+	for _, e := range b.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].BlockedAt.Before(list[j].BlockedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return persist.WriteFile(b.path, data)
+}
+
+// BlockCaller blocks a phone number so inbound calls from it are rejected
+func (pt *PersonaTools) BlockCaller(phone, reason string) error {
+	return pt.blockCaller(phone, reason, "")
+}
+
+// blockCaller records a block, noting who made it
+func (pt *PersonaTools) blockCaller(phone, reason, blockedBy string) error {
+	key := normalizePhone(phone)
+	if len(key) < minPhoneDigits {
+		return fmt.Errorf("invalid phone %q", phone)
+	}
+
+	pt.blocklist.mu.Lock()
+	defer pt.blocklist.mu.Unlock()
+	pt.blocklist.entries[key] = BlockedCaller{
+		Phone:     phone,
+		Reason:    reason,
+		BlockedBy: blockedBy,
+		BlockedAt: time.Now(),
+	}
+	if err := pt.blocklist.save(); err != nil {
+		return fmt.Errorf("failed to save blocklist: %w", err)
+	}
+	return nil
+}
+
+// UnblockCaller removes a block. It returns whether the number was blocked.
+func (pt *PersonaTools) UnblockCaller(phone string) (bool, error) {
+	key := normalizePhone(phone)
+
+	pt.blocklist.mu.Lock()
+	defer pt.blocklist.mu.Unlock()
+	if _, ok := pt.blocklist.entries[key]; !ok {
+		return false, nil
+	}
+	delete(pt.blocklist.entries, key)
+	if err := pt.blocklist.save(); err != nil {
+		return true, fmt.Errorf("failed to save blocklist: %w", err)
+	}
+	return true, nil
+}
+
+// IsBlocked reports whether calls from a phone number should be rejected
+func (pt *PersonaTools) IsBlocked(phone string) bool {
+	key := normalizePhone(phone)
+	if len(key) < minPhoneDigits {
+		return false
+	}
+
+	pt.blocklist.mu.RLock()
+	defer pt.blocklist.mu.RUnlock()
+	_, ok := pt.blocklist.entries[key]
+	return ok
+}
+
+// blockCallerTool is the block_caller tool. Without a phone it blocks the
+// caller on the current voice call.
+func (pt *PersonaTools) blockCallerTool(ctx context.Context, params map[string]any) (string, error) {
+	phone, _ := params["phone"].(string)
+	reason, _ := params["reason"].(string)
+
+	if strings.TrimSpace(phone) == "" {
+		if ch, ok := notification.ChannelFromContext(ctx); ok && ch.Type == notification.ChannelVoice {
+			phone = ch.UserID
+		}
+	}
+	if strings.TrimSpace(phone) == "" {
+		return "", fmt.Errorf("phone is required")
+	}
+
+	blockedBy := ""
+	if proc := vega.ProcessFromContext(ctx); proc != nil && proc.Agent != nil {
+		blockedBy = proc.Agent.Name
+	}
+	if err := pt.blockCaller(phone, reason, blockedBy); err != nil {
+		return "", err
+	}
+
+	pt.logger.Infof("Blocked caller %s (%s)", phone, reason)
+	return fmt.Sprintf("Blocked %s. Future calls from this number will be rejected.", phone), nil
+}
+
+// unblockCallerTool is the unblock_caller tool
+func (pt *PersonaTools) unblockCallerTool(ctx context.Context, params map[string]any) (string, error) {
+	phone, _ := params["phone"].(string)
+	if strings.TrimSpace(phone) == "" {
+		return "", fmt.Errorf("phone is required")
+	}
+
+	removed, err := pt.UnblockCaller(phone)
+	if err != nil {
+		return "", err
+	}
+	if !removed {
+		return fmt.Sprintf("%s was not blocked.", phone), nil
+	}
+	return fmt.Sprintf("Unblocked %s.", phone), nil
+}
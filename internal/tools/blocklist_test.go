@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/govega"
+)
+
+func TestBlockCallerPersistsAndNormalizes(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	if err := pt.BlockCaller("+1 (555) 010-9999", "robocalls"); err != nil {
+		t.Fatalf("BlockCaller() error = %v", err)
+	}
+	for _, phone := range []string{"+15550109999", "1-555-010-9999", "1 555 010 9999"} {
+		if !pt.IsBlocked(phone) {
+			t.Errorf("IsBlocked(%q) = false, want true", phone)
+		}
+	}
+	if pt.IsBlocked("+15550100000") {
+		t.Error("IsBlocked() = true for a different number")
+	}
+	if err := pt.BlockCaller("123", ""); err == nil {
+		t.Error("BlockCaller() with a short number should fail")
+	}
+
+	// Blocks survive a restart
+	reloaded := newCallerBlocklist(filepath.Join(dir, "knowledge", "blocked_callers.json"))
+	if _, ok := reloaded.entries["15550109999"]; !ok {
+		t.Fatalf("blocklist not persisted: %+v", reloaded.entries)
+	}
+
+	removed, err := pt.UnblockCaller("15550109999")
+	if err != nil || !removed {
+		t.Fatalf("UnblockCaller() = %v, %v; want true, nil", removed, err)
+	}
+	if pt.IsBlocked("+15550109999") {
+		t.Error("IsBlocked() = true after unblocking")
+	}
+}
+
+func TestBlockCallerToolDefaultsToCurrentCaller(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	ctx := notification.WithChannel(context.Background(), notification.ChannelContext{
+		Type:   notification.ChannelVoice,
+		UserID: "+15550104444",
+	})
+	if _, err := pt.blockCallerTool(ctx, map[string]any{"reason": "spam"}); err != nil {
+		t.Fatalf("blockCallerTool() error = %v", err)
+	}
+	if !pt.IsBlocked("+15550104444") {
+		t.Error("current caller was not blocked")
+	}
+
+	if _, err := pt.blockCallerTool(context.Background(), map[string]any{}); err == nil {
+		t.Error("blockCallerTool() outside a call without a phone should fail")
+	}
+}
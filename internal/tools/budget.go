@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/everydev1618/govega"
+)
+
+// budgetWarnThreshold is the fraction of a budget spent before it's flagged
+const budgetWarnThreshold = 0.8
+
+// BudgetStatus is a running process's spend against its budget
+type BudgetStatus struct {
+	ProcessID string  `json:"process_id"`
+	Agent     string  `json:"agent"`
+	Limit     float64 `json:"limit"` // 0 when the agent has no budget
+	Spent     float64 `json:"spent"`
+	Remaining float64 `json:"remaining"`
+	Used      float64 `json:"used"`    // Fraction of the limit spent
+	Warning   bool    `json:"warning"` // Spent at least budgetWarnThreshold of the limit
+	OnExceed  string  `json:"on_exceed,omitempty"`
+}
+
+// AgentBudget returns the budget consumption of a process
+func (pt *PersonaTools) AgentBudget(processID string) (BudgetStatus, error) {
+	proc := pt.orch.Get(processID)
+	if proc == nil {
+		return BudgetStatus{}, fmt.Errorf("process not found: %s", processID)
+	}
+
+	var agentName string
+	var budget *vega.Budget
+	if proc.Agent != nil {
+		agentName = proc.Agent.Name
+		budget = proc.Agent.Budget
+	}
+	return budgetStatus(proc.ID, agentName, budget, proc.Metrics().CostUSD), nil
+}
+
+// budgetStatus computes spend against a budget, which may be nil
+func budgetStatus(processID, agentName string, budget *vega.Budget, spent float64) BudgetStatus {
+	status := BudgetStatus{
+		ProcessID: processID,
+		Agent:     agentName,
+		Spent:     spent,
+	}
+	if budget == nil || budget.Limit <= 0 {
+		return status
+	}
+
+	status.Limit = budget.Limit
+	status.Remaining = budget.Limit - spent
+	if status.Remaining < 0 {
+		status.Remaining = 0
+	}
+	status.Used = spent / budget.Limit
+	status.Warning = status.Used >= budgetWarnThreshold
+	if budget.OnExceed == vega.BudgetBlock {
+		status.OnExceed = "block"
+	} else {
+		status.OnExceed = "warn"
+	}
+	return status
+}
+
+// getAgentBudget is the get_agent_budget tool
+func (pt *PersonaTools) getAgentBudget(ctx context.Context, params map[string]any) (string, error) {
+	processID, _ := params["process_id"].(string)
+	processID = strings.TrimSpace(processID)
+	if processID == "" {
+		return "", fmt.Errorf("process_id is required")
+	}
+
+	s, err := pt.AgentBudget(processID)
+	if err != nil {
+		return "", err
+	}
+
+	if s.Limit == 0 {
+		return fmt.Sprintf("%s (%s) has no budget set. Spent so far: $%.4f", s.Agent, s.ProcessID, s.Spent), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Budget for %s (%s):\n", s.Agent, s.ProcessID))
+	sb.WriteString(fmt.Sprintf("- Limit: $%.2f (%s when exceeded)\n", s.Limit, s.OnExceed))
+	sb.WriteString(fmt.Sprintf("- Spent: $%.4f (%.0f%%)\n", s.Spent, s.Used*100))
+	sb.WriteString(fmt.Sprintf("- Remaining: $%.4f\n", s.Remaining))
+	if s.Warning {
+		sb.WriteString(fmt.Sprintf("\nWARNING: over %.0f%% of budget used. Consider whether to let the task continue or kill it.",
+			budgetWarnThreshold*100))
+	}
+	return sb.String(), nil
+}
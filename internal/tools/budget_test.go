@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	s := budgetStatus("p1", "Gary", &vega.Budget{Limit: 5, OnExceed: vega.BudgetBlock}, 4.25)
+	if s.Remaining != 0.75 || s.Used != 0.85 || !s.Warning || s.OnExceed != "block" {
+		t.Errorf("budgetStatus at 85%% = %+v", s)
+	}
+
+	s = budgetStatus("p1", "Gary", &vega.Budget{Limit: 5}, 1)
+	if s.Warning || s.Remaining != 4 || s.OnExceed != "warn" {
+		t.Errorf("budgetStatus at 20%% = %+v", s)
+	}
+
+	// Overspend never reports negative remaining
+	if s := budgetStatus("p1", "Gary", &vega.Budget{Limit: 5}, 6); s.Remaining != 0 || !s.Warning {
+		t.Errorf("budgetStatus over limit = %+v", s)
+	}
+
+	// No budget reports spend only
+	if s := budgetStatus("p1", "Gary", nil, 2); s.Limit != 0 || s.Spent != 2 || s.Warning {
+		t.Errorf("budgetStatus without budget = %+v", s)
+	}
+}
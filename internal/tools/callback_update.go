@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/everydev1618/tron/internal/callback"
+)
+
+// CallbackUpdater changes pending completion callbacks (see callback.Registry)
+type CallbackUpdater interface {
+	Update(agentID, method, phone, email, name string) (*callback.Callback, error)
+}
+
+// SetCallbackRegistry enables update_callback
+func (pt *PersonaTools) SetCallbackRegistry(r CallbackUpdater) {
+	pt.callbackRegistry = r
+}
+
+// updateCallback changes how a pending callback reaches the customer
+func (pt *PersonaTools) updateCallback(ctx context.Context, params map[string]any) (string, error) {
+	agentID, _ := params["agent_id"].(string)
+	method, _ := params["method"].(string)
+	phone, _ := params["phone"].(string)
+	email, _ := params["email"].(string)
+	name, _ := params["name"].(string)
+
+	if pt.callbackRegistry == nil {
+		return "", fmt.Errorf("callbacks not available")
+	}
+	if agentID == "" {
+		return "", fmt.Errorf("agent_id is required")
+	}
+	method = strings.ToLower(strings.TrimSpace(method))
+	switch method {
+	case "", "call", "email", "both", "sms", "all":
+	default:
+		return "", fmt.Errorf("unknown method %q: use call, email, both, sms, or all", method)
+	}
+
+	cb, err := pt.callbackRegistry.Update(agentID, method, phone, email, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to update callback: %w", err)
+	}
+
+	var to []string
+	switch cb.Method {
+	case "webhook":
+		to = append(to, cb.WebhookURL)
+	case "email":
+		to = append(to, cb.CustomerEmail)
+	case "both", "all":
+		to = append(to, cb.CustomerPhone, cb.CustomerEmail)
+	default:
+		to = append(to, cb.CustomerPhone)
+	}
+	return fmt.Sprintf("Callback for %s updated: will %s %s when it completes.",
+		cb.AgentName, callbackVerb(cb.Method), strings.Join(to, " and ")), nil
+}
+
+// callbackVerb describes a callback method for a confirmation message
+func callbackVerb(method string) string {
+	switch method {
+	case "call":
+		return "call"
+	case "sms":
+		return "text"
+	case "both":
+		return "call and email"
+	case "all":
+		return "call, email and text"
+	case "webhook":
+		return "notify"
+	default:
+		return "email"
+	}
+}
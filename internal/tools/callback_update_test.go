@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/callback"
+)
+
+// fakeCallbacks records callback updates
+type fakeCallbacks struct {
+	agentID, method, email string
+}
+
+func (f *fakeCallbacks) Update(agentID, method, phone, email, name string) (*callback.Callback, error) {
+	f.agentID, f.method, f.email = agentID, method, email
+	return &callback.Callback{AgentID: agentID, AgentName: "Gary", Method: method, CustomerEmail: email}, nil
+}
+
+func TestUpdateCallbackTool(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	if _, err := pt.updateCallback(context.Background(), map[string]any{"agent_id": "p1"}); err == nil {
+		t.Fatal("expected an error without a callback registry")
+	}
+
+	fake := &fakeCallbacks{}
+	pt.SetCallbackRegistry(fake)
+	if _, err := pt.updateCallback(context.Background(), map[string]any{"agent_id": "p1", "method": "fax"}); err == nil {
+		t.Error("expected an unknown method to be rejected")
+	}
+
+	out, err := pt.updateCallback(context.Background(), map[string]any{"agent_id": "p1", "method": "Email", "email": "sam@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.agentID != "p1" || fake.method != "email" || fake.email != "sam@example.com" {
+		t.Errorf("update = %+v", fake)
+	}
+	if !strings.Contains(out, "will email sam@example.com") {
+		t.Errorf("output = %q", out)
+	}
+}
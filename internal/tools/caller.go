@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// minPhoneDigits is the shortest normalized number accepted as a phone
+const minPhoneDigits = 7
+
+// UpdateCaller creates or updates the contact for a phone number, so the
+// caller is recognized on their next call. Empty fields keep their current
+// values. It returns whether a new contact was created.
+func (pt *PersonaTools) UpdateCaller(c Contact) (bool, error) {
+	phone := normalizePhone(c.Phone)
+	if len(phone) < minPhoneDigits {
+		return false, fmt.Errorf("invalid phone %q", c.Phone)
+	}
+
+	pt.contacts.mu.Lock()
+	existing, exists := pt.contacts.contacts[phone]
+	if !exists && strings.TrimSpace(c.Name) == "" {
+		pt.contacts.mu.Unlock()
+		return false, fmt.Errorf("name is required for a new caller")
+	}
+
+	merged := existing
+	if !exists {
+		merged.Phone = c.Phone
+	}
+	if c.Name != "" {
+		merged.Name = c.Name
+	}
+	if c.Email != "" {
+		merged.Email = c.Email
+	}
+	if c.Company != "" {
+		merged.Company = c.Company
+	}
+	if c.Role != "" {
+		merged.Role = c.Role
+	}
+	if c.Notes != "" {
+		if merged.Notes != "" && merged.Notes != c.Notes {
+			merged.Notes += "\n" + c.Notes
+		} else {
+			merged.Notes = c.Notes
+		}
+	}
+	pt.contacts.contacts[phone] = merged
+	pt.contacts.mu.Unlock()
+
+	if err := pt.persistContacts(); err != nil {
+		return !exists, fmt.Errorf("failed to save contacts: %w", err)
+	}
+	return !exists, nil
+}
+
+// LookupCaller returns the contact for a phone number
+func (pt *PersonaTools) LookupCaller(phone string) (Contact, bool) {
+	pt.contacts.mu.RLock()
+	defer pt.contacts.mu.RUnlock()
+	c, ok := pt.contacts.contacts[normalizePhone(phone)]
+	return c, ok
+}
+
+// updateCallerTool wraps UpdateCaller as a tool
+func (pt *PersonaTools) updateCallerTool(ctx context.Context, params map[string]any) (string, error) {
+	var c Contact
+	c.Phone, _ = params["phone"].(string)
+	c.Name, _ = params["name"].(string)
+	c.Email, _ = params["email"].(string)
+	c.Company, _ = params["company"].(string)
+	c.Role, _ = params["role"].(string)
+	c.Notes, _ = params["notes"].(string)
+
+	created, err := pt.UpdateCaller(c)
+	if err != nil {
+		return "", err
+	}
+
+	pt.logger.Infof("Saved caller %s (%s)", c.Phone, c.Name)
+	if created {
+		return fmt.Sprintf("Saved new contact for %s. They'll be recognized on future calls.", c.Phone), nil
+	}
+	return fmt.Sprintf("Updated contact for %s.", c.Phone), nil
+}
+
+// CallerPromptSection returns the caller context for a call's system prompt.
+// Known callers get their contact details; unknown numbers get instructions
+// to save the caller with update_caller once they introduce themselves.
+func (pt *PersonaTools) CallerPromptSection(phone string) string {
+	if len(normalizePhone(phone)) < minPhoneDigits {
+		return ""
+	}
+
+	if info := pt.IdentifyCaller(phone); info != "" {
+		return fmt.Sprintf("\n\n## Current Caller\n%s", info)
+	}
+
+	return fmt.Sprintf("\n\n## Current Caller\nUnknown caller from %s. If they tell you who they are, "+
+		"save it with update_caller (phone %q) so they're recognized next time.", phone, phone)
+}
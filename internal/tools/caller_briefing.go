@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+const (
+	// maxCallerBriefingBytes bounds everything appended to a caller's contact
+	maxCallerBriefingBytes = 4000
+
+	// Each source gets a share so one long memory file can't crowd out the rest
+	maxBriefingMemoryBytes = 1500
+	maxBriefingFactsBytes  = 1000
+
+	// Knowledge entries mentioning the caller, from this far back, at most
+	briefingKnowledgeWindow  = 30 * 24 * time.Hour
+	maxBriefingKnowledge     = 5
+	maxBriefingEntryBytes    = 200
+	briefingKnowledgeScanMax = 500
+)
+
+// CallerBriefing returns what we know about a caller beyond their contact
+// fields: their person memory file, facts saved with save_person_memory,
+// and recent knowledge entries tagged with or mentioning their name. Each
+// source is its own section; the whole is bounded to maxCallerBriefingBytes.
+// Returns "" for unknown callers or when there's nothing to add.
+func (pt *PersonaTools) CallerBriefing(phone string) string {
+	contact, ok := pt.LookupCaller(phone)
+	if !ok || strings.TrimSpace(contact.Name) == "" {
+		return ""
+	}
+	name := strings.TrimSpace(contact.Name)
+
+	var sections []string
+	if mem, err := memory.LoadPersonMemory(pt.tronDir, name); err != nil {
+		pt.logger.Warnf("Failed to load person memory for %s: %v", name, err)
+	} else if mem = strings.TrimSpace(mem); mem != "" {
+		sections = append(sections, "### Memory\n"+textutil.Shorten(mem, maxBriefingMemoryBytes))
+	}
+	if facts := pt.personFacts(name); facts != "" {
+		sections = append(sections, "### Saved facts\n"+textutil.Shorten(facts, maxBriefingFactsBytes))
+	}
+	if entries := pt.knowledgeAbout(name, time.Now()); len(entries) > 0 {
+		var sb strings.Builder
+		sb.WriteString("### Recent knowledge\n")
+		for _, e := range entries {
+			sb.WriteString(fmt.Sprintf("- [%s] %s (%s, %s): %s\n", e.Type, e.Title, e.Author,
+				e.CreatedAt.Format("Jan 2"), textutil.Shorten(strings.Join(strings.Fields(e.Content), " "), maxBriefingEntryBytes)))
+		}
+		sections = append(sections, strings.TrimRight(sb.String(), "\n"))
+	}
+
+	if len(sections) == 0 {
+		return ""
+	}
+	return textutil.Shorten("## Caller briefing\n"+strings.Join(sections, "\n\n"), maxCallerBriefingBytes)
+}
+
+// personFacts formats the save_person_memory facts for a person, matching
+// the name case-insensitively
+func (pt *PersonaTools) personFacts(name string) string {
+	pt.personMemMu.RLock()
+	defer pt.personMemMu.RUnlock()
+
+	for person, facts := range pt.personMemory {
+		if !strings.EqualFold(strings.TrimSpace(person), name) || len(facts) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(facts))
+		for k := range facts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var sb strings.Builder
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", k, facts[k]))
+		}
+		return strings.TrimRight(sb.String(), "\n")
+	}
+	return ""
+}
+
+// knowledgeAbout returns the newest knowledge entries from the last
+// briefingKnowledgeWindow that are tagged with or mention name
+func (pt *PersonaTools) knowledgeAbout(name string, now time.Time) []knowledge.Entry {
+	store := pt.currentKnowledgeStore()
+	if store == nil {
+		return nil
+	}
+
+	recent := store.Query(knowledge.QueryOptions{
+		Since: now.Add(-briefingKnowledgeWindow),
+		Limit: briefingKnowledgeScanMax,
+	})
+	var matched []knowledge.Entry
+	for _, e := range recent {
+		if mentionsPerson(e, name) {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+	if len(matched) > maxBriefingKnowledge {
+		matched = matched[:maxBriefingKnowledge]
+	}
+	return matched
+}
+
+// mentionsPerson reports whether an entry is tagged with name, or with it
+// as "person:<name>", or names them in its title or content. Matching is on
+// the full name so a caller called "Al" doesn't match every "also".
+func mentionsPerson(e knowledge.Entry, name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return false
+	}
+	for _, tag := range e.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == name || tag == "person:"+name {
+			return true
+		}
+	}
+	return containsWord(strings.ToLower(e.Title), name) || containsWord(strings.ToLower(e.Content), name)
+}
+
+// containsWord reports whether s contains phrase with no letter or digit
+// directly before or after it
+func containsWord(s, phrase string) bool {
+	for start := 0; ; {
+		i := strings.Index(s[start:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(phrase)
+		if (i == 0 || !isWordByte(s[i-1])) && (end == len(s) || !isWordByte(s[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b >= 0x80
+}
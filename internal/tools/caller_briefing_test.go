@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+func TestCallerBriefing(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+	if _, err := pt.UpdateCaller(Contact{Phone: "+1 555 010 2000", Name: "Sam Rivera"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing remembered yet: the contact fields only
+	out, err := pt.identifyCallerTool(context.Background(), map[string]any{"phone": "+1 555 010 2000", "include_context": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Caller briefing") {
+		t.Errorf("empty briefing should be omitted:\n%s", out)
+	}
+
+	peopleDir := filepath.Join(dir, "tron.persona", "people")
+	os.MkdirAll(peopleDir, 0755)
+	if err := os.WriteFile(filepath.Join(peopleDir, "sam-rivera.md"), []byte("Prefers morning calls."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pt.savePersonMemory(context.Background(), map[string]any{"person": "sam rivera", "key": "project", "fact": "Rebuilding the storefront"})
+
+	out, _ = pt.identifyCallerTool(context.Background(), map[string]any{"phone": "+1 555 010 2000"})
+	if strings.Contains(out, "Caller briefing") {
+		t.Errorf("briefing should be opt-in:\n%s", out)
+	}
+
+	out, _ = pt.identifyCallerTool(context.Background(), map[string]any{"phone": "+1 555 010 2000", "include_context": true})
+	for _, want := range []string{"Name: Sam Rivera", "## Caller briefing", "### Memory\nPrefers morning calls.", "### Saved facts\n- project: Rebuilding the storefront"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCallerBriefingIsBounded(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+	pt.UpdateCaller(Contact{Phone: "5550103000", Name: "Lee"})
+
+	peopleDir := filepath.Join(dir, "tron.persona", "people")
+	os.MkdirAll(peopleDir, 0755)
+	os.WriteFile(filepath.Join(peopleDir, "lee.md"), []byte(strings.Repeat("remembered ", 2000)), 0644)
+	pt.personMemory["Lee"] = map[string]string{}
+	for i := 0; i < 200; i++ {
+		pt.personMemory["Lee"][strings.Repeat("k", i+1)] = strings.Repeat("fact ", 20)
+	}
+
+	briefing := pt.CallerBriefing("5550103000")
+	if len(briefing) > maxCallerBriefingBytes {
+		t.Errorf("briefing is %d bytes, limit %d", len(briefing), maxCallerBriefingBytes)
+	}
+	if !strings.Contains(briefing, "### Saved facts") {
+		t.Error("a long memory file crowded out the saved facts")
+	}
+}
+
+func TestMentionsPerson(t *testing.T) {
+	tests := []struct {
+		entry knowledge.Entry
+		want  bool
+	}{
+		{knowledge.Entry{Tags: []string{"person:sam rivera"}}, true},
+		{knowledge.Entry{Tags: []string{"Sam Rivera"}}, true},
+		{knowledge.Entry{Title: "Call notes: Sam Rivera wants a demo"}, true},
+		{knowledge.Entry{Content: "Spoke with sam rivera, who"}, true},
+		{knowledge.Entry{Content: "Sam Riverall is someone else"}, false},
+		{knowledge.Entry{Content: "Unrelated", Tags: []string{"sam"}}, false},
+	}
+	for _, tt := range tests {
+		if got := mentionsPerson(tt.entry, "Sam Rivera"); got != tt.want {
+			t.Errorf("mentionsPerson(%+v) = %v, want %v", tt.entry, got, tt.want)
+		}
+	}
+}
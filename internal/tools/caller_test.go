@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestUpdateCallerPersistsNewCaller(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", dir, nil)
+
+	if pt.IdentifyCaller("+1 (555) 222-3333") != "" {
+		t.Fatal("caller should start unknown")
+	}
+	if section := pt.CallerPromptSection("+1 (555) 222-3333"); !strings.Contains(section, "update_caller") {
+		t.Errorf("unknown caller prompt = %q, want update_caller instructions", section)
+	}
+
+	if _, err := pt.updateCallerTool(context.Background(), map[string]any{"phone": "+1 (555) 222-3333"}); err == nil {
+		t.Error("expected an error for a new caller without a name")
+	}
+
+	result, err := pt.updateCallerTool(context.Background(), map[string]any{
+		"phone":   "+1 (555) 222-3333",
+		"name":    "Pat Lee",
+		"company": "Acme",
+	})
+	if err != nil {
+		t.Fatalf("update_caller failed: %v", err)
+	}
+	if !strings.Contains(result, "new contact") {
+		t.Errorf("result = %q, want new contact", result)
+	}
+
+	if info := pt.IdentifyCaller("15552223333"); !strings.Contains(info, "Pat Lee") {
+		t.Errorf("IdentifyCaller after update = %q, want Pat Lee", info)
+	}
+
+	// Updates merge into the existing contact
+	if _, err := pt.UpdateCaller(Contact{Phone: "+1 555-222-3333", Email: "pat@acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh instance loads the saved contact from disk
+	path := filepath.Join(dir, "knowledge", "contacts.yaml")
+	reloaded := NewPersonaTools(orch, createTestConfig(), "./work", t.TempDir(), nil)
+	if err := reloaded.loadContacts(path); err != nil {
+		t.Fatalf("loadContacts failed: %v", err)
+	}
+	info := reloaded.IdentifyCaller("+1 555 222 3333")
+	if !strings.Contains(info, "Pat Lee") || !strings.Contains(info, "Acme") || !strings.Contains(info, "pat@acme.com") {
+		t.Errorf("reloaded caller = %q, want merged details", info)
+	}
+
+	// No temp files are left behind
+	entries, _ := os.ReadDir(filepath.Join(dir, "knowledge"))
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("leftover temp file %s", e.Name())
+		}
+	}
+}
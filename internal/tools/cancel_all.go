@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/everydev1618/govega"
+)
+
+// callbackCanceller drops pending completion callbacks (see callback.Registry)
+type callbackCanceller interface {
+	Cancel(agentID string) bool
+}
+
+// CancelledAgent is a process stopped by CancelDescendants
+type CancelledAgent struct {
+	ProcessID string
+	Agent     string
+	Task      string
+	Err       error // Set if the process couldn't be stopped
+}
+
+// CancelDescendants stops every running process spawned beneath rootID,
+// deepest first so a parent can't respawn work mid-cancel. Their pending
+// callbacks and notification channels are dropped first, so nobody is told
+// the work "completed". rootID itself keeps running.
+func (pt *PersonaTools) CancelDescendants(rootID string) (stopped []CancelledAgent, callbacks int) {
+	canceller, _ := pt.callbackRegistry.(callbackCanceller)
+
+	descendants := pt.processDescendants(rootID)
+	for i := len(descendants) - 1; i >= 0; i-- {
+		id := descendants[i]
+		proc := pt.orch.Get(id)
+		if proc == nil {
+			continue // Already finished
+		}
+
+		pt.callbacksMu.Lock()
+		if _, ok := pt.callbacks[id]; ok {
+			delete(pt.callbacks, id)
+			callbacks++
+		}
+		pt.callbacksMu.Unlock()
+		pt.processChannelsMu.Lock()
+		delete(pt.processChannels, id)
+		pt.processChannelsMu.Unlock()
+		if canceller != nil && canceller.Cancel(id) {
+			callbacks++
+		}
+
+		agent := "Agent"
+		if proc.Agent != nil {
+			agent = proc.Agent.Name
+		}
+		c := CancelledAgent{ProcessID: id, Agent: agent, Task: proc.Task}
+		if err := pt.orch.Kill(id); err != nil {
+			c.Err = err
+		} else {
+			pt.untrackSpawn(id)
+		}
+		stopped = append(stopped, c)
+	}
+
+	if len(stopped) > 0 {
+		pt.saveSpawnCallbacks()
+	}
+	return stopped, callbacks
+}
+
+// cancelAllAgents is the cancel_all_agents tool
+func (pt *PersonaTools) cancelAllAgents(ctx context.Context, params map[string]any) (string, error) {
+	reason, _ := params["reason"].(string)
+	reason = strings.TrimSpace(reason)
+
+	// Only the caller's own delegations, never its siblings or parent's
+	caller := vega.ProcessFromContext(ctx)
+	if caller == nil {
+		return "", fmt.Errorf("cancel_all_agents can only stop agents you spawned, and there is no calling process")
+	}
+
+	stopped, callbacks := pt.CancelDescendants(caller.ID)
+	if len(stopped) == 0 {
+		return "You have no running delegations to cancel.", nil
+	}
+
+	var sb strings.Builder
+	failed := 0
+	for _, c := range stopped {
+		if c.Err != nil {
+			failed++
+			sb.WriteString(fmt.Sprintf("- %s (%s): failed to stop: %v\n", c.Agent, c.ProcessID, c.Err))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s (%s): %s\n", c.Agent, c.ProcessID, summarizeResult(c.Task, maxSpawnTreeTask)))
+	}
+
+	summary := fmt.Sprintf("Stopped %d of %d agents", len(stopped)-failed, len(stopped))
+	if reason != "" {
+		summary += fmt.Sprintf(" (reason: %s)", reason)
+	}
+	summary += fmt.Sprintf(":\n%s", sb.String())
+	if callbacks > 0 {
+		summary += fmt.Sprintf("Cancelled %d pending callbacks.\n", callbacks)
+	}
+
+	pt.logger.Warnf("cancel_all_agents by %s stopped %d agents (%d failed), reason: %q", caller.ID, len(stopped)-failed, failed, reason)
+	return summary, nil
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestCancelAllAgentsNeedsCaller(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	// Without a calling process there is nothing to scope the cancel to
+	_, err := pt.cancelAllAgents(context.Background(), map[string]any{"reason": "test"})
+	if err == nil || !strings.Contains(err.Error(), "no calling process") {
+		t.Errorf("err = %v, want a missing caller error", err)
+	}
+
+	// Finished descendants are skipped
+	pt.recordSpawnParent("child", "root")
+	pt.callbacks["child"] = CallbackConfig{}
+	stopped, callbacks := pt.CancelDescendants("root")
+	if len(stopped) != 0 || callbacks != 0 {
+		t.Errorf("CancelDescendants = %v, %d; want nothing for finished processes", stopped, callbacks)
+	}
+}
+
+func TestCancelDescendantsSparesSiblingsAndAncestors(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	agent := vega.Agent{Name: "Gary", Tools: vega.NewTools()}
+	spawn := func(parent *vega.Process) *vega.Process {
+		t.Helper()
+		var opts []vega.SpawnOption
+		if parent != nil {
+			opts = append(opts, vega.WithParent(parent))
+		}
+		proc, err := orch.Spawn(agent, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parent != nil {
+			pt.recordSpawnParent(proc.ID, parent.ID)
+		}
+		return proc
+	}
+
+	// root -> caller -> child -> grandchild, with a sibling of the caller
+	root := spawn(nil)
+	caller := spawn(root)
+	sibling := spawn(root)
+	child := spawn(caller)
+	grandchild := spawn(child)
+
+	stopped, _ := pt.CancelDescendants(caller.ID)
+	if len(stopped) != 2 || stopped[0].ProcessID != grandchild.ID || stopped[1].ProcessID != child.ID {
+		t.Fatalf("stopped = %+v, want grandchild then child", stopped)
+	}
+
+	running := func(p *vega.Process) bool {
+		return orch.Get(p.ID) != nil && p.Status() == vega.StatusRunning
+	}
+	for _, p := range []*vega.Process{child, grandchild} {
+		if running(p) {
+			t.Errorf("descendant %s still running", p.ID)
+		}
+	}
+	for _, p := range []*vega.Process{root, caller, sibling} {
+		if !running(p) {
+			t.Errorf("%s was stopped, but isn't beneath the caller", p.ID)
+		}
+	}
+}
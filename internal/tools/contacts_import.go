@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/everydev1618/tron/internal/persist"
+	"gopkg.in/yaml.v3"
+)
+
+// Contact import row outcomes
+const (
+	ImportStatusImported = "imported"
+	ImportStatusUpdated  = "updated"
+	ImportStatusSkipped  = "skipped"
+	ImportStatusError    = "error"
+)
+
+// contactColumnAliases maps normalized CSV headers to Contact fields.
+// Headers that match nothing are kept in Contact.Meta.
+var contactColumnAliases = map[string]string{
+	"name":          "name",
+	"full name":     "name",
+	"contact name":  "name",
+	"phone":         "phone",
+	"phone number":  "phone",
+	"mobile":        "phone",
+	"mobile phone":  "phone",
+	"email":         "email",
+	"email address": "email",
+	"company":       "company",
+	"organization":  "company",
+	"account name":  "company",
+	"role":          "role",
+	"title":         "role",
+	"job title":     "role",
+	"notes":         "notes",
+	"description":   "notes",
+	"tags":          "tags",
+}
+
+// ContactImportRow is the outcome for one CSV data row
+type ContactImportRow struct {
+	Row    int    `json:"row"`
+	Name   string `json:"name,omitempty"`
+	Phone  string `json:"phone,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ContactImportSummary reports the per-row results of a CSV import
+type ContactImportSummary struct {
+	Imported int                `json:"imported"`
+	Updated  int                `json:"updated"`
+	Skipped  int                `json:"skipped"`
+	Errored  int                `json:"errored"`
+	Rows     []ContactImportRow `json:"rows"`
+}
+
+// String formats the summary with one line per skipped or failed row
+func (s *ContactImportSummary) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Imported %d, updated %d, skipped %d, errors %d",
+		s.Imported, s.Updated, s.Skipped, s.Errored))
+	for _, row := range s.Rows {
+		if row.Status == ImportStatusSkipped || row.Status == ImportStatusError {
+			sb.WriteString(fmt.Sprintf("\n  row %d (%s): %s - %s", row.Row, row.Name, row.Status, row.Reason))
+		}
+	}
+	return sb.String()
+}
+
+// record adds a row result and updates the counters
+func (s *ContactImportSummary) record(row ContactImportRow) {
+	switch row.Status {
+	case ImportStatusImported:
+		s.Imported++
+	case ImportStatusUpdated:
+		s.Updated++
+	case ImportStatusSkipped:
+		s.Skipped++
+	case ImportStatusError:
+		s.Errored++
+	}
+	s.Rows = append(s.Rows, row)
+}
+
+// ImportContactsCSV imports contacts from a CSV with a header row. Contacts are
+// keyed by normalized phone; when a phone already exists the row replaces it if
+// overwrite is set and is skipped otherwise. Bad rows are reported in the
+// summary without stopping the import. The merged contacts are persisted.
+func (pt *PersonaTools) ImportContactsCSV(reader io.Reader, overwrite bool) (*ContactImportSummary, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	fields := make([]string, len(header))
+	hasPhone := false
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if field, ok := contactColumnAliases[key]; ok {
+			fields[i] = field
+			hasPhone = hasPhone || field == "phone"
+		} else {
+			fields[i] = "meta:" + strings.TrimSpace(h)
+		}
+	}
+	if !hasPhone {
+		return nil, fmt.Errorf("CSV header has no phone column")
+	}
+
+	summary := &ContactImportSummary{}
+
+	pt.contacts.mu.Lock()
+	for rowNum := 2; ; rowNum++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				pt.contacts.mu.Unlock()
+				return nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			summary.record(ContactImportRow{Row: rowNum, Status: ImportStatusError, Reason: parseErr.Err.Error()})
+			continue
+		}
+
+		summary.record(pt.importContactRow(rowNum, fields, record, overwrite))
+	}
+	pt.contacts.mu.Unlock()
+
+	if summary.Imported+summary.Updated > 0 {
+		if err := pt.persistContacts(); err != nil {
+			return summary, fmt.Errorf("failed to save contacts: %w", err)
+		}
+	}
+
+	return summary, nil
+}
+
+// importContactRow merges one CSV record. Caller must hold contacts.mu.
+func (pt *PersonaTools) importContactRow(rowNum int, fields, record []string, overwrite bool) ContactImportRow {
+	if len(record) > len(fields) {
+		return ContactImportRow{Row: rowNum, Status: ImportStatusError,
+			Reason: fmt.Sprintf("has %d columns, header has %d", len(record), len(fields))}
+	}
+
+	var c Contact
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch field := fields[i]; field {
+		case "name":
+			c.Name = value
+		case "phone":
+			c.Phone = value
+		case "email":
+			c.Email = value
+		case "company":
+			c.Company = value
+		case "role":
+			c.Role = value
+		case "notes":
+			c.Notes = value
+		case "tags":
+			for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					c.Tags = append(c.Tags, tag)
+				}
+			}
+		default:
+			if c.Meta == nil {
+				c.Meta = make(map[string]string)
+			}
+			c.Meta[strings.TrimPrefix(field, "meta:")] = value
+		}
+	}
+
+	result := ContactImportRow{Row: rowNum, Name: c.Name, Phone: c.Phone}
+
+	phone := normalizePhone(c.Phone)
+	switch {
+	case c.Phone == "":
+		result.Status, result.Reason = ImportStatusError, "missing phone"
+		return result
+	case len(phone) < 7:
+		result.Status, result.Reason = ImportStatusError, fmt.Sprintf("invalid phone %q", c.Phone)
+		return result
+	case c.Name == "":
+		result.Status, result.Reason = ImportStatusError, "missing name"
+		return result
+	}
+
+	existing, exists := pt.contacts.contacts[phone]
+	if exists && !overwrite {
+		result.Status = ImportStatusSkipped
+		result.Reason = fmt.Sprintf("phone already belongs to %s", existing.Name)
+		return result
+	}
+
+	pt.contacts.contacts[phone] = c
+	result.Status = ImportStatusImported
+	if exists {
+		result.Status = ImportStatusUpdated
+	}
+	return result
+}
+
+// persistContacts saves all contacts back to the file they were loaded from,
+// or to the knowledge directory if none was loaded. The file is replaced
+// atomically so a crash mid-write never leaves it truncated.
+func (pt *PersonaTools) persistContacts() error {
+	pt.contacts.persistMu.Lock()
+	defer pt.contacts.persistMu.Unlock()
+
+	pt.contacts.mu.RLock()
+	list := make([]Contact, 0, len(pt.contacts.contacts)+len(pt.contacts.unkeyed))
+	for _, c := range pt.contacts.contacts {
+		list = append(list, c)
+	}
+	list = append(list, pt.contacts.unkeyed...)
+	path := pt.contacts.path
+	pt.contacts.mu.RUnlock()
+
+	if path == "" {
+		path = filepath.Join(pt.tronDir, "knowledge", "contacts.yaml")
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Phone < list[j].Phone
+	})
+
+	data, err := yaml.Marshal(struct {
+		Contacts []Contact `yaml:"contacts"`
+	}{list})
+	if err != nil {
+		return err
+	}
+
+	return persist.WriteFile(path, data)
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestImportContactsCSV(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", dir, nil)
+	pt.contacts.contacts["5551234567"] = Contact{Name: "Existing", Phone: "555-123-4567"}
+
+	csvData := `Full Name,Phone Number,Email,Job Title,Tags,Lead Source
+Jane Doe,(555) 987-6543,jane@example.com,CTO,"vip; customer",Webinar
+Dup Person,555.123.4567,dup@example.com,,,
+No Phone,,nophone@example.com,,,
+Bad Phone,12,bad@example.com,,,
+`
+
+	summary, err := pt.ImportContactsCSV(strings.NewReader(csvData), false)
+	if err != nil {
+		t.Fatalf("ImportContactsCSV failed: %v", err)
+	}
+
+	if summary.Imported != 1 || summary.Skipped != 1 || summary.Errored != 2 {
+		t.Errorf("summary = %s", summary)
+	}
+
+	jane, ok := pt.contacts.contacts["5559876543"]
+	if !ok {
+		t.Fatal("Jane was not imported")
+	}
+	if jane.Role != "CTO" || len(jane.Tags) != 2 || jane.Meta["Lead Source"] != "Webinar" {
+		t.Errorf("unexpected contact fields: %+v", jane)
+	}
+	if pt.contacts.contacts["5551234567"].Name != "Existing" {
+		t.Error("existing contact should be kept without overwrite")
+	}
+
+	// Overwrite replaces the colliding contact
+	summary, err = pt.ImportContactsCSV(strings.NewReader("name,phone\nDup Person,555-123-4567\n"), true)
+	if err != nil {
+		t.Fatalf("ImportContactsCSV failed: %v", err)
+	}
+	if summary.Updated != 1 || pt.contacts.contacts["5551234567"].Name != "Dup Person" {
+		t.Errorf("expected overwrite, got %s", summary)
+	}
+
+	// Imported contacts persist
+	reloaded := NewPersonaTools(orch, createTestConfig(), "./work", dir, nil)
+	if got := reloaded.IdentifyCaller("555-987-6543"); !strings.Contains(got, "Jane Doe") {
+		t.Errorf("expected Jane after reload, got %q", got)
+	}
+}
+
+func TestImportContactsCSVRequiresPhoneColumn(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", t.TempDir(), nil)
+	if _, err := pt.ImportContactsCSV(strings.NewReader("name,email\nJane,jane@example.com\n"), false); err == nil {
+		t.Error("expected error for CSV without a phone column")
+	}
+}
+
+func TestPersistContactsKeepsUnkeyedEntries(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	// Contacts come from the ./knowledge fallback, not the tron dir
+	workDir := t.TempDir()
+	t.Chdir(workDir)
+	if err := os.Mkdir("knowledge", 0755); err != nil {
+		t.Fatal(err)
+	}
+	seed := `contacts:
+  - name: Front Desk
+    email: desk@example.com
+  - name: Alice
+    phone: 555-111-2222
+  - name: Alice Mobile
+    phone: (555) 111-2222
+`
+	if err := os.WriteFile("knowledge/contacts.yaml", []byte(seed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tronDir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", tronDir, nil)
+	if _, err := pt.ImportContactsCSV(strings.NewReader("name,phone\nBob,555-333-4444\n"), false); err != nil {
+		t.Fatalf("ImportContactsCSV failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tronDir, "knowledge", "contacts.yaml")); !os.IsNotExist(err) {
+		t.Error("contacts should be saved to the file they were loaded from")
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, "knowledge", "contacts.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Front Desk", "desk@example.com", "Alice", "Alice Mobile", "Bob"} {
+		if !strings.Contains(string(data), name) {
+			t.Errorf("saved contacts missing %q:\n%s", name, data)
+		}
+	}
+
+	reloaded := NewPersonaTools(orch, createTestConfig(), "./work", tronDir, nil)
+	if len(reloaded.contacts.unkeyed) != 2 || len(reloaded.contacts.contacts) != 2 {
+		t.Errorf("reload kept %d unkeyed and %d keyed contacts, want 2 and 2",
+			len(reloaded.contacts.unkeyed), len(reloaded.contacts.contacts))
+	}
+}
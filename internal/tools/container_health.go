@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// containerReprobeInterval is how long Docker is treated as down after a
+// failure before the daemon is probed again
+const containerReprobeInterval = 30 * time.Second
+
+// errDockerUnreachable wraps container errors caused by a dead daemon
+var errDockerUnreachable = errors.New("Docker is unreachable")
+
+// ContainerFallback is what project commands do when Docker, available at
+// startup, stops answering
+type ContainerFallback string
+
+const (
+	ContainerFallbackHost ContainerFallback = "host" // Run on the host with a note
+	ContainerFallbackDeny ContainerFallback = "deny" // Fail until Docker is back
+)
+
+// ParseContainerFallback converts a policy string; empty means host. Unknown
+// values are an error rather than a default, since deny is a security setting.
+func ParseContainerFallback(s string) (ContainerFallback, error) {
+	switch ContainerFallback(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ContainerFallbackHost:
+		return ContainerFallbackHost, nil
+	case ContainerFallbackDeny:
+		return ContainerFallbackDeny, nil
+	}
+	return "", fmt.Errorf("unknown container fallback %q, want host or deny", s)
+}
+
+// SetContainerFallback sets whether project commands may run on the host
+// while Docker is down. Deployments relying on containers for isolation
+// should use ContainerFallbackDeny.
+func (pt *PersonaTools) SetContainerFallback(policy ContainerFallback) {
+	pt.containerFallback = policy
+}
+
+// containerHealth tracks whether the Docker daemon has stopped answering
+// since startup. The manager's own IsAvailable reflects the boot-time probe.
+type containerHealth struct {
+	mu       sync.Mutex
+	down     bool
+	lastErr  error
+	probedAt time.Time
+
+	probe func(context.Context) error // Defaults to pingDocker
+	now   func() time.Time
+}
+
+// up reports whether Docker is usable, re-probing a down daemon once
+// containerReprobeInterval has passed since the last check
+func (h *containerHealth) up(ctx context.Context) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.down {
+		return true
+	}
+
+	now := h.clock()
+	if now.Sub(h.probedAt) < containerReprobeInterval {
+		return false
+	}
+	h.probedAt = now
+
+	probe := h.probe
+	if probe == nil {
+		probe = pingDocker
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := probe(probeCtx); err != nil {
+		h.lastErr = err
+		return false
+	}
+	h.down, h.lastErr = false, nil
+	return true
+}
+
+// markDown records that a container operation found the daemon unreachable
+func (h *containerHealth) markDown(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.down, h.lastErr, h.probedAt = true, err, h.clock()
+}
+
+// reason describes the last failure for messages
+func (h *containerHealth) reason() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.lastErr == nil {
+		return errDockerUnreachable.Error()
+	}
+	return fmt.Sprintf("%v: %v", errDockerUnreachable, h.lastErr)
+}
+
+func (h *containerHealth) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// containersConfigured reports whether projects use containers at all,
+// i.e. Docker was available at startup
+func (pt *PersonaTools) containersConfigured() bool {
+	return pt.containers != nil && pt.containers.IsAvailable()
+}
+
+// containersUp reports whether container operations should be attempted now
+func (pt *PersonaTools) containersUp(ctx context.Context) bool {
+	return pt.containersConfigured() && pt.containerHealth.up(ctx)
+}
+
+// checkContainerErr marks Docker down if err shows the daemon is gone, and
+// wraps it in errDockerUnreachable so callers can fall back
+func (pt *PersonaTools) checkContainerErr(err error) error {
+	if err == nil || !daemonUnreachable(err) {
+		return err
+	}
+	pt.containerHealth.markDown(err)
+	pt.logger.Warnf("Docker daemon unreachable, container operations paused: %v", err)
+	return fmt.Errorf("%w: %v", errDockerUnreachable, err)
+}
+
+// daemonUnreachable reports whether err means the Docker daemon itself
+// can't be reached, as opposed to a command or container failing
+func daemonUnreachable(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT) && strings.Contains(err.Error(), ".sock") {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"cannot connect to the docker daemon",
+		"is the docker daemon running",
+		"error during connect",
+		"docker.sock: connect",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// pingDocker calls the daemon's /_ping endpoint at DOCKER_HOST, or the
+// default Unix socket
+func pingDocker(ctx context.Context) error {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = "unix:///var/run/docker.sock"
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("invalid DOCKER_HOST %q: %w", host, err)
+	}
+
+	base := "http://docker"
+	transport := &http.Transport{}
+	switch u.Scheme {
+	case "unix":
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", u.Path)
+		}
+	case "tcp", "http":
+		base = "http://" + u.Host
+	default:
+		return fmt.Errorf("unsupported DOCKER_HOST scheme %q", u.Scheme)
+	}
+	defer transport.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/_ping", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker ping: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runWithoutContainer runs a project command on the host after Docker went
+// away, if the fallback policy allows it
+func (pt *PersonaTools) runWithoutContainer(ctx context.Context, command, project string, timeout time.Duration) (string, error) {
+	if err := pt.checkHostFallback(project); err != nil {
+		return "", err
+	}
+	out, err := pt.executeOnHost(ctx, command, project, timeout)
+	if err != nil {
+		return "", err
+	}
+	note := fmt.Sprintf("Note: %s, so this ran on the host in %s instead of the project container.\n\n",
+		pt.containerHealth.reason(), pt.hostProjectDir(project))
+	return note + out, nil
+}
+
+// checkHostFallback returns an error if the fallback policy keeps a project
+// command off the host while Docker is down
+func (pt *PersonaTools) checkHostFallback(project string) error {
+	reason := pt.containerHealth.reason()
+	if pt.containerFallback == ContainerFallbackDeny {
+		return fmt.Errorf("%s and host fallback is disabled; try again once Docker is back", reason)
+	}
+	pt.logger.Warnf("Running command for project %s on host: %s", project, reason)
+	return nil
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestContainerHealthReprobes(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	probeErr := errors.New("still down")
+	probes := 0
+	h := &containerHealth{
+		now: func() time.Time { return now },
+		probe: func(context.Context) error {
+			probes++
+			return probeErr
+		},
+	}
+	ctx := context.Background()
+
+	if !h.up(ctx) {
+		t.Fatal("healthy until a failure is seen")
+	}
+	h.markDown(errors.New("connection refused"))
+	if h.up(ctx) || probes != 0 {
+		t.Fatalf("down within the reprobe interval without probing, probes = %d", probes)
+	}
+
+	now = now.Add(containerReprobeInterval)
+	if h.up(ctx) || probes != 1 {
+		t.Fatalf("failed probe should keep it down, probes = %d", probes)
+	}
+	if !strings.Contains(h.reason(), "still down") {
+		t.Errorf("reason = %q, want the latest probe error", h.reason())
+	}
+	if h.up(ctx) || probes != 1 {
+		t.Fatal("probed again before the interval")
+	}
+
+	now = now.Add(containerReprobeInterval)
+	probeErr = nil
+	if !h.up(ctx) || probes != 2 {
+		t.Fatalf("successful probe should bring it back, probes = %d", probes)
+	}
+}
+
+func TestDaemonUnreachable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?"), true},
+		{fmt.Errorf("exec: %w", syscall.ECONNREFUSED), true},
+		{fmt.Errorf("dial unix /var/run/docker.sock: %w", syscall.ENOENT), true},
+		{errors.New("No such container: tron-site"), false},
+		{fmt.Errorf("open /workspace/x: %w", syscall.ENOENT), false},
+	}
+	for _, tt := range tests {
+		if got := daemonUnreachable(tt.err); got != tt.want {
+			t.Errorf("daemonUnreachable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestParseContainerFallback(t *testing.T) {
+	for in, want := range map[string]ContainerFallback{"": ContainerFallbackHost, "host": ContainerFallbackHost, " DENY ": ContainerFallbackDeny} {
+		if got, err := ParseContainerFallback(in); err != nil || got != want {
+			t.Errorf("ParseContainerFallback(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseContainerFallback("dney"); err == nil {
+		t.Error("a typo must not silently allow host execution")
+	}
+}
+
+func TestRunWithoutContainer(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+	pt.containerHealth.markDown(errors.New("dial unix /var/run/docker.sock: connect: connection refused"))
+
+	out, err := pt.runWithoutContainer(context.Background(), "echo hello", "site", execTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "ran on the host") || !strings.Contains(out, "connection refused") || !strings.HasSuffix(strings.TrimSpace(out), "hello") {
+		t.Errorf("host fallback output = %q", out)
+	}
+
+	pt.SetContainerFallback(ContainerFallbackDeny)
+	if _, err := pt.runWithoutContainer(context.Background(), "echo hello", "site", execTimeout); err == nil || !strings.Contains(err.Error(), "host fallback is disabled") {
+		t.Errorf("deny policy err = %v", err)
+	}
+}
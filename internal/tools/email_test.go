@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+// recordingEmail captures emails sent through EmailSender
+type recordingEmail struct {
+	configured        bool
+	to, subject, body string
+	sent              int
+}
+
+func (r *recordingEmail) IsConfigured() bool { return r.configured }
+
+func (r *recordingEmail) Send(to, subject, body string) error {
+	r.to, r.subject, r.body = to, subject, body
+	r.sent++
+	return nil
+}
+
+func TestSendCallbackEmailUsesEmailClient(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	// No client: skipped without error
+	if err := pt.sendCallbackEmail("sam@example.com", "Done", "result"); err != nil {
+		t.Fatalf("unconfigured send returned %v", err)
+	}
+
+	fake := &recordingEmail{}
+	pt.SetEmailClient(fake)
+	if err := pt.sendCallbackEmail("sam@example.com", "Done", "result"); err != nil || fake.sent != 0 {
+		t.Fatalf("unconfigured client: err=%v sent=%d", err, fake.sent)
+	}
+
+	fake.configured = true
+	if err := pt.sendCallbackEmail("sam@example.com", "Done", "result"); err != nil {
+		t.Fatal(err)
+	}
+	if fake.to != "sam@example.com" || fake.subject != "Done" || fake.body != "result" {
+		t.Errorf("sent %+v", fake)
+	}
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SetExecDryRun makes every execute call a dry run: the command is checked
+// and described but never run. Agents can also ask for a dry run per call.
+func (pt *PersonaTools) SetExecDryRun(enabled bool) {
+	pt.execDryRun = enabled
+}
+
+// execPlan is where and how execute would run a command
+type execPlan struct {
+	Command   string
+	Project   string
+	WorkDir   string
+	Container bool
+	Fallback  string // Set when the project's container is unreachable
+	Denied    bool   // The fallback policy forbids running on the host
+}
+
+// planExec resolves where command would run, without creating anything
+func (pt *PersonaTools) planExec(ctx context.Context, command, project string) execPlan {
+	plan := execPlan{Command: command, Project: project, WorkDir: pt.workDirFor(ctx)}
+	if project != "" && pt.containersUp(ctx) {
+		plan.Container = true
+		plan.WorkDir = "/workspace"
+	} else if project != "" {
+		plan.WorkDir = pt.hostProjectDir(project)
+		if pt.containersConfigured() {
+			plan.Fallback = pt.containerHealth.reason()
+			plan.Denied = pt.containerFallback == ContainerFallbackDeny
+		}
+	}
+	return plan
+}
+
+// describe reports the plan as the execute tool's result
+func (p execPlan) describe() string {
+	var sb strings.Builder
+	sb.WriteString("Dry run: command not executed.\n")
+	sb.WriteString(fmt.Sprintf("Command: %s\n", p.Command))
+	sb.WriteString(fmt.Sprintf("Working directory: %s\n", p.WorkDir))
+	if p.Container {
+		sb.WriteString(fmt.Sprintf("Would run: in the %s project container\n", p.Project))
+	} else if p.Denied {
+		sb.WriteString(fmt.Sprintf("Would fail: %s and host fallback is disabled\n", p.Fallback))
+	} else if p.Fallback != "" {
+		sb.WriteString(fmt.Sprintf("Would run: on host (%s)\n", p.Fallback))
+	} else {
+		sb.WriteString("Would run: on host\n")
+	}
+	return sb.String()
+}
+
+// dryRunExec audits and describes a command instead of running it
+func (pt *PersonaTools) dryRunExec(ctx context.Context, command, project string) string {
+	plan := pt.planExec(ctx, command, project)
+	where := "host"
+	if plan.Container {
+		where = "container"
+	}
+
+	caller, _ := callerFromContext(ctx)
+	pt.logger.Infof("Tool execute DRY RUN for %s (%s, %s): %s", caller, where, plan.WorkDir, command)
+	return plan.describe()
+}
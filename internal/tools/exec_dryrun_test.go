@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestExecuteDryRun(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+	marker := filepath.Join(dir, "ran")
+	command := "touch " + marker
+
+	out, err := pt.execute(context.Background(), map[string]any{"command": command, "project": "site", "dry_run": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("dry run executed the command")
+	}
+	for _, want := range []string{"Dry run", command, pt.hostProjectDir("site"), "on host"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run output missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(pt.hostProjectDir("site")); !os.IsNotExist(err) {
+		t.Error("dry run created the project directory")
+	}
+
+	// Safety checks still apply
+	if _, err := pt.execute(context.Background(), map[string]any{"command": "sudo ls", "dry_run": true}); err == nil {
+		t.Error("expected a blocked command to fail in a dry run")
+	}
+
+	// Operator-wide dry run can't be bypassed per call
+	pt.SetExecDryRun(true)
+	out, err = pt.execute(context.Background(), map[string]any{"command": command, "dry_run": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) || !strings.Contains(out, "Working directory: "+dir) {
+		t.Errorf("expected a dry run in %s, got:\n%s", dir, out)
+	}
+}
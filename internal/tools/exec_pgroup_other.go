@@ -0,0 +1,8 @@
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves exec's default of killing only the shell
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
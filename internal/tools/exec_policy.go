@@ -0,0 +1,378 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultExecBlocklist is the commands execute refuses unless configured
+// otherwise. Each entry is a binary name, optionally a glob, followed by
+// arguments that must all be present for the entry to match.
+var DefaultExecBlocklist = []string{
+	"sudo",
+	"su",
+	"doas",
+	"mkfs*",
+	"dd",
+	"rm -rf /",
+	"rm -rf /*",
+	"rm -fr /",
+	"rm -fr /*",
+}
+
+// execProtectedPaths are refused as arguments or redirect targets of any
+// command, whatever the blocklist says
+var execProtectedPaths = []string{".ssh", ".aws", "/etc/passwd", "/etc/shadow", "169.254.169.254", "metadata.google.internal"}
+
+// execWrappers run the command that follows them, so the binary that
+// matters is the next one
+var execWrappers = map[string]bool{
+	"env": true, "nohup": true, "time": true, "nice": true, "exec": true,
+	"command": true, "builtin": true, "xargs": true, "timeout": true, "stdbuf": true,
+}
+
+// ExecPolicy decides which commands execute may run
+type ExecPolicy struct {
+	// Blocked commands are refused wherever they appear in a command line,
+	// including after pipes, && and inside $(...). Nil means
+	// DefaultExecBlocklist; an empty slice blocks nothing.
+	Blocked []string
+
+	// Allowed, if non-empty, turns on allowlist mode: every command in the
+	// line must start with one of these binaries
+	Allowed []string
+}
+
+// SetExecPolicy replaces the execute blocklist and allowlist
+func (pt *PersonaTools) SetExecPolicy(policy ExecPolicy) {
+	if policy.Blocked == nil {
+		policy.Blocked = DefaultExecBlocklist
+	}
+	pt.execPolicy = policy
+}
+
+// check returns an error naming the first command in line the policy refuses
+func (p ExecPolicy) check(line string) error {
+	cmds, err := parseShellCommands(line)
+	if err != nil {
+		return fmt.Errorf("blocked command: %w", err)
+	}
+
+	for _, c := range cmds {
+		for _, target := range c.redirects {
+			if strings.HasPrefix(target, "/dev/") && !harmlessDevice(target) {
+				return fmt.Errorf("blocked command: writes to device %s", target)
+			}
+		}
+		for _, arg := range append(append(c.args, c.redirects...), c.inputs...) {
+			for _, protected := range execProtectedPaths {
+				if strings.Contains(arg, protected) {
+					return fmt.Errorf("blocked command: touches protected path %q", protected)
+				}
+			}
+		}
+		if len(c.args) == 0 {
+			continue
+		}
+
+		binary := path.Base(c.args[0])
+		for _, pattern := range p.Blocked {
+			if matchesExecPattern(pattern, binary, c.args[1:]) {
+				return fmt.Errorf("blocked command: %q matches blocked pattern %q", strings.Join(c.args, " "), pattern)
+			}
+		}
+		if len(p.Allowed) > 0 && !containsString(p.Allowed, binary) {
+			return fmt.Errorf("blocked command: %q is not on the execute allowlist (allowed: %s)", binary, strings.Join(p.Allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// matchesExecPattern reports whether a blocklist entry matches a command:
+// the entry's first word matches the binary as a glob, and each further
+// word is one of the command's arguments
+func matchesExecPattern(pattern, binary string, args []string) bool {
+	words := strings.Fields(pattern)
+	if len(words) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(words[0], binary); !ok {
+		return false
+	}
+	for _, w := range words[1:] {
+		if !containsString(args, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// harmlessDevice reports whether writing to a /dev path is routine
+func harmlessDevice(target string) bool {
+	switch target {
+	case "/dev/null", "/dev/stdout", "/dev/stderr", "/dev/tty":
+		return true
+	}
+	return strings.HasPrefix(target, "/dev/fd/")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// shellCommand is one simple command from a command line
+type shellCommand struct {
+	args      []string // Binary and arguments, with quotes removed
+	redirects []string // Output redirect targets
+	inputs    []string // Input redirect sources
+}
+
+// maxShellNesting bounds recursion into $(...) and bash -c
+const maxShellNesting = 8
+
+// parseShellCommands splits a bash command line into its simple commands,
+// following pipes, lists, subshells, command substitution and bash -c, so
+// each one's binary can be checked. Quoted text stays a single argument and
+// is never treated as a command. Leading variable assignments and wrappers
+// like env and xargs are skipped so the wrapped binary is what's checked.
+func parseShellCommands(line string) ([]shellCommand, error) {
+	return parseShellNested(line, 0)
+}
+
+func parseShellNested(line string, depth int) ([]shellCommand, error) {
+	if depth > maxShellNesting {
+		return nil, fmt.Errorf("command nests too deeply to check")
+	}
+
+	var cmds []shellCommand
+	var cur shellCommand
+	var word strings.Builder
+	inWord := false
+	var redirect byte // '>' or '<' while the next word is a redirect target
+
+	endWord := func() {
+		if !inWord {
+			return
+		}
+		switch redirect {
+		case '>':
+			cur.redirects = append(cur.redirects, word.String())
+		case '<':
+			cur.inputs = append(cur.inputs, word.String())
+		default:
+			cur.args = append(cur.args, word.String())
+		}
+		redirect = 0
+		word.Reset()
+		inWord = false
+	}
+	endCommand := func() error {
+		endWord()
+		nested, err := unwrapCommand(cur, depth)
+		if err != nil {
+			return err
+		}
+		cmds = append(cmds, nested...)
+		cur = shellCommand{}
+		return nil
+	}
+	substitute := func(inner string) error {
+		nested, err := parseShellNested(inner, depth+1)
+		if err != nil {
+			return err
+		}
+		cmds = append(cmds, nested...)
+		return nil
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			word.WriteByte(line[i+1])
+			inWord = true
+			i++
+
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+
+		case c == '"':
+			// Command substitution still runs inside double quotes
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				switch {
+				case line[j] == '\\' && j+1 < len(line):
+					j++
+					word.WriteByte(line[j])
+				case line[j] == '`' || (line[j] == '$' && j+1 < len(line) && line[j+1] == '('):
+					inner, next, err := substitution(line, j)
+					if err != nil {
+						return nil, err
+					}
+					if err := substitute(inner); err != nil {
+						return nil, err
+					}
+					j = next - 1
+				default:
+					word.WriteByte(line[j])
+				}
+			}
+			if j >= len(line) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+			i = j
+
+		case c == '`' || (c == '$' && i+1 < len(line) && line[i+1] == '('):
+			inner, next, err := substitution(line, i)
+			if err != nil {
+				return nil, err
+			}
+			if err := substitute(inner); err != nil {
+				return nil, err
+			}
+			inWord = true // The substitution's output is part of a word
+			i = next - 1
+
+		case c == '>' || c == '<':
+			// A leading fd number like 2> belongs to the redirect, not the args
+			if inWord && isDigits(word.String()) {
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			for i+1 < len(line) && (line[i+1] == '>' || line[i+1] == '&' || line[i+1] == '|') {
+				i++
+			}
+			redirect = c
+
+		case c == ';' || c == '|' || c == '&' || c == '\n' || c == '(' || c == ')':
+			if err := endCommand(); err != nil {
+				return nil, err
+			}
+
+		case c == ' ' || c == '\t':
+			endWord()
+
+		case c == '#' && !inWord:
+			// Comment to end of line
+			for i+1 < len(line) && line[i+1] != '\n' {
+				i++
+			}
+
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if err := endCommand(); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+// substitution returns the body of the $(...) or `...` starting at line[i]
+// and the index just past it
+func substitution(line string, i int) (string, int, error) {
+	if line[i] == '`' {
+		end := strings.IndexByte(line[i+1:], '`')
+		if end < 0 {
+			return "", 0, fmt.Errorf("unterminated backquote")
+		}
+		return line[i+1 : i+1+end], i + end + 2, nil
+	}
+
+	depth := 0
+	for j := i + 1; j < len(line); j++ {
+		switch line[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return line[i+2 : j], j + 1, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated $(")
+}
+
+// unwrapCommand drops leading assignments and wrapper binaries from a
+// command, and parses the script of bash -c or sh -c
+func unwrapCommand(c shellCommand, depth int) ([]shellCommand, error) {
+	args := c.args
+	for len(args) > 0 {
+		switch {
+		case isAssignment(args[0]):
+			args = args[1:]
+		case execWrappers[path.Base(args[0])]:
+			args = args[1:]
+			for len(args) > 0 && (strings.HasPrefix(args[0], "-") || isAssignment(args[0]) || isDuration(args[0])) {
+				args = args[1:]
+			}
+		default:
+			c.args = args
+			if shell := path.Base(args[0]); shell == "bash" || shell == "sh" || shell == "zsh" {
+				for i, arg := range args[1:] {
+					if arg == "-c" && i+2 < len(args) {
+						nested, err := parseShellNested(args[i+2], depth+1)
+						if err != nil {
+							return nil, err
+						}
+						return append([]shellCommand{c}, nested...), nil
+					}
+				}
+			}
+			return []shellCommand{c}, nil
+		}
+	}
+	c.args = nil
+	if len(c.redirects) == 0 && len(c.inputs) == 0 {
+		return nil, nil
+	}
+	return []shellCommand{c}, nil
+}
+
+// isAssignment reports whether a word is a variable assignment like FOO=bar
+func isAssignment(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, r := range word[:eq] {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDuration reports whether a word is a timeout(1) duration like 30 or 5m
+func isDuration(word string) bool {
+	trimmed := strings.TrimRight(word, "smhd")
+	return trimmed != "" && isDigits(strings.Replace(trimmed, ".", "", 1))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
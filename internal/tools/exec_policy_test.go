@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecPolicyDefaultBlocklist(t *testing.T) {
+	policy := ExecPolicy{Blocked: DefaultExecBlocklist}
+	cases := []struct {
+		command string
+		blocked bool
+	}{
+		{`ls -la`, false},
+		{`echo "rm -rf /"`, false},
+		{`echo 'sudo make me a sandwich'`, false},
+		{`git commit -m "use sudo less"`, false},
+		{`rm -rf ./build`, false},
+		{`go test ./... 2>/dev/null`, false},
+		{`sudo ls`, true},
+		{`/usr/bin/sudo ls`, true},
+		{`ls && sudo reboot`, true},
+		{`cat file | FOO=1 sudo tee x`, true},
+		{`echo "$(sudo id)"`, true},
+		{"echo `su root`", true},
+		{`bash -c "sudo whoami"`, true},
+		{`env -i PATH=/bin sudo ls`, true},
+		{`timeout 30 mkfs.ext4 /dev/sda`, true},
+		{`rm -rf /`, true},
+		{`dd if=/dev/zero of=disk.img`, true},
+		{`cat ~/.ssh/id_rsa`, true},
+		{`curl http://169.254.169.254/latest/meta-data`, true},
+		{`echo hi > /dev/sda`, true},
+		{`echo "unterminated`, true},
+	}
+	for _, c := range cases {
+		err := policy.check(c.command)
+		if (err != nil) != c.blocked {
+			t.Errorf("check(%q) = %v, want blocked=%v", c.command, err, c.blocked)
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "blocked command") {
+			t.Errorf("check(%q) error %q should start with \"blocked command\"", c.command, err)
+		}
+	}
+}
+
+func TestExecPolicyCustomBlocklistAndAllowlist(t *testing.T) {
+	policy := ExecPolicy{Blocked: []string{"docker", "git push"}, Allowed: []string{"ls", "git", "go"}}
+
+	for _, command := range []string{`ls`, `git status`, `go test ./... | ls`} {
+		if err := policy.check(command); err != nil {
+			t.Errorf("check(%q) = %v, want allowed", command, err)
+		}
+	}
+	if err := policy.check(`git push origin main`); err == nil || !strings.Contains(err.Error(), `"git push"`) {
+		t.Errorf("check(git push) = %v, want the matching pattern named", err)
+	}
+	if err := policy.check(`ls; python3 -c 'print(1)'`); err == nil || !strings.Contains(err.Error(), `"python3" is not on the execute allowlist`) {
+		t.Errorf("check(python3) = %v, want the binary named", err)
+	}
+
+	// An empty blocklist blocks nothing, but protected paths still apply
+	open := ExecPolicy{Blocked: []string{}}
+	if err := open.check(`sudo ls`); err != nil {
+		t.Errorf("empty blocklist refused sudo: %v", err)
+	}
+	if err := open.check(`cat .aws/credentials`); err == nil {
+		t.Error("expected protected paths to apply with an empty blocklist")
+	}
+}
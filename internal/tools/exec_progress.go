@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+const (
+	// DefaultExecProgressInterval is how often a running execute call reports
+	DefaultExecProgressInterval = 30 * time.Second
+
+	// maxExecOutputBytes is how much command output execute returns
+	maxExecOutputBytes = 50000
+
+	// maxProgressLineBytes bounds the output line quoted in a progress update
+	maxProgressLineBytes = 200
+)
+
+// ExecProgress is a periodic update from an execute call that is still running
+type ExecProgress struct {
+	ProcessID string // Calling process; empty if the caller isn't a process
+	Command   string
+	Project   string
+	Elapsed   time.Duration
+	LastLine  string // Latest non-empty output line; empty for container runs
+}
+
+// String formats the update for a chat channel
+func (p ExecProgress) String() string {
+	msg := fmt.Sprintf("`%s` still running, %s elapsed",
+		textutil.Shorten(p.Command, 80), p.Elapsed.Round(time.Second))
+	if p.LastLine != "" {
+		msg += fmt.Sprintf(", last output line: %s", p.LastLine)
+	}
+	return msg
+}
+
+// SetExecProgress sets a sink that receives an update every interval while
+// an execute call runs, so long builds aren't an opaque multi-minute wait.
+// Zero interval uses DefaultExecProgressInterval; a nil sink disables it.
+func (pt *PersonaTools) SetExecProgress(sink func(ExecProgress), interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultExecProgressInterval
+	}
+	pt.execProgress = sink
+	pt.execProgressInterval = interval
+}
+
+// ExecProgressToChannel is a progress sink that posts updates to the Slack
+// channel the calling agent was spawned from
+func (pt *PersonaTools) ExecProgressToChannel(p ExecProgress) {
+	if p.ProcessID != "" {
+		pt.sendToProcessChannel(p.ProcessID, p.String())
+	}
+}
+
+// watchExec reports progress for a running command until stop is called or
+// ctx is done. lastLine may be nil when output isn't available until exit.
+func (pt *PersonaTools) watchExec(ctx context.Context, command, project string, lastLine func() string) (stop func()) {
+	sink, interval := pt.execProgress, pt.execProgressInterval
+	if sink == nil {
+		return func() {}
+	}
+
+	var processID string
+	if proc := vega.ProcessFromContext(ctx); proc != nil {
+		processID = proc.ID
+	}
+
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				p := ExecProgress{
+					ProcessID: processID,
+					Command:   command,
+					Project:   project,
+					Elapsed:   now.Sub(start),
+				}
+				if lastLine != nil {
+					p.LastLine = lastLine()
+				}
+				sink(p)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// execOutput collects a command's combined output as it runs, keeping the
+// most recent maxExecOutputBytes (the end of a build or test run is what
+// matters) and the latest non-empty line for progress updates
+type execOutput struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	dropped int    // Bytes of earlier output rolled off the front
+	line    []byte // Current unfinished line
+	last    string // Last complete non-empty line
+}
+
+func (o *execOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf.Write(p)
+	if over := o.buf.Len() - maxExecOutputBytes; over > 0 {
+		// Roll off whole lines where possible so the kept output starts cleanly
+		if i := bytes.IndexByte(o.buf.Bytes()[over:], '\n'); i >= 0 && i < maxProgressLineBytes*4 {
+			over += i + 1
+		}
+		o.buf.Next(over)
+		o.dropped += over
+	}
+
+	o.line = append(o.line, p...)
+	for {
+		i := bytes.IndexByte(o.line, '\n')
+		if i < 0 {
+			break
+		}
+		if line := cleanLine(o.line[:i]); line != "" {
+			o.last = line
+		}
+		o.line = o.line[i+1:]
+	}
+	// A line with no newline yet (a progress bar) only needs its tail
+	if over := len(o.line) - maxProgressLineBytes*4; over > 0 {
+		o.line = append([]byte(nil), o.line[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the collected output, noting how much was rolled off
+func (o *execOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.dropped > 0 {
+		return fmt.Sprintf("... (%d bytes of earlier output truncated)\n%s", o.dropped, o.buf.String())
+	}
+	return o.buf.String()
+}
+
+// LastLine returns the latest non-empty output line, shortened for a chat
+// message. An unfinished line counts, so in-place progress output shows.
+func (o *execOutput) LastLine() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	line := cleanLine(o.line)
+	if line == "" {
+		line = o.last
+	}
+	return textutil.Shorten(line, maxProgressLineBytes)
+}
+
+// cleanLine trims a line of output, keeping only what follows the last
+// carriage return since that's what a terminal would show
+func cleanLine(b []byte) string {
+	line := strings.TrimSpace(string(bytes.ToValidUTF8(b, nil)))
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = strings.TrimSpace(line[i+1:])
+	}
+	return line
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestExecOutputLastLine(t *testing.T) {
+	o := &execOutput{}
+	o.Write([]byte("building\n\n"))
+	if got := o.LastLine(); got != "building" {
+		t.Errorf("LastLine = %q, want the last non-empty line", got)
+	}
+	o.Write([]byte("step 1/3\r"))
+	o.Write([]byte("step 2/3"))
+	if got := o.LastLine(); got != "step 2/3" {
+		t.Errorf("LastLine = %q, want the in-place progress line", got)
+	}
+	o.Write([]byte("\nok\r\n"))
+	if got := o.LastLine(); got != "ok" {
+		t.Errorf("LastLine = %q, want ok", got)
+	}
+	if got := o.String(); got != "building\n\nstep 1/3\rstep 2/3\nok\r\n" {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestExecOutputKeepsTail(t *testing.T) {
+	o := &execOutput{}
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(o, "line %04d %s\n", i, strings.Repeat("x", 40))
+	}
+	got := o.String()
+	if !strings.HasPrefix(got, "... (") || !strings.Contains(got, "bytes of earlier output truncated)\nline ") {
+		t.Errorf("output should start with a truncation note then a whole line, got %q", got[:80])
+	}
+	if !strings.HasSuffix(got, "line 1999 "+strings.Repeat("x", 40)+"\n") {
+		t.Error("output lost its tail")
+	}
+	if o.buf.Len() > maxExecOutputBytes {
+		t.Errorf("kept %d bytes, want at most %d", o.buf.Len(), maxExecOutputBytes)
+	}
+}
+
+func TestExecuteReportsProgress(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+
+	var mu sync.Mutex
+	var updates []ExecProgress
+	pt.SetExecProgress(func(p ExecProgress) {
+		mu.Lock()
+		updates = append(updates, p)
+		mu.Unlock()
+	}, 20*time.Millisecond)
+
+	out, err := pt.execute(context.Background(), map[string]any{"command": "echo compiling; sleep 0.3; echo done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "done") {
+		t.Errorf("output = %q", out)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(updates) == 0 {
+		t.Fatal("expected progress updates while the command ran")
+	}
+	// A tick can land after "done" is printed but before the command exits,
+	// so look for an update from while it was sleeping
+	var mid *ExecProgress
+	for i := range updates {
+		if updates[i].LastLine == "compiling" {
+			mid = &updates[i]
+			break
+		}
+	}
+	if mid == nil || mid.Elapsed <= 0 {
+		t.Fatalf("no update showing the command mid-run: %+v", updates)
+	}
+	if msg := mid.String(); !strings.Contains(msg, "still running") || !strings.Contains(msg, "last output line: compiling") {
+		t.Errorf("update message = %q", msg)
+	}
+}
+
+func TestExecuteCancelKillsProcessGroup(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	// The child sleep holds the output pipe; killing only bash would leave
+	// the call waiting for it
+	start := time.Now()
+	_, err := pt.execute(ctx, map[string]any{"command": "sleep 30 & sleep 30; wait"})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Fatalf("err = %v, want a cancellation error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("execute took %s to return after cancel", elapsed)
+	}
+}
+
+func TestExecuteTimeoutKeepsPartialOutput(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+
+	start := time.Now()
+	_, err := pt.execute(context.Background(), map[string]any{"command": "echo step one; sleep 30", "timeout_seconds": 1})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1 seconds") || !strings.Contains(err.Error(), "step one") {
+		t.Fatalf("err = %v, want a timeout naming the wait and the partial output", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("execute took %s with a 1s timeout", elapsed)
+	}
+
+	// Requests past the cap are clamped rather than refused
+	out, err := pt.execute(context.Background(), map[string]any{"command": "echo ok", "timeout_seconds": 100000})
+	if err != nil || strings.TrimSpace(out) != "ok" {
+		t.Errorf("execute with an oversized timeout = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// execLineBuffer is how many output lines may wait for a slow reader
+	// before the command's writes block
+	execLineBuffer = 256
+
+	// maxExecLineBytes splits a line with no newline in sight, like a
+	// minified bundle, so it can't grow without bound
+	maxExecLineBytes = 16 << 10
+)
+
+// errExecTimedOut is returned by a streamed command that ran out of time
+var errExecTimedOut = errors.New("command timed out")
+
+// ExecLine is one line of output from a command run with ExecuteStream
+type ExecLine struct {
+	Stream string // "stdout" or "stderr"
+	Text   string // The line without its newline
+}
+
+// ExecuteStream runs a command where the execute tool would, sending its
+// output line by line. On the host lines arrive as they're printed; in a
+// project container they all arrive when the command exits, since that's
+// when Docker returns them. The channel is closed when the command exits;
+// wait then returns how it ended: nil, the exit error, or a timeout or
+// cancellation. Callers must drain the channel, since the command stalls
+// once execLineBuffer lines are waiting. A timeout of zero uses the execute
+// default; longer than maxExecTimeout is capped.
+func (pt *PersonaTools) ExecuteStream(ctx context.Context, command, project string, timeout time.Duration) (lines <-chan ExecLine, wait func() error) {
+	if err := pt.execPolicy.check(command); err != nil {
+		return closedExecStream(err)
+	}
+	if timeout <= 0 {
+		timeout = execTimeout
+	}
+	timeout = min(timeout, maxExecTimeout)
+
+	if project != "" && pt.containersConfigured() {
+		if pt.containersUp(ctx) {
+			return pt.streamInContainer(ctx, command, project, timeout)
+		}
+		if err := pt.checkHostFallback(project); err != nil {
+			return closedExecStream(err)
+		}
+	}
+	return pt.streamOnHost(ctx, command, project, timeout)
+}
+
+// closedExecStream is a stream for a command that never started
+func closedExecStream(err error) (<-chan ExecLine, func() error) {
+	lines := make(chan ExecLine)
+	close(lines)
+	return lines, func() error { return err }
+}
+
+// streamOnHost starts a command on the host in the caller's or project's
+// directory, streaming its output. The command's whole process group is
+// killed on timeout or cancellation.
+func (pt *PersonaTools) streamOnHost(ctx context.Context, command, project string, timeout time.Duration) (<-chan ExecLine, func() error) {
+	workDir := pt.workDirFor(ctx)
+	if project != "" {
+		workDir = pt.hostProjectDir(project)
+	}
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return closedExecStream(fmt.Errorf("failed to create working directory: %w", err))
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	cmd := exec.CommandContext(execCtx, "bash", "-c", command)
+	cmd.Dir = workDir
+	if project != "" {
+		env, err := projectEnviron(workDir)
+		if err != nil {
+			cancel()
+			return closedExecStream(err)
+		}
+		cmd.Env = env
+	}
+
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = execWaitDelay
+
+	lines := make(chan ExecLine, execLineBuffer)
+	stdout := &execLineWriter{stream: "stdout", lines: lines}
+	stderr := &execLineWriter{stream: "stderr", lines: lines}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return closedExecStream(fmt.Errorf("command failed: %v", err))
+	}
+
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		defer close(done)
+		defer cancel()
+
+		// Wait returns once the output copiers have finished, so nothing
+		// writes to lines after this
+		err := cmd.Wait()
+		stdout.flush()
+		stderr.flush()
+		close(lines)
+
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			err = fmt.Errorf("command cancelled: %w", ctx.Err())
+		case execCtx.Err() != nil:
+			err = fmt.Errorf("%w after %d seconds", errExecTimedOut, int(timeout.Seconds()))
+		}
+		runErr = err
+	}()
+
+	return lines, func() error {
+		<-done
+		return runErr
+	}
+}
+
+// streamInContainer runs a command in a project's container, streaming its
+// output once it exits. A non-zero exit is an error, as on the host.
+func (pt *PersonaTools) streamInContainer(ctx context.Context, command, project string, timeout time.Duration) (<-chan ExecLine, func() error) {
+	lines := make(chan ExecLine, execLineBuffer)
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		defer close(done)
+		defer close(lines)
+
+		stdout, stderr, exitCode, err := pt.runInContainer(ctx, project, command, timeout)
+		send := func(stream, output string) {
+			w := &execLineWriter{stream: stream, lines: lines}
+			w.Write([]byte(output))
+			w.flush()
+		}
+		send("stdout", stdout)
+		send("stderr", stderr)
+		if err == nil && exitCode != 0 {
+			err = fmt.Errorf("command exited with code %d", exitCode)
+		}
+		runErr = err
+	}()
+
+	return lines, func() error {
+		<-done
+		return runErr
+	}
+}
+
+// execLineWriter splits one of a command's output streams into lines
+type execLineWriter struct {
+	stream  string
+	lines   chan<- ExecLine
+	partial []byte // Output since the last newline
+}
+
+func (w *execLineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.send(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	for len(w.partial) > maxExecLineBytes {
+		// Cut before a rune that would straddle the limit
+		cut := maxExecLineBytes
+		for cut > maxExecLineBytes-utf8.UTFMax && !utf8.RuneStart(w.partial[cut]) {
+			cut--
+		}
+		w.send(w.partial[:cut])
+		w.partial = w.partial[cut:]
+	}
+	// Don't pin a large backing array for a short unfinished line
+	if cap(w.partial) > 2*maxExecLineBytes {
+		w.partial = append([]byte(nil), w.partial...)
+	}
+	return len(p), nil
+}
+
+// flush sends an unfinished last line
+func (w *execLineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.send(w.partial)
+		w.partial = nil
+	}
+}
+
+func (w *execLineWriter) send(b []byte) {
+	w.lines <- ExecLine{Stream: w.stream, Text: strings.TrimSuffix(string(b), "\r")}
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestExecuteStream(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(t.TempDir()))
+
+	// Lines arrive as they're printed, not when the command exits
+	start := time.Now()
+	lines, wait := pt.ExecuteStream(context.Background(), "echo one; echo oops >&2; sleep 0.5; printf two", "", 0)
+	first := <-lines
+	if first != (ExecLine{Stream: "stdout", Text: "one"}) {
+		t.Fatalf("first line = %+v", first)
+	}
+	if waited := time.Since(start); waited > 400*time.Millisecond {
+		t.Errorf("first line arrived after %s, want it before the command exits", waited)
+	}
+	var rest []ExecLine
+	for line := range lines {
+		rest = append(rest, line)
+	}
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 2 || rest[0] != (ExecLine{Stream: "stderr", Text: "oops"}) || rest[1] != (ExecLine{Stream: "stdout", Text: "two"}) {
+		t.Errorf("remaining lines = %+v", rest)
+	}
+
+	lines, wait = pt.ExecuteStream(context.Background(), "exit 3", "", 0)
+	for range lines {
+	}
+	var exitErr *exec.ExitError
+	if err := wait(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("wait() = %v, want exit status 3", err)
+	}
+
+	lines, wait = pt.ExecuteStream(context.Background(), "echo hi; sleep 10", "", time.Second)
+	for range lines {
+	}
+	if err := wait(); !errors.Is(err, errExecTimedOut) || !strings.Contains(err.Error(), "after 1 seconds") {
+		t.Errorf("wait() = %v, want a timeout", err)
+	}
+
+	// The execute blocklist applies
+	lines, wait = pt.ExecuteStream(context.Background(), "sudo ls", "", 0)
+	if _, ok := <-lines; ok {
+		t.Error("blocked command produced output")
+	}
+	if err := wait(); err == nil || !strings.Contains(err.Error(), "blocked command") {
+		t.Errorf("wait() = %v, want blocked", err)
+	}
+}
+
+func TestExecLineWriterSplitsOnRuneBoundary(t *testing.T) {
+	lines := make(chan ExecLine, 4)
+	w := &execLineWriter{stream: "stdout", lines: lines}
+
+	// A two-byte rune straddles the limit
+	long := strings.Repeat("a", maxExecLineBytes-1) + "é" + "tail"
+	w.Write([]byte(long))
+	w.flush()
+	close(lines)
+
+	var got []string
+	for line := range lines {
+		if !utf8.ValidString(line.Text) {
+			t.Errorf("line of %d bytes is not valid UTF-8", len(line.Text))
+		}
+		got = append(got, line.Text)
+	}
+	if strings.Join(got, "") != long || len(got) != 2 || len(got[0]) != maxExecLineBytes-1 {
+		t.Errorf("split into %d lines, want the rune moved whole to the second", len(got))
+	}
+}
+
+func TestExecStatusTellsTimeoutFromExit124(t *testing.T) {
+	if _, err := exec.LookPath("timeout"); err != nil {
+		t.Skip("timeout not installed")
+	}
+	run := func(command string) (string, bool, int) {
+		t.Helper()
+		argv := append([]string{"-k", "1", "1"}, withExecStatus([]string{"bash", "-c", command})...)
+		var stderr strings.Builder
+		cmd := exec.Command("timeout", argv...)
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			t.Fatal(err)
+		}
+		rest, ok := splitExecStatus(stderr.String())
+		return rest, ok, cmd.ProcessState.ExitCode()
+	}
+
+	// A command exiting 124 on its own reports its status
+	if rest, ok, code := run("echo oops >&2; exit 124"); !ok || code != timeoutExitCode || rest != "oops\n" {
+		t.Errorf("exit 124: stderr %q, marker %v, code %d", rest, ok, code)
+	}
+	// A killed one doesn't
+	if _, ok, code := run("sleep 5"); ok || code != timeoutExitCode {
+		t.Errorf("timed out: marker %v, code %d", ok, code)
+	}
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+// maxExportSize bounds the compressed size of a project export
+const maxExportSize = 100 << 20
+
+// exportTimeout bounds how long creating an export may take
+const exportTimeout = 5 * time.Minute
+
+// defaultExportExcludes are skipped unless include_all is set
+var defaultExportExcludes = []string{"node_modules", ".git"}
+
+// exportProject tars a project's workspace into the exports directory
+func (pt *PersonaTools) exportProject(ctx context.Context, params map[string]any) (string, error) {
+	project, _ := params["project"].(string)
+	includeAll, _ := params["include_all"].(bool)
+	excludeStr, _ := params["exclude"].(string)
+
+	project = sanitizeProjectName(strings.TrimSpace(project))
+	if project == "" {
+		return "", fmt.Errorf("project name is required")
+	}
+
+	var excludes []string
+	if !includeAll {
+		excludes = append(excludes, defaultExportExcludes...)
+	}
+	for _, pattern := range strings.Split(excludeStr, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if !isSafeExcludePattern(pattern) {
+			return "", fmt.Errorf("invalid exclude pattern %q", pattern)
+		}
+		excludes = append(excludes, pattern)
+	}
+
+	exportDir := filepath.Join(pt.workingDir, "exports")
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create exports directory: %w", err)
+	}
+	path := filepath.Join(exportDir, fmt.Sprintf("%s-%s.tar.gz", project, time.Now().Format("20060102-150405")))
+
+	execCtx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	var size int64
+	var err error
+	if pt.containersUp(ctx) {
+		size, err = pt.exportFromContainer(execCtx, project, excludes, path)
+	} else {
+		err = errDockerUnreachable
+	}
+	if errors.Is(err, errDockerUnreachable) {
+		// Reading the project's files needs no isolation, so no fallback policy
+		size, err = pt.exportFromHost(execCtx, project, excludes, path)
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	excluded := "nothing"
+	if len(excludes) > 0 {
+		excluded = strings.Join(excludes, ", ")
+	}
+	return fmt.Sprintf("Exported project '%s' to %s (%.1f MB, excluded: %s)",
+		project, path, float64(size)/(1<<20), excluded), nil
+}
+
+// exportFromContainer streams a tarball of /workspace out of the project container.
+// The archive is base64-encoded so binary data survives the exec output.
+func (pt *PersonaTools) exportFromContainer(ctx context.Context, project string, excludes []string, path string) (int64, error) {
+	result, err := pt.containers.Exec(ctx, project, []string{"bash", "-c", exportScript(excludes)}, "/workspace")
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("export timed out after %s", exportTimeout)
+		}
+		return 0, fmt.Errorf("container exec failed: %w", pt.checkContainerErr(err))
+	}
+
+	data, err := decodeExport(result.Stdout, result.Stderr, result.ExitCode)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := persist.WriteFile(path, data); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+	return int64(len(data)), nil
+}
+
+// exportScript tars /workspace to stdout as base64, cut off just past the
+// size limit. pipefail makes a tar failure the script's exit status, so a
+// truncated archive isn't mistaken for a whole one.
+func exportScript(excludes []string) string {
+	return fmt.Sprintf("set -o pipefail; tar -czf - %s . | head -c %d | base64 -w0", tarExcludeArgs(excludes), maxExportSize+1)
+}
+
+// decodeExport checks the output of exportScript and returns the archive
+func decodeExport(stdout, stderr string, exitCode int) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stdout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode export: %w", err)
+	}
+	// head closing the pipe early fails tar too, so check the size first
+	if len(data) > maxExportSize {
+		return nil, fmt.Errorf("export exceeds %d MB limit, exclude more paths", maxExportSize>>20)
+	}
+	// GNU tar exits 1 when a file changed while it was read; the archive
+	// is still complete
+	if exitCode > 1 {
+		return nil, fmt.Errorf("export failed (exit code %d): %s", exitCode, strings.TrimSpace(stderr))
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("export produced no data: %s", strings.TrimSpace(stderr))
+	}
+	return data, nil
+}
+
+// exportFromHost tars the project directory on the host
+func (pt *PersonaTools) exportFromHost(ctx context.Context, project string, excludes []string, path string) (int64, error) {
+	projectDir := pt.hostProjectDir(project)
+	if _, err := os.Stat(projectDir); err != nil {
+		return 0, fmt.Errorf("project %s not found", project)
+	}
+
+	args := []string{"-czf", "-"}
+	for _, pattern := range excludes {
+		args = append(args, "--exclude="+pattern)
+	}
+	args = append(args, ".")
+
+	cmd := exec.CommandContext(ctx, "tar", args...)
+	cmd.Dir = projectDir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start tar: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start tar: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	size, copyErr := io.Copy(f, io.LimitReader(stdout, maxExportSize+1))
+	if size > maxExportSize {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, fmt.Errorf("export exceeds %d MB limit, exclude more paths", maxExportSize>>20)
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("export timed out after %s", exportTimeout)
+		}
+		return 0, fmt.Errorf("tar failed: %w", err)
+	}
+	if copyErr != nil {
+		return 0, fmt.Errorf("failed to write export: %w", copyErr)
+	}
+	return size, nil
+}
+
+// tarExcludeArgs renders exclude patterns as single-quoted tar flags
+func tarExcludeArgs(excludes []string) string {
+	args := make([]string, len(excludes))
+	for i, pattern := range excludes {
+		args[i] = fmt.Sprintf("--exclude='%s'", pattern)
+	}
+	return strings.Join(args, " ")
+}
+
+// isSafeExcludePattern allows path globs but nothing that could escape shell quoting
+func isSafeExcludePattern(pattern string) bool {
+	for _, r := range pattern {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("._-/*?", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
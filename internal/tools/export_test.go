@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestExportProjectFromHost(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	workDir := t.TempDir()
+	projectDir := filepath.Join(workDir, "projects", "demo")
+	for _, f := range []string{"main.go", "node_modules/pkg/index.js", ".git/HEAD", "build.log"} {
+		path := filepath.Join(projectDir, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+	}
+
+	pt := NewPersonaTools(orch, createTestConfig(), workDir, t.TempDir(), nil)
+
+	result, err := pt.exportProject(context.Background(), map[string]any{"project": "demo", "exclude": "*.log"})
+	if err != nil {
+		t.Fatalf("exportProject failed: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(workDir, "exports", "demo-*.tar.gz"))
+	if len(matches) != 1 || !strings.Contains(result, matches[0]) {
+		t.Fatalf("expected export path in result, got %q (files %v)", result, matches)
+	}
+
+	f, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	joined := strings.Join(names, " ")
+	if !strings.Contains(joined, "main.go") {
+		t.Errorf("export missing main.go: %v", names)
+	}
+	for _, junk := range []string{"node_modules", ".git/", "build.log"} {
+		if strings.Contains(joined, junk) {
+			t.Errorf("export should exclude %s: %v", junk, names)
+		}
+	}
+}
+
+func TestExportProjectRejectsUnsafeExclude(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), t.TempDir(), t.TempDir(), nil)
+	if _, err := pt.exportProject(context.Background(), map[string]any{"project": "demo", "exclude": "x'; rm -rf ~"}); err == nil {
+		t.Error("expected unsafe exclude pattern to be rejected")
+	}
+}
+
+func TestExportScriptReportsTarFailure(t *testing.T) {
+	// A tar that dies partway through still leaves some output
+	bin := t.TempDir()
+	fake := "#!/bin/sh\nprintf partial\necho 'tar: ./data: Cannot open: Permission denied' >&2\nexit 2\n"
+	if err := os.WriteFile(filepath.Join(bin, "tar"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("bash", "-c", exportScript(nil))
+	cmd.Dir = t.TempDir()
+	cmd.Env = append(os.Environ(), "PATH="+bin+":"+os.Getenv("PATH"))
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Run()
+
+	_, err := decodeExport(stdout.String(), stderr.String(), cmd.ProcessState.ExitCode())
+	if err == nil || !strings.Contains(err.Error(), "exit code 2") || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("decodeExport() error = %v, want the tar failure", err)
+	}
+}
@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+const (
+	// fetchTimeout bounds a whole fetch, redirects included
+	fetchTimeout = 30 * time.Second
+
+	// maxFetchBodyBytes is the most of a response body that's read
+	maxFetchBodyBytes = 2 << 20
+
+	// maxFetchRedirects is how many redirects a fetch follows
+	maxFetchRedirects = 5
+
+	// Bounds on how much page text fetch_url returns
+	defaultFetchLength = 10000
+	maxFetchLength     = 50000
+)
+
+// errBlockedAddress is returned for URLs that resolve to internal addresses
+var errBlockedAddress = httpclient.ErrBlockedAddress
+
+// blockedAddr reports whether fetch_url must not connect to addr. Replaced
+// in tests, which fetch from a loopback server.
+var blockedAddr = httpclient.BlockedAddr
+
+// parseFetchURL checks that raw is an absolute http or https URL
+func parseFetchURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q (use http or https)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("URL %q has no host", raw)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("URLs with credentials are not allowed")
+	}
+	return u, nil
+}
+
+// checkFetchHost resolves host and fails if any of its addresses are blocked
+func checkFetchHost(ctx context.Context, host string) error {
+	return httpclient.CheckHost(ctx, host, blockedAddr)
+}
+
+// newFetchClient returns a client for fetching u. Every redirect is checked
+// like the original URL, and connections are checked as they're dialed
+// unless a proxy makes them (see httpclient.GuardedTransport).
+func newFetchClient(u *url.URL) *http.Client {
+	client := httpclient.New(fetchTimeout)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxFetchRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+		}
+		if _, err := parseFetchURL(req.URL.String()); err != nil {
+			return err
+		}
+		return checkFetchHost(req.Context(), req.URL.Hostname())
+	}
+	client.Transport = httpclient.GuardedTransport(u, blockedAddr)
+	return client
+}
+
+// fetchURL fetches a web page and returns its readable text
+func (pt *PersonaTools) fetchURL(ctx context.Context, params map[string]any) (string, error) {
+	raw, _ := params["url"].(string)
+	if raw == "" {
+		return "", fmt.Errorf("url is required")
+	}
+	maxLength := intParam(params, "max_length", defaultFetchLength, 100, maxFetchLength)
+
+	u, err := parseFetchURL(raw)
+	if err != nil {
+		return "", err
+	}
+	if err := checkFetchHost(ctx, u.Hostname()); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+	req.Header.Set("User-Agent", "Tron/1.0 (+fetch_url)")
+
+	resp, err := newFetchClient(u).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch returned status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	isHTML := mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	if !isHTML && !strings.HasPrefix(mediaType, "text/") && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+xml") {
+		return "", fmt.Errorf("can't read %s content", mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBodyBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var title, text string
+	if isHTML {
+		title, text = extractText(string(body))
+	} else {
+		text = strings.TrimSpace(string(body))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "URL: %s\n", resp.Request.URL)
+	if title != "" {
+		fmt.Fprintf(&sb, "Title: %s\n", title)
+	}
+	sb.WriteString("\n")
+	if text == "" {
+		sb.WriteString("(no readable text)")
+	} else if len(text) > maxLength {
+		sb.WriteString(textutil.Truncate(text, maxLength))
+		fmt.Fprintf(&sb, "\n\n[truncated: showing %d of %d bytes]", maxLength, len(text))
+	} else {
+		sb.WriteString(text)
+	}
+	return sb.String(), nil
+}
+
+var (
+	// Elements whose content is never readable text
+	skipElementRes = skipElements("head", "title", "script", "style", "noscript", "svg", "template", "iframe")
+	commentRe      = regexp.MustCompile(`(?s)<!--.*?-->`)
+	titleRe        = regexp.MustCompile(`(?is)<title\b[^>]*>(.*?)</title\s*>`)
+
+	// Tags that start a new line of text
+	blockTagRe = regexp.MustCompile(`(?i)</?(p|div|br|hr|li|ul|ol|tr|table|section|article|header|footer|nav|aside|main|blockquote|pre|h[1-6])\b[^>]*>`)
+	tagRe      = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRe    = regexp.MustCompile(`[ \t\r\f\v\x{00a0}]+`)
+)
+
+// skipElements returns patterns matching each named element and its content
+func skipElements(names ...string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(names))
+	for i, name := range names {
+		res[i] = regexp.MustCompile(`(?is)<` + name + `\b.*?</` + name + `\s*>`)
+	}
+	return res
+}
+
+// extractText pulls the title and readable text out of an HTML page. It's
+// deliberately simple: scripts, styles and comments are dropped, block
+// elements become line breaks, every other tag is removed, and blank lines
+// are dropped.
+func extractText(page string) (title, text string) {
+	if m := titleRe.FindStringSubmatch(page); m != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(tagRe.ReplaceAllString(m[1], ""))), " ")
+	}
+
+	page = commentRe.ReplaceAllString(page, "")
+	for _, re := range skipElementRes {
+		page = re.ReplaceAllString(page, "")
+	}
+	page = blockTagRe.ReplaceAllString(page, "\n")
+	page = tagRe.ReplaceAllString(page, "")
+	page = html.UnescapeString(page)
+
+	var lines []string
+	for _, line := range strings.Split(page, "\n") {
+		if line = strings.TrimSpace(spaceRe.ReplaceAllString(line, " ")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return title, strings.Join(lines, "\n")
+}
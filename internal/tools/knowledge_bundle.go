@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+// knowledgeBundleVersion is the format version written by ExportKnowledge
+const knowledgeBundleVersion = 1
+
+// knowledgeExportLimit is the most entries one export queries for
+const knowledgeExportLimit = 1_000_000
+
+// KnowledgeImportMode is how ImportKnowledge treats the existing entries
+type KnowledgeImportMode string
+
+const (
+	// KnowledgeImportMerge adds new entries and keeps the existing ones
+	KnowledgeImportMerge KnowledgeImportMode = "merge"
+	// KnowledgeImportReplace swaps the existing entries for the bundle's
+	KnowledgeImportReplace KnowledgeImportMode = "replace"
+)
+
+// KnowledgeBundle is a portable export of the knowledge base
+type KnowledgeBundle struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Entries    []KnowledgeBundleEntry `json:"entries"`
+}
+
+// KnowledgeBundleEntry is one knowledge entry in a bundle
+type KnowledgeBundleEntry struct {
+	ID        string    `json:"id,omitempty"`
+	Type      string    `json:"type"`
+	Domain    string    `json:"domain"`
+	Author    string    `json:"author"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags,omitempty"`
+	ProcessID string    `json:"process_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// KnowledgeImportResult is what happened to one bundle entry
+type KnowledgeImportResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"` // "imported", "duplicate", "invalid", or "failed"
+	Reason string `json:"reason,omitempty"`
+}
+
+// KnowledgeImportReport summarizes an import
+type KnowledgeImportReport struct {
+	Imported   int                     `json:"imported"`
+	Duplicates int                     `json:"duplicates"`
+	Invalid    int                     `json:"invalid"`
+	Failed     int                     `json:"failed"`
+	Results    []KnowledgeImportResult `json:"results"`
+}
+
+// validKnowledgeTypes and validKnowledgeDomains are the values accepted on import
+var (
+	validKnowledgeTypes = map[knowledge.EntryType]bool{
+		knowledge.TypeDiscovery: true, knowledge.TypeInsight: true, knowledge.TypeDecision: true,
+		knowledge.TypeTaskResult: true, knowledge.TypeResource: true,
+	}
+	validKnowledgeDomains = map[knowledge.Domain]bool{
+		knowledge.DomainTech: true, knowledge.DomainMarketing: true, knowledge.DomainFinance: true,
+		knowledge.DomainOps: true, knowledge.DomainProduct: true, knowledge.DomainGeneral: true,
+	}
+)
+
+// ExportKnowledge writes every knowledge entry to w as a JSON bundle
+func (pt *PersonaTools) ExportKnowledge(w io.Writer) error {
+	store := pt.currentKnowledgeStore()
+	if store == nil {
+		return fmt.Errorf("knowledge store not available")
+	}
+
+	entries := store.Query(knowledge.QueryOptions{Limit: knowledgeExportLimit})
+	bundle := KnowledgeBundle{
+		Version:    knowledgeBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Entries:    make([]KnowledgeBundleEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		be := KnowledgeBundleEntry{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			Domain:    string(e.Domain),
+			Author:    e.Author,
+			Title:     e.Title,
+			Content:   e.Content,
+			Tags:      e.Tags,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.Source != nil {
+			be.ProcessID = e.Source.ProcessID
+		}
+		bundle.Entries = append(bundle.Entries, be)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+// ImportKnowledge reads a bundle written by ExportKnowledge. In merge mode
+// its entries are added, skipping any whose ID or title and content already
+// exist; in replace mode the store is rebuilt from the bundle's valid entries
+// in one step, so a failure leaves the old entries in place. Each entry's
+// outcome is in the report; an error means nothing was imported.
+func (pt *PersonaTools) ImportKnowledge(r io.Reader, mode KnowledgeImportMode) (*KnowledgeImportReport, error) {
+	switch mode {
+	case "":
+		mode = KnowledgeImportMerge
+	case KnowledgeImportMerge, KnowledgeImportReplace:
+	default:
+		return nil, fmt.Errorf("unsupported import mode %q: use merge or replace", mode)
+	}
+
+	var bundle KnowledgeBundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid knowledge bundle: %w", err)
+	}
+	if bundle.Version > knowledgeBundleVersion {
+		return nil, fmt.Errorf("knowledge bundle version %d is newer than this server supports (%d)", bundle.Version, knowledgeBundleVersion)
+	}
+
+	// Held for writing so compaction can't swap the store mid-import
+	pt.knowledgeMu.Lock()
+	defer pt.knowledgeMu.Unlock()
+	if pt.knowledgeStore == nil {
+		return nil, fmt.Errorf("knowledge store not available")
+	}
+
+	ids := make(map[string]bool)
+	fingerprints := make(map[string]bool)
+	if mode == KnowledgeImportMerge {
+		for _, e := range pt.knowledgeStore.Query(knowledge.QueryOptions{Limit: knowledgeScanLimit}) {
+			if e.ID != "" {
+				ids[e.ID] = true
+			}
+			fingerprints[knowledgeFingerprint(e.Title, e.Content)] = true
+		}
+	}
+
+	var replacement []knowledge.Entry
+	report := &KnowledgeImportReport{Results: make([]KnowledgeImportResult, 0, len(bundle.Entries))}
+	for i, be := range bundle.Entries {
+		result := KnowledgeImportResult{Index: i, ID: be.ID, Title: be.Title}
+		entry, err := be.toEntry()
+		fingerprint := knowledgeFingerprint(entry.Title, entry.Content)
+
+		switch {
+		case err != nil:
+			result.Status, result.Reason = "invalid", err.Error()
+			report.Invalid++
+		case entry.ID != "" && ids[entry.ID]:
+			result.Status, result.Reason = "duplicate", "an entry with this ID exists"
+			report.Duplicates++
+		case fingerprints[fingerprint]:
+			result.Status, result.Reason = "duplicate", "an entry with the same title and content exists"
+			report.Duplicates++
+		default:
+			if mode == KnowledgeImportReplace {
+				replacement = append(replacement, entry)
+			} else if err := pt.knowledgeStore.Add(entry); err != nil {
+				result.Status, result.Reason = "failed", err.Error()
+				report.Failed++
+				break
+			}
+			result.Status = "imported"
+			report.Imported++
+			if entry.ID != "" {
+				ids[entry.ID] = true
+			}
+			fingerprints[fingerprint] = true
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if mode == KnowledgeImportReplace {
+		if err := pt.rebuildKnowledgeStore(replacement); err != nil {
+			return nil, fmt.Errorf("failed to replace knowledge: %w", err)
+		}
+	}
+
+	pt.logger.Infof("Knowledge import (%s): %d imported, %d duplicates, %d invalid, %d failed",
+		mode, report.Imported, report.Duplicates, report.Invalid, report.Failed)
+	return report, nil
+}
+
+// toEntry validates a bundle entry and converts it to a knowledge entry
+func (be KnowledgeBundleEntry) toEntry() (knowledge.Entry, error) {
+	entry := knowledge.Entry{
+		ID:        strings.TrimSpace(be.ID),
+		Type:      knowledge.EntryType(strings.ToLower(strings.TrimSpace(be.Type))),
+		Domain:    knowledge.Domain(strings.ToLower(strings.TrimSpace(be.Domain))),
+		Author:    strings.TrimSpace(be.Author),
+		Title:     strings.TrimSpace(be.Title),
+		Content:   be.Content,
+		Tags:      be.Tags,
+		CreatedAt: be.CreatedAt,
+	}
+	if be.ProcessID != "" {
+		entry.Source = &knowledge.Source{ProcessID: be.ProcessID}
+	}
+
+	var missing []string
+	if entry.Title == "" {
+		missing = append(missing, "title")
+	}
+	if strings.TrimSpace(entry.Content) == "" {
+		missing = append(missing, "content")
+	}
+	if entry.Author == "" {
+		missing = append(missing, "author")
+	}
+	if len(missing) > 0 {
+		return entry, fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	if !validKnowledgeTypes[entry.Type] {
+		return entry, fmt.Errorf("unknown type %q", be.Type)
+	}
+	if entry.Domain == "" {
+		entry.Domain = knowledge.DomainGeneral
+	} else if !validKnowledgeDomains[entry.Domain] {
+		return entry, fmt.Errorf("unknown domain %q", be.Domain)
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	return entry, nil
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func TestKnowledgeBundleEntryValidation(t *testing.T) {
+	valid := KnowledgeBundleEntry{Type: "Decision", Author: "Gary", Title: "Use Postgres", Content: "We picked Postgres."}
+	entry, err := valid.toEntry()
+	if err != nil {
+		t.Fatalf("valid entry rejected: %v", err)
+	}
+	if entry.Type != knowledge.TypeDecision || entry.Domain != knowledge.DomainGeneral || entry.CreatedAt.IsZero() {
+		t.Errorf("entry = %+v, want decision in general with a creation time", entry)
+	}
+
+	tests := []struct {
+		name  string
+		entry KnowledgeBundleEntry
+		want  string
+	}{
+		{"missing fields", KnowledgeBundleEntry{Type: "insight"}, "missing title, content, author"},
+		{"bad type", KnowledgeBundleEntry{Type: "rumor", Author: "Gary", Title: "t", Content: "c"}, `unknown type "rumor"`},
+		{"bad domain", KnowledgeBundleEntry{Type: "insight", Domain: "legal", Author: "Gary", Title: "t", Content: "c"}, `unknown domain "legal"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.entry.toEntry()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportKnowledgeRejectsBadInput(t *testing.T) {
+	pt := &PersonaTools{knowledgeStore: &knowledge.Store{}}
+
+	if _, err := pt.ImportKnowledge(strings.NewReader(`{"entries":[]}`), "overwrite"); err == nil || !strings.Contains(err.Error(), "unsupported import mode") {
+		t.Errorf("err = %v, want unsupported import mode", err)
+	}
+	if _, err := pt.ImportKnowledge(strings.NewReader(`not json`), KnowledgeImportMerge); err == nil {
+		t.Error("expected a malformed bundle to be rejected")
+	}
+	if _, err := pt.ImportKnowledge(strings.NewReader(`{"version":99}`), KnowledgeImportMerge); err == nil {
+		t.Error("expected a newer bundle version to be rejected")
+	}
+}
+
+func TestImportKnowledgeReplace(t *testing.T) {
+	dir := t.TempDir()
+	store, err := knowledge.NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	pt := &PersonaTools{tronDir: dir, knowledgeStore: store, logger: logging.Discard()}
+	if err := store.Add(knowledge.Entry{ID: "old", Type: knowledge.TypeInsight, Domain: knowledge.DomainGeneral,
+		Author: "Gary", Title: "Old", Content: "Stale", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	bundle := `{"version":1,"entries":[
+		{"id":"new","type":"decision","author":"Maya","title":"New","content":"Fresh"},
+		{"id":"copy","type":"decision","author":"Maya","title":"New","content":"Fresh"},
+		{"type":"rumor","author":"Maya","title":"Bad","content":"Nope"}]}`
+	report, err := pt.ImportKnowledge(strings.NewReader(bundle), KnowledgeImportReplace)
+	if err != nil {
+		t.Fatalf("ImportKnowledge: %v", err)
+	}
+	if report.Imported != 1 || report.Duplicates != 1 || report.Invalid != 1 {
+		t.Errorf("report = %+v, want 1 imported, 1 duplicate, 1 invalid", report)
+	}
+
+	// Only the bundle's entry is left, in memory and on disk
+	reloaded, err := knowledge.NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	for _, s := range []*knowledge.Store{pt.currentKnowledgeStore(), reloaded} {
+		if got := entryIDs(s.Query(knowledge.QueryOptions{Limit: 10})); !reflect.DeepEqual(got, []string{"new"}) {
+			t.Errorf("store = %v, want [new]", got)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+const (
+	// DefaultKnowledgeDedupWindow is how far back share_knowledge looks for
+	// an identical entry from the same author
+	DefaultKnowledgeDedupWindow = 24 * time.Hour
+
+	// knowledgeDedupScan caps how many of the author's entries are checked
+	knowledgeDedupScan = 100
+)
+
+// SetKnowledgeDedupWindow sets how long an identical share_knowledge entry
+// is treated as a duplicate. Zero disables dedup.
+func (pt *PersonaTools) SetKnowledgeDedupWindow(window time.Duration) {
+	pt.knowledgeDedupWindow = window
+}
+
+// knowledgeFingerprint hashes an entry's title and content, ignoring case,
+// punctuation and whitespace so trivially reworded repeats still match
+func knowledgeFingerprint(title, content string) string {
+	normalize := func(s string) string {
+		s = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return ' '
+		}, s)
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	sum := sha256.Sum256([]byte(normalize(title) + "\n" + normalize(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// findDuplicateKnowledge returns a recent entry by the same author with the
+// same fingerprint as entry, if any
+func (pt *PersonaTools) findDuplicateKnowledge(entry knowledge.Entry, now time.Time) (knowledge.Entry, bool) {
+	if pt.knowledgeDedupWindow <= 0 {
+		return knowledge.Entry{}, false
+	}
+
+	fingerprint := knowledgeFingerprint(entry.Title, entry.Content)
+	cutoff := now.Add(-pt.knowledgeDedupWindow)
+	recent := pt.currentKnowledgeStore().Query(knowledge.QueryOptions{
+		Author: entry.Author,
+		Type:   entry.Type,
+		Limit:  knowledgeDedupScan,
+	})
+	for _, e := range recent {
+		if e.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if knowledgeFingerprint(e.Title, e.Content) == fingerprint {
+			return e, true
+		}
+	}
+	return knowledge.Entry{}, false
+}
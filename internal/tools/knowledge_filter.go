@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+// knowledgeFilter narrows a knowledge query by authors, types, and domains:
+// any value within a field matches (OR), and every non-empty field must
+// match (AND). The store's QueryOptions only take one value per field, so
+// single values are pushed down to the store and lists are applied here,
+// over a scan of the whole store so older matches aren't cut off.
+type knowledgeFilter struct {
+	authors []string
+	types   []string // Lowercased
+	domains []string // Lowercased
+}
+
+// newKnowledgeFilter parses comma-separated author, type, and domain params
+func newKnowledgeFilter(authors, types, domains string) knowledgeFilter {
+	return knowledgeFilter{
+		authors: splitList(authors),
+		types:   splitList(strings.ToLower(types)),
+		domains: splitList(strings.ToLower(domains)),
+	}
+}
+
+// narrows reports whether the filter needs to be applied after the query,
+// i.e. some field has more than one value
+func (f knowledgeFilter) narrows() bool {
+	return len(f.authors) > 1 || len(f.types) > 1 || len(f.domains) > 1
+}
+
+// apply sets the single-valued fields of opts
+func (f knowledgeFilter) apply(opts *knowledge.QueryOptions) {
+	if len(f.authors) == 1 {
+		opts.Author = f.authors[0]
+	}
+	if len(f.types) == 1 {
+		opts.Type = knowledge.EntryType(f.types[0])
+	}
+	if len(f.domains) == 1 {
+		opts.Domain = knowledge.Domain(f.domains[0])
+	}
+}
+
+// match reports whether e satisfies every non-empty field
+func (f knowledgeFilter) match(e knowledge.Entry) bool {
+	if len(f.authors) > 0 && !containsFold(f.authors, e.Author) {
+		return false
+	}
+	if len(f.types) > 0 && !containsFold(f.types, string(e.Type)) {
+		return false
+	}
+	if len(f.domains) > 0 && !containsFold(f.domains, string(e.Domain)) {
+		return false
+	}
+	return true
+}
+
+// filter keeps the entries that match, in order
+func (f knowledgeFilter) filter(entries []knowledge.Entry) []knowledge.Entry {
+	var kept []knowledge.Entry
+	for _, e := range entries {
+		if f.match(e) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// splitList splits a comma-separated list, dropping blanks
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
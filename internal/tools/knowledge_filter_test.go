@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func TestKnowledgeFilter(t *testing.T) {
+	entries := []knowledge.Entry{
+		{Title: "gary-decision", Author: "Gary", Type: knowledge.TypeDecision, Domain: knowledge.DomainTech},
+		{Title: "sarah-discovery", Author: "Sarah", Type: knowledge.TypeDiscovery, Domain: knowledge.DomainMarketing},
+		{Title: "sarah-resource", Author: "Sarah", Type: knowledge.TypeResource, Domain: knowledge.DomainTech},
+		{Title: "maya-decision", Author: "Maya", Type: knowledge.TypeDecision, Domain: knowledge.DomainTech},
+	}
+
+	f := newKnowledgeFilter("gary, Sarah", "Decision,discovery", "")
+	if !f.narrows() {
+		t.Fatal("expected a multi-value filter to narrow")
+	}
+	got := f.filter(entries)
+	if len(got) != 2 || got[0].Title != "gary-decision" || got[1].Title != "sarah-discovery" {
+		t.Errorf("filter = %v, want gary-decision and sarah-discovery", got)
+	}
+
+	// Single values go to the store unchanged
+	single := newKnowledgeFilter("Gary", "Decision", "tech")
+	if single.narrows() {
+		t.Error("single values should not need post-filtering")
+	}
+	var opts knowledge.QueryOptions
+	single.apply(&opts)
+	if opts.Author != "Gary" || opts.Type != knowledge.TypeDecision || opts.Domain != knowledge.DomainTech {
+		t.Errorf("opts = %+v", opts)
+	}
+
+	if got := newKnowledgeFilter("", "", "").filter(entries); len(got) != len(entries) {
+		t.Errorf("empty filter kept %d of %d entries", len(got), len(entries))
+	}
+}
+
+func TestQueryKnowledgeListFindsOlderMatches(t *testing.T) {
+	dir := t.TempDir()
+	store, err := knowledge.NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	pt := &PersonaTools{tronDir: dir, knowledgeStore: store, logger: logging.Discard()}
+
+	// The only match is older than the limit*scopedQueryOverfetch newest entries
+	now := time.Now()
+	maya := retentionEntry("maya", 48*time.Hour, now)
+	maya.Author, maya.Title = "Maya", "Pricing page notes"
+	if err := store.Add(maya); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	for i := range 10 {
+		if err := store.Add(retentionEntry(fmt.Sprintf("gary-%d", i), time.Duration(i)*time.Minute, now)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	out, err := pt.queryKnowledge(context.Background(), map[string]any{"author": "Maya,Alex", "limit": 1, "decay": false})
+	if err != nil {
+		t.Fatalf("queryKnowledge: %v", err)
+	}
+	if !strings.Contains(out, "Pricing page notes") {
+		t.Errorf("query = %q, want Maya's older entry", out)
+	}
+}
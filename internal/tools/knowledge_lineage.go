@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+// recordSpawnParent remembers which process spawned a child, so lineage
+// queries still find the child after it leaves the live spawn tree
+func (pt *PersonaTools) recordSpawnParent(childID, parentID string) {
+	pt.spawnParentsMu.Lock()
+	defer pt.spawnParentsMu.Unlock()
+	if pt.spawnParents == nil {
+		pt.spawnParents = make(map[string]string)
+	}
+	pt.spawnParents[childID] = parentID
+}
+
+// forgetSpawnParents drops recorded spawns no lineage query can reach any
+// more. Queries come from running processes, so once every ancestor of a
+// process has finished nobody can ask for it as a descendant. finishedID
+// has just finished and counts as gone even if the orchestrator still has it.
+func (pt *PersonaTools) forgetSpawnParents(finishedID string) {
+	running := func(id string) bool {
+		if id == finishedID {
+			return false
+		}
+		p := pt.orch.Get(id)
+		if p == nil {
+			return false
+		}
+		status := p.Status()
+		return status == vega.StatusRunning || status == vega.StatusPending
+	}
+
+	pt.spawnParentsMu.Lock()
+	defer pt.spawnParentsMu.Unlock()
+
+	var unreachable []string
+	for child := range pt.spawnParents {
+		reachable := false
+		seen := map[string]bool{}
+		for id := pt.spawnParents[child]; id != "" && !seen[id]; id = pt.spawnParents[id] {
+			seen[id] = true
+			if running(id) {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			unreachable = append(unreachable, child)
+		}
+	}
+	for _, child := range unreachable {
+		delete(pt.spawnParents, child)
+	}
+}
+
+// processLineage returns rootID and, if descendants is set, every process
+// spawned beneath it
+func (pt *PersonaTools) processLineage(rootID string, descendants bool) map[string]bool {
+	lineage := map[string]bool{rootID: true}
+	if descendants {
+		for _, id := range pt.processDescendants(rootID) {
+			lineage[id] = true
+		}
+	}
+	return lineage
+}
+
+// processDescendants returns every process spawned beneath rootID,
+// shallowest first, from both recorded spawns and the live spawn tree
+func (pt *PersonaTools) processDescendants(rootID string) []string {
+	children := make(map[string][]string)
+	pt.spawnParentsMu.RLock()
+	for child, parent := range pt.spawnParents {
+		children[parent] = append(children[parent], child)
+	}
+	pt.spawnParentsMu.RUnlock()
+
+	var walk func(nodes []*SpawnNode)
+	walk = func(nodes []*SpawnNode) {
+		for _, n := range nodes {
+			for _, c := range n.Children {
+				children[n.ProcessID] = append(children[n.ProcessID], c.ProcessID)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(pt.SpawnTree(rootID))
+
+	var descendants []string
+	seen := map[string]bool{rootID: true}
+	queue := []string{rootID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range children[id] {
+			if !seen[child] {
+				seen[child] = true
+				descendants = append(descendants, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return descendants
+}
+
+// filterByLineage keeps entries whose source process is in lineage
+func filterByLineage(entries []knowledge.Entry, lineage map[string]bool) []knowledge.Entry {
+	var kept []knowledge.Entry
+	for _, e := range entries {
+		if e.Source != nil && lineage[e.Source.ProcessID] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// processIDParam resolves a process ID tool param, where "self" means the
+// calling process
+func processIDParam(ctx context.Context, id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id != "self" {
+		return id, nil
+	}
+	proc := vega.ProcessFromContext(ctx)
+	if proc == nil {
+		return "", fmt.Errorf("no calling process for \"self\"")
+	}
+	return proc.ID, nil
+}
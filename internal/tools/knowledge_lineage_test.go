@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+func TestProcessLineage(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	pt.recordSpawnParent("child-1", "root")
+	pt.recordSpawnParent("child-2", "root")
+	pt.recordSpawnParent("grandchild", "child-1")
+	pt.recordSpawnParent("other", "elsewhere")
+
+	all := pt.processLineage("root", true)
+	for _, id := range []string{"root", "child-1", "child-2", "grandchild"} {
+		if !all[id] {
+			t.Errorf("lineage missing %s: %v", id, all)
+		}
+	}
+	if all["other"] || len(all) != 4 {
+		t.Errorf("lineage = %v, want root and its three descendants", all)
+	}
+
+	if only := pt.processLineage("root", false); len(only) != 1 || !only["root"] {
+		t.Errorf("lineage without children = %v, want just root", only)
+	}
+
+	entries := []knowledge.Entry{
+		{Title: "from-root", Source: &knowledge.Source{ProcessID: "root"}},
+		{Title: "from-grandchild", Source: &knowledge.Source{ProcessID: "grandchild"}},
+		{Title: "from-other", Source: &knowledge.Source{ProcessID: "other"}},
+		{Title: "no-source"},
+	}
+	got := filterByLineage(entries, all)
+	if len(got) != 2 || got[0].Title != "from-root" || got[1].Title != "from-grandchild" {
+		t.Errorf("filterByLineage = %v, want from-root and from-grandchild", got)
+	}
+}
+
+func TestForgetSpawnParents(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	root, err := orch.Spawn(vega.Agent{Name: "Tony", Tools: vega.NewTools()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// child and grandchild have finished, but root can still ask about them
+	pt.recordSpawnParent("child", root.ID)
+	pt.recordSpawnParent("grandchild", "child")
+	// Nobody running is above these
+	pt.recordSpawnParent("orphan", "gone")
+
+	pt.forgetSpawnParents("child")
+	if got := pt.processDescendants(root.ID); len(got) != 2 {
+		t.Errorf("descendants of a running root = %v, want child and grandchild", got)
+	}
+	if _, ok := pt.spawnParents["orphan"]; ok {
+		t.Error("spawn with no running ancestor was kept")
+	}
+
+	// Once root finishes, its whole tree goes
+	pt.forgetSpawnParents(root.ID)
+	if len(pt.spawnParents) != 0 {
+		t.Errorf("spawnParents = %v after the root finished, want empty", pt.spawnParents)
+	}
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+// pinnedTag marks knowledge entries that should surface regardless of age
+const pinnedTag = "pinned"
+
+// knowledgeHalfLife is the age at which an entry's recency weight halves
+const knowledgeHalfLife = 14 * 24 * time.Hour
+
+// isPinned reports whether an entry carries the pinned tag
+func isPinned(e knowledge.Entry) bool {
+	for _, tag := range e.Tags {
+		if tag == pinnedTag {
+			return true
+		}
+	}
+	return false
+}
+
+// knowledgeScore weights tag relevance by recency. Each tag matching the
+// query adds one point of relevance; the total halves every knowledgeHalfLife.
+func knowledgeScore(e knowledge.Entry, queryTags []string, now time.Time) float64 {
+	relevance := 1.0
+	for _, want := range queryTags {
+		for _, tag := range e.Tags {
+			if tag == want {
+				relevance++
+				break
+			}
+		}
+	}
+
+	age := now.Sub(e.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+	return relevance * math.Pow(0.5, float64(age)/float64(knowledgeHalfLife))
+}
+
+// rankKnowledge orders entries pinned-first, then by recency-weighted
+// relevance, and trims to limit. Ties keep the store's order.
+func rankKnowledge(entries []knowledge.Entry, queryTags []string, now time.Time, limit int) []knowledge.Entry {
+	ranked := make([]knowledge.Entry, len(entries))
+	copy(ranked, entries)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		pi, pj := isPinned(ranked[i]), isPinned(ranked[j])
+		if pi != pj {
+			return pi
+		}
+		return knowledgeScore(ranked[i], queryTags, now) > knowledgeScore(ranked[j], queryTags, now)
+	})
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// withPinned puts the pinned entries matching opts ahead of entries. They're
+// looked up on their own with no recency cap, since a window of the newest
+// entries would miss old pins.
+func withPinned(store *knowledge.Store, opts knowledge.QueryOptions, entries []knowledge.Entry) []knowledge.Entry {
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e.ID] = true
+	}
+
+	pinnedOpts := opts
+	pinnedOpts.Tags = []string{pinnedTag}
+	pinnedOpts.Limit = knowledgeScanLimit
+	wantTags := knowledge.QueryOptions{Tags: opts.Tags}
+
+	var pinned []knowledge.Entry
+	for _, e := range store.Query(pinnedOpts) {
+		if !isPinned(e) || (e.ID != "" && seen[e.ID]) || !matchesKnowledgeQuery(e, wantTags) {
+			continue
+		}
+		pinned = append(pinned, e)
+	}
+	return append(pinned, entries...)
+}
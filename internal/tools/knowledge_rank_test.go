@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+func TestRankKnowledge(t *testing.T) {
+	now := time.Now()
+	entries := []knowledge.Entry{
+		{Title: "old", CreatedAt: now.Add(-60 * 24 * time.Hour)},
+		{Title: "fresh", CreatedAt: now.Add(-time.Hour)},
+		{Title: "old-decision", Tags: []string{pinnedTag}, CreatedAt: now.Add(-90 * 24 * time.Hour)},
+		{Title: "week", CreatedAt: now.Add(-7 * 24 * time.Hour)},
+	}
+
+	got := rankKnowledge(entries, nil, now, 0)
+	want := []string{"old-decision", "fresh", "week", "old"}
+	for i, title := range want {
+		if got[i].Title != title {
+			t.Fatalf("rank %d = %q, want %q (order %v)", i, got[i].Title, title, got)
+		}
+	}
+
+	if got := rankKnowledge(entries, nil, now, 2); len(got) != 2 {
+		t.Errorf("expected limit 2, got %d", len(got))
+	}
+}
+
+func TestKnowledgeScoreTagRelevance(t *testing.T) {
+	now := time.Now()
+	tagged := knowledge.Entry{Tags: []string{"go", "api"}, CreatedAt: now.Add(-knowledgeHalfLife)}
+	fresh := knowledge.Entry{CreatedAt: now}
+
+	// Two matching tags at one half-life (3 * 0.5) outrank a fresh untagged entry (1)
+	if knowledgeScore(tagged, []string{"go", "api"}, now) <= knowledgeScore(fresh, []string{"go", "api"}, now) {
+		t.Error("matching tags should outweigh moderate age")
+	}
+}
+
+func TestKnowledgeFingerprint(t *testing.T) {
+	base := knowledgeFingerprint("API Rate Limits", "Brave allows 1 request per second.")
+
+	same := []struct{ title, content string }{
+		{"api rate limits", "Brave allows 1 request per second."},
+		{"  API   Rate Limits ", "Brave allows 1 request\nper second"},
+		{"API rate-limits", "brave ALLOWS 1 request per second!"},
+	}
+	for _, tt := range same {
+		if got := knowledgeFingerprint(tt.title, tt.content); got != base {
+			t.Errorf("knowledgeFingerprint(%q, %q) differs from base, want equal", tt.title, tt.content)
+		}
+	}
+
+	different := []struct{ title, content string }{
+		{"API Rate Limits", "Brave allows 2 requests per second."},
+		{"API Rate", "Limits Brave allows 1 request per second."},
+	}
+	for _, tt := range different {
+		if got := knowledgeFingerprint(tt.title, tt.content); got == base {
+			t.Errorf("knowledgeFingerprint(%q, %q) equals base, want different", tt.title, tt.content)
+		}
+	}
+}
+
+func TestWithPinnedFindsOldPins(t *testing.T) {
+	store, err := knowledge.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	now := time.Now()
+	for _, e := range []knowledge.Entry{
+		retentionEntry("old-pin", 90*24*time.Hour, now, pinnedTag),
+		retentionEntry("old-pin-other-tag", 90*24*time.Hour, now, pinnedTag, "billing"),
+	} {
+		if err := store.Add(e); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	for i := range 10 {
+		if err := store.Add(retentionEntry(fmt.Sprintf("new-%d", i), time.Duration(i)*time.Minute, now)); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	// The newest window holds no pins; both are found and come first
+	opts := knowledge.QueryOptions{Limit: 4}
+	got := rankKnowledge(withPinned(store, opts, store.Query(opts)), nil, now, 3)
+	if ids := entryIDs(got); len(ids) != 3 || !isPinned(got[0]) || !isPinned(got[1]) {
+		t.Errorf("ranked = %v, want both pins first", ids)
+	}
+
+	// Pins still have to match the query's tags
+	opts.Tags = []string{"billing"}
+	if ids := entryIDs(withPinned(store, opts, nil)); !reflect.DeepEqual(ids, []string{"old-pin-other-tag"}) {
+		t.Errorf("pins tagged billing = %v, want [old-pin-other-tag]", ids)
+	}
+}
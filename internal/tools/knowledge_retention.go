@@ -0,0 +1,322 @@
+package tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+const (
+	// KnowledgeSweepInterval is how often the retention sweep compacts the
+	// knowledge store
+	KnowledgeSweepInterval = time.Hour
+
+	// knowledgeArchiveFile is the cold archive in the knowledge directory,
+	// one archived entry per line
+	knowledgeArchiveFile = "knowledge_archive.jsonl"
+
+	// maxArchiveLine bounds one archived entry when the archive is read
+	maxArchiveLine = 4 << 20
+
+	// knowledgeScanLimit is the most entries a read of the whole store returns
+	knowledgeScanLimit = 1_000_000
+)
+
+// knowledgeStoreFiles are the files knowledge.Store keeps in the knowledge
+// directory. A rebuild replaces all of them.
+var knowledgeStoreFiles = []string{"index.json", "feed.json", "entries.json"}
+
+// KnowledgeRetention bounds the knowledge store. Unpinned entries outside it
+// are moved to the cold archive, which query_knowledge reads only when asked
+// to. A zero field doesn't limit.
+type KnowledgeRetention struct {
+	MaxEntries int           // Most unpinned entries kept, newest first
+	MaxAge     time.Duration // Unpinned entries older than this are archived
+}
+
+// enabled reports whether the retention limits anything
+func (r KnowledgeRetention) enabled() bool {
+	return r.MaxEntries > 0 || r.MaxAge > 0
+}
+
+// archivedKnowledgeEntry is one line of the archive file
+type archivedKnowledgeEntry struct {
+	knowledge.Entry
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// currentKnowledgeStore returns the knowledge store, or nil if there is
+// none. Compaction replaces the store, so callers shouldn't keep it.
+func (pt *PersonaTools) currentKnowledgeStore() *knowledge.Store {
+	pt.knowledgeMu.RLock()
+	defer pt.knowledgeMu.RUnlock()
+	return pt.knowledgeStore
+}
+
+// addKnowledge adds entry to the knowledge store. Compaction waits for it,
+// so the entry can't land in a store that's being replaced.
+func (pt *PersonaTools) addKnowledge(entry knowledge.Entry) error {
+	pt.knowledgeMu.RLock()
+	defer pt.knowledgeMu.RUnlock()
+	if pt.knowledgeStore == nil {
+		return fmt.Errorf("knowledge store not available")
+	}
+	return pt.knowledgeStore.Add(entry)
+}
+
+// splitForRetention separates the unpinned entries outside r, those past
+// the newest MaxEntries and those older than MaxAge, from the ones to keep.
+// Both keep the order of entries.
+func splitForRetention(entries []knowledge.Entry, r KnowledgeRetention, now time.Time) (keep, due []knowledge.Entry) {
+	var unpinned []int
+	for i, e := range entries {
+		if !isPinned(e) {
+			unpinned = append(unpinned, i)
+		}
+	}
+	sort.SliceStable(unpinned, func(a, b int) bool {
+		return entries[unpinned[a]].CreatedAt.After(entries[unpinned[b]].CreatedAt)
+	})
+
+	archive := make(map[int]bool)
+	for rank, i := range unpinned {
+		tooMany := r.MaxEntries > 0 && rank >= r.MaxEntries
+		tooOld := r.MaxAge > 0 && now.Sub(entries[i].CreatedAt) > r.MaxAge
+		if tooMany || tooOld {
+			archive[i] = true
+		}
+	}
+
+	for i, e := range entries {
+		if archive[i] {
+			due = append(due, e)
+		} else {
+			keep = append(keep, e)
+		}
+	}
+	return keep, due
+}
+
+// SetKnowledgeRetention sets how much of the knowledge store is kept. Unless
+// it's zero, the retention sweep runs CompactKnowledge every
+// KnowledgeSweepInterval until shutdown begins.
+func (pt *PersonaTools) SetKnowledgeRetention(r KnowledgeRetention) {
+	pt.knowledgeMu.Lock()
+	pt.knowledgeRetention = r
+	pt.knowledgeMu.Unlock()
+
+	if r.enabled() {
+		pt.knowledgeSweepOnce.Do(func() {
+			go pt.runKnowledgeSweep()
+		})
+	}
+}
+
+// runKnowledgeSweep compacts the knowledge store on a ticker until shutdown
+// begins
+func (pt *PersonaTools) runKnowledgeSweep() {
+	ticker := time.NewTicker(KnowledgeSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if pt.draining.Load() {
+			return
+		}
+		if _, err := pt.CompactKnowledge(); err != nil {
+			pt.logger.Errorf("Knowledge compaction failed: %v", err)
+		}
+	}
+}
+
+// CompactKnowledge moves the unpinned entries outside the retention set with
+// SetKnowledgeRetention from the knowledge store to the cold archive, and
+// returns how many it moved. Pinned entries are always kept.
+func (pt *PersonaTools) CompactKnowledge() (int, error) {
+	pt.knowledgeMu.Lock()
+	defer pt.knowledgeMu.Unlock()
+
+	if pt.knowledgeStore == nil {
+		return 0, fmt.Errorf("knowledge store not available")
+	}
+	if !pt.knowledgeRetention.enabled() {
+		return 0, nil
+	}
+
+	now := time.Now()
+	entries := pt.knowledgeStore.Query(knowledge.QueryOptions{Limit: knowledgeScanLimit})
+	keep, due := splitForRetention(entries, pt.knowledgeRetention, now)
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	// Archive first: if the rebuild fails, the entries are in both places
+	// and the next compaction archives them again rather than losing them
+	if err := appendKnowledgeArchive(pt.knowledgeArchivePath(), due, now); err != nil {
+		return 0, fmt.Errorf("failed to archive knowledge: %w", err)
+	}
+
+	if err := pt.rebuildKnowledgeStore(keep); err != nil {
+		return 0, fmt.Errorf("failed to compact knowledge: %w", err)
+	}
+	pt.logger.Infof("Archived %d knowledge entries, %d kept", len(due), len(keep))
+	return len(due), nil
+}
+
+// rebuildKnowledgeStore replaces the store's contents with entries. A fresh
+// store is written in a staging directory and its files are renamed over the
+// live ones, entries.json last, so a crash leaves either the old entries or
+// the new ones. Caller must hold knowledgeMu for writing.
+func (pt *PersonaTools) rebuildKnowledgeStore(entries []knowledge.Entry) error {
+	staging, err := os.MkdirTemp(pt.tronDir, ".knowledge-rebuild-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	fresh, err := knowledge.NewStore(staging)
+	if err != nil {
+		return err
+	}
+	// Re-add in the order the entries were first added
+	ordered := make([]knowledge.Entry, len(entries))
+	copy(ordered, entries)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+	})
+	for _, e := range ordered {
+		if err := fresh.Add(e); err != nil {
+			return err
+		}
+	}
+
+	liveDir := filepath.Join(pt.tronDir, "knowledge")
+	stagedDir := filepath.Join(staging, "knowledge")
+	for _, name := range knowledgeStoreFiles {
+		staged, live := filepath.Join(stagedDir, name), filepath.Join(liveDir, name)
+		if _, err := os.Stat(staged); errors.Is(err, os.ErrNotExist) {
+			// The fresh store has nothing to write here
+			if err := os.Remove(live); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(staged, live); err != nil {
+			return err
+		}
+	}
+
+	reopened, err := knowledge.NewStore(pt.tronDir)
+	if err != nil {
+		return err
+	}
+	pt.knowledgeStore = reopened
+	return nil
+}
+
+// knowledgeArchivePath is where archived entries are kept
+func (pt *PersonaTools) knowledgeArchivePath() string {
+	return filepath.Join(pt.tronDir, "knowledge", knowledgeArchiveFile)
+}
+
+// appendKnowledgeArchive appends entries to the archive at path
+func appendKnowledgeArchive(path string, entries []knowledge.Entry, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(archivedKnowledgeEntry{Entry: e, ArchivedAt: now}); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// searchKnowledgeArchive returns the archived entries at path that match
+// accepts, newest first. The file is read a line at a time rather than
+// loaded; an entry archived twice is returned once.
+func searchKnowledgeArchive(path string, match func(knowledge.Entry) bool) ([]knowledge.Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var found []knowledge.Entry
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxArchiveLine)
+	for scanner.Scan() {
+		var ae archivedKnowledgeEntry
+		if err := json.Unmarshal(scanner.Bytes(), &ae); err != nil {
+			continue // A line cut short by a crash mid-append
+		}
+		if ae.ID != "" && seen[ae.ID] {
+			continue
+		}
+		seen[ae.ID] = true
+		if match(ae.Entry) {
+			found = append(found, ae.Entry)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].CreatedAt.After(found[j].CreatedAt)
+	})
+	return found, scanner.Err()
+}
+
+// matchesKnowledgeQuery reports whether e passes the filters in opts
+func matchesKnowledgeQuery(e knowledge.Entry, opts knowledge.QueryOptions) bool {
+	if opts.Domain != "" && e.Domain != opts.Domain {
+		return false
+	}
+	if opts.Author != "" && !strings.EqualFold(e.Author, opts.Author) {
+		return false
+	}
+	if opts.Type != "" && e.Type != opts.Type {
+		return false
+	}
+	if opts.Since != nil && e.CreatedAt.Before(*opts.Since) {
+		return false
+	}
+	for _, want := range opts.Tags {
+		found := false
+		for _, tag := range e.Tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/tron/internal/knowledge"
+	"github.com/everydev1618/tron/internal/logging"
+)
+
+func retentionEntry(id string, age time.Duration, now time.Time, tags ...string) knowledge.Entry {
+	return knowledge.Entry{
+		ID:        id,
+		Type:      knowledge.TypeInsight,
+		Domain:    knowledge.DomainTech,
+		Author:    "Gary",
+		Title:     "Note " + id,
+		Content:   "Content of " + id,
+		Tags:      tags,
+		CreatedAt: now.Add(-age),
+	}
+}
+
+func entryIDs(entries []knowledge.Entry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestSplitForRetention(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	entries := []knowledge.Entry{
+		retentionEntry("newest", time.Minute, now),
+		retentionEntry("new", time.Hour, now),
+		retentionEntry("mid", 10*day, now),
+		retentionEntry("old", 20*day, now),
+		retentionEntry("old-pinned", 25*day, now, pinnedTag),
+	}
+
+	tests := []struct {
+		name      string
+		retention KnowledgeRetention
+		due       []string
+	}{
+		{"max entries", KnowledgeRetention{MaxEntries: 2}, []string{"mid", "old"}},
+		{"max age", KnowledgeRetention{MaxAge: 15 * day}, []string{"old"}},
+		{"both", KnowledgeRetention{MaxEntries: 3, MaxAge: 5 * day}, []string{"mid", "old"}},
+		{"within limits", KnowledgeRetention{MaxEntries: 10, MaxAge: 30 * day}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, due := splitForRetention(entries, tt.retention, now)
+			if got := entryIDs(due); !reflect.DeepEqual(got, tt.due) {
+				t.Errorf("due = %v, want %v", got, tt.due)
+			}
+			if len(keep)+len(due) != len(entries) || !isPinned(keep[len(keep)-1]) {
+				t.Errorf("keep = %v, want the rest including the pinned entry", entryIDs(keep))
+			}
+		})
+	}
+}
+
+func TestKnowledgeArchiveSearch(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "knowledge", knowledgeArchiveFile)
+
+	if found, err := searchKnowledgeArchive(path, func(knowledge.Entry) bool { return true }); err != nil || found != nil {
+		t.Fatalf("missing archive = %v, %v; want nothing", found, err)
+	}
+
+	older := retentionEntry("older", 48*time.Hour, now, "go")
+	newer := retentionEntry("newer", 24*time.Hour, now, "go", "perf")
+	if err := appendKnowledgeArchive(path, []knowledge.Entry{older, newer}, now); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	// An entry archived again after a failed rebuild shows up once
+	if err := appendKnowledgeArchive(path, []knowledge.Entry{newer}, now); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	all, err := searchKnowledgeArchive(path, func(knowledge.Entry) bool { return true })
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := entryIDs(all); !reflect.DeepEqual(got, []string{"newer", "older"}) {
+		t.Errorf("search = %v, want [newer older]", got)
+	}
+
+	opts := knowledge.QueryOptions{Author: "gary", Tags: []string{"perf"}}
+	tagged, err := searchKnowledgeArchive(path, func(e knowledge.Entry) bool { return matchesKnowledgeQuery(e, opts) })
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := entryIDs(tagged); !reflect.DeepEqual(got, []string{"newer"}) {
+		t.Errorf("search by tag = %v, want [newer]", got)
+	}
+}
+
+func TestCompactKnowledgeShrinksStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := knowledge.NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	pt := &PersonaTools{tronDir: dir, knowledgeStore: store, logger: logging.Discard()}
+
+	now := time.Now()
+	for _, e := range []knowledge.Entry{
+		retentionEntry("pinned", 20*24*time.Hour, now, pinnedTag),
+		retentionEntry("stale", 20*24*time.Hour, now),
+		retentionEntry("fresh", time.Hour, now),
+	} {
+		if err := store.Add(e); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	// With no retention set, nothing moves
+	if n, err := pt.CompactKnowledge(); err != nil || n != 0 {
+		t.Fatalf("CompactKnowledge without retention = %d, %v; want 0", n, err)
+	}
+
+	pt.knowledgeRetention = KnowledgeRetention{MaxAge: 7 * 24 * time.Hour}
+	if n, err := pt.CompactKnowledge(); err != nil || n != 1 {
+		t.Fatalf("CompactKnowledge = %d, %v; want 1", n, err)
+	}
+
+	// The store itself no longer holds the archived entry, and a reload agrees
+	reloaded, err := knowledge.NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	for _, s := range []*knowledge.Store{pt.currentKnowledgeStore(), reloaded} {
+		if got := len(s.Query(knowledge.QueryOptions{Limit: 10})); got != 2 {
+			t.Errorf("store holds %d entries, want 2", got)
+		}
+	}
+
+	archived, err := searchKnowledgeArchive(pt.knowledgeArchivePath(), func(knowledge.Entry) bool { return true })
+	if err != nil || !reflect.DeepEqual(entryIDs(archived), []string{"stale"}) {
+		t.Errorf("archive = %v, %v; want [stale]", entryIDs(archived), err)
+	}
+
+	if n, err := pt.CompactKnowledge(); err != nil || n != 0 {
+		t.Errorf("second CompactKnowledge = %d, %v; want 0", n, err)
+	}
+}
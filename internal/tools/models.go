@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/everydev1618/govega/dsl"
+)
+
+// knownModels are the Anthropic model IDs and aliases agents may use
+var knownModels = map[string]bool{
+	"claude-opus-4-1-20250805":   true,
+	"claude-opus-4-1":            true,
+	"claude-opus-4-20250514":     true,
+	"claude-opus-4-0":            true,
+	"claude-sonnet-4-5-20250929": true,
+	"claude-sonnet-4-5":          true,
+	"claude-sonnet-4-20250514":   true,
+	"claude-sonnet-4-0":          true,
+	"claude-haiku-4-5-20251001":  true,
+	"claude-haiku-4-5":           true,
+	"claude-3-7-sonnet-20250219": true,
+	"claude-3-7-sonnet-latest":   true,
+	"claude-3-5-haiku-20241022":  true,
+	"claude-3-5-haiku-latest":    true,
+	"claude-3-haiku-20240307":    true,
+}
+
+var knownModelsMu sync.RWMutex
+
+// AddKnownModels accepts additional model IDs, e.g. models released after
+// this list was written
+func AddKnownModels(models ...string) {
+	knownModelsMu.Lock()
+	defer knownModelsMu.Unlock()
+	for _, m := range models {
+		if m = strings.TrimSpace(m); m != "" {
+			knownModels[m] = true
+		}
+	}
+}
+
+// knownModelList returns the accepted model IDs, sorted
+func knownModelList() []string {
+	knownModelsMu.RLock()
+	defer knownModelsMu.RUnlock()
+	list := make([]string, 0, len(knownModels))
+	for m := range knownModels {
+		list = append(list, m)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// resolveModel returns the model an agent runs with: its own, or the
+// config's default_model when it sets none
+func resolveModel(config *dsl.Document, agent *dsl.Agent) string {
+	if agent.Model != "" {
+		return agent.Model
+	}
+	if config.Settings != nil {
+		return config.Settings.DefaultModel
+	}
+	return ""
+}
+
+// isKnownModel reports whether model is on the known list
+func isKnownModel(model string) bool {
+	knownModelsMu.RLock()
+	defer knownModelsMu.RUnlock()
+	return knownModels[model]
+}
+
+// ValidateModels checks the model every agent resolves to, after falling
+// back to settings.default_model. An agent with no model at all is an
+// error, so it fails at load time instead of deep inside the orchestrator.
+// A model missing from the known list only produces a warning: the list
+// lags behind new releases, and the provider has the final say.
+func ValidateModels(config *dsl.Document) (warnings []string, err error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(config.Agents))
+	for name := range config.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		agent := config.Agents[name]
+		if agent == nil {
+			continue
+		}
+		switch model := resolveModel(config, agent); {
+		case model == "":
+			problems = append(problems, fmt.Sprintf("agent %s has no model set and there is no settings.default_model", name))
+		case !isKnownModel(model):
+			warnings = append(warnings, fmt.Sprintf("agent %s uses unrecognized model %q (known: %s)", name, model, strings.Join(knownModelList(), ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("invalid model config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega/dsl"
+)
+
+func TestValidateModels(t *testing.T) {
+	config := &dsl.Document{Agents: map[string]*dsl.Agent{
+		"Gary":  {Name: "Gary", Model: "claude-sonet-4-20250514"},
+		"Sarah": {Name: "Sarah"},
+		"Tony":  {Name: "Tony", Model: "claude-sonnet-4-20250514"},
+	}}
+
+	warnings, err := ValidateModels(config)
+	if err == nil || !strings.Contains(err.Error(), "agent Sarah has no model set") {
+		t.Fatalf("err = %v, want Sarah's missing model", err)
+	}
+	if strings.Contains(err.Error(), "Gary") {
+		t.Errorf("an unrecognized model should only warn: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `agent Gary uses unrecognized model "claude-sonet-4-20250514"`) {
+		t.Errorf("warnings = %q, want one naming Gary's typo", warnings)
+	}
+
+	// Sarah inherits the default model
+	config.Settings = &dsl.Settings{DefaultModel: "claude-sonnet-4-0"}
+	if warnings, err := ValidateModels(config); err != nil || len(warnings) != 1 {
+		t.Errorf("with a default model: warnings = %q, err = %v; want only Gary's warning", warnings, err)
+	}
+
+	AddKnownModels(" claude-sonet-4-20250514 ")
+	t.Cleanup(func() {
+		knownModelsMu.Lock()
+		delete(knownModels, "claude-sonet-4-20250514")
+		knownModelsMu.Unlock()
+	})
+	if warnings, err := ValidateModels(config); err != nil || len(warnings) != 0 {
+		t.Errorf("after adding the model: warnings = %q, err = %v", warnings, err)
+	}
+}
@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/persist"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+// NotifyLevel is how much a Slack channel or user hears about finished
+// agents. Failures are always posted in full.
+type NotifyLevel string
+
+const (
+	NotifyAll    NotifyLevel = "all"    // Every completion with its result (the default)
+	NotifyQuiet  NotifyLevel = "quiet"  // A one-line notice per completion, without the result
+	NotifyErrors NotifyLevel = "errors" // Only failures
+)
+
+// ParseNotifyLevel parses a notification level, e.g. from a tool call
+func ParseNotifyLevel(s string) (NotifyLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "all":
+		return NotifyAll, nil
+	case "quiet":
+		return NotifyQuiet, nil
+	case "errors", "errors-only", "errors_only":
+		return NotifyErrors, nil
+	default:
+		return "", fmt.Errorf("invalid notification level %q (use all, quiet, or errors)", s)
+	}
+}
+
+// notifyPrefs persists notification levels set for Slack channels and users
+type notifyPrefs struct {
+	mu       sync.RWMutex
+	Channels map[string]NotifyLevel `json:"channels,omitempty"`
+	Users    map[string]NotifyLevel `json:"users,omitempty"`
+	path     string
+}
+
+// newNotifyPrefs loads notification preferences from path, if present
+func newNotifyPrefs(path string) *notifyPrefs {
+	p := &notifyPrefs{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, p)
+	}
+	if p.Channels == nil {
+		p.Channels = make(map[string]NotifyLevel)
+	}
+	if p.Users == nil {
+		p.Users = make(map[string]NotifyLevel)
+	}
+	return p
+}
+
+// levelFor returns the level for a notification in ch. A user's own
+// preference applies to their requests wherever they're made; otherwise the
+// channel's applies.
+func (p *notifyPrefs) levelFor(ch notification.ChannelContext) NotifyLevel {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if level, ok := p.Users[ch.UserID]; ok && ch.UserID != "" {
+		return level
+	}
+	if level, ok := p.Channels[ch.ChannelID]; ok && ch.ChannelID != "" {
+		return level
+	}
+	return NotifyAll
+}
+
+// set records level in prefs (Channels or Users), or clears it if level is
+// empty, and saves
+func (p *notifyPrefs) set(prefs map[string]NotifyLevel, id string, level NotifyLevel) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if level == "" {
+		delete(prefs, id)
+	} else {
+		prefs[id] = level
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return persist.WriteFile(p.path, data)
+}
+
+// setNotificationLevel is the set_notification_level tool. It applies to
+// the Slack channel the request came from, or with scope "me" to the
+// requesting user's own requests.
+func (pt *PersonaTools) setNotificationLevel(ctx context.Context, params map[string]any) (string, error) {
+	raw, _ := params["level"].(string)
+	scope, _ := params["scope"].(string)
+	scope = strings.ToLower(strings.TrimSpace(scope))
+
+	var level NotifyLevel
+	if !strings.EqualFold(strings.TrimSpace(raw), "default") {
+		var err error
+		if level, err = ParseNotifyLevel(raw); err != nil {
+			return "", err
+		}
+	}
+
+	ch, ok := notification.ChannelFromContext(ctx)
+	if !ok || ch.Type != notification.ChannelSlack {
+		return "", fmt.Errorf("notification levels can only be set from Slack")
+	}
+
+	var prefs map[string]NotifyLevel
+	var kind, id, who string
+	switch scope {
+	case "", "channel":
+		prefs, kind, id, who = pt.notifyPrefs.Channels, "channel", ch.ChannelID, "this channel"
+	case "me", "user":
+		prefs, kind, id, who = pt.notifyPrefs.Users, "user", ch.UserID, "your requests"
+		if ch.UserName != "" {
+			who = ch.UserName + "'s requests"
+		}
+	default:
+		return "", fmt.Errorf("invalid scope %q (use channel or me)", scope)
+	}
+	if id == "" {
+		return "", fmt.Errorf("no Slack %s ID in this conversation", kind)
+	}
+
+	if err := pt.notifyPrefs.set(prefs, id, level); err != nil {
+		return "", fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	by := ""
+	if proc := vega.ProcessFromContext(ctx); proc != nil && proc.Agent != nil {
+		by = " by " + proc.Agent.Name
+	}
+	pt.logger.Infof("Notification level for %s %s set to %q%s", kind, id, level, by)
+
+	switch level {
+	case "":
+		return fmt.Sprintf("Notifications for %s are back to the default (every completion, with results).", who), nil
+	case NotifyQuiet:
+		return fmt.Sprintf("Notifications for %s: a one-line notice when work completes, full details only for failures.", who), nil
+	case NotifyErrors:
+		return fmt.Sprintf("Notifications for %s: failures only.", who), nil
+	default:
+		return fmt.Sprintf("Notifications for %s: every completion, with results.", who), nil
+	}
+}
+
+// notifySlackBrief posts a one-line completion notice, without the result
+func (pt *PersonaTools) notifySlackBrief(channelID, agentName string, p *vega.Process) {
+	if pt.slackClient == nil {
+		pt.logger.Warnf("Slack client not configured, cannot notify channel %s", channelID)
+		return
+	}
+	msg, err := pt.templates.Render(notification.TemplateSlackComplete, notification.CompletionData{
+		Persona:   notification.DefaultPersona,
+		AgentName: agentName,
+		AgentID:   p.ID,
+		Task:      p.Task,
+		Success:   true,
+	})
+	if err != nil {
+		pt.logger.Warnf("%v", err)
+	}
+	if err := pt.slackClient.SendMessage(channelID, strings.TrimSpace(msg)); err != nil {
+		pt.logger.Errorf("Failed to send Slack notification: %v", err)
+	}
+}
+
+// notifyFailure tells the Slack channel a spawned agent was started from
+// that it failed. Failures are posted whatever the notification level.
+func (pt *PersonaTools) notifyFailure(p *vega.Process, agentName string, failure error) {
+	pt.processChannelsMu.Lock()
+	ch, ok := pt.processChannels[p.ID]
+	delete(pt.processChannels, p.ID)
+	pt.processChannelsMu.Unlock()
+	if !ok {
+		return
+	}
+	pt.saveSpawnCallbacks()
+
+	if ch.Type != notification.ChannelSlack {
+		return
+	}
+	if pt.slackClient == nil {
+		pt.logger.Warnf("Slack client not configured, cannot notify channel %s", ch.ChannelID)
+		return
+	}
+	msg, err := pt.templates.Render(notification.TemplateSlackComplete, notification.CompletionData{
+		Persona:   notification.DefaultPersona,
+		AgentName: agentName,
+		AgentID:   p.ID,
+		Task:      p.Task,
+		Error:     textutil.Shorten(failure.Error(), 500),
+	})
+	if err != nil {
+		pt.logger.Warnf("%v", err)
+	}
+	if err := pt.slackClient.SendMessage(ch.ChannelID, strings.TrimSpace(msg)); err != nil {
+		pt.logger.Errorf("Failed to send Slack notification: %v", err)
+	}
+}
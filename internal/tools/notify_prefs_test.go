@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/notification"
+)
+
+// slackMessages captures the text of every message sent through SlackPoster
+type slackMessages struct {
+	texts []string
+}
+
+func (s *slackMessages) SendMessage(channel, text string) error {
+	s.texts = append(s.texts, text)
+	return nil
+}
+
+func TestNotificationLevels(t *testing.T) {
+	dir := t.TempDir()
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+	slack := &slackMessages{}
+	pt.SetSlackClient(slack)
+
+	ch := notification.ChannelContext{Type: notification.ChannelSlack, ChannelID: "C1", UserID: "U1", UserName: "Sam"}
+	ctx := notification.WithChannel(context.Background(), ch)
+	proc := &vega.Process{ID: "p1", Agent: &vega.Agent{Name: "Gary"}, Task: "build the pricing page"}
+
+	notify := func() string {
+		t.Helper()
+		slack.texts = nil
+		pt.notifyChannel(ch, proc, "Shipped it at /pricing")
+		return strings.Join(slack.texts, "\n")
+	}
+	set := func(params map[string]any) {
+		t.Helper()
+		if _, err := pt.setNotificationLevel(ctx, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := notify(); !strings.Contains(got, "Shipped it") {
+		t.Errorf("default notice = %q, want the result", got)
+	}
+
+	set(map[string]any{"level": "quiet"})
+	if got := notify(); !strings.Contains(got, "*Gary* completed") || strings.Contains(got, "Shipped it") {
+		t.Errorf("quiet notice = %q, want one line without the result", got)
+	}
+
+	set(map[string]any{"level": "errors-only"})
+	if got := notify(); got != "" {
+		t.Errorf("errors-only posted %q", got)
+	}
+
+	// A user's own preference wins over the channel's
+	set(map[string]any{"level": "all", "scope": "me"})
+	if got := notify(); !strings.Contains(got, "Shipped it") {
+		t.Errorf("user preference ignored, got %q", got)
+	}
+
+	// Preferences survive a restart
+	prefs := newNotifyPrefs(filepath.Join(dir, "knowledge", "notification_prefs.json"))
+	if prefs.Channels["C1"] != NotifyErrors || prefs.Users["U1"] != NotifyAll {
+		t.Errorf("saved prefs = %+v / %+v", prefs.Channels, prefs.Users)
+	}
+
+	// Clearing the user's preference falls back to the channel's
+	set(map[string]any{"level": "default", "scope": "me"})
+	if got := notify(); got != "" {
+		t.Errorf("after clearing the user level, posted %q", got)
+	}
+
+	// Failures are posted whatever the level
+	pt.processChannels[proc.ID] = ch
+	slack.texts = nil
+	pt.notifyFailure(proc, "Gary", errors.New("budget exceeded"))
+	if got := strings.Join(slack.texts, "\n"); !strings.Contains(got, "*Gary* failed") || !strings.Contains(got, "budget exceeded") {
+		t.Errorf("failure notice = %q", got)
+	}
+	if _, ok := pt.processChannels[proc.ID]; ok {
+		t.Error("failed process is still waiting on a notification")
+	}
+}
+
+func TestSetNotificationLevelValidation(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(t.TempDir()))
+	slackCtx := notification.WithChannel(context.Background(), notification.ChannelContext{Type: notification.ChannelSlack, ChannelID: "C1"})
+
+	if _, err := pt.setNotificationLevel(slackCtx, map[string]any{"level": "loud"}); err == nil {
+		t.Error("expected an invalid level to be rejected")
+	}
+	if _, err := pt.setNotificationLevel(slackCtx, map[string]any{"level": "quiet", "scope": "team"}); err == nil {
+		t.Error("expected an invalid scope to be rejected")
+	}
+	if _, err := pt.setNotificationLevel(slackCtx, map[string]any{"level": "quiet", "scope": "me"}); err == nil {
+		t.Error("expected scope me without a user ID to be rejected")
+	}
+	if _, err := pt.setNotificationLevel(context.Background(), map[string]any{"level": "quiet"}); err == nil {
+		t.Error("expected a non-Slack conversation to be rejected")
+	}
+}
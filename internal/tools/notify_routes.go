@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/notification"
+	"gopkg.in/yaml.v3"
+)
+
+// NotificationRoute sends completions of matching tasks to extra targets,
+// on top of the channel the agent was spawned from. A task matches when it
+// contains any keyword as a whole word, carries any tag as a #hashtag, or
+// was done by any listed agent; matching ignores case.
+type NotificationRoute struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Agents   []string `yaml:"agents,omitempty"`
+
+	// Targets
+	Slack []string `yaml:"slack,omitempty"` // Channel IDs
+	Email []string `yaml:"email,omitempty"`
+}
+
+// validate checks that a route can both match and deliver
+func (r NotificationRoute) validate() error {
+	if len(r.Keywords)+len(r.Tags)+len(r.Agents) == 0 {
+		return fmt.Errorf("route %q has no keywords, tags, or agents to match", r.Name)
+	}
+	if len(r.Slack)+len(r.Email) == 0 {
+		return fmt.Errorf("route %q has no slack or email targets", r.Name)
+	}
+	return nil
+}
+
+// matches reports whether a task done by agent falls under the route
+func (r NotificationRoute) matches(agent, task string) bool {
+	lower := strings.ToLower(task)
+	for _, k := range r.Keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && containsWord(lower, k) {
+			return true
+		}
+	}
+	for _, tag := range r.Tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag != "" && containsWord(lower, "#"+tag) {
+			return true
+		}
+	}
+	return containsFold(r.Agents, agent)
+}
+
+// loadNotificationRoutes loads routes from a YAML file of the form:
+//
+//	routes:
+//	  - name: ops
+//	    keywords: [deploy, incident]
+//	    tags: [ops]
+//	    slack: [C0123OPS]
+//	    email: [oncall@example.com]
+func loadNotificationRoutes(path string) ([]NotificationRoute, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Routes []NotificationRoute `yaml:"routes"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	var errs []error
+	for _, r := range file.Routes {
+		errs = append(errs, r.validate())
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Routes, nil
+}
+
+// SetNotificationRoutes replaces the notification routes, e.g. when they
+// come from somewhere other than notification_routes.yaml
+func (pt *PersonaTools) SetNotificationRoutes(routes []NotificationRoute) error {
+	for _, r := range routes {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+	pt.routesMu.Lock()
+	defer pt.routesMu.Unlock()
+	pt.routes = routes
+	return nil
+}
+
+// notifyRoutes sends a completion to the targets of every matching route.
+// Each target is notified once, and never again if it's the origin channel
+// notifyChannel already told.
+func (pt *PersonaTools) notifyRoutes(origin notification.ChannelContext, p *vega.Process, result string) {
+	pt.routesMu.RLock()
+	routes := pt.routes
+	pt.routesMu.RUnlock()
+	if len(routes) == 0 {
+		return
+	}
+
+	agentName := "Agent"
+	if p.Agent != nil {
+		agentName = p.Agent.Name
+	}
+
+	sent := make(map[string]bool)
+	if origin.Type == notification.ChannelSlack {
+		sent["slack:"+origin.ChannelID] = true
+	}
+	if origin.Email != "" {
+		sent["email:"+strings.ToLower(origin.Email)] = true
+	}
+
+	for _, r := range routes {
+		if !r.matches(agentName, p.Task) {
+			continue
+		}
+		for _, channel := range r.Slack {
+			if key := "slack:" + channel; !sent[key] {
+				sent[key] = true
+				pt.logger.Infof("Route %q: notifying Slack %s of %s", r.Name, channel, p.ID)
+				pt.notifySlack(channel, agentName, p, result)
+			}
+		}
+		for _, addr := range r.Email {
+			if key := "email:" + strings.ToLower(addr); !sent[key] {
+				sent[key] = true
+				pt.logger.Infof("Route %q: emailing %s about %s", r.Name, addr, p.ID)
+				pt.sendCallbackEmail(addr, fmt.Sprintf("%s completed: %s", agentName, summarizeResult(p.Task, 60)), result)
+			}
+		}
+	}
+}
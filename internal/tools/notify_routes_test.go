@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/notification"
+)
+
+// slackLog captures every message sent through SlackPoster
+type slackLog struct {
+	channels []string
+}
+
+func (s *slackLog) SendMessage(channel, text string) error {
+	s.channels = append(s.channels, channel)
+	return nil
+}
+
+func TestNotificationRouteMatches(t *testing.T) {
+	r := NotificationRoute{Keywords: []string{"deploy"}, Tags: []string{"#ops"}, Agents: []string{"Gary"}}
+	tests := []struct {
+		agent, task string
+		want        bool
+	}{
+		{"Maya", "Deploy the landing page", true},
+		{"Maya", "Write up the redeployment plan", false},
+		{"Maya", "Rotate the certs #ops", true},
+		{"Maya", "Review shops list", false},
+		{"gary", "Anything at all", true},
+		{"Maya", "Draft the newsletter", false},
+	}
+	for _, tt := range tests {
+		if got := r.matches(tt.agent, tt.task); got != tt.want {
+			t.Errorf("matches(%q, %q) = %v, want %v", tt.agent, tt.task, got, tt.want)
+		}
+	}
+}
+
+func TestLoadNotificationRoutes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notification_routes.yaml")
+
+	os.WriteFile(path, []byte("routes:\n  - name: ops\n    keywords: [deploy, incident]\n    slack: [C0OPS]\n    email: [oncall@example.com]\n"), 0644)
+	routes, err := loadNotificationRoutes(path)
+	if err != nil || len(routes) != 1 || routes[0].Slack[0] != "C0OPS" || routes[0].Email[0] != "oncall@example.com" {
+		t.Fatalf("loadNotificationRoutes = %+v, %v", routes, err)
+	}
+
+	os.WriteFile(path, []byte("routes:\n  - name: nowhere\n    keywords: [deploy]\n  - name: everything\n    slack: [C0OPS]\n"), 0644)
+	_, err = loadNotificationRoutes(path)
+	if err == nil || !strings.Contains(err.Error(), "nowhere") || !strings.Contains(err.Error(), "everything") {
+		t.Errorf("expected both invalid routes reported, got %v", err)
+	}
+}
+
+func TestNotifyRoutesFansOut(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir))
+	slack := &slackLog{}
+	mail := &recordingEmail{configured: true}
+	pt.SetSlackClient(slack)
+	pt.SetEmailClient(mail)
+
+	err := pt.SetNotificationRoutes([]NotificationRoute{
+		{Name: "ops", Keywords: []string{"deploy"}, Slack: []string{"C0OPS", "C0ORIGIN"}, Email: []string{"oncall@example.com"}},
+		{Name: "incidents", Keywords: []string{"incident", "deploy"}, Slack: []string{"C0OPS", "C0INC"}},
+		{Name: "finance", Keywords: []string{"invoice"}, Slack: []string{"C0FIN"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &vega.Process{ID: "proc-1", Task: "Deploy the API to production"}
+	origin := notification.ChannelContext{Type: notification.ChannelSlack, ChannelID: "C0ORIGIN"}
+	pt.notifyRoutes(origin, p, "deployed")
+
+	sort.Strings(slack.channels)
+	if got := strings.Join(slack.channels, ","); got != "C0INC,C0OPS" {
+		t.Errorf("Slack notified %s, want each matching target once and not the origin", got)
+	}
+	if mail.sent != 1 || mail.to != "oncall@example.com" || !strings.Contains(mail.subject, "Deploy the API") {
+		t.Errorf("email sent=%d to=%q subject=%q", mail.sent, mail.to, mail.subject)
+	}
+
+	if err := pt.SetNotificationRoutes([]NotificationRoute{{Name: "bad", Slack: []string{"C0OPS"}}}); err == nil {
+		t.Error("a route with nothing to match should be rejected")
+	}
+}
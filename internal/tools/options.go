@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/everydev1618/govega/container"
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/memory"
+	"github.com/everydev1618/tron/internal/notification"
+)
+
+// Option configures PersonaTools at construction.
+type Option func(*PersonaTools)
+
+// WithWorkingDir sets the directory projects and exec run in. Defaults to ".".
+func WithWorkingDir(dir string) Option {
+	return func(pt *PersonaTools) {
+		if dir != "" {
+			pt.workingDir = dir
+		}
+	}
+}
+
+// WithTronDir sets the directory holding knowledge, directives and other
+// saved state. Defaults to the working directory.
+func WithTronDir(dir string) Option {
+	return func(pt *PersonaTools) {
+		pt.tronDir = dir
+	}
+}
+
+// WithContainerManager enables project containers and the project registry.
+func WithContainerManager(cm *container.Manager) Option {
+	return func(pt *PersonaTools) {
+		pt.containers = cm
+	}
+}
+
+// WithLogger replaces the default "tools" logger, including for messages
+// logged while loading saved state.
+func WithLogger(l logging.Logger) Option {
+	return func(pt *PersonaTools) {
+		pt.logger = l
+	}
+}
+
+// WithEmailClient sets the client the send_email tool uses.
+func WithEmailClient(client EmailSender) Option {
+	return func(pt *PersonaTools) {
+		pt.SetEmailClient(client)
+	}
+}
+
+// WithSummarizer sets the summarizer used to condense long task results in
+// Slack and SMS notices. Nil (the default) truncates them instead.
+func WithSummarizer(s memory.Summarizer) Option {
+	return func(pt *PersonaTools) {
+		pt.SetSummarizer(s)
+	}
+}
+
+// WithNotificationTemplates sets the templates used for Slack notifications.
+func WithNotificationTemplates(t *notification.Templates) Option {
+	return func(pt *PersonaTools) {
+		pt.SetNotificationTemplates(t)
+	}
+}
+
+// WithToolConcurrency overrides per-tool concurrency limits; see
+// SetToolConcurrency.
+func WithToolConcurrency(limits map[string]int) Option {
+	return func(pt *PersonaTools) {
+		pt.SetToolConcurrency(limits)
+	}
+}
+
+// WithToolQueueTimeout sets how long a call waits for a busy tool.
+// Defaults to DefaultToolQueueTimeout.
+func WithToolQueueTimeout(d time.Duration) Option {
+	return func(pt *PersonaTools) {
+		pt.SetToolQueueTimeout(d)
+	}
+}
+
+// WithKnowledgeDedupWindow sets how long identical share_knowledge entries
+// are skipped. Defaults to DefaultKnowledgeDedupWindow; zero disables dedup.
+func WithKnowledgeDedupWindow(window time.Duration) Option {
+	return func(pt *PersonaTools) {
+		pt.SetKnowledgeDedupWindow(window)
+	}
+}
+
+// WithExecDryRun makes execute describe commands instead of running them.
+func WithExecDryRun(enabled bool) Option {
+	return func(pt *PersonaTools) {
+		pt.SetExecDryRun(enabled)
+	}
+}
+
+// WithContainerFallback sets whether project commands may run on the host
+// while Docker is down; see SetContainerFallback.
+func WithContainerFallback(policy ContainerFallback) Option {
+	return func(pt *PersonaTools) {
+		pt.SetContainerFallback(policy)
+	}
+}
+
+// WithAgentDirRetention sets how long a spawned agent's own working
+// directory is kept after it finishes; see SetAgentDirRetention.
+func WithAgentDirRetention(d time.Duration) Option {
+	return func(pt *PersonaTools) {
+		pt.SetAgentDirRetention(d)
+	}
+}
+
+// WithSearchProviders sets the providers web_search tries, in order; see
+// SetSearchProviders.
+func WithSearchProviders(providers ...SearchProvider) Option {
+	return func(pt *PersonaTools) {
+		pt.SetSearchProviders(providers)
+	}
+}
+
+// WithSearchCache sets the web_search result cache size and TTL; see
+// SetSearchCache. Defaults to DefaultSearchCacheSize and DefaultSearchCacheTTL.
+func WithSearchCache(size int, ttl time.Duration) Option {
+	return func(pt *PersonaTools) {
+		pt.SetSearchCache(size, ttl)
+	}
+}
+
+// WithExecPolicy sets the execute blocklist and allowlist; see SetExecPolicy.
+func WithExecPolicy(policy ExecPolicy) Option {
+	return func(pt *PersonaTools) {
+		pt.SetExecPolicy(policy)
+	}
+}
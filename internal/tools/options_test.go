@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestNewPersonaToolsWithOptions(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(),
+		WithWorkingDir(dir),
+		WithToolQueueTimeout(5*time.Second),
+		WithKnowledgeDedupWindow(0),
+	)
+	if pt.workingDir != dir || pt.tronDir != dir {
+		t.Errorf("dirs = %q, %q, want tron dir to default to working dir %q", pt.workingDir, pt.tronDir, dir)
+	}
+	if pt.toolQueueTimeout != 5*time.Second || pt.knowledgeDedupWindow != 0 {
+		t.Errorf("tunables = %v, %v, want 5s, 0", pt.toolQueueTimeout, pt.knowledgeDedupWindow)
+	}
+
+	defaults := NewPersonaToolsWithOptions(orch, createTestConfig(), WithTronDir(t.TempDir()))
+	if defaults.workingDir != "." || defaults.toolQueueTimeout != DefaultToolQueueTimeout || defaults.knowledgeDedupWindow != DefaultKnowledgeDedupWindow {
+		t.Errorf("defaults = %q, %v, %v", defaults.workingDir, defaults.toolQueueTimeout, defaults.knowledgeDedupWindow)
+	}
+
+	// The positional constructor maps onto the same options
+	tronDir := t.TempDir()
+	shim := NewPersonaTools(orch, createTestConfig(), dir, tronDir, nil)
+	if shim.workingDir != dir || shim.tronDir != tronDir || shim.containers != nil {
+		t.Errorf("shim dirs = %q, %q", shim.workingDir, shim.tronDir)
+	}
+}
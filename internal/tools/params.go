@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// intParam reads a whole-number tool param clamped to [lo, hi]. Missing,
+// zero, and unreadable values (NaN, "ten", a list) get def rather than an
+// error, since a model that sends a strange limit still wants results.
+// Numbers sent as strings ("25") are accepted.
+func intParam(params map[string]any, name string, def, lo, hi int) int {
+	var f float64
+	switch v := params[name].(type) {
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return def
+		}
+		f = n
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return def
+		}
+		f = n
+	default:
+		return def
+	}
+
+	// Clamp before converting: int() of an out-of-range float is undefined
+	switch {
+	case math.IsNaN(f) || f == 0:
+		return def
+	case f < float64(lo):
+		return lo
+	case f > float64(hi):
+		return hi
+	}
+	return int(f)
+}
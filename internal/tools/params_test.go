@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestIntParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  int
+	}{
+		{"missing", nil, 10},
+		{"zero", 0.0, 10},
+		{"in range", 25.0, 25},
+		{"fraction", 7.9, 7},
+		{"negative", -5.0, 1},
+		{"huge", 1e9, 100},
+		{"beyond int", 1e300, 100},
+		{"negative infinity", math.Inf(-1), 1},
+		{"NaN", math.NaN(), 10},
+		{"int", 30, 30},
+		{"json number", json.Number("40"), 40},
+		{"numeric string", " 15 ", 15},
+		{"word", "ten", 10},
+		{"bool", true, 10},
+		{"list", []any{5.0}, 10},
+	}
+	for _, tt := range tests {
+		params := map[string]any{}
+		if tt.value != nil {
+			params["limit"] = tt.value
+		}
+		if got := intParam(params, "limit", 10, 1, 100); got != tt.want {
+			t.Errorf("%s: intParam(%v) = %d, want %d", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
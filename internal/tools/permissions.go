@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/everydev1618/govega"
+	"gopkg.in/yaml.v3"
+)
+
+// ToolPermissions restricts which tools an agent may use.
+// Allow narrows the tool set to the listed names (empty means no restriction);
+// Deny always wins and strips tools even if they are builtins.
+type ToolPermissions struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+// loadToolPermissions loads per-agent permissions from a YAML file of the form:
+//
+//	agents:
+//	  Gary:
+//	    deny: [execute, start_server]
+func loadToolPermissions(path string) (map[string]ToolPermissions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Agents map[string]ToolPermissions `yaml:"agents"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file.Agents, nil
+}
+
+// SetToolPermissions sets the allow/deny lists for an agent
+func (pt *PersonaTools) SetToolPermissions(agent string, perms ToolPermissions) {
+	pt.permissionsMu.Lock()
+	defer pt.permissionsMu.Unlock()
+	pt.permissions[agent] = perms
+}
+
+// toolPermissionsFor returns the permissions configured for an agent
+func (pt *PersonaTools) toolPermissionsFor(agent string) (ToolPermissions, bool) {
+	pt.permissionsMu.RLock()
+	defer pt.permissionsMu.RUnlock()
+	perms, ok := pt.permissions[agent]
+	return perms, ok
+}
+
+// permittedTools filters tool names through the allow and deny lists
+func permittedTools(names []string, perms ToolPermissions) []string {
+	allow := make(map[string]bool, len(perms.Allow))
+	for _, name := range perms.Allow {
+		allow[name] = true
+	}
+	deny := make(map[string]bool, len(perms.Deny))
+	for _, name := range perms.Deny {
+		deny[name] = true
+	}
+
+	var permitted []string
+	for _, name := range names {
+		if deny[name] {
+			continue
+		}
+		if len(allow) > 0 && !allow[name] {
+			continue
+		}
+		permitted = append(permitted, name)
+	}
+	return permitted
+}
+
+// restrictTools applies an agent's allow and deny lists to its tool set.
+// Filter with no names means no filter, so an agent left with nothing gets
+// an empty set rather than every tool back.
+func restrictTools(tools *vega.Tools, perms ToolPermissions) *vega.Tools {
+	var names []string
+	for _, schema := range tools.Schema() {
+		names = append(names, schema.Name)
+	}
+	permitted := permittedTools(names, perms)
+	if len(permitted) == 0 {
+		return vega.NewTools()
+	}
+	return tools.Filter(permitted...)
+}
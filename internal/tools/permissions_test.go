@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestPermittedTools(t *testing.T) {
+	names := []string{"read_file", "write_file", "execute", "web_search", "start_server"}
+
+	tests := []struct {
+		name  string
+		perms ToolPermissions
+		want  []string
+	}{
+		{
+			name:  "no restrictions",
+			perms: ToolPermissions{},
+			want:  names,
+		},
+		{
+			name:  "deny only",
+			perms: ToolPermissions{Deny: []string{"execute", "start_server"}},
+			want:  []string{"read_file", "write_file", "web_search"},
+		},
+		{
+			name:  "allow only",
+			perms: ToolPermissions{Allow: []string{"read_file", "web_search"}},
+			want:  []string{"read_file", "web_search"},
+		},
+		{
+			name:  "deny wins over allow",
+			perms: ToolPermissions{Allow: []string{"read_file", "execute"}, Deny: []string{"execute"}},
+			want:  []string{"read_file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := permittedTools(names, tt.perms)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("permittedTools() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadToolPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool_permissions.yaml")
+	data := "agents:\n  Gary:\n    deny:\n      - execute\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := loadToolPermissions(path)
+	if err != nil {
+		t.Fatalf("loadToolPermissions() error = %v", err)
+	}
+	if got := perms["Gary"].Deny; !reflect.DeepEqual(got, []string{"execute"}) {
+		t.Errorf("Gary deny = %v, want [execute]", got)
+	}
+}
+
+func TestRestrictToolsDenyAll(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	tools := vega.NewTools()
+	pt.RegisterTo(tools)
+	var names []string
+	for _, schema := range tools.Schema() {
+		names = append(names, schema.Name)
+	}
+
+	for name, perms := range map[string]ToolPermissions{
+		"deny everything":       {Deny: names},
+		"allow matches nothing": {Allow: []string{"no_such_tool"}},
+	} {
+		if got := restrictTools(tools, perms).Schema(); len(got) != 0 {
+			t.Errorf("%s: agent keeps %d tools, want none", name, len(got))
+		}
+	}
+
+	if got := restrictTools(tools, ToolPermissions{Allow: []string{"web_search"}}).Schema(); len(got) != 1 || got[0].Name != "web_search" {
+		t.Errorf("allow web_search: got %v", got)
+	}
+}
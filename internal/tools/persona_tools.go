@@ -2,22 +2,27 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"net/http"
-	"net/smtp"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/everydev1618/tron/internal/governance"
 	"github.com/everydev1618/tron/internal/knowledge"
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/memory"
 	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/persist"
+	"github.com/everydev1618/tron/internal/sms"
 	"github.com/everydev1618/tron/internal/subdomain"
+	"github.com/everydev1618/tron/internal/textutil"
 	"github.com/everydev1618/govega"
 	"github.com/everydev1618/govega/container"
 	"github.com/everydev1618/govega/dsl"
@@ -29,11 +34,26 @@ type SlackPoster interface {
 	SendMessage(channel, text string) error
 }
 
+// SlackUploader is implemented by Slack clients that can upload files
+type SlackUploader interface {
+	UploadFile(channel, filename, content, title string) error
+}
+
+// EmailSender sends plain notification emails (for testability)
+type EmailSender interface {
+	IsConfigured() bool
+	Send(to, subject, body string) error
+}
+
+// slackUploadThreshold is the result size above which results are uploaded as a file
+const slackUploadThreshold = 3000
+
 // PersonaTools provides Tony's orchestration tools
 type PersonaTools struct {
 	orch       *vega.Orchestrator
 	config     *dsl.Document
 	contacts   *ContactDB
+	blocklist  *callerBlocklist
 	workingDir string
 	tronDir    string
 
@@ -41,7 +61,7 @@ type PersonaTools struct {
 	containers *container.Manager
 	projects   *container.ProjectRegistry
 
-	// Server process management (for *.hellotron.com routing)
+	// Server process management (for subdomain routing)
 	processManager *subdomain.ProcessManager
 
 	// Track spawned agents and their callbacks
@@ -52,8 +72,19 @@ type PersonaTools struct {
 	processChannels   map[string]notification.ChannelContext
 	processChannelsMu sync.RWMutex
 
-	// Slack client for notifications
+	// Serializes writes of the two maps above to disk
+	spawnCallbacksSaveMu sync.Mutex
+
+	// Slack, SMS, and email clients for notifications
 	slackClient SlackPoster
+	smsNotifier *sms.Notifier
+	emailClient EmailSender
+
+	// Whether voice callers without an email are texted their results
+	textVoiceCallers bool
+
+	// Pending completion callbacks (optional, enables update_callback)
+	callbackRegistry CallbackUpdater
 
 	// Memory storage
 	directives    map[string]string
@@ -61,8 +92,115 @@ type PersonaTools struct {
 	directivesMu  sync.RWMutex
 	personMemMu   sync.RWMutex
 
-	// Shared knowledge store
-	knowledgeStore *knowledge.Store
+	// Directives scoped to a project, keyed by project name
+	projectDirectives map[string]map[string]string
+
+	// Project scope of spawned processes
+	processProjects   map[string]string
+	processProjectsMu sync.RWMutex
+
+	// Spawned processes' own working directories (see newAgentDir)
+	agentDirs         map[string]string
+	agentDirRetention time.Duration
+	agentDirsMu       sync.RWMutex
+
+	// Parent of each spawned process, kept after it finishes for lineage queries
+	spawnParents   map[string]string
+	spawnParentsMu sync.RWMutex
+
+	// Shared knowledge store. Compaction replaces it under knowledgeMu,
+	// which also guards the retention.
+	knowledgeStore     *knowledge.Store
+	knowledgeMu        sync.RWMutex
+	knowledgeRetention KnowledgeRetention
+	knowledgeSweepOnce sync.Once
+
+	// Operating framework given to C-level spawns, cached between spawns
+	governance *governance.Store
+
+	// Identical share_knowledge entries within this window are skipped
+	knowledgeDedupWindow time.Duration
+
+	// Per-agent tool allow/deny lists
+	permissions   map[string]ToolPermissions
+	permissionsMu sync.RWMutex
+
+	logger logging.Logger
+
+	// Slack channel for raw tool error reports (empty disables)
+	opsChannel string
+
+	// Condenses long results in Slack notifications (optional)
+	summarizer memory.Summarizer
+
+	// Notification layouts; nil uses the built-in wording
+	templates *notification.Templates
+
+	// Per-channel and per-user Slack notification levels
+	notifyPrefs *notifyPrefs
+
+	// Extra notification targets for matching tasks
+	routes   []NotificationRoute
+	routesMu sync.RWMutex
+
+	// Heartbeats and stuck detection for running spawns
+	spawnWatches     map[string]*spawnWatch
+	spawnWatchesMu   sync.Mutex
+	spawnMonitorOnce sync.Once
+	spawnMonitorStop chan struct{}
+	stopMonitorOnce  sync.Once
+
+	// Web search backends in fallback order, shared in-flight and recent
+	// searches, and the Brave quota state
+	searchProviders []SearchProvider
+	searches        searchGroup
+	searchQuota     searchQuotaTracker
+
+	// Tool calls and spawned agents in progress, and whether new spawns
+	// are refused for shutdown
+	inflight atomic.Int64
+	spawned  atomic.Int64
+	draining atomic.Bool
+
+	// Results of completed spawns, kept for later retrieval
+	results *resultStore
+
+	// Supervision for agents whose config doesn't set one
+	defaultSupervision vega.Supervision
+
+	// Global cap on concurrently running spawned agents (nil = no cap)
+	spawnSlots     chan struct{}
+	spawnLimitMode SpawnLimitMode
+
+	// Per-tool concurrency limits for heavy tools (see tool_limits.go)
+	toolSlots        map[string]chan struct{}
+	toolSlotsMu      sync.RWMutex
+	toolQueueTimeout time.Duration
+
+	// Describe execute calls instead of running them
+	execDryRun bool
+
+	// Which commands execute refuses or, in allowlist mode, permits
+	execPolicy ExecPolicy
+
+	// Whether Docker has gone away since startup, and what to do then
+	containerHealth   containerHealth
+	containerFallback ContainerFallback
+
+	// Projects whose container image has no timeout(1); execute relies on
+	// the exec deadline there
+	noTimeoutMu       sync.Mutex
+	noTimeoutProjects map[string]bool
+
+	// Receives updates while execute runs (optional)
+	execProgress         func(ExecProgress)
+	execProgressInterval time.Duration
+
+	// Called for each failed tool call (optional)
+	recordToolError func(ToolError)
+
+	// Called when a spawned agent starts and finishes (optional)
+	recordProcess func(ProcessEvent)
 }
 
 // CallbackConfig stores callback information for spawned agents
@@ -76,6 +214,16 @@ type CallbackConfig struct {
 type ContactDB struct {
 	contacts map[string]Contact
 	mu       sync.RWMutex
+
+	// Entries that can't be looked up by phone (no phone, or a phone an
+	// earlier entry already uses). Kept so saving never drops them.
+	unkeyed []Contact
+
+	// File the contacts were loaded from; saves go back to it
+	path string
+
+	// Serializes writes of contacts.yaml
+	persistMu sync.Mutex
 }
 
 // Contact represents a contact entry
@@ -90,26 +238,62 @@ type Contact struct {
 	Meta    map[string]string `yaml:"meta,omitempty"`
 }
 
-// NewPersonaTools creates a new PersonaTools instance
+// NewPersonaTools creates a new PersonaTools instance.
+//
+// Deprecated: use NewPersonaToolsWithOptions with WithWorkingDir, WithTronDir
+// and WithContainerManager. This shim will be removed in the next release.
 func NewPersonaTools(orch *vega.Orchestrator, config *dsl.Document, workingDir, tronDir string, cm *container.Manager) *PersonaTools {
+	return NewPersonaToolsWithOptions(orch, config,
+		WithWorkingDir(workingDir),
+		WithTronDir(tronDir),
+		WithContainerManager(cm),
+	)
+}
+
+// NewPersonaToolsWithOptions creates a new PersonaTools instance configured
+// by opts. Options are applied before saved state is loaded, so directories
+// and the logger are in place by then.
+func NewPersonaToolsWithOptions(orch *vega.Orchestrator, config *dsl.Document, opts ...Option) *PersonaTools {
 	pt := &PersonaTools{
-		orch:            orch,
-		config:          config,
-		contacts:        &ContactDB{contacts: make(map[string]Contact)},
-		workingDir:      workingDir,
-		tronDir:         tronDir,
-		containers:      cm,
-		callbacks:       make(map[string]CallbackConfig),
-		processChannels: make(map[string]notification.ChannelContext),
-		directives:      make(map[string]string),
-		personMemory:    make(map[string]map[string]string),
-	}
+		orch:              orch,
+		config:            config,
+		contacts:          &ContactDB{contacts: make(map[string]Contact)},
+		workingDir:        ".",
+		callbacks:         make(map[string]CallbackConfig),
+		processChannels:   make(map[string]notification.ChannelContext),
+		directives:        make(map[string]string),
+		projectDirectives: make(map[string]map[string]string),
+		processProjects:   make(map[string]string),
+		agentDirs:         make(map[string]string),
+		personMemory:      make(map[string]map[string]string),
+		permissions:       make(map[string]ToolPermissions),
+		spawnWatches:      make(map[string]*spawnWatch),
+		spawnMonitorStop:  make(chan struct{}),
+		logger:            logging.New("tools"),
+	}
+	pt.defaultSupervision = DefaultSupervision
+	pt.knowledgeDedupWindow = DefaultKnowledgeDedupWindow
+	pt.toolQueueTimeout = DefaultToolQueueTimeout
+	pt.containerFallback = ContainerFallbackHost
+	pt.SetToolConcurrency(nil)
+	pt.SetSearchProviders(nil)
+	pt.SetExecPolicy(ExecPolicy{})
+
+	for _, opt := range opts {
+		opt(pt)
+	}
+	if pt.tronDir == "" {
+		pt.tronDir = pt.workingDir
+	}
+	tronDir, workingDir, cm := pt.tronDir, pt.workingDir, pt.containers
+
+	pt.results = newResultStore(filepath.Join(tronDir, "tron.work", "process_results"))
 
 	// Initialize shared knowledge store
 	if ks, err := knowledge.NewStore(tronDir); err == nil {
 		pt.knowledgeStore = ks
 	} else {
-		log.Printf("[tools] Failed to initialize knowledge store: %v", err)
+		pt.logger.Errorf("Failed to initialize knowledge store: %v", err)
 	}
 
 	// Create project registry if container manager is available
@@ -122,14 +306,42 @@ func NewPersonaTools(orch *vega.Orchestrator, config *dsl.Document, workingDir,
 
 	// Load contacts from knowledge directory (check tron dir first, then current dir)
 	knowledgeDir := filepath.Join(tronDir, "knowledge")
+	pt.governance = governance.NewStore(knowledgeDir)
 	if err := pt.loadContacts(filepath.Join(knowledgeDir, "contacts.yaml")); err != nil {
 		// Try current directory as fallback
 		pt.loadContacts("knowledge/contacts.yaml")
 	}
 
+	pt.blocklist = newCallerBlocklist(filepath.Join(knowledgeDir, "blocked_callers.json"))
+	pt.notifyPrefs = newNotifyPrefs(filepath.Join(knowledgeDir, "notification_prefs.json"))
+
+	// Load saved directives (global and per-project)
+	pt.loadDirectives()
+
+	// Load notification routes (optional)
+	if routes, err := loadNotificationRoutes(filepath.Join(tronDir, "notification_routes.yaml")); err == nil {
+		pt.routes = routes
+	} else if !os.IsNotExist(err) {
+		pt.logger.Errorf("Failed to load notification routes: %v", err)
+	}
+
+	// Load tool permissions (optional)
+	if perms, err := loadToolPermissions(filepath.Join(tronDir, "tool_permissions.yaml")); err == nil {
+		for agent, p := range perms {
+			pt.permissions[agent] = p
+		}
+	} else if !os.IsNotExist(err) {
+		pt.logger.Errorf("Failed to load tool permissions: %v", err)
+	}
+
 	return pt
 }
 
+// SetLogger replaces the logger used by the persona tools
+func (pt *PersonaTools) SetLogger(l logging.Logger) {
+	pt.logger = l
+}
+
 // SetProcessManager sets the server process manager for subdomain routing
 func (pt *PersonaTools) SetProcessManager(pm *subdomain.ProcessManager) {
 	pt.processManager = pm
@@ -153,10 +365,19 @@ func (pt *PersonaTools) loadContacts(path string) error {
 	defer pt.contacts.mu.Unlock()
 
 	for _, c := range contactList.Contacts {
-		// Index by phone number (normalized)
+		// Index by phone number (normalized). The last entry for a phone
+		// wins the lookup; the rest are kept aside so they survive a save.
 		phone := normalizePhone(c.Phone)
+		if phone == "" {
+			pt.contacts.unkeyed = append(pt.contacts.unkeyed, c)
+			continue
+		}
+		if prev, ok := pt.contacts.contacts[phone]; ok {
+			pt.contacts.unkeyed = append(pt.contacts.unkeyed, prev)
+		}
 		pt.contacts.contacts[phone] = c
 	}
+	pt.contacts.path = path
 
 	return nil
 }
@@ -175,9 +396,8 @@ func normalizePhone(phone string) string {
 // RegisterTo registers all persona tools to a vega.Tools instance
 func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 	// spawn_agent - Delegate work to a team member
-	tools.Register("spawn_agent", vega.ToolDef{
+	pt.register(tools, "spawn_agent", pt.spawnAgent, vega.ToolDef{
 		Description: "Spawn a team member agent to handle a task. Returns the process ID.",
-		Fn:          pt.spawnAgent,
 		Params: map[string]vega.ParamDef{
 			"agent": {
 				Type:        "string",
@@ -194,13 +414,22 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "Additional context or files to provide",
 				Required:    false,
 			},
+			"project": {
+				Type:        "string",
+				Description: "Project the task belongs to; the agent sees that project's directives and knowledge",
+				Required:    false,
+			},
+			"share_workdir": {
+				Type:        "boolean",
+				Description: "Run in the shared working directory instead of the agent's own scratch directory, when agents need to work on the same files (default false)",
+				Required:    false,
+			},
 		},
 	})
 
 	// schedule_callback - Request notification when work completes
-	tools.Register("schedule_callback", vega.ToolDef{
+	pt.register(tools, "schedule_callback", pt.scheduleCallback, vega.ToolDef{
 		Description: "Schedule an email notification when a spawned agent completes its work",
-		Fn:          pt.scheduleCallback,
 		Params: map[string]vega.ParamDef{
 			"process_id": {
 				Type:        "string",
@@ -220,23 +449,199 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 		},
 	})
 
+	// update_callback - Change how a pending callback is delivered
+	pt.register(tools, "update_callback", pt.updateCallback, vega.ToolDef{
+		Description: "Change how a pending completion callback reaches the customer, e.g. email instead of a call. Only the fields you pass are changed; callbacks that already fired can't be updated.",
+		Params: map[string]vega.ParamDef{
+			"agent_id": {
+				Type:        "string",
+				Description: "Agent or process ID the callback was registered for",
+				Required:    true,
+			},
+			"method": {
+				Type:        "string",
+				Description: "New delivery method: call, email, both (call and email), sms, or all (call, email and text)",
+				Required:    false,
+			},
+			"phone": {
+				Type:        "string",
+				Description: "New phone number for calls or texts",
+				Required:    false,
+			},
+			"email": {
+				Type:        "string",
+				Description: "New email address",
+				Required:    false,
+			},
+			"name": {
+				Type:        "string",
+				Description: "Customer name to use",
+				Required:    false,
+			},
+		},
+	})
+
+	// get_result - Re-read what a completed agent produced
+	pt.register(tools, "get_result", pt.getResult, vega.ToolDef{
+		Description: "Get the stored result of a completed agent by process ID, or list recent results (optionally for one agent)",
+		Params: map[string]vega.ParamDef{
+			"process_id": {
+				Type:        "string",
+				Description: "Process ID of the completed agent (returns the full result)",
+				Required:    false,
+			},
+			"agent": {
+				Type:        "string",
+				Description: "List results from this team member only (e.g. Gary)",
+				Required:    false,
+			},
+			"since": {
+				Type:        "string",
+				Description: "How far back to list, as a duration like 24h (default) or 168h",
+				Required:    false,
+			},
+		},
+	})
+
+	// get_agent_budget - Check a running agent's spend against its budget
+	pt.register(tools, "get_agent_budget", pt.getAgentBudget, vega.ToolDef{
+		Description: "Check how much of its budget a running agent has spent: limit, spent so far, remaining, and a warning when over 80%",
+		Params: map[string]vega.ParamDef{
+			"process_id": {
+				Type:        "string",
+				Description: "Process ID from spawn_agent",
+				Required:    true,
+			},
+		},
+	})
+
+	// get_spawn_tree - Inspect delegated work and what it spawned
+	pt.register(tools, "get_spawn_tree", pt.getSpawnTree, vega.ToolDef{
+		Description: "Show the tree of running agent processes and the agents they spawned, with task and status",
+		Params: map[string]vega.ParamDef{
+			"process_id": {
+				Type:        "string",
+				Description: "Process to root the tree at, or \"self\" for your own delegations (default: all processes)",
+				Required:    false,
+			},
+		},
+	})
+
+	// cancel_all_agents - Emergency stop for everything the caller delegated
+	pt.register(tools, "cancel_all_agents", pt.cancelAllAgents, vega.ToolDef{
+		Description: "Emergency stop: kill every agent you spawned, and everything they spawned, and cancel their pending callbacks. Only your own delegations are affected. Use for a runaway budget or wrong instructions that spread.",
+		Params: map[string]vega.ParamDef{
+			"reason": {
+				Type:        "string",
+				Description: "Why everything is being stopped (logged for the incident)",
+				Required:    false,
+			},
+		},
+	})
+
 	// identify_caller - Look up caller by phone number
-	tools.Register("identify_caller", vega.ToolDef{
+	pt.register(tools, "identify_caller", pt.identifyCallerTool, vega.ToolDef{
 		Description: "Look up a caller by their phone number",
-		Fn:          pt.identifyCallerTool,
 		Params: map[string]vega.ParamDef{
 			"phone": {
 				Type:        "string",
 				Description: "Phone number to look up",
 				Required:    true,
 			},
+			"include_context": {
+				Type:        "boolean",
+				Description: "Also include a briefing: what we remember about them and recent knowledge mentioning them (default false)",
+				Required:    false,
+			},
+		},
+	})
+
+	// update_caller - Save who a caller is so they're recognized next time
+	pt.register(tools, "update_caller", pt.updateCallerTool, vega.ToolDef{
+		Description: "Save or update the contact for a caller's phone number, e.g. after an unknown caller introduces themselves",
+		Params: map[string]vega.ParamDef{
+			"phone": {
+				Type:        "string",
+				Description: "The caller's phone number",
+				Required:    true,
+			},
+			"name": {
+				Type:        "string",
+				Description: "The caller's name (required for new callers)",
+				Required:    false,
+			},
+			"email": {
+				Type:        "string",
+				Description: "Email address",
+				Required:    false,
+			},
+			"company": {
+				Type:        "string",
+				Description: "Company they work for",
+				Required:    false,
+			},
+			"role": {
+				Type:        "string",
+				Description: "Their role or title",
+				Required:    false,
+			},
+			"notes": {
+				Type:        "string",
+				Description: "Anything worth remembering about them",
+				Required:    false,
+			},
+		},
+	})
+
+	// block_caller - Reject future calls from a nuisance number
+	pt.register(tools, "block_caller", pt.blockCallerTool, vega.ToolDef{
+		Description: "Block a phone number so future calls from it are rejected, e.g. spam or abusive callers. Defaults to the current caller on a voice call.",
+		Params: map[string]vega.ParamDef{
+			"phone": {
+				Type:        "string",
+				Description: "The phone number to block (defaults to the current caller)",
+				Required:    false,
+			},
+			"reason": {
+				Type:        "string",
+				Description: "Why the number is blocked",
+				Required:    false,
+			},
+		},
+	})
+
+	// set_notification_level - Choose how chatty completion notices are
+	pt.register(tools, "set_notification_level", pt.setNotificationLevel, vega.ToolDef{
+		Description: "Set how much a Slack channel (or the requesting user) hears when agents finish: all (every completion with its result), quiet (a one-line notice), or errors (failures only). Failures are always posted. Use default to clear the setting.",
+		Params: map[string]vega.ParamDef{
+			"level": {
+				Type:        "string",
+				Description: "all, quiet, errors, or default",
+				Required:    true,
+			},
+			"scope": {
+				Type:        "string",
+				Description: "channel (this channel, the default) or me (the requesting user's own requests, wherever they ask)",
+				Required:    false,
+			},
+		},
+	})
+
+	// unblock_caller - Undo a block
+	pt.register(tools, "unblock_caller", pt.unblockCallerTool, vega.ToolDef{
+		Description: "Remove a phone number from the caller blocklist",
+		Params: map[string]vega.ParamDef{
+			"phone": {
+				Type:        "string",
+				Description: "The phone number to unblock",
+				Required:    true,
+			},
 		},
 	})
 
 	// create_project - Set up a new project workspace
-	tools.Register("create_project", vega.ToolDef{
+	pt.register(tools, "create_project", pt.createProject, vega.ToolDef{
 		Description: "Create a new project workspace in the work directory",
-		Fn:          pt.createProject,
 		Params: map[string]vega.ParamDef{
 			"name": {
 				Type:        "string",
@@ -253,13 +658,17 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "Project template (go, python, node, react, empty)",
 				Required:    false,
 			},
+			"image": {
+				Type:        "string",
+				Description: "Container base image, e.g. golang:1.23 or node:20 (defaults based on template)",
+				Required:    false,
+			},
 		},
 	})
 
 	// save_directive - Save an important instruction
-	tools.Register("save_directive", vega.ToolDef{
+	pt.register(tools, "save_directive", pt.saveDirective, vega.ToolDef{
 		Description: "Save an important instruction or directive for future reference",
-		Fn:          pt.saveDirective,
 		Params: map[string]vega.ParamDef{
 			"key": {
 				Type:        "string",
@@ -271,13 +680,17 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "The directive or instruction to remember",
 				Required:    true,
 			},
+			"project": {
+				Type:        "string",
+				Description: "Scope the directive to a project instead of applying it everywhere",
+				Required:    false,
+			},
 		},
 	})
 
 	// save_person_memory - Remember facts about a person
-	tools.Register("save_person_memory", vega.ToolDef{
+	pt.register(tools, "save_person_memory", pt.savePersonMemory, vega.ToolDef{
 		Description: "Save facts about a person for future conversations",
-		Fn:          pt.savePersonMemory,
 		Params: map[string]vega.ParamDef{
 			"person": {
 				Type:        "string",
@@ -298,26 +711,56 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 	})
 
 	// web_search - Search the web
-	tools.Register("web_search", vega.ToolDef{
-		Description: "Search the web for current information (stub - implement with real search API)",
-		Fn:          pt.webSearch,
+	pt.register(tools, "web_search", pt.webSearch, vega.ToolDef{
+		Description: "Search the web for current information",
 		Params: map[string]vega.ParamDef{
 			"query": {
 				Type:        "string",
 				Description: "Search query",
 				Required:    true,
 			},
+			"count": {
+				Type:        "number",
+				Description: "Number of results to return (1-20, default 5)",
+				Required:    false,
+			},
+			"freshness": {
+				Type:        "string",
+				Description: "Limit results by age: pd (past day), pw (past week), pm (past month), py (past year)",
+				Required:    false,
+			},
+			"provider": {
+				Type:        "string",
+				Description: "Force a search provider: brave, google, or serpapi (default: the first configured, falling back if it fails)",
+				Required:    false,
+			},
+		},
+	})
+
+	// fetch_url - Read a web page
+	pt.register(tools, "fetch_url", pt.fetchURL, vega.ToolDef{
+		Description: "Fetch a web page (http or https) and return its readable text, e.g. to read a web_search result. Internal and private network addresses are refused",
+		Params: map[string]vega.ParamDef{
+			"url": {
+				Type:        "string",
+				Description: "The URL to fetch",
+				Required:    true,
+			},
+			"max_length": {
+				Type:        "number",
+				Description: "Most bytes of page text to return (100-50000, default 10000)",
+				Required:    false,
+			},
 		},
 	})
 
 	// execute - Run shell commands (in container if available)
-	execDesc := "Execute a shell command in the working directory"
+	execDesc := "Execute a shell command in the working directory. If a project is specified, runs in the project directory with its .env loaded"
 	if pt.containers != nil && pt.containers.IsAvailable() {
 		execDesc = "Execute a shell command. If a project is specified, runs inside the project's Docker container"
 	}
-	tools.Register("execute", vega.ToolDef{
+	pt.register(tools, "execute", pt.execute, vega.ToolDef{
 		Description: execDesc,
-		Fn:          pt.execute,
 		Params: map[string]vega.ParamDef{
 			"command": {
 				Type:        "string",
@@ -329,13 +772,44 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "Project name to execute in (uses container if available)",
 				Required:    false,
 			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Show the command, working directory, and whether it would run in a container or on host, without running it (default false)",
+				Required:    false,
+			},
+			"timeout_seconds": {
+				Type:        "number",
+				Description: "How long to let the command run (1-600, default 120). Raise it for builds and test suites; lower it for quick checks that might hang",
+				Required:    false,
+			},
+		},
+	})
+
+	// export_project - Archive a project's files for backup or handoff
+	pt.register(tools, "export_project", pt.exportProject, vega.ToolDef{
+		Description: "Export a project's workspace as a .tar.gz archive and return its path. Skips node_modules and .git unless include_all is set.",
+		Params: map[string]vega.ParamDef{
+			"project": {
+				Type:        "string",
+				Description: "Project name to export",
+				Required:    true,
+			},
+			"include_all": {
+				Type:        "boolean",
+				Description: "Include node_modules and .git (default false)",
+				Required:    false,
+			},
+			"exclude": {
+				Type:        "string",
+				Description: "Comma-separated extra paths or globs to exclude (e.g. dist,*.log)",
+				Required:    false,
+			},
 		},
 	})
 
 	// get_project_status - Check container status for a project
-	tools.Register("get_project_status", vega.ToolDef{
+	pt.register(tools, "get_project_status", pt.getProjectStatus, vega.ToolDef{
 		Description: "Get the status of a project's container (running, stopped, etc.)",
-		Fn:          pt.getProjectStatus,
 		Params: map[string]vega.ParamDef{
 			"project": {
 				Type:        "string",
@@ -346,9 +820,8 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 	})
 
 	// start_server - Start a server process for a project and get its public URL
-	tools.Register("start_server", vega.ToolDef{
-		Description: "Start a server process for a project. Returns a unique public URL (https://xxxx.hellotron.com) that routes to the server.",
-		Fn:          pt.startServer,
+	pt.register(tools, "start_server", pt.startServer, vega.ToolDef{
+		Description: "Start a server process for a project. Returns a unique public HTTPS URL on its own subdomain that routes to the server. Variables in the project's .env file are set; PORT is always assigned by Tron.",
 		Params: map[string]vega.ParamDef{
 			"project": {
 				Type:        "string",
@@ -360,13 +833,50 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "Command to start the server (will receive PORT env variable)",
 				Required:    true,
 			},
+			"health_path": {
+				Type:        "string",
+				Description: "Optional HTTP path (e.g. /health) that must answer before the server counts as ready; otherwise it's ready once it accepts connections",
+			},
+			"routes": {
+				Type:        "string",
+				Description: "Optional comma-separated names of extra services the command runs, e.g. \"api,admin\". Each gets its own public URL and a port in PORT_<NAME> (e.g. PORT_API).",
+			},
 		},
 	})
 
 	// stop_server - Stop a running server
-	tools.Register("stop_server", vega.ToolDef{
+	pt.register(tools, "stop_server", pt.stopServer, vega.ToolDef{
 		Description: "Stop a running server for a project",
-		Fn:          pt.stopServer,
+		Params: map[string]vega.ParamDef{
+			"project": {
+				Type:        "string",
+				Description: "Project name",
+				Required:    true,
+			},
+		},
+	})
+
+	// restart_server - Bounce a running server after a code change
+	pt.register(tools, "restart_server", pt.restartServer, vega.ToolDef{
+		Description: "Restart a project's running server with the same command and environment, e.g. after a code change. The public URL stays the same.",
+		Params: map[string]vega.ParamDef{
+			"project": {
+				Type:        "string",
+				Description: "Project name",
+				Required:    true,
+			},
+		},
+	})
+
+	// stop_all_servers - Stop every running server
+	pt.register(tools, "stop_all_servers", pt.stopAllServers, vega.ToolDef{
+		Description: "Stop every running project server, freeing their ports",
+		Params:      map[string]vega.ParamDef{},
+	})
+
+	// get_server_logs - See what a server printed
+	pt.register(tools, "get_server_logs", pt.getServerLogs, vega.ToolDef{
+		Description: "Get the recent output (stdout and stderr) of a project's server, including one that crashed. Use it to find out why a server failed.",
 		Params: map[string]vega.ParamDef{
 			"project": {
 				Type:        "string",
@@ -377,9 +887,8 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 	})
 
 	// get_server_url - Get the URL of a running server
-	tools.Register("get_server_url", vega.ToolDef{
+	pt.register(tools, "get_server_url", pt.getServerURL, vega.ToolDef{
 		Description: "Get the public URL of a running server for a project",
-		Fn:          pt.getServerURL,
 		Params: map[string]vega.ParamDef{
 			"project": {
 				Type:        "string",
@@ -390,23 +899,20 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 	})
 
 	// list_servers - List all running servers
-	tools.Register("list_servers", vega.ToolDef{
+	pt.register(tools, "list_servers", pt.listServers, vega.ToolDef{
 		Description: "List all running project servers with their URLs",
-		Fn:          pt.listServers,
 		Params:      map[string]vega.ParamDef{},
 	})
 
 	// list_projects - List all projects
-	tools.Register("list_projects", vega.ToolDef{
+	pt.register(tools, "list_projects", pt.listProjects, vega.ToolDef{
 		Description: "List all projects in the work directory. Use this to see what projects exist before answering questions about current work.",
-		Fn:          pt.listProjects,
 		Params:      map[string]vega.ParamDef{},
 	})
 
 	// share_knowledge - Share a discovery, insight, or decision with the team
-	tools.Register("share_knowledge", vega.ToolDef{
+	pt.register(tools, "share_knowledge", pt.shareKnowledge, vega.ToolDef{
 		Description: "Share a discovery, insight, decision, or task result with the team. Other team members will see this in their knowledge feed.",
-		Fn:          pt.shareKnowledge,
 		Params: map[string]vega.ParamDef{
 			"type": {
 				Type:        "string",
@@ -433,27 +939,41 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "Comma-separated tags for categorization",
 				Required:    false,
 			},
+			"project": {
+				Type:        "string",
+				Description: "Scope the entry to a project (defaults to your current project)",
+				Required:    false,
+			},
+			"pin": {
+				Type:        "boolean",
+				Description: "Pin the entry so it stays at the top of query results regardless of age",
+				Required:    false,
+			},
+			"force": {
+				Type:        "boolean",
+				Description: "Share even if you recently shared the same title and content",
+				Required:    false,
+			},
 		},
 	})
 
 	// query_knowledge - Search the shared knowledge base
-	tools.Register("query_knowledge", vega.ToolDef{
-		Description: "Search the shared knowledge base for entries by domain, author, type, or tags. Use this to find what other team members have discovered.",
-		Fn:          pt.queryKnowledge,
+	pt.register(tools, "query_knowledge", pt.queryKnowledge, vega.ToolDef{
+		Description: "Search the shared knowledge base for entries by domain, author, type, or tags. Use this to find what other team members have discovered. Domain, author, and type each accept a comma-separated list to match any of several values.",
 		Params: map[string]vega.ParamDef{
 			"domain": {
 				Type:        "string",
-				Description: "Filter by domain: tech, marketing, finance, ops, product, general",
+				Description: "Filter by domain, or comma-separated domains: tech, marketing, finance, ops, product, general",
 				Required:    false,
 			},
 			"author": {
 				Type:        "string",
-				Description: "Filter by author name (e.g., Tony, Maya, Gary)",
+				Description: "Filter by author name, or comma-separated names (e.g., \"Gary, Sarah\")",
 				Required:    false,
 			},
 			"type": {
 				Type:        "string",
-				Description: "Filter by type: discovery, insight, decision, task_result, resource",
+				Description: "Filter by type, or comma-separated types: discovery, insight, decision, task_result, resource",
 				Required:    false,
 			},
 			"tags": {
@@ -461,18 +981,42 @@ func (pt *PersonaTools) RegisterTo(tools *vega.Tools) {
 				Description: "Comma-separated tags to filter by",
 				Required:    false,
 			},
+			"project": {
+				Type:        "string",
+				Description: "Only show entries for this project plus global ones (defaults to your current project)",
+				Required:    false,
+			},
+			"decay": {
+				Type:        "boolean",
+				Description: "Rank pinned entries first, then newer entries higher (default true). Set false for the store's chronological order.",
+				Required:    false,
+			},
+			"archived": {
+				Type:        "boolean",
+				Description: "Search the archive of old entries moved out by retention instead of the active knowledge base, newest first (default false)",
+				Required:    false,
+			},
+			"process_id": {
+				Type:        "string",
+				Description: "Only show entries produced by this process (or \"self\"), to review what a delegated task found",
+				Required:    false,
+			},
+			"include_children": {
+				Type:        "boolean",
+				Description: "With process_id, also include entries from the processes it spawned, at any depth (default true)",
+				Required:    false,
+			},
 			"limit": {
 				Type:        "number",
-				Description: "Maximum number of results (default 10)",
+				Description: "Maximum number of results (1-100, default 10)",
 				Required:    false,
 			},
 		},
 	})
 
 	// get_knowledge_feed - Get recent team activity
-	tools.Register("get_knowledge_feed", vega.ToolDef{
+	pt.register(tools, "get_knowledge_feed", pt.getKnowledgeFeed, vega.ToolDef{
 		Description: "Get a digest of recent team knowledge and activity from the last 24 hours. Shows what other team members have discovered or decided.",
-		Fn:          pt.getKnowledgeFeed,
 		Params:      map[string]vega.ParamDef{},
 	})
 }
@@ -482,12 +1026,21 @@ func (pt *PersonaTools) spawnAgent(ctx context.Context, params map[string]any) (
 	agentName, _ := params["agent"].(string)
 	task, _ := params["task"].(string)
 	taskContext, _ := params["context"].(string)
+	shareWorkDir, _ := params["share_workdir"].(bool)
+	project := pt.projectScope(ctx, params)
+
+	if pt.draining.Load() {
+		return "", ErrShuttingDown
+	}
 
 	// Get agent definition from config
 	agentDef, ok := pt.config.Agents[agentName]
 	if !ok {
 		return "", fmt.Errorf("unknown team member: %s", agentName)
 	}
+	if err := validateModel(agentName, agentDef.Model); err != nil {
+		return "", err
+	}
 
 	// Build the agent with both builtin and custom tools
 	vegaTools := vega.NewTools(vega.WithSandbox(pt.workingDir))
@@ -500,10 +1053,26 @@ func (pt *PersonaTools) spawnAgent(ctx context.Context, params map[string]any) (
 		vegaTools = vegaTools.Filter(agentDef.Tools...)
 	}
 
+	// Apply allow/deny permissions last so denied builtins are stripped too
+	if perms, ok := pt.toolPermissionsFor(agentName); ok {
+		vegaTools = restrictTools(vegaTools, perms)
+	}
+
+	// Give the agent its own directory so concurrent agents don't clobber
+	// each other's scratch files
+	workDir, workDirPrompt := pt.workingDir, ""
+	if !shareWorkDir {
+		dir, err := pt.newAgentDir(agentName)
+		if err != nil {
+			return "", fmt.Errorf("cannot spawn %s: %w", agentName, err)
+		}
+		workDir, workDirPrompt = dir, pt.agentDirPromptSection(dir)
+	}
+
 	agent := vega.Agent{
 		Name:   agentDef.Name,
 		Model:  agentDef.Model,
-		System: vega.StaticPrompt(agentDef.System),
+		System: vega.StaticPrompt(agentDef.System + pt.governance.PromptSection(agentDef.Name) + pt.DirectivesPromptSection(project) + workDirPrompt),
 		Tools:  vegaTools,
 	}
 
@@ -514,39 +1083,44 @@ func (pt *PersonaTools) spawnAgent(ctx context.Context, params map[string]any) (
 		agent.Budget = parseBudget(agentDef.Budget)
 	}
 
-	// Create supervision from config
-	var supervision vega.Supervision
-	if agentDef.Supervision != nil {
-		supervision = vega.Supervision{
-			Strategy:    parseStrategy(agentDef.Supervision.Strategy),
-			MaxRestarts: agentDef.Supervision.MaxRestarts,
-			Window:      parseWindow(agentDef.Supervision.Window),
-		}
-	} else {
-		supervision = vega.Supervision{
-			Strategy:    vega.Restart,
-			MaxRestarts: 3,
-		}
+	// Create supervision from config, falling back to the default
+	supervision, err := pt.supervisionFor(agentDef.Supervision)
+	if err != nil {
+		pt.discardAgentDir(workDir)
+		return "", fmt.Errorf("invalid supervision for %s: %w", agentName, err)
 	}
 
 	// Build spawn options
 	spawnOpts := []vega.SpawnOption{
 		vega.WithSupervision(supervision),
 		vega.WithTask(task),
-		vega.WithWorkDir(pt.workingDir),
+		vega.WithWorkDir(workDir),
 		vega.WithSpawnReason(task),
 	}
 
 	// Get the parent process from context for spawn tree tracking
-	if parentProc := vega.ProcessFromContext(ctx); parentProc != nil {
+	parentProc := vega.ProcessFromContext(ctx)
+	if parentProc != nil {
 		spawnOpts = append(spawnOpts, vega.WithParent(parentProc))
 	}
 
+	// Take a slot under the global spawn cap; it's held until the agent finishes
+	release, err := pt.acquireSpawnSlot(ctx)
+	if err != nil {
+		pt.discardAgentDir(workDir)
+		return "", fmt.Errorf("cannot spawn %s: %w", agentName, err)
+	}
+
 	// Spawn the process
 	proc, err := pt.orch.Spawn(agent, spawnOpts...)
 	if err != nil {
+		release()
+		pt.discardAgentDir(workDir)
 		return "", fmt.Errorf("failed to spawn %s: %w", agentName, err)
 	}
+	if workDir != pt.workingDir {
+		pt.setAgentDir(proc.ID, workDir)
+	}
 
 	// Send the initial task
 	fullTask := task
@@ -559,6 +1133,12 @@ func (pt *PersonaTools) spawnAgent(ctx context.Context, params map[string]any) (
 		pt.processChannelsMu.Lock()
 		pt.processChannels[proc.ID] = ch
 		pt.processChannelsMu.Unlock()
+		pt.saveSpawnCallbacks()
+	}
+
+	pt.setProcessProject(proc.ID, project)
+	if parentProc != nil {
+		pt.recordSpawnParent(proc.ID, parentProc.ID)
 	}
 
 	// Set up the callback handler (idempotent, only runs once)
@@ -566,11 +1146,49 @@ func (pt *PersonaTools) spawnAgent(ctx context.Context, params map[string]any) (
 
 	// Send the task and handle completion in background
 	future := proc.SendAsync(fullTask)
+	pt.trackSpawn(proc, agentName)
+	started := ProcessEvent{ProcessID: proc.ID, Agent: agentName, Task: task, Project: project, Model: agentDef.Model}
+	if parentProc != nil {
+		started.ParentID = parentProc.ID
+		if parentProc.Agent != nil {
+			started.ParentAgent = parentProc.Agent.Name
+		}
+	}
+	pt.emitProcessEvent(started)
 
-	// Wait for completion and mark process as done
+	// Wait for completion and mark process as done. Awaiting the future is what
+	// drives Complete/Fail; progress reporting runs on the shared spawn monitor.
+	pt.spawned.Add(1)
 	go func() {
+		defer pt.spawned.Add(-1)
+		defer release()
 		result, err := future.Await(context.Background())
+		pt.untrackSpawn(proc.ID)
+		pt.setProcessProject(proc.ID, "")
+		pt.releaseAgentDir(proc.ID)
+		pt.forgetSpawnParents(proc.ID)
+
+		record := ResultRecord{ProcessID: proc.ID, Agent: agentName, Task: task, Project: project, Result: result}
 		if err != nil {
+			record.Error = err.Error()
+		}
+		pt.recordResult(record)
+
+		pt.emitProcessEvent(ProcessEvent{
+			ProcessID: proc.ID,
+			Agent:     agentName,
+			Task:      task,
+			Project:   project,
+			Model:     agentDef.Model,
+			Done:      true,
+			Result:    result,
+			Err:       err,
+			Duration:  time.Since(proc.StartedAt),
+			Metrics:   proc.Metrics(),
+		})
+
+		if err != nil {
+			pt.notifyFailure(proc, agentName, err)
 			proc.Fail(err)
 		} else {
 			proc.Complete(result)
@@ -596,10 +1214,21 @@ func parseStrategy(s string) vega.Strategy {
 	}
 }
 
-// parseWindow converts a duration string like "10m" to time.Duration
-func parseWindow(s string) time.Duration {
-	d, _ := time.ParseDuration(s)
-	return d
+// parseWindow converts a duration string like "10m" to time.Duration.
+// An empty string is a zero window; anything unparseable is an error.
+func parseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q: %w", s, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid window %q: must not be negative", s)
+	}
+	return d, nil
 }
 
 // parseBudget converts a budget string like "$5.00" to a Budget struct
@@ -638,6 +1267,7 @@ func (pt *PersonaTools) scheduleCallback(ctx context.Context, params map[string]
 		SpawnedAt: time.Now(),
 	}
 	pt.callbacksMu.Unlock()
+	pt.saveSpawnCallbacks()
 
 	// Note: OnProcessComplete is a global callback, so we check the process ID in the callback
 	// This is a one-time setup - multiple schedules for different processes are okay
@@ -664,6 +1294,7 @@ func (pt *PersonaTools) setupCallbackHandlerOnce() {
 				pt.callbacksMu.Lock()
 				delete(pt.callbacks, p.ID)
 				pt.callbacksMu.Unlock()
+				pt.saveSpawnCallbacks()
 			}
 
 			// Channel-aware notifications
@@ -676,37 +1307,33 @@ func (pt *PersonaTools) setupCallbackHandlerOnce() {
 				pt.processChannelsMu.Lock()
 				delete(pt.processChannels, p.ID)
 				pt.processChannelsMu.Unlock()
+				pt.saveSpawnCallbacks()
 			}
+
+			// Fan out to any extra audiences the task's routes name
+			pt.notifyRoutes(ch, p, result)
 		})
 	})
 }
 
+// SetEmailClient sets the client used for callback and voice follow-up emails
+func (pt *PersonaTools) SetEmailClient(client EmailSender) {
+	pt.emailClient = client
+}
+
 // sendCallbackEmail sends a notification email
 func (pt *PersonaTools) sendCallbackEmail(to, subject, body string) error {
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUser := os.Getenv("SMTP_USER")
-	smtpPass := os.Getenv("SMTP_PASS")
-	fromEmail := os.Getenv("SMTP_FROM")
-
-	if smtpHost == "" {
-		// Log but don't fail if SMTP not configured
-		fmt.Printf("SMTP not configured, would send email to %s: %s\n", to, subject)
+	if pt.emailClient == nil || !pt.emailClient.IsConfigured() {
+		// Log but don't fail if email isn't configured
+		pt.logger.Warnf("Email not configured, skipping email to %s: %s", to, subject)
 		return nil
 	}
 
-	if smtpPort == "" {
-		smtpPort = "587"
-	}
-	if fromEmail == "" {
-		fromEmail = smtpUser
+	if err := pt.emailClient.Send(to, subject, body); err != nil {
+		pt.logger.Errorf("Failed to send email to %s: %v", to, err)
+		return err
 	}
-
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
-		fromEmail, to, subject, body)
-
-	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
-	return smtp.SendMail(smtpHost+":"+smtpPort, auth, fromEmail, []string{to}, []byte(msg))
+	return nil
 }
 
 // SetSlackClient sets the Slack client for sending notifications
@@ -721,41 +1348,149 @@ func (pt *PersonaTools) notifyChannel(ch notification.ChannelContext, p *vega.Pr
 		agentName = p.Agent.Name
 	}
 
-	switch ch.Type {
-	case notification.ChannelSlack:
-		if pt.slackClient != nil {
-			msg := fmt.Sprintf("*%s* completed: _%s_\n\n%s",
-				agentName, p.Task, summarizeResult(result, 500))
-			if err := pt.slackClient.SendMessage(ch.ChannelID, msg); err != nil {
-				log.Printf("[notification] Failed to send Slack notification: %v", err)
-			}
-		} else {
-			log.Printf("[notification] Slack client not configured, cannot notify channel %s", ch.ChannelID)
-		}
+	switch ch.Type {
+	case notification.ChannelSlack:
+		switch pt.notifyPrefs.levelFor(ch) {
+		case NotifyErrors:
+			pt.logger.Debugf("Skipping completion notice for %s in %s (errors only)", p.ID, ch.ChannelID)
+		case NotifyQuiet:
+			pt.notifySlackBrief(ch.ChannelID, agentName, p)
+		default:
+			pt.notifySlack(ch.ChannelID, agentName, p, result)
+		}
+
+	case notification.ChannelVoice:
+		// Voice calls have ended - send email if available, else text the
+		// caller if that's been turned on
+		if ch.Email != "" {
+			pt.sendCallbackEmail(ch.Email,
+				fmt.Sprintf("%s completed your request", agentName),
+				result)
+		} else if pt.textVoiceCallers && pt.smsNotifier.IsConfigured() && ch.UserID != "" {
+			pt.notifySMS(ch.UserID, agentName, p, result)
+		} else {
+			// Otherwise log only - user can't be notified
+			pt.logger.Infof("Voice call completed for %s, no notification channel available", ch.UserID)
+		}
+
+	case notification.ChannelSMS:
+		if pt.smsNotifier.IsConfigured() {
+			pt.notifySMS(ch.UserID, agentName, p, result)
+		} else {
+			pt.logger.Warnf("SMS not configured, cannot text %s", ch.UserID)
+		}
+
+	case notification.ChannelAPI:
+		// API calls are synchronous, no notification needed
+		pt.logger.Debugf("API process %s completed", p.ID)
+	}
+}
+
+// notifySlack posts a completion to a Slack channel, uploading long results
+// as a file when the client supports it
+func (pt *PersonaTools) notifySlack(channelID, agentName string, p *vega.Process, result string) {
+	if pt.slackClient == nil {
+		pt.logger.Warnf("Slack client not configured, cannot notify channel %s", channelID)
+		return
+	}
+	if uploader, ok := pt.slackClient.(SlackUploader); ok && len(result) > slackUploadThreshold {
+		if pt.uploadResult(uploader, channelID, agentName, p, result) {
+			return
+		}
+	}
+	msg, err := pt.templates.Render(notification.TemplateSlackComplete, notification.CompletionData{
+		Persona:   notification.DefaultPersona,
+		AgentName: agentName,
+		AgentID:   p.ID,
+		Task:      p.Task,
+		Success:   true,
+		Result:    pt.resultPreview(result, 500),
+	})
+	if err != nil {
+		pt.logger.Warnf("%v", err)
+	}
+	if err := pt.slackClient.SendMessage(channelID, strings.TrimSpace(msg)); err != nil {
+		pt.logger.Errorf("Failed to send Slack notification: %v", err)
+	}
+}
+
+// SetNotificationTemplates sets the templates used for Slack notifications
+func (pt *PersonaTools) SetNotificationTemplates(t *notification.Templates) {
+	pt.templates = t
+}
+
+// SetSMSNotifier sets the notifier used for SMS completion notifications
+func (pt *PersonaTools) SetSMSNotifier(n *sms.Notifier) {
+	pt.smsNotifier = n
+}
+
+// SetTextVoiceCallers sets whether voice callers with no email on file are
+// texted their results. Off by default: they called, they didn't ask for texts.
+func (pt *PersonaTools) SetTextVoiceCallers(enabled bool) {
+	pt.textVoiceCallers = enabled
+}
+
+// notifySMS texts a short summary of a result
+func (pt *PersonaTools) notifySMS(phone, agentName string, p *vega.Process, result string) {
+	msg := fmt.Sprintf("%s finished: %s. %s",
+		agentName, summarizeResult(p.Task, 80), pt.resultPreview(result, sms.MaxLength))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := pt.smsNotifier.Notify(ctx, phone, msg); err != nil {
+		pt.logger.Errorf("Failed to send SMS notification: %v", err)
+	}
+}
+
+// uploadResult posts a short summary and uploads the full result as a file.
+// Returns false if the upload failed so the caller can fall back to inline text.
+func (pt *PersonaTools) uploadResult(uploader SlackUploader, channel, agentName string, p *vega.Process, result string) bool {
+	filename := fmt.Sprintf("%s-result-%s.md", strings.ToLower(agentName), p.ID)
+	title := fmt.Sprintf("%s: %s", agentName, summarizeResult(p.Task, 80))
+
+	if err := uploader.UploadFile(channel, filename, result, title); err != nil {
+		pt.logger.Errorf("Failed to upload result file: %v", err)
+		return false
+	}
+
+	msg := fmt.Sprintf("*%s* completed: _%s_\n\n%s\n\n_Full result (%d chars) attached as %s_",
+		agentName, p.Task, pt.resultPreview(result, 300), len(result), filename)
+	if err := pt.slackClient.SendMessage(channel, msg); err != nil {
+		pt.logger.Errorf("Failed to send Slack notification: %v", err)
+	}
+	return true
+}
+
+// SetSummarizer sets the summarizer used to condense long results in notifications
+func (pt *PersonaTools) SetSummarizer(s memory.Summarizer) {
+	pt.summarizer = s
+}
 
-	case notification.ChannelVoice:
-		// Voice calls have ended - send email if available
-		if ch.Email != "" {
-			pt.sendCallbackEmail(ch.Email,
-				fmt.Sprintf("%s completed your request", agentName),
-				result)
-		} else {
-			// Otherwise log only - user can't be notified
-			log.Printf("[notification] Voice call completed for %s, no notification channel available", ch.UserID)
-		}
+// resultPreview condenses a result to roughly maxLen characters, summarizing
+// when a summarizer is set and falling back to truncation otherwise
+func (pt *PersonaTools) resultPreview(result string, maxLen int) string {
+	if len(result) <= maxLen || pt.summarizer == nil {
+		return summarizeResult(result, maxLen)
+	}
 
-	case notification.ChannelAPI:
-		// API calls are synchronous, no notification needed
-		log.Printf("[notification] API process %s completed", p.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	summary, err := pt.summarizer.Summarize(ctx, result)
+	if err != nil || strings.TrimSpace(summary) == "" {
+		pt.logger.Warnf("Result summarization failed, truncating instead: %v", err)
+		return summarizeResult(result, maxLen)
 	}
+	return strings.TrimSpace(summary)
 }
 
-// summarizeResult truncates result to maxLen characters
+// summarizeResult truncates result to maxLen bytes without splitting a
+// character
 func summarizeResult(result string, maxLen int) string {
 	if len(result) <= maxLen {
 		return result
 	}
-	return result[:maxLen] + "..."
+	return textutil.Truncate(result, maxLen) + "..."
 }
 
 // identifyCallerTool wraps IdentifyCaller as a tool
@@ -765,6 +1500,11 @@ func (pt *PersonaTools) identifyCallerTool(ctx context.Context, params map[strin
 	if result == "" {
 		return "Unknown caller", nil
 	}
+	if include, _ := params["include_context"].(bool); include {
+		if briefing := pt.CallerBriefing(phone); briefing != "" {
+			result += "\n" + briefing
+		}
+	}
 	return result, nil
 }
 
@@ -806,27 +1546,47 @@ func (pt *PersonaTools) createProject(ctx context.Context, params map[string]any
 	name, _ := params["name"].(string)
 	description, _ := params["description"].(string)
 	template, _ := params["template"].(string)
+	image, _ := params["image"].(string)
 
-	// Sanitize project name
-	safeName := strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
-			return r
+	image = strings.TrimSpace(image)
+	if image == "" {
+		image = templateImages[template]
+	}
+	if image != "" {
+		if err := validateImage(image); err != nil {
+			return "", err
 		}
-		return '-'
-	}, name)
+	}
+
+	safeName := sanitizeProjectName(name)
 
 	var projectDir string
 	var containerStatus string
 
 	// Use project registry if available (creates container)
-	if pt.projects != nil {
-		project, err := pt.projects.GetOrCreateProject(ctx, safeName, description, "")
-		if err != nil {
+	if pt.projects != nil && pt.containersUp(ctx) {
+		project, err := pt.projects.GetOrCreateProject(ctx, safeName, description, image)
+		err = pt.checkContainerErr(err)
+		if err != nil && !errors.Is(err, errDockerUnreachable) {
 			return "", fmt.Errorf("failed to create project: %w", err)
 		}
+		if err == nil {
+			projectDir = pt.projects.GetProjectPath(safeName)
+			containerStatus = fmt.Sprintf("\nContainer status: %s", project.Status)
+			if image != "" {
+				containerStatus += fmt.Sprintf("\nImage: %s", image)
+			}
+		}
+	}
+	if pt.projects != nil && projectDir == "" {
+		// Docker went away: lay out the files where the container will mount them
 		projectDir = pt.projects.GetProjectPath(safeName)
-		containerStatus = fmt.Sprintf("\nContainer status: %s", project.Status)
-	} else {
+		if err := os.MkdirAll(projectDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create project directory: %w", err)
+		}
+		containerStatus = fmt.Sprintf("\nContainer status: not created (%s). Run create_project again once Docker is back to start it.",
+			pt.containerHealth.reason())
+	} else if pt.projects == nil {
 		// Fallback to simple directory creation
 		projectDir = filepath.Join(pt.workingDir, "projects", safeName)
 		if err := os.MkdirAll(projectDir, 0755); err != nil {
@@ -842,7 +1602,7 @@ func (pt *PersonaTools) createProject(ctx context.Context, params map[string]any
 
 	// Apply template if specified
 	if template != "" {
-		if err := pt.applyTemplate(projectDir, template); err != nil {
+		if err := pt.applyTemplate(projectDir, template, newTemplateData(name, description)); err != nil {
 			return "", fmt.Errorf("failed to apply template: %w", err)
 		}
 	}
@@ -850,53 +1610,44 @@ func (pt *PersonaTools) createProject(ctx context.Context, params map[string]any
 	return fmt.Sprintf("Created project '%s' at %s%s", name, projectDir, containerStatus), nil
 }
 
-// applyTemplate applies a project template
-func (pt *PersonaTools) applyTemplate(dir, template string) error {
-	switch template {
-	case "go":
-		return os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
-
-import "fmt"
-
-func main() {
-	fmt.Println("Hello, World!")
-}
-`), 0644)
-
-	case "python":
-		return os.WriteFile(filepath.Join(dir, "main.py"), []byte(`#!/usr/bin/env python3
-
-def main():
-    print("Hello, World!")
-
-if __name__ == "__main__":
-    main()
-`), 0644)
-
-	case "node":
-		os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
-  "name": "project",
-  "version": "1.0.0",
-  "main": "index.js"
+// templateImages maps project templates to a default container image
+var templateImages = map[string]string{
+	"go":     "golang:1.23",
+	"python": "python:3.12",
+	"node":   "node:20",
+	"react":  "node:20",
 }
-`), 0644)
-		return os.WriteFile(filepath.Join(dir, "index.js"), []byte(`console.log("Hello, World!");
-`), 0644)
 
-	case "react":
-		// Just create a basic structure
-		os.MkdirAll(filepath.Join(dir, "src"), 0755)
-		return os.WriteFile(filepath.Join(dir, "src", "App.jsx"), []byte(`export default function App() {
-  return <h1>Hello, World!</h1>;
+// allowedImages lists the image repositories create_project may pull.
+// Only official images are allowed; tags are free-form.
+var allowedImages = map[string]bool{
+	"golang": true,
+	"python": true,
+	"node":   true,
+	"rust":   true,
+	"ruby":   true,
+	"ubuntu": true,
+	"debian": true,
+	"alpine": true,
 }
-`), 0644)
-
-	case "empty":
-		return nil
 
-	default:
-		return fmt.Errorf("unknown template: %s", template)
+// validateImage checks an image reference against the allowlist
+func validateImage(image string) error {
+	repo, tag, _ := strings.Cut(image, ":")
+	if !allowedImages[repo] {
+		allowed := make([]string, 0, len(allowedImages))
+		for name := range allowedImages {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+		return fmt.Errorf("image %q is not allowed (allowed: %s)", image, strings.Join(allowed, ", "))
+	}
+	for _, r := range tag {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_') {
+			return fmt.Errorf("invalid image tag %q", tag)
+		}
 	}
+	return nil
 }
 
 // saveDirective saves a directive
@@ -904,29 +1655,39 @@ func (pt *PersonaTools) saveDirective(ctx context.Context, params map[string]any
 	key, _ := params["key"].(string)
 	directive, _ := params["directive"].(string)
 
+	project := pt.projectScope(ctx, params)
+
+	// Update and write under one lock so concurrent saves reach disk in order
 	pt.directivesMu.Lock()
-	pt.directives[key] = directive
-	pt.directivesMu.Unlock()
+	defer pt.directivesMu.Unlock()
+
+	if project != "" {
+		if pt.projectDirectives[project] == nil {
+			pt.projectDirectives[project] = make(map[string]string)
+		}
+		pt.projectDirectives[project][key] = directive
+		if err := pt.persistProjectDirectives(project); err != nil {
+			return "", fmt.Errorf("failed to save directive: %w", err)
+		}
+
+		return fmt.Sprintf("Saved directive '%s' for project %s: %s", key, project, directive), nil
+	}
 
-	// Persist to file
-	pt.persistDirectives()
+	pt.directives[key] = directive
+	if err := pt.persistDirectives(); err != nil {
+		return "", fmt.Errorf("failed to save directive: %w", err)
+	}
 
 	return fmt.Sprintf("Saved directive '%s': %s", key, directive), nil
 }
 
-// persistDirectives saves directives to disk
+// persistDirectives saves directives to disk. The caller holds directivesMu.
 func (pt *PersonaTools) persistDirectives() error {
-	pt.directivesMu.RLock()
-	defer pt.directivesMu.RUnlock()
-
 	data, err := yaml.Marshal(pt.directives)
 	if err != nil {
 		return err
 	}
-
-	knowledgeDir := filepath.Join(pt.tronDir, "knowledge")
-	os.MkdirAll(knowledgeDir, 0755)
-	return os.WriteFile(filepath.Join(knowledgeDir, "directives.yaml"), data, 0644)
+	return persist.WriteFile(filepath.Join(pt.tronDir, "knowledge", "directives.yaml"), data)
 }
 
 // savePersonMemory saves memory about a person
@@ -935,111 +1696,191 @@ func (pt *PersonaTools) savePersonMemory(ctx context.Context, params map[string]
 	key, _ := params["key"].(string)
 	fact, _ := params["fact"].(string)
 
+	// Update and write under one lock so concurrent saves reach disk in order
 	pt.personMemMu.Lock()
+	defer pt.personMemMu.Unlock()
+
 	if pt.personMemory[person] == nil {
 		pt.personMemory[person] = make(map[string]string)
 	}
 	pt.personMemory[person][key] = fact
-	pt.personMemMu.Unlock()
-
-	// Persist to file
-	pt.persistPersonMemory()
+	if err := pt.persistPersonMemory(); err != nil {
+		return "", fmt.Errorf("failed to save memory about %s: %w", person, err)
+	}
 
 	return fmt.Sprintf("Remembered about %s: %s = %s", person, key, fact), nil
 }
 
-// persistPersonMemory saves person memory to disk
+// persistPersonMemory saves person memory to disk. The caller holds personMemMu.
 func (pt *PersonaTools) persistPersonMemory() error {
-	pt.personMemMu.RLock()
-	defer pt.personMemMu.RUnlock()
-
 	data, err := yaml.Marshal(pt.personMemory)
 	if err != nil {
 		return err
 	}
-
-	knowledgeDir := filepath.Join(pt.tronDir, "knowledge")
-	os.MkdirAll(knowledgeDir, 0755)
-	return os.WriteFile(filepath.Join(knowledgeDir, "person_memory.yaml"), data, 0644)
+	return persist.WriteFile(filepath.Join(pt.tronDir, "knowledge", "person_memory.yaml"), data)
 }
 
-// webSearch performs a web search using Brave Search API
+// maxWebSearchCount is the most results Brave returns per request
+const maxWebSearchCount = 20
+
+// webSearch performs a web search with the first configured provider,
+// falling back to the next if it is down or rate limited
 func (pt *PersonaTools) webSearch(ctx context.Context, params map[string]any) (string, error) {
 	query, _ := params["query"].(string)
 	if query == "" {
 		return "", fmt.Errorf("query is required")
 	}
 
-	apiKey := os.Getenv("BRAVE_SEARCH_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("BRAVE_SEARCH_API_KEY not configured")
-	}
+	count := intParam(params, "count", 5, 1, maxWebSearchCount)
 
-	// Build request
-	searchURL := "https://api.search.brave.com/res/v1/web/search"
-	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	freshness, _ := params["freshness"].(string)
+	freshness = strings.ToLower(strings.TrimSpace(freshness))
+	switch freshness {
+	case "", "pd", "pw", "pm", "py":
+	default:
+		return "", fmt.Errorf("invalid freshness %q (use pd, pw, pm, or py)", freshness)
 	}
 
-	// Add query parameters
-	q := req.URL.Query()
-	q.Add("q", query)
-	q.Add("count", "5") // Top 5 results
-	req.URL.RawQuery = q.Encode()
-
-	// Add headers
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Subscription-Token", apiKey)
-
-	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	provider, _ := params["provider"].(string)
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	providers, err := pt.searchProvidersFor(provider)
 	if err != nil {
-		return "", fmt.Errorf("search request failed: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("search API returned status %d", resp.StatusCode)
+	// Identical concurrent searches share one request; the shared request
+	// must outlive any single caller giving up
+	key := searchKey(query, count, freshness)
+	if provider != "" {
+		key += "|" + provider
 	}
+	return pt.searches.do(key, func() (string, error) {
+		req := SearchRequest{Query: query, Count: count, Freshness: freshness}
+		return pt.searchWithFallback(context.WithoutCancel(ctx), providers, req)
+	})
+}
 
-	// Parse response
-	var result braveSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
+// Limits for the supplementary sections so they don't crowd out web results
+const (
+	maxNewsResults     = 3
+	maxFAQResults      = 3
+	maxInfoboxLength   = 500
+	maxFAQAnswerLength = 300
+)
 
-	// Format results
+// formatBraveResults formats web results plus any infobox, news, and FAQ sections
+func formatBraveResults(query string, result *braveSearchResponse) string {
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("Search results for: %s\n\n", query))
 
-	if len(result.Web.Results) == 0 {
+	if len(result.Web.Results) == 0 && len(result.News.Results) == 0 &&
+		len(result.FAQ.Results) == 0 && len(result.Infobox.Results) == 0 {
 		output.WriteString("No results found.")
-		return output.String(), nil
+		return output.String()
+	}
+
+	if len(result.Infobox.Results) > 0 {
+		box := result.Infobox.Results[0]
+		desc := box.LongDesc
+		if desc == "" {
+			desc = box.Description
+		}
+		output.WriteString(fmt.Sprintf("[Infobox] %s\n", box.Title))
+		if desc != "" {
+			output.WriteString(fmt.Sprintf("   %s\n", summarizeResult(desc, maxInfoboxLength)))
+		}
+		if box.URL != "" {
+			output.WriteString(fmt.Sprintf("   URL: %s\n", box.URL))
+		}
+		output.WriteString("\n")
 	}
 
 	for i, r := range result.Web.Results {
 		output.WriteString(fmt.Sprintf("%d. %s\n", i+1, r.Title))
 		output.WriteString(fmt.Sprintf("   URL: %s\n", r.URL))
+		if r.Age != "" {
+			output.WriteString(fmt.Sprintf("   Age: %s\n", r.Age))
+		} else if r.PageAge != "" {
+			output.WriteString(fmt.Sprintf("   Published: %s\n", r.PageAge))
+		}
 		if r.Description != "" {
 			output.WriteString(fmt.Sprintf("   %s\n", r.Description))
 		}
 		output.WriteString("\n")
 	}
 
-	return output.String(), nil
+	if len(result.News.Results) > 0 {
+		output.WriteString("News:\n")
+		for i, r := range result.News.Results {
+			if i >= maxNewsResults {
+				break
+			}
+			published := r.Age
+			if published == "" {
+				published = r.PageAge
+			}
+			if published != "" {
+				output.WriteString(fmt.Sprintf("- %s (%s)\n", r.Title, published))
+			} else {
+				output.WriteString(fmt.Sprintf("- %s\n", r.Title))
+			}
+			output.WriteString(fmt.Sprintf("  URL: %s\n", r.URL))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(result.FAQ.Results) > 0 {
+		output.WriteString("FAQ:\n")
+		for i, r := range result.FAQ.Results {
+			if i >= maxFAQResults {
+				break
+			}
+			output.WriteString(fmt.Sprintf("Q: %s\n", r.Question))
+			output.WriteString(fmt.Sprintf("A: %s\n", summarizeResult(r.Answer, maxFAQAnswerLength)))
+			if r.URL != "" {
+				output.WriteString(fmt.Sprintf("   Source: %s\n", r.URL))
+			}
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
 }
 
 // braveSearchResponse represents the Brave Search API response
 type braveSearchResponse struct {
 	Web struct {
+		Results []braveResult `json:"results"`
+	} `json:"web"`
+	News struct {
+		Results []braveResult `json:"results"`
+	} `json:"news"`
+	FAQ struct {
+		Results []struct {
+			Question string `json:"question"`
+			Answer   string `json:"answer"`
+			Title    string `json:"title"`
+			URL      string `json:"url"`
+		} `json:"results"`
+	} `json:"faq"`
+	Infobox struct {
 		Results []struct {
 			Title       string `json:"title"`
-			URL         string `json:"url"`
 			Description string `json:"description"`
+			LongDesc    string `json:"long_desc"`
+			URL         string `json:"url"`
 		} `json:"results"`
-	} `json:"web"`
+	} `json:"infobox"`
+}
+
+// braveResult is a web or news result; other providers' results are
+// mapped onto it so every provider formats the same way
+type braveResult struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Age         string `json:"age,omitempty"`
+	PageAge     string `json:"page_age,omitempty"`
 }
 
 // execute runs a shell command, optionally in a project's container
@@ -1051,68 +1892,104 @@ func (pt *PersonaTools) execute(ctx context.Context, params map[string]any) (str
 		return "", fmt.Errorf("command is required")
 	}
 
-	// Security: block dangerous commands
-	blockedPatterns := []string{
-		"rm -rf /",
-		"rm -rf /*",
-		"sudo",
-		"su ",
-		".ssh",
-		".aws",
-		"/etc/passwd",
-		"curl.*metadata",
-		"> /dev",
-		"mkfs",
-		"dd if=",
-	}
-	lowerCmd := strings.ToLower(command)
-	for _, pattern := range blockedPatterns {
-		if strings.Contains(lowerCmd, pattern) {
-			return "", fmt.Errorf("blocked command: contains dangerous pattern %q", pattern)
-		}
+	// Security: refuse blocked binaries, protected paths and, in allowlist
+	// mode, anything not listed
+	if err := pt.execPolicy.check(command); err != nil {
+		return "", err
+	}
+
+	// Checks above still apply, so a dry run shows whether a command is blocked
+	if dryRun, _ := params["dry_run"].(bool); dryRun || pt.execDryRun {
+		return pt.dryRunExec(ctx, command, project), nil
 	}
 
+	timeout := time.Duration(intParam(params, "timeout_seconds",
+		int(execTimeout.Seconds()), 1, int(maxExecTimeout.Seconds()))) * time.Second
+
 	// If project specified and containers available, run in container
-	if project != "" && pt.containers != nil && pt.containers.IsAvailable() {
-		return pt.executeInContainer(ctx, project, command)
+	if project != "" && pt.containersConfigured() {
+		if pt.containersUp(ctx) {
+			out, err := pt.executeInContainer(ctx, project, command, timeout)
+			if !errors.Is(err, errDockerUnreachable) {
+				return out, err
+			}
+		}
+		return pt.runWithoutContainer(ctx, command, project, timeout)
 	}
 
 	// Otherwise run on host
-	return pt.executeOnHost(ctx, command, project)
+	return pt.executeOnHost(ctx, command, project, timeout)
 }
 
-// executeInContainer runs a command inside a project's Docker container
-func (pt *PersonaTools) executeInContainer(ctx context.Context, project, command string) (string, error) {
-	execCtx, cancel := context.WithTimeout(ctx, 120*time.Second)
-	defer cancel()
+const (
+	// execTimeout is how long an execute call may run by default
+	execTimeout = 120 * time.Second
 
-	result, err := pt.containers.Exec(execCtx, project, []string{"bash", "-c", command}, "/workspace")
-	if err != nil {
-		return "", fmt.Errorf("container exec failed: %w", err)
+	// maxExecTimeout caps the timeout_seconds an agent can ask for
+	maxExecTimeout = 600 * time.Second
+)
+
+// execTimeoutError reports a command that ran out of time, with whatever
+// it printed before it was stopped
+func execTimeoutError(timeout time.Duration, output string) error {
+	if output == "" {
+		return fmt.Errorf("command timed out after %d seconds with no output", int(timeout.Seconds()))
 	}
+	return fmt.Errorf("command timed out after %d seconds; partial output:\n%s", int(timeout.Seconds()), output)
+}
+
+// execWaitDelay bounds how long a killed command's output pipes may stay
+// open, e.g. held by a background child that escaped the process group
+const execWaitDelay = 5 * time.Second
+
+// Exit statuses coreutils timeout uses when it kills a command, and that a
+// shell reports when timeout itself is missing
+const (
+	timeoutExitCode  = 124
+	notFoundExitCode = 127
+)
+
+// execStatusMarker ends a container command's stderr with the command's own
+// exit status. timeout(1) kills the wrapper that prints it, so a missing
+// marker tells a timeout apart from a command that exits 124 itself.
+const execStatusMarker = "\n__tron_exec_status="
+
+// executeInContainer runs a command inside a project's Docker container
+func (pt *PersonaTools) executeInContainer(ctx context.Context, project, command string, timeout time.Duration) (string, error) {
+	// Container output only arrives at exit, so updates carry elapsed time only
+	stop := pt.watchExec(ctx, command, project, nil)
+	stdout, stderr, exitCode, err := pt.runInContainer(ctx, project, command, timeout)
+	stop()
 
 	var output strings.Builder
-	if result.Stdout != "" {
-		output.WriteString(result.Stdout)
+	if stdout != "" {
+		output.WriteString(stdout)
 	}
-	if result.Stderr != "" {
+	if stderr != "" {
 		if output.Len() > 0 {
 			output.WriteString("\n")
 		}
 		output.WriteString("stderr: ")
-		output.WriteString(result.Stderr)
+		output.WriteString(stderr)
 	}
 
 	outputStr := output.String()
-	if len(outputStr) > 50000 {
-		outputStr = outputStr[:50000] + "\n... (truncated)"
+	if len(outputStr) > maxExecOutputBytes {
+		outputStr = textutil.Truncate(outputStr, maxExecOutputBytes) + "\n... (truncated)"
+	}
+
+	if errors.Is(err, errExecTimedOut) {
+		return "", execTimeoutError(timeout, outputStr)
+	}
+	if err != nil {
+		return "", err
 	}
 
-	if result.ExitCode != 0 {
+	if exitCode != 0 {
 		if outputStr == "" {
-			return "", fmt.Errorf("command failed with exit code %d", result.ExitCode)
+			return "", fmt.Errorf("command failed with exit code %d", exitCode)
 		}
-		return outputStr + fmt.Sprintf("\n\nExit code: %d", result.ExitCode), nil
+		return outputStr + fmt.Sprintf("\n\nExit code: %d", exitCode), nil
 	}
 
 	if outputStr == "" {
@@ -1121,44 +1998,149 @@ func (pt *PersonaTools) executeInContainer(ctx context.Context, project, command
 	return outputStr, nil
 }
 
-// executeOnHost runs a command on the host
-func (pt *PersonaTools) executeOnHost(ctx context.Context, command, project string) (string, error) {
-	// Determine working directory
-	workDir := pt.workingDir
-	if project != "" {
-		workDir = filepath.Join(pt.workingDir, "vega.work", "projects", project)
-		if _, err := os.Stat(workDir); os.IsNotExist(err) {
-			// Try without vega.work prefix
-			workDir = filepath.Join(pt.workingDir, "projects", project)
+// runInContainer runs a command in a project's container and returns its
+// output once it exits. A command that ran out of time returns what it
+// printed with an errExecTimedOut error.
+func (pt *PersonaTools) runInContainer(ctx context.Context, project, command string, timeout time.Duration) (stdout, stderr string, exitCode int, err error) {
+	// The project's .env applies as it does on the host
+	vars, err := loadProjectEnv(pt.hostProjectDir(project))
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	wrapped := withExecStatus(withEnv(vars, []string{"bash", "-c", command}))
+
+	if !pt.imageLacksTimeout(project) {
+		stdout, stderr, exitCode, err = pt.execWithTimeout(ctx, project, wrapped, timeout)
+		if err != nil {
+			return "", "", 0, err
+		}
+		var ok bool
+		stderr, ok = splitExecStatus(stderr)
+		switch {
+		case ok:
+			return stdout, stderr, exitCode, nil
+		case exitCode == timeoutExitCode:
+			return stdout, stderr, exitCode, fmt.Errorf("%w after %d seconds", errExecTimedOut, int(timeout.Seconds()))
+		case exitCode != notFoundExitCode:
+			return stdout, stderr, exitCode, nil
 		}
+		// timeout(1) isn't in the image, so the command never ran
+		pt.logger.Warnf("Container for %s has no timeout command; using the exec deadline instead", project)
+		pt.noTimeoutMu.Lock()
+		if pt.noTimeoutProjects == nil {
+			pt.noTimeoutProjects = make(map[string]bool)
+		}
+		pt.noTimeoutProjects[project] = true
+		pt.noTimeoutMu.Unlock()
 	}
 
-	// Ensure working directory exists
-	if err := os.MkdirAll(workDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create working directory: %w", err)
+	// Without timeout(1) the exec deadline is the only bound, and a command
+	// that hits it loses its output
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := pt.containers.Exec(execCtx, project, wrapped, "/workspace")
+	if err != nil {
+		return "", "", 0, pt.containerExecErr(ctx, execCtx, err, timeout)
 	}
+	stderr, _ = splitExecStatus(result.Stderr)
+	return result.Stdout, stderr, result.ExitCode, nil
+}
 
-	execCtx, cancel := context.WithTimeout(ctx, 120*time.Second)
+// execWithTimeout runs argv under timeout(1) in a project's container. The
+// command is bounded inside the container so that a hung command still
+// returns whatever it printed; the outer context gets a grace period and
+// only fires if the container itself stops responding.
+func (pt *PersonaTools) execWithTimeout(ctx context.Context, project string, argv []string, timeout time.Duration) (stdout, stderr string, exitCode int, err error) {
+	execCtx, cancel := context.WithTimeout(ctx, timeout+15*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(execCtx, "bash", "-c", command)
-	cmd.Dir = workDir
+	seconds := strconv.Itoa(int(timeout.Seconds()))
+	result, err := pt.containers.Exec(execCtx, project, append([]string{"timeout", "-k", "5", seconds}, argv...), "/workspace")
+	if err != nil {
+		return "", "", 0, pt.containerExecErr(ctx, execCtx, err, timeout)
+	}
+	return result.Stdout, result.Stderr, result.ExitCode, nil
+}
+
+// containerExecErr explains a failed container exec: the caller gave up,
+// the deadline passed, or the container couldn't run it
+func (pt *PersonaTools) containerExecErr(ctx, execCtx context.Context, err error, timeout time.Duration) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("command cancelled: %w", ctx.Err())
+	}
+	if execCtx.Err() != nil {
+		return fmt.Errorf("%w after %d seconds", errExecTimedOut, int(timeout.Seconds()))
+	}
+	return fmt.Errorf("container exec failed: %w", pt.checkContainerErr(err))
+}
+
+// imageLacksTimeout reports whether a project's container was found to have
+// no timeout(1)
+func (pt *PersonaTools) imageLacksTimeout(project string) bool {
+	pt.noTimeoutMu.Lock()
+	defer pt.noTimeoutMu.Unlock()
+	return pt.noTimeoutProjects[project]
+}
+
+// withExecStatus wraps argv so its exit status is reported on stderr (see
+// execStatusMarker)
+func withExecStatus(argv []string) []string {
+	script := `"$@"; s=$?; printf '` + execStatusMarker + `%d\n' "$s" >&2; exit "$s"`
+	return append([]string{"bash", "-c", script, "tron-exec"}, argv...)
+}
+
+// splitExecStatus removes the execStatusMarker line from a container
+// command's stderr, reporting whether it was there
+func splitExecStatus(stderr string) (string, bool) {
+	i := strings.LastIndex(stderr, execStatusMarker)
+	if i < 0 {
+		return stderr, false
+	}
+	tail := strings.TrimSuffix(stderr[i+len(execStatusMarker):], "\n")
+	if _, err := strconv.Atoi(tail); err != nil {
+		return stderr, false
+	}
+	return stderr[:i], true
+}
+
+// hostProjectDir returns a project's directory when running without containers
+func (pt *PersonaTools) hostProjectDir(project string) string {
+	dir := filepath.Join(pt.workingDir, "vega.work", "projects", project)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		// Try without vega.work prefix
+		dir = filepath.Join(pt.workingDir, "projects", project)
+	}
+	return dir
+}
 
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
+// executeOnHost runs a command on the host, collecting its streamed output
+func (pt *PersonaTools) executeOnHost(ctx context.Context, command, project string, timeout time.Duration) (string, error) {
+	lines, wait := pt.streamOnHost(ctx, command, project, timeout)
 
-	if len(outputStr) > 50000 {
-		outputStr = outputStr[:50000] + "\n... (truncated)"
+	output := &execOutput{}
+	stop := pt.watchExec(ctx, command, project, output.LastLine)
+	for line := range lines {
+		output.Write([]byte(line.Text + "\n"))
 	}
+	err := wait()
+	stop()
+	outputStr := output.String()
 
 	if err != nil {
-		if execCtx.Err() != nil {
-			return "", fmt.Errorf("command timed out after 120 seconds")
-		}
-		if outputStr == "" {
-			return "", fmt.Errorf("command failed: %v", err)
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(err, errExecTimedOut):
+			return "", execTimeoutError(timeout, outputStr)
+		case errors.As(err, &exitErr) || errors.Is(err, exec.ErrWaitDelay):
+			if outputStr == "" {
+				return "", fmt.Errorf("command failed: %v", err)
+			}
+			return outputStr + fmt.Sprintf("\n\nError: %v", err), nil
+		default:
+			// Cancelled, or never started
+			return "", err
 		}
-		return outputStr + fmt.Sprintf("\n\nError: %v", err), nil
 	}
 
 	if outputStr == "" {
@@ -1175,11 +2157,20 @@ func (pt *PersonaTools) getProjectStatus(ctx context.Context, params map[string]
 		return "", fmt.Errorf("project name is required")
 	}
 
-	if pt.containers == nil || !pt.containers.IsAvailable() {
+	if !pt.containersConfigured() {
 		return "Docker not available - projects run in direct mode", nil
 	}
 
-	status, err := pt.containers.GetProjectStatus(ctx, project)
+	var status *container.ProjectStatus
+	err := errDockerUnreachable
+	if pt.containersUp(ctx) {
+		status, err = pt.containers.GetProjectStatus(ctx, project)
+		err = pt.checkContainerErr(err)
+	}
+	if errors.Is(err, errDockerUnreachable) {
+		return fmt.Sprintf("Project: %s\nContainer status unknown: %s. Files are at %s.\n",
+			project, pt.containerHealth.reason(), pt.hostProjectDir(project)), nil
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to get project status: %w", err)
 	}
@@ -1200,10 +2191,19 @@ func (pt *PersonaTools) getProjectStatus(ctx context.Context, params map[string]
 	return result.String(), nil
 }
 
+// errServerManagementUnavailable is returned by every server tool when no process manager is configured
+var errServerManagementUnavailable = errors.New("server management not available (no process manager configured)")
+
+// serverReadyTimeout is how long start_server waits for a server to accept
+// connections before giving up on returning its URL
+const serverReadyTimeout = 30 * time.Second
+
 // startServer starts a server process for a project
 func (pt *PersonaTools) startServer(ctx context.Context, params map[string]any) (string, error) {
 	project, _ := params["project"].(string)
 	command, _ := params["command"].(string)
+	healthPath, _ := params["health_path"].(string)
+	routesParam, _ := params["routes"].(string)
 
 	if project == "" {
 		return "", fmt.Errorf("project name is required")
@@ -1213,7 +2213,7 @@ func (pt *PersonaTools) startServer(ctx context.Context, params map[string]any)
 	}
 
 	if pt.processManager == nil {
-		return "", fmt.Errorf("server management not available")
+		return "", errServerManagementUnavailable
 	}
 
 	// Determine working directory for the project
@@ -1225,17 +2225,43 @@ func (pt *PersonaTools) startServer(ctx context.Context, params map[string]any)
 		}
 	}
 
-	// Prepare environment
-	env := os.Environ()
+	// Prepare environment: inherited, then the project's .env
+	env, err := projectEnviron(workDir)
+	if err != nil {
+		return "", err
+	}
+
+	var routes []string
+	for _, name := range strings.Split(routesParam, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			routes = append(routes, name)
+		}
+	}
 
-	// Start the server process
-	proc, err := pt.processManager.StartServer(ctx, project, command, workDir, env)
+	// Start the server process, returning once it's accepting connections
+	proc, err := pt.processManager.StartServer(ctx, project, command, workDir, env,
+		subdomain.WaitHealthy(serverReadyTimeout, healthPath), subdomain.WithRoutes(routes...))
 	if err != nil {
+		if proc != nil {
+			return "", fmt.Errorf("server for project %q started but isn't ready: %w. Don't share its URL yet; check get_server_logs", project, err)
+		}
+		if errors.Is(err, subdomain.ErrNoPortsAvailable) {
+			return "", fmt.Errorf("all %d server slots are in use, stop a server with stop_server first", subdomain.Capacity())
+		}
 		return "", fmt.Errorf("failed to start server: %w", err)
 	}
 
-	return fmt.Sprintf("Server started for project '%s'\nURL: %s\nPort: %d\nSubdomain: %s",
-		project, proc.URL, proc.Port, proc.Subdomain), nil
+	return fmt.Sprintf("Server started for project '%s'\nURL: %s\nPort: %d\nSubdomain: %s%s",
+		project, proc.URL, proc.Port, proc.Subdomain, describeRoutes(proc.Routes)), nil
+}
+
+// describeRoutes lists a server's named routes, one per line
+func describeRoutes(routes []subdomain.Allocation) string {
+	var sb strings.Builder
+	for _, r := range routes {
+		sb.WriteString(fmt.Sprintf("\nRoute %s: %s (%s=%d)", r.Route, r.URL, subdomain.RoutePortVar(r.Route), r.Port))
+	}
+	return sb.String()
 }
 
 // stopServer stops a running server
@@ -1247,7 +2273,7 @@ func (pt *PersonaTools) stopServer(ctx context.Context, params map[string]any) (
 	}
 
 	if pt.processManager == nil {
-		return "", fmt.Errorf("server management not available")
+		return "", errServerManagementUnavailable
 	}
 
 	if err := pt.processManager.StopServer(project); err != nil {
@@ -1257,6 +2283,62 @@ func (pt *PersonaTools) stopServer(ctx context.Context, params map[string]any) (
 	return fmt.Sprintf("Server stopped for project '%s'", project), nil
 }
 
+// restartServer restarts a running server, keeping its URL
+func (pt *PersonaTools) restartServer(ctx context.Context, params map[string]any) (string, error) {
+	project, _ := params["project"].(string)
+
+	if project == "" {
+		return "", fmt.Errorf("project name is required")
+	}
+
+	if pt.processManager == nil {
+		return "", errServerManagementUnavailable
+	}
+
+	proc, err := pt.processManager.RestartServer(project)
+	if err != nil {
+		return "", fmt.Errorf("failed to restart server: %w", err)
+	}
+
+	return fmt.Sprintf("Server restarted for project '%s'\nURL: %s\nPort: %d",
+		project, proc.URL, proc.Port), nil
+}
+
+// stopAllServers stops every running server
+func (pt *PersonaTools) stopAllServers(ctx context.Context, params map[string]any) (string, error) {
+	if pt.processManager == nil {
+		return "", errServerManagementUnavailable
+	}
+
+	stopped := pt.processManager.StopAll()
+	if len(stopped) == 0 {
+		return "No servers were running", nil
+	}
+	return fmt.Sprintf("Stopped %d server(s): %s", len(stopped), strings.Join(stopped, ", ")), nil
+}
+
+// getServerLogs returns a server's recent output
+func (pt *PersonaTools) getServerLogs(ctx context.Context, params map[string]any) (string, error) {
+	project, _ := params["project"].(string)
+
+	if project == "" {
+		return "", fmt.Errorf("project name is required")
+	}
+
+	if pt.processManager == nil {
+		return "", errServerManagementUnavailable
+	}
+
+	logs, err := pt.processManager.GetServerLogs(project)
+	if err != nil {
+		return "", fmt.Errorf("no server logs for project %q (it was never started or has been stopped)", project)
+	}
+	if logs == "" {
+		return fmt.Sprintf("Server for project '%s' hasn't printed anything", project), nil
+	}
+	return fmt.Sprintf("Server output for project '%s':\n%s", project, logs), nil
+}
+
 // getServerURL gets the URL of a running server
 func (pt *PersonaTools) getServerURL(ctx context.Context, params map[string]any) (string, error) {
 	project, _ := params["project"].(string)
@@ -1266,22 +2348,23 @@ func (pt *PersonaTools) getServerURL(ctx context.Context, params map[string]any)
 	}
 
 	if pt.processManager == nil {
-		return "", fmt.Errorf("server management not available")
+		return "", errServerManagementUnavailable
 	}
 
 	proc := pt.processManager.GetServer(project)
 	if proc == nil {
 		return "", fmt.Errorf("no server running for project %q", project)
 	}
+	pt.processManager.Touch(project)
 
-	return fmt.Sprintf("URL: %s\nStatus: %s\nPort: %d",
-		proc.URL, proc.Status, proc.Port), nil
+	return fmt.Sprintf("URL: %s\nStatus: %s\nReady: %t\nPort: %d%s",
+		proc.URL, proc.Status, proc.Ready, proc.Port, describeRoutes(proc.Routes)), nil
 }
 
 // listServers lists all running servers
 func (pt *PersonaTools) listServers(ctx context.Context, params map[string]any) (string, error) {
 	if pt.processManager == nil {
-		return "", fmt.Errorf("server management not available")
+		return "", errServerManagementUnavailable
 	}
 
 	servers := pt.processManager.ListServers()
@@ -1296,7 +2379,14 @@ func (pt *PersonaTools) listServers(ctx context.Context, params map[string]any)
 		result.WriteString(fmt.Sprintf("Project: %s\n", s.ProjectName))
 		result.WriteString(fmt.Sprintf("  URL: %s\n", s.URL))
 		result.WriteString(fmt.Sprintf("  Port: %d\n", s.Port))
+		for _, r := range s.Routes {
+			result.WriteString(fmt.Sprintf("  Route %s: %s (port %d)\n", r.Route, r.URL, r.Port))
+		}
 		result.WriteString(fmt.Sprintf("  Status: %s\n", s.Status))
+		result.WriteString(fmt.Sprintf("  Ready: %t\n", s.Ready))
+		if s.RestartCount > 0 {
+			result.WriteString(fmt.Sprintf("  Restarts after crash: %d\n", s.RestartCount))
+		}
 		result.WriteString(fmt.Sprintf("  Started: %s\n\n", s.StartedAt.Format(time.RFC3339)))
 	}
 
@@ -1360,7 +2450,7 @@ func (pt *PersonaTools) listProjects(ctx context.Context, params map[string]any)
 
 // shareKnowledge shares a discovery, insight, or decision with the team
 func (pt *PersonaTools) shareKnowledge(ctx context.Context, params map[string]any) (string, error) {
-	if pt.knowledgeStore == nil {
+	if pt.currentKnowledgeStore() == nil {
 		return "", fmt.Errorf("knowledge store not available")
 	}
 
@@ -1394,6 +2484,14 @@ func (pt *PersonaTools) shareKnowledge(ctx context.Context, params map[string]an
 		}
 	}
 
+	// Scope to the active project, if any
+	if project := pt.projectScope(ctx, params); project != "" {
+		tags = append(tags, projectTagPrefix+project)
+	}
+	if pin, _ := params["pin"].(bool); pin {
+		tags = append(tags, pinnedTag)
+	}
+
 	// Map type string to EntryType
 	var kt knowledge.EntryType
 	switch strings.ToLower(entryType) {
@@ -1447,16 +2545,31 @@ func (pt *PersonaTools) shareKnowledge(ctx context.Context, params map[string]an
 		Source:  source,
 	}
 
-	if err := pt.knowledgeStore.Add(entry); err != nil {
+	// Skip repeats, e.g. from an agent stuck in a loop
+	if force, _ := params["force"].(bool); !force {
+		if dup, ok := pt.findDuplicateKnowledge(entry, time.Now()); ok {
+			return fmt.Sprintf("Already shared: [%s] %s (%s ago). Not added again; pass force=true to share anyway.",
+				dup.Type, dup.Title, time.Since(dup.CreatedAt).Round(time.Minute)), nil
+		}
+	}
+
+	if err := pt.addKnowledge(entry); err != nil {
 		return "", fmt.Errorf("failed to save knowledge: %w", err)
 	}
 
 	return fmt.Sprintf("Knowledge shared: [%s] %s\nThis will appear in the team's knowledge feed.", kt, title), nil
 }
 
+// query_knowledge result limits; the cap keeps one call from dumping the store
+const (
+	defaultKnowledgeQueryLimit = 10
+	maxKnowledgeQueryLimit     = 100
+)
+
 // queryKnowledge searches the shared knowledge base
 func (pt *PersonaTools) queryKnowledge(ctx context.Context, params map[string]any) (string, error) {
-	if pt.knowledgeStore == nil {
+	store := pt.currentKnowledgeStore()
+	if store == nil {
 		return "", fmt.Errorf("knowledge store not available")
 	}
 
@@ -1464,51 +2577,99 @@ func (pt *PersonaTools) queryKnowledge(ctx context.Context, params map[string]an
 	author, _ := params["author"].(string)
 	entryType, _ := params["type"].(string)
 	tagsStr, _ := params["tags"].(string)
-	limitFloat, _ := params["limit"].(float64)
-
-	limit := int(limitFloat)
-	if limit == 0 {
-		limit = 10
-	}
+	limit := intParam(params, "limit", defaultKnowledgeQueryLimit, 1, maxKnowledgeQueryLimit)
 
-	// Parse tags
-	var tags []string
-	if tagsStr != "" {
-		for _, t := range strings.Split(tagsStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tags = append(tags, t)
-			}
-		}
-	}
+	tags := splitList(tagsStr)
 
-	// Build query options
+	// Build query options; lists of authors, types, or domains are matched
+	// after the query since the store filters on one value per field
 	opts := knowledge.QueryOptions{
 		Limit: limit,
 		Tags:  tags,
 	}
+	filter := newKnowledgeFilter(author, entryType, domain)
+	filter.apply(&opts)
+
+	// Recency weighting is on unless the caller wants the store's own order
+	decay := true
+	if d, ok := params["decay"].(bool); ok {
+		decay = d
+	}
 
-	if domain != "" {
-		opts.Domain = knowledge.Domain(strings.ToLower(domain))
+	// Restrict to what a process, and optionally its spawned children, produced
+	var lineage map[string]bool
+	if processID, _ := params["process_id"].(string); strings.TrimSpace(processID) != "" {
+		rootID, err := processIDParam(ctx, processID)
+		if err != nil {
+			return "", err
+		}
+		includeChildren := true
+		if c, ok := params["include_children"].(bool); ok {
+			includeChildren = c
+		}
+		lineage = pt.processLineage(rootID, includeChildren)
 	}
-	if author != "" {
-		opts.Author = author
+
+	// Within a project, hide other projects' entries but keep global ones
+	project := pt.projectScope(ctx, params)
+
+	// Entries compacted out of the store are only searched on request
+	if archived, _ := params["archived"].(bool); archived {
+		entries, err := searchKnowledgeArchive(pt.knowledgeArchivePath(), func(e knowledge.Entry) bool {
+			return matchesKnowledgeQuery(e, opts) && filter.match(e)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to search the knowledge archive: %w", err)
+		}
+		if lineage != nil {
+			entries = filterByLineage(entries, lineage)
+		}
+		if project != "" {
+			entries = filterByProject(entries, project, 0)
+		}
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+		return knowledge.FormatEntriesForQuery(entries), nil
 	}
-	if entryType != "" {
-		opts.Type = knowledge.EntryType(strings.ToLower(entryType))
+
+	// A lineage or a list of values can match entries anywhere in the
+	// store, so those scan all of it rather than a window of the newest
+	if lineage != nil || filter.narrows() {
+		opts.Limit = knowledgeExportLimit
+	} else if project != "" || decay {
+		opts.Limit = limit * scopedQueryOverfetch
 	}
 
-	entries := pt.knowledgeStore.Query(opts)
+	entries := store.Query(opts)
+	if decay {
+		entries = withPinned(store, opts, entries)
+	}
+	if lineage != nil {
+		entries = filterByLineage(entries, lineage)
+	}
+	if filter.narrows() {
+		entries = filter.filter(entries)
+	}
+	if project != "" {
+		entries = filterByProject(entries, project, 0)
+	}
+	if decay {
+		entries = rankKnowledge(entries, tags, time.Now(), limit)
+	} else if len(entries) > limit {
+		entries = entries[:limit]
+	}
 	return knowledge.FormatEntriesForQuery(entries), nil
 }
 
 // getKnowledgeFeed returns the recent activity feed
 func (pt *PersonaTools) getKnowledgeFeed(ctx context.Context, params map[string]any) (string, error) {
-	if pt.knowledgeStore == nil {
+	store := pt.currentKnowledgeStore()
+	if store == nil {
 		return "", fmt.Errorf("knowledge store not available")
 	}
 
-	feedSection := knowledge.GetFeedPromptSection(pt.knowledgeStore)
+	feedSection := knowledge.GetFeedPromptSection(store)
 	if feedSection == "" {
 		return "No recent team activity in the last 24 hours.", nil
 	}
@@ -1516,15 +2677,16 @@ func (pt *PersonaTools) getKnowledgeFeed(ctx context.Context, params map[string]
 	return feedSection, nil
 }
 
-// GetKnowledgeStore returns the knowledge store for external use
+// GetKnowledgeStore returns the knowledge store for external use. Compaction
+// replaces the store, so fetch it again for each use.
 func (pt *PersonaTools) GetKnowledgeStore() *knowledge.Store {
-	return pt.knowledgeStore
+	return pt.currentKnowledgeStore()
 }
 
 // ListServersForDisplay returns a formatted string of running servers for Slack display
 func (pt *PersonaTools) ListServersForDisplay() string {
 	if pt.processManager == nil {
-		return "Server management not available"
+		return errServerManagementUnavailable.Error()
 	}
 
 	servers := pt.processManager.ListServers()
@@ -1535,7 +2697,11 @@ func (pt *PersonaTools) ListServersForDisplay() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("*Running Servers (%d):*\n", len(servers)))
 	for _, s := range servers {
-		sb.WriteString(fmt.Sprintf("• *%s*: %s (port %d, %s)\n", s.ProjectName, s.URL, s.Port, s.Status))
+		status := s.Status
+		if s.Status == "running" && !s.Ready {
+			status = "starting"
+		}
+		sb.WriteString(fmt.Sprintf("• *%s*: %s (port %d, %s)\n", s.ProjectName, s.URL, s.Port, status))
 	}
 	return sb.String()
 }
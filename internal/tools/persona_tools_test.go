@@ -2,13 +2,19 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/everydev1618/govega"
 	"github.com/everydev1618/govega/dsl"
+	"github.com/everydev1618/tron/internal/notification"
+	"gopkg.in/yaml.v3"
 )
 
 // mockLLM implements vega.LLM for testing
@@ -132,6 +138,7 @@ func TestRegisterTo(t *testing.T) {
 		"save_directive",
 		"save_person_memory",
 		"web_search",
+		"fetch_url",
 	}
 
 	for _, name := range expectedTools {
@@ -409,6 +416,64 @@ func TestSavePersonMemory(t *testing.T) {
 	}
 }
 
+func TestConcurrentSavesKeepEveryEntry(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	const saves = 50
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < saves; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := pt.saveDirective(ctx, map[string]any{"key": fmt.Sprintf("d%d", i), "directive": "x"}); err != nil {
+				t.Errorf("saveDirective: %v", err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := pt.savePersonMemory(ctx, map[string]any{"person": "Alice", "key": fmt.Sprintf("m%d", i), "fact": "y"}); err != nil {
+				t.Errorf("savePersonMemory: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// What's on disk must match memory, with no entry lost to a stale write
+	var directives map[string]string
+	readYAML(t, filepath.Join(dir, "knowledge", "directives.yaml"), &directives)
+	var memory map[string]map[string]string
+	readYAML(t, filepath.Join(dir, "knowledge", "person_memory.yaml"), &memory)
+	for i := 0; i < saves; i++ {
+		if _, ok := directives[fmt.Sprintf("d%d", i)]; !ok {
+			t.Errorf("directive d%d missing from disk", i)
+		}
+		if _, ok := memory["Alice"][fmt.Sprintf("m%d", i)]; !ok {
+			t.Errorf("memory m%d missing from disk", i)
+		}
+	}
+
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "knowledge", "*.tmp")); len(leftovers) > 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
+
+func readYAML(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s is not valid YAML: %v", path, err)
+	}
+}
+
 func TestNormalizePhone(t *testing.T) {
 	tests := []struct {
 		input string
@@ -457,19 +522,25 @@ func TestParseStrategy(t *testing.T) {
 
 func TestParseWindow(t *testing.T) {
 	tests := []struct {
-		input string
-		want  time.Duration
+		input   string
+		want    time.Duration
+		wantErr bool
 	}{
-		{"10m", 10 * time.Minute},
-		{"1h", time.Hour},
-		{"30s", 30 * time.Second},
-		{"", 0},
-		{"invalid", 0},
+		{"10m", 10 * time.Minute, false},
+		{"1h", time.Hour, false},
+		{"30s", 30 * time.Second, false},
+		{"", 0, false},
+		{"invalid", 0, true},
+		{"10min", 0, true},
+		{"-5m", 0, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got := parseWindow(tt.input)
+			got, err := parseWindow(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWindow(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
 			if got != tt.want {
 				t.Errorf("parseWindow(%q) = %v, want %v", tt.input, got, tt.want)
 			}
@@ -508,6 +579,65 @@ func TestParseBudget(t *testing.T) {
 	}
 }
 
+func TestValidateImage(t *testing.T) {
+	tests := []struct {
+		image   string
+		wantErr bool
+	}{
+		{"golang:1.23", false},
+		{"node", false},
+		{"python:3.12-slim", false},
+		{"evil/miner:latest", true},
+		{"ghcr.io/someone/image", true},
+		{"golang:1.23;rm", true},
+		{"nginx", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			err := validateImage(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateImage(%q) error = %v, wantErr %v", tt.image, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatBraveResults(t *testing.T) {
+	payload := `{
+		"web": {"results": [{"title": "Go 1.25 released", "url": "https://go.dev/blog", "description": "Release notes", "age": "2 days ago"}]},
+		"news": {"results": [{"title": "Go ships new release", "url": "https://news.example.com/go", "page_age": "2025-08-12T10:00:00"}]},
+		"faq": {"results": [{"question": "What is Go?", "answer": "A programming language.", "url": "https://go.dev"}]},
+		"infobox": {"results": [{"title": "Go (programming language)", "long_desc": "Go is a statically typed language."}]}
+	}`
+
+	var result braveSearchResponse
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		t.Fatalf("failed to parse payload: %v", err)
+	}
+
+	got := formatBraveResults("golang", &result)
+	for _, want := range []string{
+		"[Infobox] Go (programming language)",
+		"1. Go 1.25 released",
+		"Age: 2 days ago",
+		"News:",
+		"Go ships new release (2025-08-12T10:00:00)",
+		"FAQ:",
+		"Q: What is Go?",
+		"A: A programming language.",
+	} {
+		if !contains(got, want) {
+			t.Errorf("formatBraveResults() missing %q in:\n%s", want, got)
+		}
+	}
+
+	empty := formatBraveResults("nothing", &braveSearchResponse{})
+	if !contains(empty, "No results found.") {
+		t.Errorf("formatBraveResults() for empty response = %q", empty)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -520,3 +650,87 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestSpawnWatchHeartbeatBackoff(t *testing.T) {
+	start := time.Now()
+	w := newSpawnWatch(nil, "Gary", start)
+
+	var beats []time.Duration
+	for elapsed := time.Duration(0); elapsed <= 2*time.Hour; elapsed += time.Minute {
+		// Keep making progress so only heartbeats fire
+		if hb, _ := w.check(start.Add(elapsed), int(elapsed/time.Minute)); hb {
+			beats = append(beats, elapsed)
+		}
+	}
+
+	want := []time.Duration{5 * time.Minute, 15 * time.Minute, 35 * time.Minute, 75 * time.Minute, 115 * time.Minute}
+	if len(beats) != len(want) {
+		t.Fatalf("heartbeats at %v, want %v", beats, want)
+	}
+	for i := range want {
+		if beats[i] != want[i] {
+			t.Errorf("heartbeat %d at %v, want %v", i, beats[i], want[i])
+		}
+	}
+}
+
+func TestSpawnWatchStuckDetection(t *testing.T) {
+	start := time.Now()
+	w := newSpawnWatch(nil, "Gary", start)
+
+	if _, stuck := w.check(start.Add(10*time.Minute), 0); stuck {
+		t.Error("reported stuck before threshold")
+	}
+	if _, stuck := w.check(start.Add(stuckThreshold), 0); !stuck {
+		t.Error("expected stuck at threshold with no progress")
+	}
+	if _, stuck := w.check(start.Add(stuckThreshold+time.Minute), 0); stuck {
+		t.Error("stuck should only be reported once")
+	}
+
+	// Progress resets the stuck state
+	w.check(start.Add(20*time.Minute), 5)
+	if _, stuck := w.check(start.Add(20*time.Minute+stuckThreshold), 5); !stuck {
+		t.Error("expected stuck again after progress stalls")
+	}
+}
+
+// uploadingSlack records messages and uploaded files
+type uploadingSlack struct {
+	recordingSlack
+	filename string
+	content  string
+}
+
+func (u *uploadingSlack) UploadFile(channel, filename, content, title string) error {
+	u.filename = filename
+	u.content = content
+	return nil
+}
+
+func TestNotifyChannelUploadsLargeResults(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+	slack := &uploadingSlack{}
+	pt.SetSlackClient(slack)
+
+	ch := notification.ChannelContext{Type: notification.ChannelSlack, ChannelID: "C123"}
+	proc := &vega.Process{ID: "p1", Agent: &vega.Agent{Name: "Gary"}, Task: "write report"}
+
+	pt.notifyChannel(ch, proc, "short result")
+	if slack.filename != "" {
+		t.Error("small result should not be uploaded")
+	}
+
+	large := strings.Repeat("x", slackUploadThreshold+1)
+	pt.notifyChannel(ch, proc, large)
+	if slack.content != large {
+		t.Fatal("large result should be uploaded in full")
+	}
+	if !strings.Contains(slack.text, slack.filename) {
+		t.Errorf("summary should reference the uploaded file, got %q", slack.text)
+	}
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+// ProcessEvent describes a spawned agent starting or finishing
+type ProcessEvent struct {
+	ProcessID string
+	Agent     string
+	Task      string
+	Project   string
+	Model     string
+	Done      bool // False when the agent starts, true when it finishes
+
+	// Set only when the agent starts, if another agent spawned it
+	ParentID    string
+	ParentAgent string
+
+	// Set only when Done
+	Result   string
+	Err      error
+	Duration time.Duration
+	Metrics  vega.ProcessMetrics
+}
+
+// SetProcessRecorder sets a function called when a spawned agent starts and
+// finishes, e.g. to record it in history. A nil recorder disables recording.
+func (pt *PersonaTools) SetProcessRecorder(record func(ProcessEvent)) {
+	pt.recordProcess = record
+}
+
+// emitProcessEvent passes ev to the process recorder, if any
+func (pt *PersonaTools) emitProcessEvent(ev ProcessEvent) {
+	if pt.recordProcess != nil {
+		pt.recordProcess(ev)
+	}
+}
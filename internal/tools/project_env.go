@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectEnvFile is the per-project environment file
+const projectEnvFile = ".env"
+
+// loadProjectEnv reads KEY=VALUE pairs from a project's .env file. A missing
+// file is not an error. PORT is dropped since the server allocator owns it.
+func loadProjectEnv(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, projectEnvFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", projectEnvFile, err)
+	}
+
+	vars, err := parseDotEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", projectEnvFile, err)
+	}
+
+	env := make([]string, 0, len(vars))
+	for _, kv := range vars {
+		if strings.HasPrefix(kv, "PORT=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env, nil
+}
+
+// parseDotEnv parses .env content into KEY=VALUE entries in file order.
+// Supports comments, blank lines, an optional "export " prefix, single
+// quotes (literal), double quotes (with \n, \t, \" and \\ escapes) and
+// trailing " # comments" on unquoted values.
+func parseDotEnv(data []byte) ([]string, error) {
+	var env []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validEnvKey(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		env = append(env, key+"="+value)
+	}
+	return env, scanner.Err()
+}
+
+// parseDotEnvValue unquotes a single .env value
+func parseDotEnvValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+
+	switch quote := v[0]; quote {
+	case '\'':
+		end := strings.IndexByte(v[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return v[1 : end+1], nil
+	case '"':
+		var sb strings.Builder
+		for i := 1; i < len(v); i++ {
+			c := v[i]
+			if c == '"' {
+				return sb.String(), nil
+			}
+			if c == '\\' && i+1 < len(v) {
+				i++
+				switch v[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case 'r':
+					sb.WriteByte('\r')
+				default:
+					sb.WriteByte(v[i])
+				}
+				continue
+			}
+			sb.WriteByte(c)
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	}
+
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// validEnvKey reports whether s is a usable environment variable name
+func validEnvKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// mergeEnv returns base with overrides applied; overridden keys are
+// replaced in place and new keys appended
+func mergeEnv(base, overrides []string) []string {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	index := make(map[string]int, len(base))
+	merged := make([]string, 0, len(base)+len(overrides))
+	for _, kv := range base {
+		key, _, _ := strings.Cut(kv, "=")
+		if i, ok := index[key]; ok {
+			merged[i] = kv
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, kv)
+	}
+	for _, kv := range overrides {
+		key, _, _ := strings.Cut(kv, "=")
+		if i, ok := index[key]; ok {
+			merged[i] = kv
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, kv)
+	}
+	return merged
+}
+
+// projectEnviron is the inherited environment with a project's .env applied
+func projectEnviron(dir string) ([]string, error) {
+	overrides, err := loadProjectEnv(dir)
+	if err != nil {
+		return nil, err
+	}
+	return mergeEnv(os.Environ(), overrides), nil
+}
+
+// withEnv prefixes argv with env(1) so the command it runs sees vars, for
+// a container exec that takes no environment of its own
+func withEnv(vars, argv []string) []string {
+	if len(vars) == 0 {
+		return argv
+	}
+	prefixed := make([]string, 0, 1+len(vars)+len(argv))
+	prefixed = append(prefixed, "env")
+	prefixed = append(prefixed, vars...)
+	return append(prefixed, argv...)
+}
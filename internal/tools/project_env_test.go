@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	data := []byte(`# database
+DB_HOST=localhost
+export DB_USER = admin
+DB_PASS='p@ss # not a comment'
+GREETING="hello\nworld \"quoted\""
+API_URL=https://example.com/api # trailing comment
+EMPTY=
+`)
+	got, err := parseDotEnv(data)
+	if err != nil {
+		t.Fatalf("parseDotEnv() error = %v", err)
+	}
+	want := []string{
+		"DB_HOST=localhost",
+		"DB_USER=admin",
+		"DB_PASS=p@ss # not a comment",
+		"GREETING=hello\nworld \"quoted\"",
+		"API_URL=https://example.com/api",
+		"EMPTY=",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDotEnv() = %q, want %q", got, want)
+	}
+}
+
+func TestParseDotEnvErrors(t *testing.T) {
+	for _, data := range []string{"NOEQUALS", "1BAD=x", `A="open`, "B='open"} {
+		if _, err := parseDotEnv([]byte(data)); err == nil {
+			t.Errorf("parseDotEnv(%q) expected error", data)
+		}
+	}
+}
+
+func TestLoadProjectEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	env, err := loadProjectEnv(dir)
+	if err != nil || env != nil {
+		t.Fatalf("loadProjectEnv() without .env = %v, %v; want nil, nil", env, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=9999\nHOME=/project\nNEW=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env, err = loadProjectEnv(dir)
+	if err != nil {
+		t.Fatalf("loadProjectEnv() error = %v", err)
+	}
+	if want := []string{"HOME=/project", "NEW=1"}; !reflect.DeepEqual(env, want) {
+		t.Errorf("loadProjectEnv() = %q, want %q (PORT dropped)", env, want)
+	}
+
+	merged := mergeEnv([]string{"HOME=/root", "PATH=/bin"}, env)
+	if want := []string{"HOME=/project", "PATH=/bin", "NEW=1"}; !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeEnv() = %q, want %q", merged, want)
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	argv := []string{"bash", "-c", "echo $API_KEY"}
+	if got := withEnv(nil, argv); !reflect.DeepEqual(got, argv) {
+		t.Errorf("withEnv(nil) = %q, want argv unchanged", got)
+	}
+	want := []string{"env", "API_KEY=a b", "DEBUG=1", "bash", "-c", "echo $API_KEY"}
+	if got := withEnv([]string{"API_KEY=a b", "DEBUG=1"}, argv); !reflect.DeepEqual(got, want) {
+		t.Errorf("withEnv() = %q, want %q", got, want)
+	}
+}
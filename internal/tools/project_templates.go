@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateData is the data scaffolded project files are rendered with
+type templateData struct {
+	Name        string // Project name as given
+	Description string
+	PackageName string // npm-style package name: lowercase, dashes
+	ModulePath  string // Go module path
+}
+
+// newTemplateData builds template data for a project
+func newTemplateData(name, description string) templateData {
+	pkg := strings.Trim(strings.ToLower(sanitizeProjectName(strings.TrimSpace(name))), "-_.")
+	if pkg == "" {
+		pkg = "project"
+	}
+	return templateData{
+		Name:        name,
+		Description: description,
+		PackageName: pkg,
+		ModulePath:  pkg,
+	}
+}
+
+// templateFuncs are available in template files. json quotes a value as a
+// JSON (and JavaScript/Python) string literal; oneline flattens text for
+// single-line comments.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"oneline": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// projectTemplates maps template names to file paths and their contents,
+// rendered with text/template and templateData
+var projectTemplates = map[string]map[string]string{
+	"go": {
+		"go.mod": `module {{.ModulePath}}
+
+go 1.23
+`,
+		"main.go": `// {{oneline .Name}}{{with .Description}}: {{oneline .}}{{end}}
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println({{printf "Hello from %s!" .Name | printf "%q"}})
+}
+`,
+	},
+	"python": {
+		"main.py": `#!/usr/bin/env python3
+# {{oneline .Name}}{{with .Description}}: {{oneline .}}{{end}}
+
+
+def main():
+    print({{printf "Hello from %s!" .Name | json}})
+
+
+if __name__ == "__main__":
+    main()
+`,
+	},
+	"node": {
+		"package.json": `{
+  "name": {{json .PackageName}},
+  "version": "1.0.0",
+  "description": {{json .Description}},
+  "main": "index.js"
+}
+`,
+		"index.js": `console.log({{json (printf "Hello from %s!" .Name)}});
+`,
+	},
+	"react": {
+		"package.json": `{
+  "name": {{json .PackageName}},
+  "version": "1.0.0",
+  "description": {{json .Description}},
+  "private": true
+}
+`,
+		"src/App.jsx": `export default function App() {
+  return <h1>{ {{- json .Name -}} }</h1>;
+}
+`,
+	},
+	"empty": {},
+}
+
+// applyTemplate scaffolds a project from a named template
+func (pt *PersonaTools) applyTemplate(dir, name string, data templateData) error {
+	files, ok := projectTemplates[name]
+	if !ok {
+		return fmt.Errorf("unknown template: %s", name)
+	}
+	return renderTemplateFiles(dir, files, data)
+}
+
+// renderTemplateFiles renders each file with data and writes it under dir.
+// Paths are relative and may include subdirectories.
+func renderTemplateFiles(dir string, files map[string]string, data templateData) error {
+	for path, content := range files {
+		tmpl, err := template.New(path).Funcs(templateFuncs).Option("missingkey=error").Parse(content)
+		if err != nil {
+			return fmt.Errorf("invalid template file %s: %w", path, err)
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", path, err)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(target, []byte(sb.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyTemplateSubstitutesProjectName(t *testing.T) {
+	pt := &PersonaTools{}
+	data := newTemplateData(`My "Cool" App`, "Tracks\nthings")
+
+	if data.PackageName != "my--cool--app" {
+		t.Errorf("PackageName = %q", data.PackageName)
+	}
+
+	for name := range projectTemplates {
+		dir := t.TempDir()
+		if err := pt.applyTemplate(dir, name, data); err != nil {
+			t.Fatalf("applyTemplate(%s): %v", name, err)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := pt.applyTemplate(dir, "node", data); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkg struct{ Name, Description string }
+	if err := json.Unmarshal(raw, &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v\n%s", err, raw)
+	}
+	if pkg.Name != data.PackageName || pkg.Description != "Tracks\nthings" {
+		t.Errorf("package.json = %+v", pkg)
+	}
+
+	dir = t.TempDir()
+	if err := pt.applyTemplate(dir, "go", data); err != nil {
+		t.Fatal(err)
+	}
+	gomod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.HasPrefix(string(gomod), "module my--cool--app\n") {
+		t.Errorf("go.mod = %q", gomod)
+	}
+	main, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+	if !strings.Contains(string(main), `fmt.Println("Hello from My \"Cool\" App!")`) ||
+		!strings.HasPrefix(string(main), "// My \"Cool\" App: Tracks things\n") {
+		t.Errorf("main.go = %s", main)
+	}
+
+	if err := pt.applyTemplate(t.TempDir(), "cobol", data); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
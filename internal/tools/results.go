@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everydev1618/tron/internal/persist"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+const (
+	// resultRetention is how long completed results are kept
+	resultRetention = 30 * 24 * time.Hour
+
+	// maxStoredResults caps the number of results kept, oldest dropped first
+	maxStoredResults = 500
+
+	// maxStoredResultBytes caps the size of a single stored result
+	maxStoredResultBytes = 64 * 1024
+
+	// resultListPreview is the preview length when listing results
+	resultListPreview = 200
+)
+
+// ResultRecord is the stored outcome of a completed process
+type ResultRecord struct {
+	ProcessID   string    `json:"process_id"`
+	Agent       string    `json:"agent"`
+	Task        string    `json:"task"`
+	Project     string    `json:"project,omitempty"`
+	Result      string    `json:"result,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Truncated   bool      `json:"truncated,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// resultStore persists completed process results, one JSON file per
+// process in a directory, so storing a result writes only that result
+type resultStore struct {
+	mu      sync.RWMutex
+	records map[string]ResultRecord // process ID -> record
+	dir     string
+	now     func() time.Time
+}
+
+// newResultStore loads results from dir, dropping any past retention
+func newResultStore(dir string) *resultStore {
+	s := &resultStore{
+		records: make(map[string]ResultRecord),
+		dir:     dir,
+		now:     time.Now,
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var r ResultRecord
+		if json.Unmarshal(data, &r) == nil && r.ProcessID != "" {
+			s.records[r.ProcessID] = r
+		}
+	}
+	s.prune()
+	return s
+}
+
+// add stores a record, replacing any earlier one for the same process
+func (s *resultStore) add(r ResultRecord) error {
+	if len(r.Result) > maxStoredResultBytes {
+		r.Result = textutil.Truncate(r.Result, maxStoredResultBytes)
+		r.Truncated = true
+	}
+	if r.CompletedAt.IsZero() {
+		r.CompletedAt = s.now()
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := persist.WriteFile(s.path(r.ProcessID), data); err != nil {
+		return err
+	}
+	s.records[r.ProcessID] = r
+	s.prune()
+	return nil
+}
+
+// path is the file a process's result is kept in
+func (s *resultStore) path(processID string) string {
+	return filepath.Join(s.dir, url.PathEscape(processID)+".json")
+}
+
+// get returns the record for a process
+func (s *resultStore) get(processID string) (ResultRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.records[processID]
+	return r, ok
+}
+
+// list returns records completed since the given time, newest first,
+// optionally filtered to one agent (case-insensitive)
+func (s *resultStore) list(agent string, since time.Time) []ResultRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []ResultRecord
+	for _, r := range s.records {
+		if r.CompletedAt.Before(since) {
+			continue
+		}
+		if agent != "" && !strings.EqualFold(r.Agent, agent) {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CompletedAt.After(out[j].CompletedAt) })
+	return out
+}
+
+// prune drops records past retention and the oldest beyond the cap, with
+// their files. Caller must hold the lock (or own the store during
+// construction).
+func (s *resultStore) prune() {
+	cutoff := s.now().Add(-resultRetention)
+	for id, r := range s.records {
+		if r.CompletedAt.Before(cutoff) {
+			s.remove(id)
+		}
+	}
+
+	if len(s.records) <= maxStoredResults {
+		return
+	}
+	all := make([]ResultRecord, 0, len(s.records))
+	for _, r := range s.records {
+		all = append(all, r)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].CompletedAt.Before(all[j].CompletedAt) })
+	for _, r := range all[:len(all)-maxStoredResults] {
+		s.remove(r.ProcessID)
+	}
+}
+
+// remove drops a record and its file. A file that can't be removed is
+// pruned again on the next load. Caller must hold the lock.
+func (s *resultStore) remove(processID string) {
+	delete(s.records, processID)
+	os.Remove(s.path(processID))
+}
+
+// recordResult stores a finished process's result
+func (pt *PersonaTools) recordResult(r ResultRecord) {
+	if err := pt.results.add(r); err != nil {
+		pt.logger.Errorf("Failed to store result for %s: %v", r.ProcessID, err)
+	}
+}
+
+// Result returns the stored result of a completed process, e.g. to retry
+// a failed notification
+func (pt *PersonaTools) Result(processID string) (ResultRecord, bool) {
+	return pt.results.get(processID)
+}
+
+// FullResult returns a completed process's result text, or false if it
+// isn't stored or was truncated for storage. It suits
+// callback.Registry.SetResultLookup.
+func (pt *PersonaTools) FullResult(processID string) (string, bool) {
+	r, ok := pt.results.get(processID)
+	if !ok || r.Truncated {
+		return "", false
+	}
+	return r.Result, true
+}
+
+// RecentResults returns results completed within the window, newest first,
+// optionally for a single agent
+func (pt *PersonaTools) RecentResults(agent string, window time.Duration) []ResultRecord {
+	return pt.results.list(agent, time.Now().Add(-window))
+}
+
+// getResult is the get_result tool
+func (pt *PersonaTools) getResult(ctx context.Context, params map[string]any) (string, error) {
+	processID, _ := params["process_id"].(string)
+	agent, _ := params["agent"].(string)
+	sinceStr, _ := params["since"].(string)
+
+	if processID = strings.TrimSpace(processID); processID != "" {
+		r, ok := pt.Result(processID)
+		if !ok {
+			return "", fmt.Errorf("no stored result for process %s", processID)
+		}
+		return formatResultRecord(r), nil
+	}
+
+	window := 24 * time.Hour
+	if sinceStr != "" {
+		d, err := time.ParseDuration(sinceStr)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("invalid since %q, use a duration like 24h or 168h", sinceStr)
+		}
+		window = d
+	}
+
+	records := pt.RecentResults(strings.TrimSpace(agent), window)
+	if len(records) == 0 {
+		return fmt.Sprintf("No results stored in the last %s.", window), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d results in the last %s (use process_id for the full text):\n", len(records), window))
+	for _, r := range records {
+		outcome := summarizeResult(r.Result, resultListPreview)
+		if r.Error != "" {
+			outcome = "FAILED: " + r.Error
+		}
+		sb.WriteString(fmt.Sprintf("\n- %s (%s) at %s\n  Task: %s\n  %s\n",
+			r.Agent, r.ProcessID, r.CompletedAt.Format("2006-01-02 15:04"), summarizeResult(r.Task, 100), outcome))
+	}
+	return sb.String(), nil
+}
+
+// formatResultRecord renders a stored result in full
+func formatResultRecord(r ResultRecord) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Result from %s (%s), completed %s\n", r.Agent, r.ProcessID, r.CompletedAt.Format(time.RFC1123)))
+	sb.WriteString(fmt.Sprintf("Task: %s\n", r.Task))
+	if r.Project != "" {
+		sb.WriteString(fmt.Sprintf("Project: %s\n", r.Project))
+	}
+	if r.Error != "" {
+		sb.WriteString(fmt.Sprintf("\nFailed: %s\n", r.Error))
+	}
+	if r.Result != "" {
+		sb.WriteString("\n" + r.Result)
+	}
+	if r.Truncated {
+		sb.WriteString("\n\n[result truncated for storage]")
+	}
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultStorePersistsAndPrunes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tron.work", "process_results")
+	now := time.Now().Truncate(time.Second)
+
+	s := newResultStore(dir)
+	s.now = func() time.Time { return now }
+
+	if err := s.add(ResultRecord{ProcessID: "old", Agent: "Gary", Task: "t", Result: "r", CompletedAt: now.Add(-resultRetention - time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.add(ResultRecord{ProcessID: "p1", Agent: "Gary", Task: "build the site", Result: strings.Repeat("x", maxStoredResultBytes+10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.get("old"); ok {
+		t.Error("result past retention should be pruned")
+	}
+	r, ok := s.get("p1")
+	if !ok || !r.Truncated || len(r.Result) != maxStoredResultBytes || !r.CompletedAt.Equal(now) {
+		t.Errorf("stored record = %+v", r)
+	}
+
+	// Each result has its own file, and pruned ones are removed
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 || filepath.Base(files[0]) != "p1.json" {
+		t.Errorf("result files = %v, want [p1.json]", files)
+	}
+
+	// Results survive a restart
+	reloaded := newResultStore(dir)
+	if _, ok := reloaded.get("p1"); !ok {
+		t.Error("result should be reloaded from disk")
+	}
+}
+
+func TestResultStoreCapsCount(t *testing.T) {
+	s := newResultStore(t.TempDir())
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < maxStoredResults+5; i++ {
+		s.add(ResultRecord{ProcessID: string(rune('a'+i%26)) + time.Duration(i).String(), CompletedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+	if n := len(s.list("", time.Time{})); n != maxStoredResults {
+		t.Errorf("stored %d results, want %d", n, maxStoredResults)
+	}
+}
+
+func TestGetResultTool(t *testing.T) {
+	pt := &PersonaTools{results: newResultStore(t.TempDir())}
+	pt.results.add(ResultRecord{ProcessID: "p1", Agent: "Gary", Task: "build the site", Result: "Deployed to staging."})
+	pt.results.add(ResultRecord{ProcessID: "p2", Agent: "Sarah", Task: "write copy", Error: "budget exceeded"})
+
+	out, err := pt.getResult(context.Background(), map[string]any{"process_id": "p1"})
+	if err != nil || !strings.Contains(out, "Deployed to staging.") {
+		t.Errorf("get_result by process = %q, %v", out, err)
+	}
+
+	out, err = pt.getResult(context.Background(), map[string]any{"agent": "gary"})
+	if err != nil || !strings.Contains(out, "p1") || strings.Contains(out, "p2") {
+		t.Errorf("get_result for gary = %q, %v", out, err)
+	}
+
+	if _, err := pt.getResult(context.Background(), map[string]any{"process_id": "missing"}); err == nil {
+		t.Error("expected an error for an unknown process")
+	}
+}
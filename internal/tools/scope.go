@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/knowledge"
+	"github.com/everydev1618/tron/internal/persist"
+	"gopkg.in/yaml.v3"
+)
+
+// projectTagPrefix marks knowledge entries that belong to a project.
+// Entries without a project tag are global and visible in every scope.
+const projectTagPrefix = "project:"
+
+// scopedQueryOverfetch widens store queries so enough entries remain after
+// dropping other projects' entries
+const scopedQueryOverfetch = 4
+
+// sanitizeProjectName makes a project name safe for use in paths
+func sanitizeProjectName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// projectScope returns the active project for a tool call: the explicit
+// project param if given, otherwise the project the caller was spawned for
+func (pt *PersonaTools) projectScope(ctx context.Context, params map[string]any) string {
+	if project, _ := params["project"].(string); strings.TrimSpace(project) != "" {
+		return sanitizeProjectName(strings.TrimSpace(project))
+	}
+	if proc := vega.ProcessFromContext(ctx); proc != nil {
+		pt.processProjectsMu.RLock()
+		defer pt.processProjectsMu.RUnlock()
+		return pt.processProjects[proc.ID]
+	}
+	return ""
+}
+
+// setProcessProject records the project scope a process was spawned with
+func (pt *PersonaTools) setProcessProject(processID, project string) {
+	pt.processProjectsMu.Lock()
+	defer pt.processProjectsMu.Unlock()
+	if project == "" {
+		delete(pt.processProjects, processID)
+		return
+	}
+	pt.processProjects[processID] = project
+}
+
+// projectDirectivesPath returns where a project's directives are stored
+func (pt *PersonaTools) projectDirectivesPath(project string) string {
+	return filepath.Join(pt.tronDir, "knowledge", "projects", project, "directives.yaml")
+}
+
+// loadDirectives reads global and per-project directives from disk
+func (pt *PersonaTools) loadDirectives() {
+	knowledgeDir := filepath.Join(pt.tronDir, "knowledge")
+
+	pt.directivesMu.Lock()
+	defer pt.directivesMu.Unlock()
+
+	if data, err := os.ReadFile(filepath.Join(knowledgeDir, "directives.yaml")); err == nil {
+		if err := yaml.Unmarshal(data, &pt.directives); err != nil {
+			pt.logger.Warnf("Failed to parse directives: %v", err)
+		}
+	}
+
+	projects, err := os.ReadDir(filepath.Join(knowledgeDir, "projects"))
+	if err != nil {
+		return
+	}
+	for _, p := range projects {
+		if !p.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(pt.projectDirectivesPath(p.Name()))
+		if err != nil {
+			continue
+		}
+		directives := make(map[string]string)
+		if err := yaml.Unmarshal(data, &directives); err != nil {
+			pt.logger.Warnf("Failed to parse directives for project %s: %v", p.Name(), err)
+			continue
+		}
+		pt.projectDirectives[p.Name()] = directives
+	}
+}
+
+// persistProjectDirectives saves a project's directives to disk. The caller
+// holds directivesMu.
+func (pt *PersonaTools) persistProjectDirectives(project string) error {
+	data, err := yaml.Marshal(pt.projectDirectives[project])
+	if err != nil {
+		return err
+	}
+	return persist.WriteFile(pt.projectDirectivesPath(project), data)
+}
+
+// DirectivesPromptSection formats directives for injection into a system prompt.
+// Global directives always apply; a project's directives are added on top and
+// override global ones with the same key.
+func (pt *PersonaTools) DirectivesPromptSection(project string) string {
+	pt.directivesMu.RLock()
+	merged := make(map[string]string, len(pt.directives))
+	for k, v := range pt.directives {
+		merged[k] = v
+	}
+	for k, v := range pt.projectDirectives[project] {
+		merged[k] = v
+	}
+	pt.directivesMu.RUnlock()
+
+	if len(merged) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Directives\n")
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", k, merged[k]))
+	}
+	return sb.String()
+}
+
+// entryProject returns the project an entry is scoped to, or "" if global
+func entryProject(e knowledge.Entry) string {
+	for _, tag := range e.Tags {
+		if strings.HasPrefix(tag, projectTagPrefix) {
+			return strings.TrimPrefix(tag, projectTagPrefix)
+		}
+	}
+	return ""
+}
+
+// filterByProject keeps global entries and those scoped to project, up to limit
+func filterByProject(entries []knowledge.Entry, project string, limit int) []knowledge.Entry {
+	var kept []knowledge.Entry
+	for _, e := range entries {
+		if p := entryProject(e); p != "" && p != project {
+			continue
+		}
+		kept = append(kept, e)
+		if limit > 0 && len(kept) == limit {
+			break
+		}
+	}
+	return kept
+}
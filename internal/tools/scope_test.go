@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/knowledge"
+)
+
+func TestFilterByProject(t *testing.T) {
+	entries := []knowledge.Entry{
+		{Title: "global"},
+		{Title: "a1", Tags: []string{"go", projectTagPrefix + "alpha"}},
+		{Title: "b1", Tags: []string{projectTagPrefix + "beta"}},
+		{Title: "a2", Tags: []string{projectTagPrefix + "alpha"}},
+	}
+
+	got := filterByProject(entries, "alpha", 0)
+	var titles []string
+	for _, e := range got {
+		titles = append(titles, e.Title)
+	}
+	if strings.Join(titles, ",") != "global,a1,a2" {
+		t.Errorf("filterByProject(alpha) = %v, want global,a1,a2", titles)
+	}
+
+	if got := filterByProject(entries, "alpha", 2); len(got) != 2 {
+		t.Errorf("expected limit to cap results at 2, got %d", len(got))
+	}
+}
+
+func TestProjectDirectives(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", dir, nil)
+
+	ctx := context.Background()
+	pt.saveDirective(ctx, map[string]any{"key": "indent", "directive": "use spaces"})
+	pt.saveDirective(ctx, map[string]any{"key": "indent", "directive": "use tabs", "project": "alpha"})
+
+	if got := pt.DirectivesPromptSection("beta"); !strings.Contains(got, "use spaces") || strings.Contains(got, "use tabs") {
+		t.Errorf("project beta should only see global directives, got %q", got)
+	}
+	if got := pt.DirectivesPromptSection("alpha"); !strings.Contains(got, "use tabs") || strings.Contains(got, "use spaces") {
+		t.Errorf("project alpha directive should override global, got %q", got)
+	}
+
+	// Directives survive a restart
+	reloaded := NewPersonaTools(orch, createTestConfig(), "./work", dir, nil)
+	if got := reloaded.DirectivesPromptSection("alpha"); !strings.Contains(got, "use tabs") {
+		t.Errorf("expected project directive after reload, got %q", got)
+	}
+}
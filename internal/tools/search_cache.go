@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// braveSearchURL is the Brave web search endpoint (overridden in tests)
+var braveSearchURL = "https://api.search.brave.com/res/v1/web/search"
+
+const (
+	// DefaultSearchCacheTTL is how long a successful search result is reused
+	DefaultSearchCacheTTL = 15 * time.Minute
+
+	// DefaultSearchCacheSize is how many results are kept; the least
+	// recently used is evicted past it
+	DefaultSearchCacheSize = 500
+)
+
+// cachedNote marks a result served from the cache rather than the provider
+const cachedNote = "\n\n(cached)"
+
+// searchKey normalizes a search so equivalent queries share results
+func searchKey(query string, count int, freshness string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return fmt.Sprintf("%s|%d|%s", normalized, count, freshness)
+}
+
+// searchCall is a search in flight that later callers wait on
+type searchCall struct {
+	done   chan struct{}
+	result string
+	err    error
+}
+
+// cachedSearch is a completed search result, an element of searchGroup.lru
+type cachedSearch struct {
+	key     string
+	result  string
+	expires time.Time
+}
+
+// searchGroup deduplicates concurrent identical searches and keeps
+// successful results in an LRU cache. The zero value caches up to
+// DefaultSearchCacheSize results for DefaultSearchCacheTTL.
+type searchGroup struct {
+	mu    sync.Mutex
+	calls map[string]*searchCall
+	cache map[string]*list.Element
+	lru   *list.List // Most recently used at the front
+
+	ttl        time.Duration // Zero means the default; negative disables caching
+	maxEntries int           // Zero means the default
+}
+
+// configure sets the cache size and TTL, dropping anything cached. A
+// negative ttl disables caching; zero values mean the defaults.
+func (g *searchGroup) configure(maxEntries int, ttl time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.maxEntries, g.ttl = maxEntries, ttl
+	g.cache, g.lru = nil, nil
+}
+
+// do returns the cached result for key, joins a search already in flight
+// for it, or runs fn. Only successful results are cached, and a cache hit
+// is marked "(cached)".
+func (g *searchGroup) do(key string, fn func() (string, error)) (string, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*searchCall)
+	}
+	if g.cache == nil {
+		g.cache = make(map[string]*list.Element)
+		g.lru = list.New()
+	}
+
+	if el, ok := g.cache[key]; ok {
+		cached := el.Value.(*cachedSearch)
+		if time.Now().Before(cached.expires) {
+			g.lru.MoveToFront(el)
+			g.mu.Unlock()
+			return cached.result + cachedNote, nil
+		}
+		g.removeLocked(el)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		return call.result, call.err
+	}
+
+	call := &searchCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.result, call.err = fn()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	if call.err == nil {
+		g.storeLocked(key, call.result)
+	}
+	g.mu.Unlock()
+	close(call.done)
+
+	return call.result, call.err
+}
+
+// storeLocked caches a result, evicting the least recently used entries
+// past the size limit. Caller must hold g.mu.
+func (g *searchGroup) storeLocked(key, result string) {
+	ttl := g.ttl
+	if ttl == 0 {
+		ttl = DefaultSearchCacheTTL
+	}
+	if ttl < 0 {
+		return
+	}
+	maxEntries := g.maxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultSearchCacheSize
+	}
+
+	if el, ok := g.cache[key]; ok {
+		g.removeLocked(el)
+	}
+	g.cache[key] = g.lru.PushFront(&cachedSearch{key: key, result: result, expires: time.Now().Add(ttl)})
+	for g.lru.Len() > maxEntries {
+		g.removeLocked(g.lru.Back())
+	}
+}
+
+// removeLocked drops a cache entry. Caller must hold g.mu.
+func (g *searchGroup) removeLocked(el *list.Element) {
+	g.lru.Remove(el)
+	delete(g.cache, el.Value.(*cachedSearch).key)
+}
+
+// SetSearchCache sets how many web_search results are cached and for how
+// long. Zero values keep the defaults; a negative ttl disables the cache,
+// though concurrent identical searches still share one request.
+func (pt *PersonaTools) SetSearchCache(size int, ttl time.Duration) {
+	pt.searches.configure(size, ttl)
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestSearchKeyNormalizesQuery(t *testing.T) {
+	if searchKey("  Go   Generics ", 5, "") != searchKey("go generics", 5, "") {
+		t.Error("case and whitespace variants should share a key")
+	}
+	if searchKey("go", 5, "") == searchKey("go", 10, "") {
+		t.Error("different counts should not share a key")
+	}
+	if searchKey("go", 5, "") == searchKey("go", 5, "pw") {
+		t.Error("different freshness should not share a key")
+	}
+}
+
+func TestWebSearchDeduplicatesConcurrentQueries(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(100 * time.Millisecond) // hold the request open so callers overlap
+		w.Write([]byte(`{"web":{"results":[{"title":"Go Generics","url":"https://go.dev/doc","description":"Type parameters"}]}}`))
+	}))
+	defer upstream.Close()
+
+	defer func(url string) { braveSearchURL = url }(braveSearchURL)
+	braveSearchURL = upstream.URL
+	t.Setenv("BRAVE_SEARCH_API_KEY", "test-key")
+
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	const callers = 10
+	results := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			query := "go generics"
+			if i%2 == 1 {
+				query = "  Go Generics "
+			}
+			result, err := pt.webSearch(context.Background(), map[string]any{"query": query})
+			if err != nil {
+				t.Errorf("webSearch() error = %v", err)
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("upstream requests = %d, want 1", n)
+	}
+	for i, result := range results {
+		if result != results[0] || !strings.Contains(result, "Go Generics") {
+			t.Errorf("caller %d got %q, want the shared result", i, result)
+		}
+	}
+
+	// The shared result also populates the cache
+	cached, err := pt.webSearch(context.Background(), map[string]any{"query": "go generics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(cached, "\n\n(cached)") || !strings.HasPrefix(cached, results[0]) {
+		t.Errorf("cached search = %q, want the result marked (cached)", cached)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("upstream requests after cached search = %d, want 1", n)
+	}
+}
+
+func TestSearchGroupDoesNotCacheErrors(t *testing.T) {
+	var g searchGroup
+	calls := 0
+	fail := func() (string, error) {
+		calls++
+		return "", context.DeadlineExceeded
+	}
+
+	g.do("k", fail)
+	g.do("k", fail)
+	if calls != 2 {
+		t.Errorf("failed search ran %d times, want 2 (errors are not cached)", calls)
+	}
+}
+
+func TestSearchGroupEvictsLeastRecentlyUsed(t *testing.T) {
+	var g searchGroup
+	g.configure(2, time.Minute)
+	calls := 0
+	search := func(result string) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return result, nil
+		}
+	}
+
+	g.do("a", search("A"))
+	g.do("b", search("B"))
+	if got, _ := g.do("a", search("A")); got != "A\n\n(cached)" {
+		t.Fatalf("do(a) = %q, want a cache hit", got)
+	}
+	g.do("c", search("C")) // evicts b, the least recently used
+
+	calls = 0
+	g.do("a", search("A"))
+	g.do("c", search("C"))
+	if calls != 0 {
+		t.Errorf("a and c ran %d searches, want both cached", calls)
+	}
+	if got, _ := g.do("b", search("B")); got != "B" || calls != 1 {
+		t.Errorf("do(b) = %q after %d searches, want b evicted and searched again", got, calls)
+	}
+}
+
+func TestSearchGroupTTL(t *testing.T) {
+	var g searchGroup
+	g.configure(0, 20*time.Millisecond)
+	calls := 0
+	search := func() (string, error) {
+		calls++
+		return "result", nil
+	}
+
+	g.do("k", search)
+	g.do("k", search)
+	time.Sleep(30 * time.Millisecond)
+	g.do("k", search)
+	if calls != 2 {
+		t.Errorf("searches = %d, want 2 (one cache hit, then expiry)", calls)
+	}
+
+	// A negative TTL turns the cache off
+	g.configure(0, -1)
+	calls = 0
+	g.do("k", search)
+	g.do("k", search)
+	if calls != 2 {
+		t.Errorf("searches with caching disabled = %d, want 2", calls)
+	}
+}
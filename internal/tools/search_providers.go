@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+)
+
+// Search endpoints for the non-Brave providers (overridden in tests)
+var (
+	googleSearchURL  = "https://www.googleapis.com/customsearch/v1"
+	serpAPISearchURL = "https://serpapi.com/search.json"
+)
+
+// maxGoogleSearchCount is the most results Google Custom Search returns per request
+const maxGoogleSearchCount = 10
+
+// SearchRequest is a web_search call as passed to a provider
+type SearchRequest struct {
+	Query     string
+	Count     int
+	Freshness string // "", pd, pw, pm, or py
+}
+
+// SearchProvider is a web search backend. Search returns results formatted
+// the way web_search has always presented them, so agents see the same
+// layout whichever provider answered.
+type SearchProvider interface {
+	// Name identifies the provider for the provider param, e.g. "brave"
+	Name() string
+
+	// Configured reports whether the provider has the credentials it needs
+	Configured() bool
+
+	Search(ctx context.Context, req SearchRequest) (string, error)
+}
+
+// searchStatusError is a non-OK HTTP response from a search provider
+type searchStatusError struct {
+	Provider string
+	Status   int
+	Err      error // Detail, e.g. a quota explanation; nil for a bare status
+}
+
+func (e *searchStatusError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s search API returned status %d", e.Provider, e.Status)
+}
+
+func (e *searchStatusError) Unwrap() error {
+	return e.Err
+}
+
+// shouldFallBack reports whether a failed search is worth retrying with the
+// next provider: the provider is down, rate limited, or out of quota. Bad
+// requests and parse errors would fail the same way anywhere.
+func shouldFallBack(err error) bool {
+	if errors.Is(err, ErrSearchQuotaExhausted) {
+		return true
+	}
+	var status *searchStatusError
+	if errors.As(err, &status) {
+		return status.Status == http.StatusTooManyRequests || status.Status >= 500
+	}
+	return false
+}
+
+// defaultSearchProviders returns the built-in providers in the order they
+// are tried: Brave, Google Custom Search, then SerpAPI. Credentials are
+// read from the environment on each search.
+func defaultSearchProviders(quota *searchQuotaTracker) []SearchProvider {
+	return []SearchProvider{
+		&braveProvider{quota: quota},
+		googleProvider{},
+		serpAPIProvider{},
+	}
+}
+
+// SetSearchProviders replaces the providers web_search tries, in order.
+// Nil restores the built-in Brave, Google and SerpAPI providers.
+func (pt *PersonaTools) SetSearchProviders(providers []SearchProvider) {
+	if providers == nil {
+		providers = defaultSearchProviders(&pt.searchQuota)
+	}
+	pt.searchProviders = providers
+}
+
+// searchProvidersFor returns the configured providers to try, or just the
+// named one if the caller forced a provider
+func (pt *PersonaTools) searchProvidersFor(name string) ([]SearchProvider, error) {
+	var names []string
+	var configured []SearchProvider
+	for _, p := range pt.searchProviders {
+		names = append(names, p.Name())
+		if name != "" && p.Name() != name {
+			continue
+		}
+		if !p.Configured() {
+			if name != "" {
+				return nil, fmt.Errorf("search provider %s is not configured", name)
+			}
+			continue
+		}
+		configured = append(configured, p)
+	}
+
+	if name != "" && len(configured) == 0 {
+		return nil, fmt.Errorf("unknown search provider %q (use %s)", name, strings.Join(names, ", "))
+	}
+	if len(configured) == 0 {
+		return nil, fmt.Errorf("no web search provider configured (set BRAVE_SEARCH_API_KEY, GOOGLE_SEARCH_API_KEY and GOOGLE_SEARCH_ENGINE_ID, or SERPAPI_API_KEY)")
+	}
+	return configured, nil
+}
+
+// searchWithFallback tries each provider in turn, moving on only when one
+// is down or rate limited
+func (pt *PersonaTools) searchWithFallback(ctx context.Context, providers []SearchProvider, req SearchRequest) (string, error) {
+	var firstErr error
+	for i, p := range providers {
+		result, err := p.Search(ctx, req)
+		if err == nil {
+			return result, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if !shouldFallBack(err) || i == len(providers)-1 {
+			if i > 0 {
+				return "", fmt.Errorf("%s search failed after falling back: %w", p.Name(), err)
+			}
+			return "", err
+		}
+		pt.logger.Warnf("%s search failed, trying %s: %v", p.Name(), providers[i+1].Name(), err)
+	}
+	return "", firstErr
+}
+
+// braveProvider searches with the Brave Search API, tracking its quota
+type braveProvider struct {
+	quota *searchQuotaTracker
+}
+
+func (p *braveProvider) Name() string { return "brave" }
+
+func (p *braveProvider) Configured() bool {
+	return os.Getenv("BRAVE_SEARCH_API_KEY") != ""
+}
+
+func (p *braveProvider) Search(ctx context.Context, req SearchRequest) (string, error) {
+	// Don't spend calls that will only be refused. Cached results were
+	// already served by webSearch, so this only stops new queries.
+	if q := p.quota.get(); q.Exhausted(time.Now()) {
+		return "", quotaSkippedError(q, time.Now())
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", braveSearchURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	q := httpReq.URL.Query()
+	q.Add("q", req.Query)
+	q.Add("count", strconv.Itoa(req.Count))
+	if req.Freshness != "" {
+		q.Add("freshness", req.Freshness)
+	}
+	httpReq.URL.RawQuery = q.Encode()
+
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("X-Subscription-Token", os.Getenv("BRAVE_SEARCH_API_KEY"))
+
+	client := httpclient.New(30 * time.Second)
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("search request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	p.quota.observe(resp, time.Now())
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", &searchStatusError{Provider: p.Name(), Status: resp.StatusCode, Err: quotaError(p.quota.get(), time.Now())}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &searchStatusError{Provider: p.Name(), Status: resp.StatusCode}
+	}
+
+	var result braveSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return formatBraveResults(req.Query, &result), nil
+}
+
+// googleProvider searches with the Google Custom Search JSON API
+type googleProvider struct{}
+
+func (googleProvider) Name() string { return "google" }
+
+func (googleProvider) Configured() bool {
+	return os.Getenv("GOOGLE_SEARCH_API_KEY") != "" && os.Getenv("GOOGLE_SEARCH_ENGINE_ID") != ""
+}
+
+// googleDateRestrict maps web_search freshness to Google's dateRestrict
+var googleDateRestrict = map[string]string{"pd": "d1", "pw": "w1", "pm": "m1", "py": "y1"}
+
+func (p googleProvider) Search(ctx context.Context, req SearchRequest) (string, error) {
+	params := map[string]string{
+		"key": os.Getenv("GOOGLE_SEARCH_API_KEY"),
+		"cx":  os.Getenv("GOOGLE_SEARCH_ENGINE_ID"),
+		"q":   req.Query,
+		"num": strconv.Itoa(min(req.Count, maxGoogleSearchCount)),
+	}
+	if restrict := googleDateRestrict[req.Freshness]; restrict != "" {
+		params["dateRestrict"] = restrict
+	}
+
+	var result struct {
+		Items []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := getSearchJSON(ctx, p.Name(), googleSearchURL, params, &result); err != nil {
+		return "", err
+	}
+
+	var out braveSearchResponse
+	for _, item := range result.Items {
+		out.Web.Results = append(out.Web.Results, braveResult{Title: item.Title, URL: item.Link, Description: item.Snippet})
+	}
+	return formatBraveResults(req.Query, &out), nil
+}
+
+// serpAPIProvider searches Google through SerpAPI
+type serpAPIProvider struct{}
+
+func (serpAPIProvider) Name() string { return "serpapi" }
+
+func (serpAPIProvider) Configured() bool {
+	return os.Getenv("SERPAPI_API_KEY") != ""
+}
+
+// serpAPIRecency maps web_search freshness to Google's tbs recency filter
+var serpAPIRecency = map[string]string{"pd": "qdr:d", "pw": "qdr:w", "pm": "qdr:m", "py": "qdr:y"}
+
+func (p serpAPIProvider) Search(ctx context.Context, req SearchRequest) (string, error) {
+	params := map[string]string{
+		"engine":  "google",
+		"api_key": os.Getenv("SERPAPI_API_KEY"),
+		"q":       req.Query,
+		"num":     strconv.Itoa(req.Count),
+	}
+	if tbs := serpAPIRecency[req.Freshness]; tbs != "" {
+		params["tbs"] = tbs
+	}
+
+	var result struct {
+		Error          string `json:"error"`
+		OrganicResults []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+			Date    string `json:"date"`
+		} `json:"organic_results"`
+	}
+	if err := getSearchJSON(ctx, p.Name(), serpAPISearchURL, params, &result); err != nil {
+		return "", err
+	}
+	if result.Error != "" && len(result.OrganicResults) == 0 && !strings.Contains(result.Error, "hasn't returned any results") {
+		return "", fmt.Errorf("serpapi search failed: %s", result.Error)
+	}
+
+	var out braveSearchResponse
+	for i, r := range result.OrganicResults {
+		if i >= req.Count {
+			break
+		}
+		out.Web.Results = append(out.Web.Results, braveResult{Title: r.Title, URL: r.Link, Description: r.Snippet, Age: r.Date})
+	}
+	return formatBraveResults(req.Query, &out), nil
+}
+
+// getSearchJSON GETs endpoint with params and decodes the JSON response
+// into v. Non-OK statuses come back as *searchStatusError.
+func getSearchJSON(ctx context.Context, provider, endpoint string, params map[string]string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	q := req.URL.Query()
+	for k, val := range params {
+		q.Set(k, val)
+	}
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", "application/json")
+
+	client := httpclient.New(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s search request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &searchStatusError{Provider: provider, Status: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", provider, err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+// clearSearchEnv unsets every provider's credentials for the test
+func clearSearchEnv(t *testing.T) {
+	for _, key := range []string{"BRAVE_SEARCH_API_KEY", "GOOGLE_SEARCH_API_KEY", "GOOGLE_SEARCH_ENGINE_ID", "SERPAPI_API_KEY"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestWebSearchFallsBackOnServerError(t *testing.T) {
+	clearSearchEnv(t)
+	var braveCalls atomic.Int32
+	brave := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		braveCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer brave.Close()
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cx") != "engine" || r.URL.Query().Get("dateRestrict") != "w1" {
+			t.Errorf("google query = %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"items":[{"title":"Go Generics","link":"https://go.dev/doc","snippet":"Type parameters"}]}`))
+	}))
+	defer google.Close()
+
+	defer func(b, g string) { braveSearchURL, googleSearchURL = b, g }(braveSearchURL, googleSearchURL)
+	braveSearchURL, googleSearchURL = brave.URL, google.URL
+	t.Setenv("BRAVE_SEARCH_API_KEY", "brave-key")
+	t.Setenv("GOOGLE_SEARCH_API_KEY", "google-key")
+	t.Setenv("GOOGLE_SEARCH_ENGINE_ID", "engine")
+
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	result, err := pt.webSearch(context.Background(), map[string]any{"query": "go generics", "freshness": "pw"})
+	if err != nil {
+		t.Fatalf("webSearch() error = %v", err)
+	}
+	want := "Search results for: go generics\n\n1. Go Generics\n   URL: https://go.dev/doc\n   Type parameters\n\n"
+	if result != want {
+		t.Errorf("webSearch() = %q, want the usual layout %q", result, want)
+	}
+	if n := braveCalls.Load(); n != 1 {
+		t.Errorf("brave calls = %d, want 1", n)
+	}
+}
+
+func TestWebSearchForcedProvider(t *testing.T) {
+	clearSearchEnv(t)
+	serp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"organic_results":[{"title":"Serp Result","link":"https://example.com","snippet":"From SerpAPI"}]}`))
+	}))
+	defer serp.Close()
+
+	defer func(u string) { serpAPISearchURL = u }(serpAPISearchURL)
+	serpAPISearchURL = serp.URL
+	t.Setenv("BRAVE_SEARCH_API_KEY", "brave-key")
+	t.Setenv("SERPAPI_API_KEY", "serp-key")
+
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	result, err := pt.webSearch(context.Background(), map[string]any{"query": "q", "provider": "SerpAPI"})
+	if err != nil || !strings.Contains(result, "Serp Result") {
+		t.Fatalf("webSearch() = %q, %v; want the SerpAPI result", result, err)
+	}
+
+	if _, err := pt.webSearch(context.Background(), map[string]any{"query": "q", "provider": "google"}); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("forcing an unconfigured provider: err = %v", err)
+	}
+	if _, err := pt.webSearch(context.Background(), map[string]any{"query": "q", "provider": "bing"}); err == nil || !strings.Contains(err.Error(), "unknown search provider") {
+		t.Errorf("forcing an unknown provider: err = %v", err)
+	}
+}
+
+func TestWebSearchNoProviderConfigured(t *testing.T) {
+	clearSearchEnv(t)
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	_, err := pt.webSearch(context.Background(), map[string]any{"query": "q"})
+	if err == nil || !strings.Contains(err.Error(), "no web search provider configured") {
+		t.Errorf("webSearch() error = %v", err)
+	}
+}
+
+func TestShouldFallBack(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&searchStatusError{Provider: "brave", Status: 500}, true},
+		{&searchStatusError{Provider: "brave", Status: 429}, true},
+		{&searchStatusError{Provider: "brave", Status: 400}, false},
+		{ErrSearchQuotaExhausted, true},
+		{context.DeadlineExceeded, false},
+	}
+	for _, c := range cases {
+		if got := shouldFallBack(c.err); got != c.want {
+			t.Errorf("shouldFallBack(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
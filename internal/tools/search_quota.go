@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSearchQuotaExhausted is returned without calling Brave while the
+// monthly quota is known to be used up
+var ErrSearchQuotaExhausted = errors.New("web search quota exhausted")
+
+// SearchQuota is the latest Brave Search quota state seen in response headers
+type SearchQuota struct {
+	Known            bool      // Whether any rate-limit headers have been seen
+	Limit            int       // Requests allowed in the quota window (e.g. per month)
+	Remaining        int       // Requests left in the quota window
+	ResetAt          time.Time // When the quota window resets
+	RateLimitedUntil time.Time // Set by a 429 for the short (per-second) window
+	UpdatedAt        time.Time
+}
+
+// Exhausted reports whether the quota is known to be used up at now
+func (q SearchQuota) Exhausted(now time.Time) bool {
+	return q.Known && q.Remaining <= 0 && now.Before(q.ResetAt)
+}
+
+// searchQuotaTracker holds the latest quota state. The zero value is ready to use.
+type searchQuotaTracker struct {
+	mu    sync.Mutex
+	state SearchQuota
+}
+
+// get returns the current state
+func (t *searchQuotaTracker) get() SearchQuota {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// observe records the rate-limit headers of a Brave response
+func (t *searchQuotaTracker) observe(resp *http.Response, now time.Time) {
+	limits := headerInts(resp.Header.Get("X-RateLimit-Limit"))
+	remaining := headerInts(resp.Header.Get("X-RateLimit-Remaining"))
+	resets := headerInts(resp.Header.Get("X-RateLimit-Reset"))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Brave lists one value per window, shortest first; the last is the quota
+	if len(remaining) > 0 {
+		t.state.Known = true
+		t.state.Remaining = remaining[len(remaining)-1]
+		if len(limits) > 0 {
+			t.state.Limit = limits[len(limits)-1]
+		}
+		if len(resets) > 0 {
+			t.state.ResetAt = now.Add(time.Duration(resets[len(resets)-1]) * time.Second)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests && !t.state.Exhausted(now) {
+		wait := time.Second
+		if len(resets) > 0 {
+			wait = time.Duration(resets[0]) * time.Second
+		} else if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(s) * time.Second
+		}
+		t.state.RateLimitedUntil = now.Add(wait)
+	}
+	t.state.UpdatedAt = now
+}
+
+// headerInts parses a comma-separated list of integers like "1, 15000"
+func headerInts(v string) []int {
+	if v == "" {
+		return nil
+	}
+	var out []int
+	for _, part := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// quotaError explains a 429 using the latest quota state
+func quotaError(q SearchQuota, now time.Time) error {
+	if q.Exhausted(now) {
+		return fmt.Errorf("search API returned status 429: %w (%d of %d used); resets %s (in %s)",
+			ErrSearchQuotaExhausted, q.Limit-q.Remaining, q.Limit,
+			q.ResetAt.Format("Jan 2 15:04 MST"), q.ResetAt.Sub(now).Round(time.Minute))
+	}
+	if wait := q.RateLimitedUntil.Sub(now); wait > 0 {
+		return fmt.Errorf("search API returned status 429: rate limited, retry in %s", wait.Round(time.Second))
+	}
+	return fmt.Errorf("search API returned status 429: rate limited, retry shortly")
+}
+
+// quotaSkippedError explains a search that was never sent because the
+// quota seen in earlier responses is used up
+func quotaSkippedError(q SearchQuota, now time.Time) error {
+	return fmt.Errorf("search skipped without calling the API: %w by the locally tracked count (%d of %d used); resets %s (in %s)",
+		ErrSearchQuotaExhausted, q.Limit-q.Remaining, q.Limit,
+		q.ResetAt.Format("Jan 2 15:04 MST"), q.ResetAt.Sub(now).Round(time.Minute))
+}
+
+// SearchQuota returns the latest known Brave Search quota state
+func (pt *PersonaTools) SearchQuota() SearchQuota {
+	return pt.searchQuota.get()
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestSearchQuotaTracksHeadersAndShortCircuits(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		w.Header().Set("X-RateLimit-Limit", "1, 2000")
+		w.Header().Set("X-RateLimit-Reset", "1, 86400")
+		if n == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0, 1")
+			w.Write([]byte(`{"web":{"results":[]}}`))
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "0, 0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	defer func(url string) { braveSearchURL = url }(braveSearchURL)
+	braveSearchURL = upstream.URL
+	t.Setenv("BRAVE_SEARCH_API_KEY", "test-key")
+
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	if q := pt.SearchQuota(); q.Known {
+		t.Fatalf("SearchQuota() before any search = %+v, want unknown", q)
+	}
+
+	if _, err := pt.webSearch(context.Background(), map[string]any{"query": "first"}); err != nil {
+		t.Fatalf("webSearch() error = %v", err)
+	}
+	q := pt.SearchQuota()
+	if !q.Known || q.Limit != 2000 || q.Remaining != 1 {
+		t.Fatalf("SearchQuota() = %+v, want 1 of 2000 remaining", q)
+	}
+	if d := time.Until(q.ResetAt); d < 23*time.Hour || d > 25*time.Hour {
+		t.Errorf("ResetAt in %s, want about 24h", d)
+	}
+
+	_, err := pt.webSearch(context.Background(), map[string]any{"query": "second"})
+	if !errors.Is(err, ErrSearchQuotaExhausted) {
+		t.Fatalf("webSearch() on 429 error = %v, want ErrSearchQuotaExhausted", err)
+	}
+	if !strings.Contains(err.Error(), "resets") {
+		t.Errorf("error %q should say when the quota resets", err)
+	}
+
+	// Known exhaustion skips the API entirely, and says so
+	_, err = pt.webSearch(context.Background(), map[string]any{"query": "third"})
+	if !errors.Is(err, ErrSearchQuotaExhausted) {
+		t.Fatalf("webSearch() when exhausted error = %v, want ErrSearchQuotaExhausted", err)
+	}
+	if strings.Contains(err.Error(), "429") || !strings.Contains(err.Error(), "skipped") {
+		t.Errorf("error %q should say the call was skipped, not that the API refused it", err)
+	}
+
+	// Cached results are still served
+	if result, err := pt.webSearch(context.Background(), map[string]any{"query": "first"}); err != nil || !strings.HasSuffix(result, cachedNote) {
+		t.Errorf("cached webSearch() when exhausted = %q, %v", result, err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("upstream requests = %d, want 2", n)
+	}
+}
+
+func TestQuotaErrorRateLimited(t *testing.T) {
+	now := time.Now()
+	q := SearchQuota{Known: true, Limit: 2000, Remaining: 500, ResetAt: now.Add(time.Hour), RateLimitedUntil: now.Add(2 * time.Second)}
+	err := quotaError(q, now)
+	if errors.Is(err, ErrSearchQuotaExhausted) {
+		t.Fatalf("quotaError() = %v, want a rate limit error", err)
+	}
+	if !strings.Contains(err.Error(), "retry in 2s") {
+		t.Errorf("quotaError() = %q, want retry hint", err)
+	}
+}
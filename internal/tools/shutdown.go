@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrShuttingDown is returned for new spawns once shutdown has begun
+var ErrShuttingDown = errors.New("tron is shutting down, not accepting new work")
+
+// drainPollInterval is how often Drain and AwaitSpawns check for work
+// still running
+const drainPollInterval = 100 * time.Millisecond
+
+// StopAccepting refuses new spawns. Tool calls already running are unaffected.
+func (pt *PersonaTools) StopAccepting() {
+	pt.draining.Store(true)
+}
+
+// InFlight returns the number of tool calls currently running
+func (pt *PersonaTools) InFlight() int {
+	return int(pt.inflight.Load())
+}
+
+// Drain stops accepting new spawns and waits for in-flight tool calls to
+// finish, then stops the spawn monitor. It returns the number still running
+// when ctx is done.
+func (pt *PersonaTools) Drain(ctx context.Context) int {
+	pt.StopAccepting()
+	defer pt.stopMonitorOnce.Do(func() { close(pt.spawnMonitorStop) })
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n := pt.InFlight()
+		if n == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return n
+		case <-ticker.C:
+		}
+	}
+}
+
+// AwaitSpawns waits for spawned agents to finish and their completions to
+// be announced. It returns the number still running when ctx is done;
+// those are stopped with the orchestrator and never report back.
+func (pt *PersonaTools) AwaitSpawns(ctx context.Context) int {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n := int(pt.spawned.Load())
+		if n == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return n
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/persist"
+)
+
+// spawnCallbacksState is the on-disk form of pending completion callbacks
+// and channel contexts, keyed by process ID
+type spawnCallbacksState struct {
+	Callbacks map[string]CallbackConfig              `json:"callbacks,omitempty"`
+	Channels  map[string]notification.ChannelContext `json:"channels,omitempty"`
+}
+
+// spawnCallbacksPath is where pending callbacks survive a restart
+func (pt *PersonaTools) spawnCallbacksPath() string {
+	return filepath.Join(pt.tronDir, "tron.work", "spawn_callbacks.json")
+}
+
+// saveSpawnCallbacks writes pending callbacks and channel contexts so
+// completions are still announced after a restart
+func (pt *PersonaTools) saveSpawnCallbacks() {
+	state := spawnCallbacksState{
+		Callbacks: make(map[string]CallbackConfig),
+		Channels:  make(map[string]notification.ChannelContext),
+	}
+	pt.callbacksMu.RLock()
+	for id, cb := range pt.callbacks {
+		state.Callbacks[id] = cb
+	}
+	pt.callbacksMu.RUnlock()
+	pt.processChannelsMu.RLock()
+	for id, ch := range pt.processChannels {
+		state.Channels[id] = ch
+	}
+	pt.processChannelsMu.RUnlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		pt.logger.Errorf("Failed to marshal spawn callbacks: %v", err)
+		return
+	}
+
+	pt.spawnCallbacksSaveMu.Lock()
+	defer pt.spawnCallbacksSaveMu.Unlock()
+
+	if err := persist.WriteFile(pt.spawnCallbacksPath(), data); err != nil {
+		pt.logger.Errorf("Failed to save spawn callbacks: %v", err)
+	}
+}
+
+// RestoreSpawnCallbacks restores completion notifications for agents that
+// survived a restart. Call it once the orchestrator has recovered its
+// processes: entries for processes it doesn't know are dropped for good.
+func (pt *PersonaTools) RestoreSpawnCallbacks() {
+	pt.loadSpawnCallbacks(func(id string) bool { return pt.orch.Get(id) != nil })
+}
+
+// loadSpawnCallbacks restores saved callbacks and channel contexts for
+// processes that are still alive, dropping the rest
+func (pt *PersonaTools) loadSpawnCallbacks(alive func(processID string) bool) {
+	data, err := os.ReadFile(pt.spawnCallbacksPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			pt.logger.Warnf("Failed to load spawn callbacks: %v", err)
+		}
+		return
+	}
+
+	var state spawnCallbacksState
+	if err := json.Unmarshal(data, &state); err != nil {
+		pt.logger.Errorf("Failed to parse spawn callbacks: %v", err)
+		return
+	}
+
+	restored, dropped := 0, 0
+	pt.callbacksMu.Lock()
+	for id, cb := range state.Callbacks {
+		if !alive(id) {
+			dropped++
+			continue
+		}
+		pt.callbacks[id] = cb
+		restored++
+	}
+	pt.callbacksMu.Unlock()
+
+	pt.processChannelsMu.Lock()
+	for id, ch := range state.Channels {
+		if !alive(id) {
+			dropped++
+			continue
+		}
+		pt.processChannels[id] = ch
+		restored++
+	}
+	pt.processChannelsMu.Unlock()
+
+	if dropped > 0 {
+		pt.saveSpawnCallbacks()
+	}
+	if restored > 0 {
+		pt.logger.Infof("Restored %d pending spawn notifications (%d dropped for finished processes)", restored, dropped)
+		pt.setupCallbackHandlerOnce()
+	}
+}
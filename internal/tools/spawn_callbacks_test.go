@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/notification"
+)
+
+func TestSpawnCallbacksSurviveRestart(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	pt := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+	restarted := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+	again := NewPersonaTools(orch, createTestConfig(), dir, dir, nil)
+
+	pt.callbacks["live"] = CallbackConfig{Email: "sam@example.com", Subject: "Done"}
+	pt.callbacks["gone"] = CallbackConfig{Email: "old@example.com"}
+	pt.processChannels["live"] = notification.ChannelContext{Type: notification.ChannelSlack, ChannelID: "C123"}
+	pt.processChannels["gone"] = notification.ChannelContext{Type: notification.ChannelSlack, ChannelID: "C999"}
+	pt.saveSpawnCallbacks()
+
+	restarted.loadSpawnCallbacks(func(id string) bool { return id == "live" })
+
+	if cb, ok := restarted.callbacks["live"]; !ok || cb.Email != "sam@example.com" {
+		t.Errorf("live callback = %+v, %v", cb, ok)
+	}
+	if ch := restarted.processChannels["live"]; ch.ChannelID != "C123" {
+		t.Errorf("live channel = %+v", ch)
+	}
+	if _, ok := restarted.callbacks["gone"]; ok {
+		t.Error("callback for a finished process was restored")
+	}
+	if _, ok := restarted.processChannels["gone"]; ok {
+		t.Error("channel for a finished process was restored")
+	}
+
+	// Dropped entries are pruned from disk too
+	again.loadSpawnCallbacks(func(string) bool { return true })
+	if _, ok := again.callbacks["gone"]; ok || len(again.callbacks) != 1 {
+		t.Errorf("callbacks after prune = %v", again.callbacks)
+	}
+}
+
+func TestRestoreSpawnCallbacksAfterRecovery(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	dir := t.TempDir()
+
+	proc, err := orch.Spawn(vega.Agent{Name: "Gary", Tools: vega.NewTools()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir), WithTronDir(dir))
+	pt.callbacks[proc.ID] = CallbackConfig{Email: "sam@example.com"}
+	pt.callbacks["gone"] = CallbackConfig{Email: "old@example.com"}
+	pt.saveSpawnCallbacks()
+
+	// Construction runs before recovery, so it mustn't judge saved entries
+	restarted := NewPersonaToolsWithOptions(orch, createTestConfig(), WithWorkingDir(dir), WithTronDir(dir))
+	if len(restarted.callbacks) != 0 {
+		t.Fatalf("callbacks loaded at construction: %v", restarted.callbacks)
+	}
+
+	restarted.RestoreSpawnCallbacks()
+	if cb, ok := restarted.callbacks[proc.ID]; !ok || cb.Email != "sam@example.com" {
+		t.Errorf("recovered callback = %+v, %v", cb, ok)
+	}
+	if _, ok := restarted.callbacks["gone"]; ok {
+		t.Error("callback for an unknown process was restored")
+	}
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSpawnLimit is returned when the spawn cap is reached and spawns are not
+// queued, or a queued spawn waited too long for a slot
+var ErrSpawnLimit = errors.New("too many agents running")
+
+// spawnQueueTimeout bounds how long a queued spawn waits for a free slot
+const spawnQueueTimeout = 10 * time.Minute
+
+// SpawnLimitMode is what spawn_agent does when the cap is reached
+type SpawnLimitMode string
+
+const (
+	SpawnLimitQueue  SpawnLimitMode = "queue"  // Wait for a running agent to finish
+	SpawnLimitReject SpawnLimitMode = "reject" // Fail the spawn immediately
+)
+
+// ParseSpawnLimitMode converts a mode string, defaulting to queue
+func ParseSpawnLimitMode(s string) SpawnLimitMode {
+	if strings.EqualFold(strings.TrimSpace(s), string(SpawnLimitReject)) {
+		return SpawnLimitReject
+	}
+	return SpawnLimitQueue
+}
+
+// SetMaxConcurrentSpawns caps how many spawned agents run at once across all
+// personas. A limit of 0 or less removes the cap. Call before tools are used.
+func (pt *PersonaTools) SetMaxConcurrentSpawns(limit int, mode SpawnLimitMode) {
+	if limit <= 0 {
+		pt.spawnSlots = nil
+		return
+	}
+	pt.spawnSlots = make(chan struct{}, limit)
+	pt.spawnLimitMode = mode
+}
+
+// SpawnCapacity returns how many spawn slots are in use and the cap.
+// A limit of 0 means spawns are not capped.
+func (pt *PersonaTools) SpawnCapacity() (inUse, limit int) {
+	if pt.spawnSlots == nil {
+		return 0, 0
+	}
+	return len(pt.spawnSlots), cap(pt.spawnSlots)
+}
+
+// acquireSpawnSlot takes a spawn slot, waiting for one in queue mode. The
+// returned release is safe to call more than once.
+func (pt *PersonaTools) acquireSpawnSlot(ctx context.Context) (release func(), err error) {
+	slots := pt.spawnSlots
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	if pt.spawnLimitMode == SpawnLimitReject {
+		select {
+		case slots <- struct{}{}:
+		default:
+			return nil, fmt.Errorf("%w (%d/%d), try again when one finishes", ErrSpawnLimit, len(slots), cap(slots))
+		}
+	} else {
+		timer := time.NewTimer(spawnQueueTimeout)
+		defer timer.Stop()
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, fmt.Errorf("%w, waited %s for a free slot", ErrSpawnLimit, spawnQueueTimeout)
+		}
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { <-slots }) }, nil
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestSpawnSlotsRejectAtCap(t *testing.T) {
+	pt := NewPersonaTools(vega.NewOrchestrator(vega.WithLLM(&mockLLM{})), createTestConfig(), "./work", ".", nil)
+	pt.SetMaxConcurrentSpawns(1, SpawnLimitReject)
+
+	release, err := pt.acquireSpawnSlot(context.Background())
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	if inUse, limit := pt.SpawnCapacity(); inUse != 1 || limit != 1 {
+		t.Errorf("SpawnCapacity() = %d/%d, want 1/1", inUse, limit)
+	}
+
+	if _, err := pt.acquireSpawnSlot(context.Background()); !errors.Is(err, ErrSpawnLimit) {
+		t.Fatalf("acquire at cap = %v, want ErrSpawnLimit", err)
+	}
+
+	// Releasing twice must not free a slot someone else holds
+	release()
+	release()
+	if inUse, _ := pt.SpawnCapacity(); inUse != 0 {
+		t.Errorf("in use after release = %d, want 0", inUse)
+	}
+}
+
+func TestSpawnSlotsQueueUntilReleased(t *testing.T) {
+	pt := NewPersonaTools(vega.NewOrchestrator(vega.WithLLM(&mockLLM{})), createTestConfig(), "./work", ".", nil)
+	pt.SetMaxConcurrentSpawns(1, SpawnLimitQueue)
+
+	release, err := pt.acquireSpawnSlot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := pt.acquireSpawnSlot(context.Background())
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("queued acquire returned early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("queued acquire: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued acquire did not get the released slot")
+	}
+
+	// A queued spawn gives up when its caller does
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pt.acquireSpawnSlot(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire with cancelled ctx = %v, want context.Canceled", err)
+	}
+}
+
+func TestSpawnSlotsUnlimitedByDefault(t *testing.T) {
+	pt := NewPersonaTools(vega.NewOrchestrator(vega.WithLLM(&mockLLM{})), createTestConfig(), "./work", ".", nil)
+	for i := 0; i < 100; i++ {
+		if _, err := pt.acquireSpawnSlot(context.Background()); err != nil {
+			t.Fatalf("acquire %d without a cap: %v", i, err)
+		}
+	}
+	if inUse, limit := pt.SpawnCapacity(); inUse != 0 || limit != 0 {
+		t.Errorf("SpawnCapacity() = %d/%d, want 0/0", inUse, limit)
+	}
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/notification"
+)
+
+const (
+	// spawnMonitorInterval is how often running spawns are checked
+	spawnMonitorInterval = 30 * time.Second
+
+	// First heartbeat after this long; each following one doubles up to the max
+	heartbeatInitial = 5 * time.Minute
+	heartbeatMax     = 40 * time.Minute
+
+	// A spawn with no new iterations, tool calls, or tokens for this long is reported as stuck
+	stuckThreshold = 15 * time.Minute
+)
+
+// spawnWatch tracks a running spawn for heartbeats and stuck detection
+type spawnWatch struct {
+	proc      *vega.Process
+	agentName string
+	startedAt time.Time
+
+	interval      time.Duration
+	nextHeartbeat time.Time
+
+	progress      int
+	lastProgress  time.Time
+	stuckReported bool
+}
+
+// newSpawnWatch creates a watch starting at now
+func newSpawnWatch(proc *vega.Process, agentName string, now time.Time) *spawnWatch {
+	return &spawnWatch{
+		proc:          proc,
+		agentName:     agentName,
+		startedAt:     now,
+		interval:      heartbeatInitial,
+		nextHeartbeat: now.Add(heartbeatInitial),
+		lastProgress:  now,
+	}
+}
+
+// check updates the watch with the latest progress counter and reports
+// whether a heartbeat is due and whether the spawn just became stuck
+func (w *spawnWatch) check(now time.Time, progress int) (heartbeat, stuck bool) {
+	if progress != w.progress {
+		w.progress = progress
+		w.lastProgress = now
+		w.stuckReported = false
+	} else if !w.stuckReported && now.Sub(w.lastProgress) >= stuckThreshold {
+		w.stuckReported = true
+		stuck = true
+	}
+
+	if !now.Before(w.nextHeartbeat) {
+		heartbeat = true
+		w.interval *= 2
+		if w.interval > heartbeatMax {
+			w.interval = heartbeatMax
+		}
+		w.nextHeartbeat = now.Add(w.interval)
+	}
+
+	return heartbeat, stuck
+}
+
+// trackSpawn starts watching a spawned process
+func (pt *PersonaTools) trackSpawn(proc *vega.Process, agentName string) {
+	pt.spawnWatchesMu.Lock()
+	pt.spawnWatches[proc.ID] = newSpawnWatch(proc, agentName, time.Now())
+	pt.spawnWatchesMu.Unlock()
+
+	pt.spawnMonitorOnce.Do(func() {
+		go pt.runSpawnMonitor()
+	})
+}
+
+// untrackSpawn stops watching a process
+func (pt *PersonaTools) untrackSpawn(processID string) {
+	pt.spawnWatchesMu.Lock()
+	delete(pt.spawnWatches, processID)
+	pt.spawnWatchesMu.Unlock()
+}
+
+// runSpawnMonitor checks every tracked spawn on a single shared ticker
+// until Drain stops it
+func (pt *PersonaTools) runSpawnMonitor() {
+	ticker := time.NewTicker(spawnMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pt.spawnMonitorStop:
+			return
+		case now := <-ticker.C:
+			pt.checkSpawns(now)
+		}
+	}
+}
+
+// spawnUpdate is a progress message for the channel a spawn came from
+type spawnUpdate struct {
+	processID string
+	msg       string
+}
+
+// checkSpawns emits heartbeats and stuck warnings for running spawns. The
+// messages are sent after the watches are unlocked, so a slow Slack post
+// doesn't hold up spawns starting and finishing.
+func (pt *PersonaTools) checkSpawns(now time.Time) {
+	for _, u := range pt.dueSpawnUpdates(now) {
+		pt.sendToProcessChannel(u.processID, u.msg)
+	}
+}
+
+// dueSpawnUpdates advances every watch and returns the messages now due
+func (pt *PersonaTools) dueSpawnUpdates(now time.Time) []spawnUpdate {
+	pt.spawnWatchesMu.Lock()
+	defer pt.spawnWatchesMu.Unlock()
+
+	var updates []spawnUpdate
+	for id, w := range pt.spawnWatches {
+		if status := w.proc.Status(); status != vega.StatusRunning && status != vega.StatusPending {
+			delete(pt.spawnWatches, id)
+			continue
+		}
+
+		m := w.proc.Metrics()
+		heartbeat, stuck := w.check(now, m.Iterations+m.ToolCalls+m.OutputTokens)
+		elapsed := now.Sub(w.startedAt).Round(time.Minute)
+
+		if stuck {
+			pt.logger.Warnf("Spawn %s (%s) has made no progress for %s", id, w.agentName, stuckThreshold)
+			updates = append(updates, spawnUpdate{id, fmt.Sprintf(":warning: *%s* appears stuck: no progress in the last %s (%s elapsed)",
+				w.agentName, stuckThreshold, elapsed)})
+		} else if heartbeat {
+			updates = append(updates, spawnUpdate{id, fmt.Sprintf("*%s* is still working, %s elapsed", w.agentName, elapsed)})
+		}
+	}
+	return updates
+}
+
+// sendToProcessChannel posts a message to the channel a process was spawned from
+func (pt *PersonaTools) sendToProcessChannel(processID, msg string) {
+	pt.processChannelsMu.RLock()
+	ch, ok := pt.processChannels[processID]
+	pt.processChannelsMu.RUnlock()
+
+	if !ok || ch.Type != notification.ChannelSlack || pt.slackClient == nil {
+		return
+	}
+	if err := pt.slackClient.SendMessage(ch.ChannelID, msg); err != nil {
+		pt.logger.Errorf("Failed to send progress update: %v", err)
+	}
+}
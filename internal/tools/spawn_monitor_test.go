@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestDrainStopsSpawnMonitor(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	stopped := make(chan struct{})
+	go func() {
+		pt.runSpawnMonitor()
+		close(stopped)
+	}()
+
+	pt.Drain(context.Background())
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("spawn monitor still running after Drain")
+	}
+
+	// A second Drain doesn't close the stop channel again
+	pt.Drain(context.Background())
+}
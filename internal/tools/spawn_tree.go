@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/tron/internal/textutil"
+)
+
+// maxSpawnTreeTask caps task text in the human-readable tree summary
+const maxSpawnTreeTask = 80
+
+// SpawnNode is a process in the spawn tree with the processes it spawned
+type SpawnNode struct {
+	ProcessID string       `json:"process_id"`
+	Agent     string       `json:"agent"`
+	Task      string       `json:"task"`
+	Status    string       `json:"status"`
+	StartedAt time.Time    `json:"started_at"`
+	Children  []*SpawnNode `json:"children,omitempty"`
+}
+
+// SpawnTree returns the live spawn tree rooted at rootID, or every root
+// process if rootID is empty. It returns nil if rootID is not found.
+func (pt *PersonaTools) SpawnTree(rootID string) []*SpawnNode {
+	return buildSpawnTree(pt.orch.GetSpawnTree(), rootID)
+}
+
+// buildSpawnTree converts orchestrator tree nodes, optionally re-rooted at
+// rootID. A process seen twice is not descended into again, so a malformed
+// tree with a cycle still terminates.
+func buildSpawnTree(roots []*vega.SpawnTreeNode, rootID string) []*SpawnNode {
+	if rootID != "" {
+		root := findSpawnTreeNode(roots, rootID, make(map[string]bool))
+		if root == nil {
+			return nil
+		}
+		roots = []*vega.SpawnTreeNode{root}
+	}
+
+	seen := make(map[string]bool)
+	nodes := make([]*SpawnNode, 0, len(roots))
+	for _, r := range roots {
+		if node := convertSpawnTreeNode(r, seen); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// findSpawnTreeNode searches the tree depth-first for a process
+func findSpawnTreeNode(nodes []*vega.SpawnTreeNode, id string, seen map[string]bool) *vega.SpawnTreeNode {
+	for _, n := range nodes {
+		if n == nil || seen[n.ProcessID] {
+			continue
+		}
+		seen[n.ProcessID] = true
+		if n.ProcessID == id {
+			return n
+		}
+		if found := findSpawnTreeNode(n.Children, id, seen); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// convertSpawnTreeNode copies a node and its descendants, skipping any
+// process already in seen
+func convertSpawnTreeNode(n *vega.SpawnTreeNode, seen map[string]bool) *SpawnNode {
+	if n == nil || seen[n.ProcessID] {
+		return nil
+	}
+	seen[n.ProcessID] = true
+
+	node := &SpawnNode{
+		ProcessID: n.ProcessID,
+		Agent:     n.AgentName,
+		Task:      n.Task,
+		Status:    string(n.Status),
+		StartedAt: n.StartedAt,
+	}
+	for _, child := range n.Children {
+		if c := convertSpawnTreeNode(child, seen); c != nil {
+			node.Children = append(node.Children, c)
+		}
+	}
+	return node
+}
+
+// formatSpawnTree renders the tree as an indented list
+func formatSpawnTree(nodes []*SpawnNode) string {
+	var sb strings.Builder
+	var walk func(n *SpawnNode, depth int)
+	walk = func(n *SpawnNode, depth int) {
+		task := n.Task
+		if len(task) > maxSpawnTreeTask {
+			task = textutil.Truncate(task, maxSpawnTreeTask) + "..."
+		}
+		sb.WriteString(fmt.Sprintf("%s- %s [%s] %s: %s\n",
+			strings.Repeat("  ", depth), n.Agent, n.Status, n.ProcessID, task))
+		for _, c := range n.Children {
+			walk(c, depth+1)
+		}
+	}
+	for _, n := range nodes {
+		walk(n, 0)
+	}
+	return sb.String()
+}
+
+// countSpawnNodes returns the number of processes in the tree
+func countSpawnNodes(nodes []*SpawnNode) int {
+	total := 0
+	for _, n := range nodes {
+		total += 1 + countSpawnNodes(n.Children)
+	}
+	return total
+}
+
+// getSpawnTree is the get_spawn_tree tool
+func (pt *PersonaTools) getSpawnTree(ctx context.Context, params map[string]any) (string, error) {
+	rootID, _ := params["process_id"].(string)
+	rootID, err := processIDParam(ctx, rootID)
+	if err != nil {
+		return "", err
+	}
+
+	tree := pt.SpawnTree(rootID)
+	if rootID != "" && tree == nil {
+		return "", fmt.Errorf("process not found: %s", rootID)
+	}
+	if len(tree) == 0 {
+		return "No agent processes are running.", nil
+	}
+
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode spawn tree: %w", err)
+	}
+
+	return fmt.Sprintf("Spawn tree (%d processes):\n%s\n```json\n%s\n```",
+		countSpawnNodes(tree), formatSpawnTree(tree), data), nil
+}
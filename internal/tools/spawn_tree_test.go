@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestBuildSpawnTree(t *testing.T) {
+	leaf := &vega.SpawnTreeNode{ProcessID: "p3", AgentName: "Derek", Task: "write tests", Status: vega.StatusRunning}
+	child := &vega.SpawnTreeNode{ProcessID: "p2", AgentName: "Gary", Task: "build api", Status: vega.StatusRunning,
+		Children: []*vega.SpawnTreeNode{leaf}}
+	root := &vega.SpawnTreeNode{ProcessID: "p1", AgentName: "Tony", Task: "ship it", Status: vega.StatusRunning,
+		Children: []*vega.SpawnTreeNode{child}}
+	other := &vega.SpawnTreeNode{ProcessID: "p4", AgentName: "Maya", Task: "research", Status: vega.StatusRunning}
+	roots := []*vega.SpawnTreeNode{root, other}
+
+	all := buildSpawnTree(roots, "")
+	if len(all) != 2 || countSpawnNodes(all) != 4 {
+		t.Fatalf("full tree = %d roots, %d nodes; want 2 roots, 4 nodes", len(all), countSpawnNodes(all))
+	}
+
+	sub := buildSpawnTree(roots, "p2")
+	if len(sub) != 1 || sub[0].Agent != "Gary" || len(sub[0].Children) != 1 || sub[0].Children[0].Agent != "Derek" {
+		t.Errorf("tree rooted at p2 = %+v, want Gary -> Derek", sub)
+	}
+
+	if buildSpawnTree(roots, "missing") != nil {
+		t.Error("unknown root should return nil")
+	}
+
+	summary := formatSpawnTree(sub)
+	if !strings.Contains(summary, "- Gary [running] p2: build api\n  - Derek") {
+		t.Errorf("summary not indented by depth:\n%s", summary)
+	}
+}
+
+func TestBuildSpawnTreeSurvivesCycles(t *testing.T) {
+	a := &vega.SpawnTreeNode{ProcessID: "a", AgentName: "Tony"}
+	b := &vega.SpawnTreeNode{ProcessID: "b", AgentName: "Gary"}
+	a.Children = []*vega.SpawnTreeNode{b}
+	b.Children = []*vega.SpawnTreeNode{a}
+
+	tree := buildSpawnTree([]*vega.SpawnTreeNode{a}, "")
+	if n := countSpawnNodes(tree); n != 2 {
+		t.Errorf("cyclic tree has %d nodes, want 2", n)
+	}
+	if buildSpawnTree([]*vega.SpawnTreeNode{a}, "missing") != nil {
+		t.Error("search through a cycle should terminate and find nothing")
+	}
+}
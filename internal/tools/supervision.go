@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/govega/dsl"
+)
+
+// DefaultSupervision applies to agents whose config has no supervision block
+var DefaultSupervision = vega.Supervision{
+	Strategy:    vega.Restart,
+	MaxRestarts: 3,
+	Window:      10 * time.Minute,
+}
+
+// knownStrategies are the supervision strategy names accepted in config
+var knownStrategies = map[string]bool{"": true, "restart": true, "stop": true, "escalate": true, "restartall": true}
+
+// SetDefaultSupervision sets the supervision used for agents without their
+// own, validated like agent config. An empty window keeps the built-in one.
+func (pt *PersonaTools) SetDefaultSupervision(def dsl.SupervisionDef) error {
+	s, err := buildSupervision(&def, DefaultSupervision.Window)
+	if err != nil {
+		return fmt.Errorf("invalid default supervision: %w", err)
+	}
+	pt.defaultSupervision = s
+	return nil
+}
+
+// supervisionFor converts an agent's supervision config, using the default
+// when it has none. A missing window inherits the default's window.
+func (pt *PersonaTools) supervisionFor(def *dsl.SupervisionDef) (vega.Supervision, error) {
+	if def == nil {
+		return pt.defaultSupervision, nil
+	}
+	return buildSupervision(def, pt.defaultSupervision.Window)
+}
+
+// buildSupervision validates and converts a supervision config. An empty
+// window uses defaultWindow; an explicit zero window with restarts is
+// rejected, since the restart limit would never reset.
+func buildSupervision(def *dsl.SupervisionDef, defaultWindow time.Duration) (vega.Supervision, error) {
+	if !knownStrategies[strings.ToLower(def.Strategy)] {
+		return vega.Supervision{}, fmt.Errorf("unknown strategy %q (want restart, stop, escalate, or restartall)", def.Strategy)
+	}
+	if def.MaxRestarts < 0 {
+		return vega.Supervision{}, fmt.Errorf("max_restarts must not be negative, got %d", def.MaxRestarts)
+	}
+
+	window, err := parseWindow(def.Window)
+	if err != nil {
+		return vega.Supervision{}, err
+	}
+	if strings.TrimSpace(def.Window) == "" {
+		window = defaultWindow
+	}
+	if def.MaxRestarts > 0 && window <= 0 {
+		return vega.Supervision{}, fmt.Errorf("max_restarts %d needs a non-zero window", def.MaxRestarts)
+	}
+
+	return vega.Supervision{
+		Strategy:    parseStrategy(def.Strategy),
+		MaxRestarts: def.MaxRestarts,
+		Window:      window,
+	}, nil
+}
+
+// ValidateSupervision checks every agent's supervision config, so mistakes
+// like a "10min" window fail at load time instead of silently disabling the
+// restart window
+func ValidateSupervision(config *dsl.Document) error {
+	if config == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(config.Agents))
+	for name := range config.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		agent := config.Agents[name]
+		if agent == nil || agent.Supervision == nil {
+			continue
+		}
+		if _, err := buildSupervision(agent.Supervision, DefaultSupervision.Window); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid supervision config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+	"github.com/everydev1618/govega/dsl"
+)
+
+func TestValidateSupervisionRejectsMalformedWindow(t *testing.T) {
+	config := &dsl.Document{Agents: map[string]*dsl.Agent{
+		"Gary":   {Name: "Gary", Supervision: &dsl.SupervisionDef{Strategy: "restart", MaxRestarts: 3, Window: "10min"}},
+		"Sarah":  {Name: "Sarah", Supervision: &dsl.SupervisionDef{Strategy: "restart", MaxRestarts: 3, Window: "0s"}},
+		"Derek":  {Name: "Derek", Supervision: &dsl.SupervisionDef{Strategy: "restrat", MaxRestarts: 1, Window: "5m"}},
+		"Claire": {Name: "Claire", Supervision: &dsl.SupervisionDef{Strategy: "restart", MaxRestarts: 2}},
+		"Tony":   {Name: "Tony"},
+	}}
+
+	err := ValidateSupervision(config)
+	if err == nil {
+		t.Fatal("expected malformed supervision to fail validation")
+	}
+	for _, name := range []string{"Gary", "Sarah", "Derek"} {
+		if !strings.Contains(err.Error(), name+":") {
+			t.Errorf("error should name %s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"Claire", "Tony"} {
+		if strings.Contains(err.Error(), name+":") {
+			t.Errorf("%s's config is valid but was reported: %v", name, err)
+		}
+	}
+}
+
+func TestSupervisionForInheritsDefaultWindow(t *testing.T) {
+	pt := &PersonaTools{defaultSupervision: DefaultSupervision}
+
+	s, err := pt.supervisionFor(&dsl.SupervisionDef{Strategy: "stop", MaxRestarts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Strategy != vega.Stop || s.MaxRestarts != 2 || s.Window != DefaultSupervision.Window {
+		t.Errorf("supervisionFor() = %+v, want stop/2 with the default window", s)
+	}
+
+	if err := pt.SetDefaultSupervision(dsl.SupervisionDef{Strategy: "restart", MaxRestarts: 5, Window: "30m"}); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := pt.supervisionFor(nil); s.MaxRestarts != 5 || s.Window != 30*time.Minute {
+		t.Errorf("default after SetDefaultSupervision = %+v", s)
+	}
+
+	if err := pt.SetDefaultSupervision(dsl.SupervisionDef{MaxRestarts: 3, Window: "10min"}); err == nil {
+		t.Error("expected an error for a malformed default window")
+	}
+}
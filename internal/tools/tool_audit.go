@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/everydev1618/govega"
+)
+
+// maxAuditParamLength bounds each param value in an ops report
+const maxAuditParamLength = 120
+
+// sensitiveParamHints marks params whose values are never posted to Slack
+var sensitiveParamHints = []string{"key", "token", "secret", "password", "pass", "auth"}
+
+// SetOpsChannel enables posting raw tool errors to a Slack channel.
+// An empty channel disables reporting.
+func (pt *PersonaTools) SetOpsChannel(channel string) {
+	pt.opsChannel = channel
+}
+
+// register adds a tool whose function is wrapped with the audit hook
+func (pt *PersonaTools) register(tools *vega.Tools, name string, fn func(context.Context, map[string]any) (string, error), def vega.ToolDef) {
+	def.Fn = pt.auditTool(name, fn)
+	tools.Register(name, def)
+}
+
+// auditTool wraps a tool function so failures are logged and reported
+// centrally, and so heavy tools respect their concurrency limits
+func (pt *PersonaTools) auditTool(name string, fn func(context.Context, map[string]any) (string, error)) func(context.Context, map[string]any) (string, error) {
+	return func(ctx context.Context, params map[string]any) (string, error) {
+		pt.inflight.Add(1)
+		defer pt.inflight.Add(-1)
+
+		release, err := pt.acquireToolSlot(ctx, name)
+		if err != nil {
+			pt.reportToolError(ctx, name, params, err)
+			return "", err
+		}
+		defer release()
+
+		result, err := fn(ctx, params)
+		if err != nil {
+			pt.reportToolError(ctx, name, params, err)
+		}
+		return result, err
+	}
+}
+
+// callerFromContext returns the name of the agent making a tool call
+// ("unknown" outside a process) and its process ID
+func callerFromContext(ctx context.Context) (caller, processID string) {
+	caller = "unknown"
+	if proc := vega.ProcessFromContext(ctx); proc != nil {
+		processID = proc.ID
+		if proc.Agent != nil {
+			caller = proc.Agent.Name
+		}
+	}
+	return caller, processID
+}
+
+// reportToolError logs a failed tool call, records it if a recorder is set,
+// and, if configured, posts it to the ops channel
+func (pt *PersonaTools) reportToolError(ctx context.Context, name string, params map[string]any, err error) {
+	caller, processID := callerFromContext(ctx)
+
+	category := CategorizeToolError(err)
+	pt.logger.Warnf("Tool %s failed for %s (%s): %v", name, caller, category, err)
+
+	if pt.recordToolError != nil {
+		pt.recordToolError(ToolError{
+			Tool:      name,
+			Agent:     caller,
+			ProcessID: processID,
+			Category:  category,
+			Err:       err,
+		})
+	}
+
+	if pt.opsChannel == "" || pt.slackClient == nil {
+		return
+	}
+
+	msg := fmt.Sprintf(":warning: Tool `%s` failed for *%s*\n*Error:* %s", name, caller, err)
+	if summary := summarizeParams(params); summary != "" {
+		msg += "\n*Params:* " + summary
+	}
+
+	if postErr := pt.slackClient.SendMessage(pt.opsChannel, msg); postErr != nil {
+		pt.logger.Errorf("Failed to post tool error to ops channel: %v", postErr)
+	}
+}
+
+// summarizeParams renders params as key=value pairs with secrets redacted
+func summarizeParams(params map[string]any) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value := fmt.Sprintf("%v", params[k])
+		if isSensitiveParam(k) {
+			value = "[redacted]"
+		} else {
+			value = summarizeResult(value, maxAuditParamLength)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", k, value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isSensitiveParam reports whether a param name looks like it holds a credential
+func isSensitiveParam(name string) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range sensitiveParamHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
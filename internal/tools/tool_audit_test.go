@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+// recordingSlack captures messages sent through SlackPoster
+type recordingSlack struct {
+	channel string
+	text    string
+}
+
+func (r *recordingSlack) SendMessage(channel, text string) error {
+	r.channel = channel
+	r.text = text
+	return nil
+}
+
+func TestSummarizeParamsRedactsSecrets(t *testing.T) {
+	got := summarizeParams(map[string]any{
+		"command": "ls -la",
+		"api_key": "sk-live-123",
+		"token":   "xoxb-abc",
+	})
+
+	if strings.Contains(got, "sk-live-123") || strings.Contains(got, "xoxb-abc") {
+		t.Errorf("summarizeParams() leaked a secret: %s", got)
+	}
+	if !strings.Contains(got, `command="ls -la"`) {
+		t.Errorf("summarizeParams() = %s, want command included", got)
+	}
+}
+
+func TestAuditToolReportsToOpsChannel(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+	slack := &recordingSlack{}
+	pt.SetSlackClient(slack)
+
+	failing := pt.auditTool("execute", func(ctx context.Context, params map[string]any) (string, error) {
+		return "", errors.New("blocked command")
+	})
+
+	// Reporting is disabled until an ops channel is set
+	failing(context.Background(), map[string]any{"command": "sudo ls"})
+	if slack.text != "" {
+		t.Fatalf("reported without an ops channel: %q", slack.text)
+	}
+
+	pt.SetOpsChannel("C-OPS")
+	if _, err := failing(context.Background(), map[string]any{"command": "sudo ls"}); err == nil {
+		t.Fatal("expected wrapped tool to return its error")
+	}
+	if slack.channel != "C-OPS" {
+		t.Errorf("posted to %q, want C-OPS", slack.channel)
+	}
+	if !strings.Contains(slack.text, "execute") || !strings.Contains(slack.text, "blocked command") {
+		t.Errorf("ops message = %q, want tool name and error", slack.text)
+	}
+}
+
+func TestDrainWaitsForInFlightToolCalls(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slow := pt.auditTool("execute", func(ctx context.Context, params map[string]any) (string, error) {
+		close(started)
+		<-release
+		return "ok", nil
+	})
+	go slow(context.Background(), nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if n := pt.Drain(ctx); n != 1 {
+		t.Fatalf("Drain() with a blocked call = %d, want 1", n)
+	}
+
+	if _, err := pt.spawnAgent(context.Background(), map[string]any{"agent": "Tony", "task": "x"}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("spawnAgent() while draining = %v, want ErrShuttingDown", err)
+	}
+
+	close(release)
+	if n := pt.Drain(context.Background()); n != 0 {
+		t.Errorf("Drain() after release = %d, want 0", n)
+	}
+}
+
+func TestAwaitSpawnsWaitsForRunningAgents(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+	pt.spawned.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if n := pt.AwaitSpawns(ctx); n != 1 {
+		t.Fatalf("AwaitSpawns() with a running agent = %d, want 1", n)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		pt.spawned.Add(-1)
+	}()
+	if n := pt.AwaitSpawns(context.Background()); n != 0 {
+		t.Errorf("AwaitSpawns() after the agent finished = %d, want 0", n)
+	}
+}
+
+func TestCategorizeToolError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.New(`blocked command: contains dangerous pattern "sudo"`), ToolErrorBlocked},
+		{ErrShuttingDown, ToolErrorBlocked},
+		{context.DeadlineExceeded, ToolErrorTimeout},
+		{errors.New("search API returned status 502"), ToolErrorAPI},
+		{errors.New("unknown team member: Bob"), ToolErrorNotFound},
+		{errors.New("name is required for a new caller"), ToolErrorInvalid},
+		{errors.New("something odd"), ToolErrorOther},
+	}
+	for _, tt := range tests {
+		if got := CategorizeToolError(tt.err); got != tt.want {
+			t.Errorf("CategorizeToolError(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestAuditToolRecordsFailures(t *testing.T) {
+	llm := &mockLLM{}
+	orch := vega.NewOrchestrator(vega.WithLLM(llm))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+
+	var recorded []ToolError
+	pt.SetToolErrorRecorder(func(te ToolError) { recorded = append(recorded, te) })
+
+	failing := pt.auditTool("execute", func(ctx context.Context, params map[string]any) (string, error) {
+		return "", errors.New("blocked command: contains dangerous pattern \"sudo\"")
+	})
+	ok := pt.auditTool("read_file", func(ctx context.Context, params map[string]any) (string, error) {
+		return "ok", nil
+	})
+	failing(context.Background(), nil)
+	ok(context.Background(), nil)
+
+	if len(recorded) != 1 {
+		t.Fatalf("recorded %d tool errors, want 1", len(recorded))
+	}
+	if recorded[0].Tool != "execute" || recorded[0].Category != ToolErrorBlocked {
+		t.Errorf("recorded %+v, want execute/%s", recorded[0], ToolErrorBlocked)
+	}
+}
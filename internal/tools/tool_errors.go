@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+)
+
+// Tool error categories, for grouping failures in history
+const (
+	ToolErrorBlocked  = "blocked" // Refused by a safety check or permission
+	ToolErrorTimeout  = "timeout" // Deadline exceeded or cancelled
+	ToolErrorAPI      = "api"     // An external API or network call failed
+	ToolErrorNotFound = "not_found"
+	ToolErrorInvalid  = "invalid_input"
+	ToolErrorOther    = "other"
+)
+
+// ToolError describes a failed tool call
+type ToolError struct {
+	Tool      string
+	Agent     string
+	ProcessID string
+	Category  string
+	Err       error
+}
+
+// SetToolErrorRecorder sets a function called for every failed tool call,
+// e.g. to record it in history. A nil recorder disables recording.
+func (pt *PersonaTools) SetToolErrorRecorder(record func(ToolError)) {
+	pt.recordToolError = record
+}
+
+// CategorizeToolError classifies a tool error by its cause
+func CategorizeToolError(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled), isTimeout(err):
+		return ToolErrorTimeout
+	case errors.Is(err, ErrShuttingDown), errors.Is(err, ErrSpawnLimit), errors.Is(err, ErrToolBusy):
+		return ToolErrorBlocked
+	case errors.Is(err, os.ErrNotExist):
+		return ToolErrorNotFound
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ToolErrorAPI
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case containsAny(msg, "blocked", "not allowed", "not permitted", "denied", "forbidden"):
+		return ToolErrorBlocked
+	case containsAny(msg, "timed out", "timeout"):
+		return ToolErrorTimeout
+	case containsAny(msg, "api returned", "status code", "returned status", "api error", "request failed", "not configured"):
+		return ToolErrorAPI
+	case containsAny(msg, "not found", "unknown", "no such"):
+		return ToolErrorNotFound
+	case containsAny(msg, "required", "invalid", "must be"):
+		return ToolErrorInvalid
+	}
+	return ToolErrorOther
+}
+
+// isTimeout reports whether err is a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrToolBusy is returned when a rate-limited tool has no free slot within
+// the queue timeout
+var ErrToolBusy = errors.New("system busy")
+
+// DefaultToolConcurrency caps resource-heavy tools. Tools not listed are
+// unlimited.
+var DefaultToolConcurrency = map[string]int{
+	"execute":        4,
+	"start_server":   2,
+	"create_project": 2,
+	"export_project": 2,
+}
+
+// DefaultToolQueueTimeout bounds how long a call waits for a tool slot
+const DefaultToolQueueTimeout = time.Minute
+
+// ParseToolConcurrency parses limits like "execute=4,start_server=2"
+func ParseToolConcurrency(s string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, part := range splitList(s) {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid tool limit %q, use tool=N", part)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tool limit %q: %w", part, err)
+		}
+		limits[name] = n
+	}
+	return limits, nil
+}
+
+// SetToolConcurrency overrides per-tool concurrency limits on top of the
+// defaults. A limit of 0 or less makes that tool unlimited.
+func (pt *PersonaTools) SetToolConcurrency(limits map[string]int) {
+	merged := make(map[string]int, len(DefaultToolConcurrency)+len(limits))
+	for name, n := range DefaultToolConcurrency {
+		merged[name] = n
+	}
+	for name, n := range limits {
+		merged[name] = n
+	}
+
+	slots := make(map[string]chan struct{}, len(merged))
+	for name, n := range merged {
+		if n > 0 {
+			slots[name] = make(chan struct{}, n)
+		}
+	}
+
+	pt.toolSlotsMu.Lock()
+	defer pt.toolSlotsMu.Unlock()
+	pt.toolSlots = slots
+}
+
+// SetToolQueueTimeout sets how long a call waits for a busy tool
+func (pt *PersonaTools) SetToolQueueTimeout(d time.Duration) {
+	pt.toolQueueTimeout = d
+}
+
+// acquireToolSlot takes a slot for a limited tool, waiting up to the queue
+// timeout. Unlimited tools return immediately.
+func (pt *PersonaTools) acquireToolSlot(ctx context.Context, name string) (release func(), err error) {
+	pt.toolSlotsMu.RLock()
+	slots := pt.toolSlots[name]
+	pt.toolSlotsMu.RUnlock()
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	timer := time.NewTimer(pt.toolQueueTimeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %d %s calls already running, waited %s; try again shortly",
+			ErrToolBusy, cap(slots), name, pt.toolQueueTimeout)
+	}
+	return func() { <-slots }, nil
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/everydev1618/govega"
+)
+
+func TestParseToolConcurrency(t *testing.T) {
+	limits, err := ParseToolConcurrency("execute=1, start_server = 3,web_search=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits["execute"] != 1 || limits["start_server"] != 3 || limits["web_search"] != 0 {
+		t.Errorf("limits = %v", limits)
+	}
+
+	for _, bad := range []string{"execute", "execute=many", "=2"} {
+		if _, err := ParseToolConcurrency(bad); err == nil {
+			t.Errorf("ParseToolConcurrency(%q) should fail", bad)
+		}
+	}
+}
+
+func TestToolConcurrencyLimit(t *testing.T) {
+	orch := vega.NewOrchestrator(vega.WithLLM(&mockLLM{}))
+	defer orch.Shutdown(context.Background())
+
+	pt := NewPersonaTools(orch, createTestConfig(), "./work", ".", nil)
+	pt.SetToolConcurrency(map[string]int{"execute": 1})
+	pt.SetToolQueueTimeout(50 * time.Millisecond)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	calls := 0
+	tool := func(name string) func(context.Context, map[string]any) (string, error) {
+		return pt.auditTool(name, func(ctx context.Context, params map[string]any) (string, error) {
+			if params["hold"] == true {
+				close(started)
+				<-release
+			}
+			calls++
+			return "ok", nil
+		})
+	}
+	execute := tool("execute")
+
+	go func() {
+		execute(context.Background(), map[string]any{"hold": true})
+		close(done)
+	}()
+	<-started
+
+	if _, err := execute(context.Background(), nil); !errors.Is(err, ErrToolBusy) {
+		t.Fatalf("second execute = %v, want ErrToolBusy", err)
+	}
+	if CategorizeToolError(ErrToolBusy) != ToolErrorBlocked {
+		t.Error("busy tools should be categorized as blocked")
+	}
+
+	// Unlimited tools don't wait
+	if _, err := tool("query_knowledge")(context.Background(), nil); err != nil {
+		t.Errorf("unlimited tool = %v", err)
+	}
+
+	close(release)
+	<-done
+	if _, err := execute(context.Background(), nil); err != nil {
+		t.Errorf("execute after release = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
@@ -7,7 +7,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/everydev1618/tron/internal/httpclient"
+	"github.com/everydev1618/tron/internal/logging"
+	"github.com/everydev1618/tron/internal/notification"
+	"github.com/everydev1618/tron/internal/textutil"
 )
 
 const (
@@ -20,7 +26,10 @@ type Client struct {
 	apiKey      string
 	phoneID     string
 	assistantID string
+	assistants  map[string]string // Lowercase persona name -> assistant ID
 	httpClient  *http.Client
+	templates   *notification.Templates
+	logger      logging.Logger
 }
 
 // NewClient creates a new VAPI client
@@ -29,23 +38,71 @@ func NewClient(apiKey, phoneID, assistantID string) *Client {
 		apiKey:      apiKey,
 		phoneID:     phoneID,
 		assistantID: assistantID,
-		httpClient: &http.Client{
-			Timeout: apiTimeout,
-		},
+		httpClient:  httpclient.New(apiTimeout),
+		logger:      logging.New("vapi"),
+	}
+}
+
+// SetTemplates sets the templates used for the call's first message
+func (c *Client) SetTemplates(t *notification.Templates) {
+	c.templates = t
+}
+
+// SetPersonaAssistants maps persona names to VAPI assistant IDs, so a
+// callback about a persona's work is made in that persona's voice. Names
+// match case-insensitively; personas without an entry use the default
+// assistant.
+func (c *Client) SetPersonaAssistants(assistants map[string]string) {
+	c.assistants = make(map[string]string, len(assistants))
+	for persona, id := range assistants {
+		c.assistants[strings.ToLower(persona)] = id
 	}
 }
 
+// AssistantFor returns the assistant ID to use for a persona's calls
+func (c *Client) AssistantFor(persona string) string {
+	if id := c.assistants[strings.ToLower(persona)]; id != "" {
+		return id
+	}
+	return c.assistantID
+}
+
 // IsConfigured returns true if the client has required credentials
 func (c *Client) IsConfigured() bool {
-	return c.apiKey != "" && c.phoneID != "" && c.assistantID != ""
+	return c.apiKey != "" && c.phoneID != "" && (c.assistantID != "" || len(c.assistants) > 0)
+}
+
+// ParsePersonaAssistants parses "Maya:asst_123,Gary:asst_456" into a map of
+// persona name to assistant ID
+func ParsePersonaAssistants(s string) (map[string]string, error) {
+	assistants := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		persona, id, ok := strings.Cut(pair, ":")
+		persona, id = strings.TrimSpace(persona), strings.TrimSpace(id)
+		if !ok || persona == "" || id == "" {
+			return nil, fmt.Errorf("invalid persona assistant %q, want Persona:assistantID", pair)
+		}
+		assistants[persona] = id
+	}
+	return assistants, nil
 }
 
 // CallbackContext provides context for callback calls
 type CallbackContext struct {
+	PersonaName string // Who is calling; defaults to notification.DefaultPersona
+	AssistantID string // Overrides the persona's assistant for this call
 	AgentName   string
 	TaskSummary string
 	Result      string
 	ProjectName string
+	Stats       string // One-line duration/cost summary, if known
+
+	// Variables are extra assistant variableValues, e.g. a batch's rollup
+	Variables map[string]string
 }
 
 // CallRequest is the request body for initiating a call
@@ -81,9 +138,20 @@ func (c *Client) Call(ctx context.Context, customerPhone, customerName string, c
 		return nil, fmt.Errorf("VAPI client not configured")
 	}
 
+	var persona, assistantID string
+	if callbackCtx != nil {
+		persona, assistantID = callbackCtx.PersonaName, callbackCtx.AssistantID
+	}
+	if assistantID == "" {
+		assistantID = c.AssistantFor(persona)
+	}
+	if assistantID == "" {
+		return nil, fmt.Errorf("no VAPI assistant for persona %q and no default assistant", persona)
+	}
+
 	req := CallRequest{
 		PhoneNumberID: c.phoneID,
-		AssistantID:   c.assistantID,
+		AssistantID:   assistantID,
 		Customer: Customer{
 			Number: customerPhone,
 			Name:   customerName,
@@ -95,11 +163,15 @@ func (c *Client) Call(ctx context.Context, customerPhone, customerName string, c
 		req.AssistantOverrides = &AssistantOverrides{
 			VariableValues: map[string]string{
 				"agentName":   callbackCtx.AgentName,
-				"taskSummary": summarize(callbackCtx.TaskSummary, 100),
-				"result":      summarize(callbackCtx.Result, 200),
+				"taskSummary": textutil.Shorten(callbackCtx.TaskSummary, 100),
+				"result":      textutil.Shorten(callbackCtx.Result, 200),
 				"projectName": callbackCtx.ProjectName,
+				"stats":       callbackCtx.Stats,
 			},
-			FirstMessage: buildFirstMessage(callbackCtx),
+			FirstMessage: c.buildFirstMessage(callbackCtx),
+		}
+		for k, v := range callbackCtx.Variables {
+			req.AssistantOverrides.VariableValues[k] = v
 		}
 	}
 
@@ -143,17 +215,25 @@ func (c *Client) createCall(ctx context.Context, callReq CallRequest) (*CallResp
 	return &callResp, nil
 }
 
-func buildFirstMessage(ctx *CallbackContext) string {
+func (c *Client) buildFirstMessage(ctx *CallbackContext) string {
 	if ctx == nil {
 		return ""
 	}
-	return fmt.Sprintf("Hey, this is Tony. I'm calling to let you know that %s has finished working on %s.",
-		ctx.AgentName, summarize(ctx.TaskSummary, 50))
-}
-
-func summarize(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+	persona := ctx.PersonaName
+	if persona == "" {
+		persona = notification.DefaultPersona
+	}
+	msg, err := c.templates.Render(notification.TemplateVoiceFirstMessage, notification.CompletionData{
+		Persona:   persona,
+		AgentName: ctx.AgentName,
+		Task:      ctx.TaskSummary,
+		Project:   ctx.ProjectName,
+		Success:   true,
+		Result:    ctx.Result,
+		Stats:     ctx.Stats,
+	})
+	if err != nil {
+		c.logger.Warnf("%v", err)
 	}
-	return s[:maxLen-3] + "..."
+	return strings.TrimSpace(msg)
 }
@@ -0,0 +1,100 @@
+package vapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePersonaAssistants(t *testing.T) {
+	got, err := ParsePersonaAssistants(" Maya:asst_1, Gary : asst_2 ,")
+	if err != nil || len(got) != 2 || got["Maya"] != "asst_1" || got["Gary"] != "asst_2" {
+		t.Errorf("ParsePersonaAssistants = %v, %v", got, err)
+	}
+	for _, bad := range []string{"Maya", "Maya:", ":asst_1"} {
+		if _, err := ParsePersonaAssistants(bad); err == nil {
+			t.Errorf("ParsePersonaAssistants(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCallUsesPersonaAssistant(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CallRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = append(got, req.AssistantID)
+		w.Write([]byte(`{"id":"call_1"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", "phone", "asst_default")
+	c.httpClient = srv.Client()
+	c.httpClient.Transport = rewriteTransport{srv.URL}
+	c.SetPersonaAssistants(map[string]string{"Maya": "asst_maya"})
+
+	for _, cc := range []*CallbackContext{
+		{PersonaName: "maya"},
+		{PersonaName: "Gary"},
+		nil,
+		{PersonaName: "Maya", AssistantID: "asst_once"},
+	} {
+		if _, err := c.Call(context.Background(), "+15550100", "Sam", cc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"asst_maya", "asst_default", "asst_default", "asst_once"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("assistants = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCallVariables(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CallRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		got = req.AssistantOverrides.VariableValues
+		w.Write([]byte(`{"id":"call_1"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", "phone", "asst_default")
+	c.httpClient = srv.Client()
+	c.httpClient.Transport = rewriteTransport{srv.URL}
+
+	cc := &CallbackContext{AgentName: "your team", Variables: map[string]string{"rollup": "2 succeeded, 0 failed", "failed": "0"}}
+	if _, err := c.Call(context.Background(), "+15550100", "Sam", cc); err != nil {
+		t.Fatal(err)
+	}
+	if got["agentName"] != "your team" || got["rollup"] != "2 succeeded, 0 failed" || got["failed"] != "0" {
+		t.Errorf("variableValues = %v, want the standard ones plus the extras", got)
+	}
+}
+
+func TestCallWithoutDefaultAssistant(t *testing.T) {
+	c := NewClient("key", "phone", "")
+	if c.IsConfigured() {
+		t.Fatal("no assistants at all should be unconfigured")
+	}
+	c.SetPersonaAssistants(map[string]string{"Maya": "asst_maya"})
+	if !c.IsConfigured() {
+		t.Fatal("a persona mapping alone should be enough")
+	}
+	if _, err := c.Call(context.Background(), "+15550100", "Sam", &CallbackContext{PersonaName: "Gary"}); err == nil {
+		t.Error("expected an error for an unmapped persona with no default")
+	}
+}
+
+// rewriteTransport sends requests meant for the VAPI API to a test server
+type rewriteTransport struct{ url string }
+
+func (t rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.URL.Scheme, r.URL.Host = "http", t.url[len("http://"):]
+	return http.DefaultTransport.RoundTrip(r)
+}
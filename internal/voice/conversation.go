@@ -0,0 +1,34 @@
+package voice
+
+import (
+	"context"
+	"time"
+)
+
+// Turn roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Turn is one message in a conversation
+type Turn struct {
+	Role    string
+	Content string
+	At      time.Time
+}
+
+type turnsKey struct{}
+
+// ContextWithTurns attaches a conversation's prior turns, oldest first
+func ContextWithTurns(ctx context.Context, turns []Turn) context.Context {
+	return context.WithValue(ctx, turnsKey{}, turns)
+}
+
+// TurnsFromContext returns the prior turns attached by ContextWithTurns.
+// It's empty for the first message of a conversation, or when the caller
+// has no history to pass on.
+func TurnsFromContext(ctx context.Context) []Turn {
+	turns, _ := ctx.Value(turnsKey{}).([]Turn)
+	return turns
+}
@@ -0,0 +1,21 @@
+package voice
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTurnsFromContext(t *testing.T) {
+	if turns := TurnsFromContext(context.Background()); len(turns) != 0 {
+		t.Errorf("turns without history = %+v, want none", turns)
+	}
+
+	turns := []Turn{
+		{Role: RoleUser, Content: "hi"},
+		{Role: RoleAssistant, Content: "hello"},
+	}
+	got := TurnsFromContext(ContextWithTurns(context.Background(), turns))
+	if len(got) != 2 || got[0].Content != "hi" || got[1].Role != RoleAssistant {
+		t.Errorf("turns = %+v, want %+v", got, turns)
+	}
+}
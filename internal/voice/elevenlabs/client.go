@@ -2,6 +2,7 @@ package elevenlabs
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/everydev1618/tron/internal/httpclient"
 	"github.com/gorilla/websocket"
 )
 
@@ -20,24 +22,39 @@ const (
 	defaultFormat      = "pcm_16000"
 )
 
+// DefaultInterimInterval is how often interim user transcripts are passed
+// on by default
+const DefaultInterimInterval = 250 * time.Millisecond
+
+// NoConfidence is TranscriptEvent.Confidence when ElevenLabs didn't give one
+const NoConfidence = -1.0
+
 // Client handles ElevenLabs conversational AI
 type Client struct {
-	apiKey     string
-	agentID    string
-	httpClient *http.Client
+	apiKey          string
+	agentID         string
+	httpClient      *http.Client
+	interimInterval time.Duration
 }
 
 // NewClient creates a new ElevenLabs client
 func NewClient(apiKey, agentID string) *Client {
 	return &Client{
-		apiKey:  apiKey,
-		agentID: agentID,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		apiKey:          apiKey,
+		agentID:         agentID,
+		httpClient:      httpclient.New(30 * time.Second),
+		interimInterval: DefaultInterimInterval,
 	}
 }
 
+// SetInterimInterval sets how often sessions pass interim user transcripts
+// to Transcripts(). Interims arriving sooner after the last one are dropped
+// from the channel (Transcript() still has the latest); finals are always
+// sent right away. Zero passes on every interim.
+func (c *Client) SetInterimInterval(d time.Duration) {
+	c.interimInterval = d
+}
+
 // IsConfigured returns true if the client has required credentials
 func (c *Client) IsConfigured() bool {
 	return c.apiKey != "" && c.agentID != ""
@@ -49,6 +66,9 @@ type TranscriptEvent struct {
 	Text      string `json:"text"`
 	IsFinal   bool   `json:"is_final"`
 	Timestamp int64  `json:"timestamp"`
+	// Recognition confidence from 0 to 1, or NoConfidence if ElevenLabs
+	// didn't send one (always the case for agent responses)
+	Confidence float64 `json:"confidence"`
 }
 
 // AgentResponse represents an agent's text response
@@ -67,8 +87,23 @@ type Session struct {
 	transcripts    chan TranscriptEvent
 	audioOut       chan []byte
 	agentResponses chan AgentResponse
+	interruptions  chan struct{}
 	done           chan struct{}
 	closeOnce      sync.Once
+
+	// The read loop closes the event channels when it exits; chanMu and
+	// closed stop Interrupt touching them after that
+	chanMu sync.Mutex
+	closed bool
+
+	// Ordered conversation so far (finals plus the latest user interim)
+	history   []TranscriptEvent
+	historyMu sync.Mutex
+
+	// Interim throttling (see Client.SetInterimInterval); lastInterim is
+	// only touched by the read loop
+	interimInterval time.Duration
+	lastInterim     time.Time
 }
 
 // GetSignedURL gets a signed WebSocket URL for connecting
@@ -122,6 +157,7 @@ func (c *Client) Connect(ctx context.Context) (*Session, error) {
 	// Connect WebSocket
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		Proxy:            httpclient.Proxy,
 	}
 
 	conn, _, err := dialer.DialContext(ctx, signedURL, nil)
@@ -130,11 +166,13 @@ func (c *Client) Connect(ctx context.Context) (*Session, error) {
 	}
 
 	session := &Session{
-		conn:           conn,
-		transcripts:    make(chan TranscriptEvent, 100),
-		audioOut:       make(chan []byte, 100),
-		agentResponses: make(chan AgentResponse, 100),
-		done:           make(chan struct{}),
+		conn:            conn,
+		transcripts:     make(chan TranscriptEvent, 100),
+		audioOut:        make(chan []byte, 100),
+		agentResponses:  make(chan AgentResponse, 100),
+		interruptions:   make(chan struct{}, 1),
+		done:            make(chan struct{}),
+		interimInterval: c.interimInterval,
 	}
 
 	// Start read loop
@@ -169,6 +207,84 @@ func (s *Session) SendText(text string) error {
 	return s.conn.WriteJSON(msg)
 }
 
+// ConversationInit personalizes a conversation before it starts
+type ConversationInit struct {
+	// DynamicVariables fill {{placeholders}} in the agent's prompt and first message
+	DynamicVariables map[string]string
+	// Prompt, if set, replaces the agent's system prompt (the override must be
+	// allowed in the agent's security settings)
+	Prompt string
+	// FirstMessage, if set, replaces the agent's greeting
+	FirstMessage string
+}
+
+// SendInitiation sends conversation initiation data. It must be sent right
+// after connecting, before any audio.
+func (s *Session) SendInitiation(init ConversationInit) error {
+	msg := initiationMessage{
+		Type:             "conversation_initiation_client_data",
+		DynamicVariables: init.DynamicVariables,
+	}
+	if init.Prompt != "" || init.FirstMessage != "" {
+		msg.Override = &conversationOverride{}
+		msg.Override.Agent.FirstMessage = init.FirstMessage
+		if init.Prompt != "" {
+			msg.Override.Agent.Prompt = &promptOverride{Prompt: init.Prompt}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteJSON(msg)
+}
+
+// Interrupt stops the agent mid-utterance (barge-in). It drops any audio that
+// hasn't been forwarded yet, notifies Interruptions() listeners so they can
+// stop local playback, and sends user_activity so ElevenLabs stops speaking.
+func (s *Session) Interrupt() error {
+	if !s.interrupt() {
+		return fmt.Errorf("session closed")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteJSON(baseMessage{Type: "user_activity"})
+}
+
+// interrupt flushes pending audio and signals an interruption. It reports
+// false, doing nothing, once the session has ended.
+func (s *Session) interrupt() bool {
+	s.chanMu.Lock()
+	defer s.chanMu.Unlock()
+	if s.closed {
+		return false
+	}
+
+	s.flushAudio()
+	select {
+	case s.interruptions <- struct{}{}:
+	default:
+		// An interruption is already pending
+	}
+	return true
+}
+
+// flushAudio discards audio chunks that haven't been consumed yet
+func (s *Session) flushAudio() int {
+	flushed := 0
+	for {
+		select {
+		case _, ok := <-s.audioOut:
+			if !ok {
+				return flushed
+			}
+			flushed++
+		default:
+			return flushed
+		}
+	}
+}
+
 // Close closes the session
 func (s *Session) Close() error {
 	s.closeOnce.Do(func() {
@@ -193,6 +309,56 @@ func (s *Session) AgentResponses() <-chan AgentResponse {
 	return s.agentResponses
 }
 
+// Transcript returns an ordered snapshot of the conversation so far.
+// Interim user transcripts are collapsed so only the latest one for the
+// current utterance is included; it is replaced by the final when it arrives.
+func (s *Session) Transcript() []TranscriptEvent {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	snapshot := make([]TranscriptEvent, len(s.history))
+	copy(snapshot, s.history)
+	return snapshot
+}
+
+// recordTranscript appends an event to the ordered transcript
+func (s *Session) recordTranscript(ev TranscriptEvent) {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	// An interim or final for the same utterance supersedes the previous interim
+	if n := len(s.history); n > 0 {
+		last := s.history[n-1]
+		if ev.Role == "user" && last.Role == "user" && !last.IsFinal {
+			s.history[n-1] = ev
+			return
+		}
+	}
+	s.history = append(s.history, ev)
+}
+
+// throttleInterim reports whether ev is an interim that came too soon after
+// the last one passed on. A final resets the interval, so the first interim
+// of the next utterance always goes through.
+func (s *Session) throttleInterim(ev TranscriptEvent) bool {
+	if ev.IsFinal {
+		s.lastInterim = time.Time{}
+		return false
+	}
+	now := time.Now()
+	if s.interimInterval > 0 && !s.lastInterim.IsZero() && now.Sub(s.lastInterim) < s.interimInterval {
+		return true
+	}
+	s.lastInterim = now
+	return false
+}
+
+// Interruptions returns a channel that receives when the agent is interrupted
+// and any buffered playback should be stopped
+func (s *Session) Interruptions() <-chan struct{} {
+	return s.interruptions
+}
+
 // Done returns a channel that closes when the session ends
 func (s *Session) Done() <-chan struct{} {
 	return s.done
@@ -203,30 +369,68 @@ func (s *Session) ConversationID() string {
 	return s.conversationID
 }
 
-// Message types
+// Message types. Incoming events nest their payload under a
+// "<type>_event" object, e.g. audio arrives as
+// {"type":"audio","audio_event":{"audio_base_64":"...","event_id":1}}.
 type baseMessage struct {
 	Type string `json:"type"`
 }
 
 type conversationInitMessage struct {
-	Type           string `json:"type"`
-	ConversationID string `json:"conversation_id"`
+	Event struct {
+		ConversationID string `json:"conversation_id"`
+	} `json:"conversation_initiation_metadata_event"`
 }
 
 type userTranscriptMessage struct {
-	Type       string `json:"type"`
-	Transcript string `json:"user_transcription_event>user_transcript"`
-	IsFinal    bool   `json:"is_final"`
+	Event struct {
+		UserTranscript string   `json:"user_transcript"`
+		IsFinal        *bool    `json:"is_final"` // ElevenLabs only sends finals; absent means final
+		Confidence     *float64 `json:"confidence"`
+	} `json:"user_transcription_event"`
+}
+
+// confidence returns the transcript's confidence, or NoConfidence if it's
+// missing or out of range
+func (m userTranscriptMessage) confidence() float64 {
+	c := m.Event.Confidence
+	if c == nil || *c < 0 || *c > 1 {
+		return NoConfidence
+	}
+	return *c
 }
 
 type agentResponseMessage struct {
-	Type     string `json:"type"`
-	Response string `json:"agent_response"`
+	Event struct {
+		AgentResponse string `json:"agent_response"`
+	} `json:"agent_response_event"`
+	AgentResponse string `json:"agent_response"` // Older flat form
+}
+
+// text returns the response from either the nested or flat form
+func (m agentResponseMessage) text() string {
+	if m.Event.AgentResponse != "" {
+		return m.Event.AgentResponse
+	}
+	return m.AgentResponse
 }
 
 type audioMessage struct {
-	Type  string `json:"type"`
-	Audio []byte `json:"audio"`
+	Event struct {
+		Audio   string `json:"audio_base_64"`
+		EventID int    `json:"event_id"`
+	} `json:"audio_event"`
+}
+
+type pingMessage struct {
+	Event struct {
+		EventID int `json:"event_id"`
+	} `json:"ping_event"`
+}
+
+type pongMessage struct {
+	Type    string `json:"type"`
+	EventID int    `json:"event_id"`
 }
 
 type audioInputMessage struct {
@@ -234,6 +438,23 @@ type audioInputMessage struct {
 	Data []byte `json:"data"`
 }
 
+type initiationMessage struct {
+	Type             string                `json:"type"`
+	Override         *conversationOverride `json:"conversation_config_override,omitempty"`
+	DynamicVariables map[string]string     `json:"dynamic_variables,omitempty"`
+}
+
+type conversationOverride struct {
+	Agent struct {
+		Prompt       *promptOverride `json:"prompt,omitempty"`
+		FirstMessage string          `json:"first_message,omitempty"`
+	} `json:"agent"`
+}
+
+type promptOverride struct {
+	Prompt string `json:"prompt"`
+}
+
 type textInputMessage struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
@@ -244,9 +465,13 @@ func (s *Session) readLoop() {
 		s.closeOnce.Do(func() {
 			close(s.done)
 		})
+		s.chanMu.Lock()
+		defer s.chanMu.Unlock()
+		s.closed = true
 		close(s.transcripts)
 		close(s.audioOut)
 		close(s.agentResponses)
+		close(s.interruptions)
 	}()
 
 	for {
@@ -278,71 +503,97 @@ func (s *Session) handleMessage(data []byte) {
 	switch base.Type {
 	case "conversation_initiation_metadata":
 		var msg conversationInitMessage
-		if err := json.Unmarshal(data, &msg); err == nil {
-			s.conversationID = msg.ConversationID
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Failed to parse %s message: %v", base.Type, err)
+			return
 		}
+		s.conversationID = msg.Event.ConversationID
 
 	case "user_transcript":
-		var msg struct {
-			Type                    string `json:"type"`
-			UserTranscriptionEvent struct {
-				UserTranscript string `json:"user_transcript"`
-				IsFinal        bool   `json:"is_final"`
-			} `json:"user_transcription_event"`
+		var msg userTranscriptMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Failed to parse %s message: %v", base.Type, err)
+			return
+		}
+		// User speaking while agent audio is still queued means barge-in
+		if len(s.audioOut) > 0 {
+			s.interrupt()
+		}
+		ev := TranscriptEvent{
+			Role:       "user",
+			Text:       msg.Event.UserTranscript,
+			IsFinal:    msg.Event.IsFinal == nil || *msg.Event.IsFinal,
+			Timestamp:  time.Now().UnixMilli(),
+			Confidence: msg.confidence(),
 		}
-		if err := json.Unmarshal(data, &msg); err == nil {
+		s.recordTranscript(ev)
+		if !s.throttleInterim(ev) {
 			select {
-			case s.transcripts <- TranscriptEvent{
-				Role:      "user",
-				Text:      msg.UserTranscriptionEvent.UserTranscript,
-				IsFinal:   msg.UserTranscriptionEvent.IsFinal,
-				Timestamp: time.Now().UnixMilli(),
-			}:
+			case s.transcripts <- ev:
 			default:
 			}
 		}
 
 	case "agent_response":
-		var msg struct {
-			Type          string `json:"type"`
-			AgentResponse string `json:"agent_response"`
+		var msg agentResponseMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Failed to parse %s message: %v", base.Type, err)
+			return
 		}
-		if err := json.Unmarshal(data, &msg); err == nil {
-			select {
-			case s.agentResponses <- AgentResponse{
-				Text:      msg.AgentResponse,
-				Timestamp: time.Now().UnixMilli(),
-			}:
-			default:
-			}
-			// Also send as transcript
-			select {
-			case s.transcripts <- TranscriptEvent{
-				Role:      "agent",
-				Text:      msg.AgentResponse,
-				IsFinal:   true,
-				Timestamp: time.Now().UnixMilli(),
-			}:
-			default:
-			}
+		text := msg.text()
+		select {
+		case s.agentResponses <- AgentResponse{
+			Text:      text,
+			Timestamp: time.Now().UnixMilli(),
+		}:
+		default:
+		}
+		// Also send as transcript
+		ev := TranscriptEvent{
+			Role:       "agent",
+			Text:       text,
+			IsFinal:    true,
+			Timestamp:  time.Now().UnixMilli(),
+			Confidence: NoConfidence,
+		}
+		s.recordTranscript(ev)
+		select {
+		case s.transcripts <- ev:
+		default:
 		}
 
 	case "audio":
-		var msg struct {
-			Type  string `json:"type"`
-			Audio []byte `json:"audio"`
+		var msg audioMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Failed to parse %s message: %v", base.Type, err)
+			return
 		}
-		if err := json.Unmarshal(data, &msg); err == nil {
-			select {
-			case s.audioOut <- msg.Audio:
-			default:
-			}
+		audio, err := base64.StdEncoding.DecodeString(msg.Event.Audio)
+		if err != nil {
+			log.Printf("Failed to decode audio event %d: %v", msg.Event.EventID, err)
+			return
+		}
+		if len(audio) == 0 {
+			return
+		}
+		select {
+		case s.audioOut <- audio:
+		default:
 		}
 
+	case "interruption":
+		// ElevenLabs detected the user talking over the agent
+		s.interrupt()
+
 	case "ping":
-		// Respond with pong
+		// Respond with a pong echoing the ping's event ID
+		var msg pingMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Failed to parse %s message: %v", base.Type, err)
+			return
+		}
 		s.mu.Lock()
-		s.conn.WriteJSON(map[string]string{"type": "pong"})
+		s.conn.WriteJSON(pongMessage{Type: "pong", EventID: msg.Event.EventID})
 		s.mu.Unlock()
 	}
 }
@@ -0,0 +1,290 @@
+package elevenlabs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestSession creates a session without a websocket connection, for
+// exercising message handling directly.
+func newTestSession() *Session {
+	return &Session{
+		transcripts:    make(chan TranscriptEvent, 100),
+		audioOut:       make(chan []byte, 100),
+		agentResponses: make(chan AgentResponse, 100),
+		interruptions:  make(chan struct{}, 1),
+		done:           make(chan struct{}),
+	}
+}
+
+func TestBargeInFlushesQueuedAudio(t *testing.T) {
+	s := newTestSession()
+	s.audioOut <- []byte{1, 2, 3}
+	s.audioOut <- []byte{4, 5, 6}
+
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"wait, stop"}}`))
+
+	if n := len(s.audioOut); n != 0 {
+		t.Errorf("audioOut has %d queued chunks after barge-in, want 0", n)
+	}
+	select {
+	case <-s.Interruptions():
+	default:
+		t.Error("expected an interruption signal after barge-in")
+	}
+}
+
+func TestInterruptionEventFlushesAudio(t *testing.T) {
+	s := newTestSession()
+	s.audioOut <- []byte{1}
+
+	s.handleMessage([]byte(`{"type":"interruption","interruption_event":{"event_id":7}}`))
+
+	if n := len(s.audioOut); n != 0 {
+		t.Errorf("audioOut has %d queued chunks after interruption, want 0", n)
+	}
+	select {
+	case <-s.Interruptions():
+	default:
+		t.Error("expected an interruption signal")
+	}
+}
+
+func TestInterruptAfterSessionEnds(t *testing.T) {
+	// The server hangs up straight away, ending the read loop
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newTestSession()
+	s.conn = conn
+	s.audioOut <- []byte{1}
+
+	loopDone := make(chan struct{})
+	go func() {
+		s.readLoop()
+		close(loopDone)
+	}()
+	<-loopDone
+
+	interrupted := make(chan error, 1)
+	go func() { interrupted <- s.Interrupt() }()
+	select {
+	case err := <-interrupted:
+		if err == nil {
+			t.Error("Interrupt() after the session ended = nil, want an error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Interrupt() hung after the session ended")
+	}
+}
+
+func TestUserTranscriptWithoutQueuedAudioIsNotInterruption(t *testing.T) {
+	s := newTestSession()
+
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"hello"}}`))
+
+	select {
+	case <-s.Interruptions():
+		t.Error("unexpected interruption with no queued audio")
+	default:
+	}
+}
+
+func TestTranscriptOrderingAndInterimCollapse(t *testing.T) {
+	s := newTestSession()
+
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's the","is_final":false}}`))
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's the status","is_final":false}}`))
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's the status?","is_final":true}}`))
+	s.handleMessage([]byte(`{"type":"agent_response","agent_response":"Gary is still working on it."}`))
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"thanks","is_final":false}}`))
+
+	got := s.Transcript()
+	want := []struct {
+		role    string
+		text    string
+		isFinal bool
+	}{
+		{"user", "what's the status?", true},
+		{"agent", "Gary is still working on it.", true},
+		{"user", "thanks", false},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Transcript() has %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Role != w.role || got[i].Text != w.text || got[i].IsFinal != w.isFinal {
+			t.Errorf("event %d = %+v, want role=%s text=%q final=%v", i, got[i], w.role, w.text, w.isFinal)
+		}
+	}
+
+	// Snapshot must not alias internal state
+	got[0].Text = "mutated"
+	if s.Transcript()[0].Text == "mutated" {
+		t.Error("Transcript() returned a slice sharing internal storage")
+	}
+}
+
+// loadFixture reads a sample ElevenLabs websocket message from testdata.
+// The samples are written to the documented schema, not recorded traffic.
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestHandleMessageFixtures(t *testing.T) {
+	s := newTestSession()
+
+	s.handleMessage(loadFixture(t, "conversation_initiation_metadata.json"))
+	if got, want := s.ConversationID(), "conv_01jr8kq3m9f2x7c4b6d1a5e0t"; got != want {
+		t.Errorf("ConversationID() = %q, want %q", got, want)
+	}
+
+	s.handleMessage(loadFixture(t, "audio.json"))
+	select {
+	case audio := <-s.Audio():
+		if want := []byte{0x00, 0x01, 0x02, 0xff, 0x00, 0xfe}; !bytes.Equal(audio, want) {
+			t.Errorf("audio = %v, want %v", audio, want)
+		}
+	default:
+		t.Fatal("expected a decoded audio chunk")
+	}
+
+	s.handleMessage(loadFixture(t, "user_transcript.json"))
+	s.handleMessage(loadFixture(t, "agent_response.json"))
+
+	select {
+	case resp := <-s.AgentResponses():
+		if want := "Gary is finishing the landing page. Want me to check in with him?"; resp.Text != want {
+			t.Errorf("agent response = %q, want %q", resp.Text, want)
+		}
+	default:
+		t.Fatal("expected an agent response")
+	}
+
+	got := s.Transcript()
+	if len(got) != 2 {
+		t.Fatalf("Transcript() has %d events, want 2: %+v", len(got), got)
+	}
+	if got[0].Role != "user" || got[0].Text != "What's Gary working on right now?" || !got[0].IsFinal {
+		t.Errorf("user event = %+v, want final user transcript", got[0])
+	}
+	if got[1].Role != "agent" || got[1].Text == "" {
+		t.Errorf("agent event = %+v, want agent response", got[1])
+	}
+}
+
+func TestHandleMessageInvalidAudioIsDropped(t *testing.T) {
+	s := newTestSession()
+
+	s.handleMessage([]byte(`{"type":"audio","audio_event":{"audio_base_64":"not base64!","event_id":1}}`))
+	s.handleMessage([]byte(`{"type":"audio","audio":"AAEC"}`))
+
+	if n := len(s.audioOut); n != 0 {
+		t.Errorf("audioOut has %d chunks, want 0 for malformed audio", n)
+	}
+}
+
+func TestTranscriptConfidence(t *testing.T) {
+	s := newTestSession()
+
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"call Sam","confidence":0.42}}`))
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"call Sam back"}}`))
+	s.handleMessage([]byte(`{"type":"user_transcript","user_transcription_event":{"user_transcript":"tomorrow","confidence":7}}`))
+	s.handleMessage([]byte(`{"type":"agent_response","agent_response":"Will do."}`))
+
+	for i, want := range []float64{0.42, NoConfidence, NoConfidence, NoConfidence} {
+		if ev := <-s.Transcripts(); ev.Confidence != want {
+			t.Errorf("event %d (%q) confidence = %v, want %v", i, ev.Text, ev.Confidence, want)
+		}
+	}
+}
+
+func TestInterimTranscriptsAreThrottled(t *testing.T) {
+	s := newTestSession()
+	s.interimInterval = time.Hour
+
+	for _, msg := range []string{
+		`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's","is_final":false}}`,
+		`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's the","is_final":false}}`,
+		`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's the status","is_final":false}}`,
+		`{"type":"user_transcript","user_transcription_event":{"user_transcript":"what's the status?","is_final":true}}`,
+		`{"type":"user_transcript","user_transcription_event":{"user_transcript":"and","is_final":false}}`,
+		`{"type":"user_transcript","user_transcription_event":{"user_transcript":"and Maya","is_final":false}}`,
+	} {
+		s.handleMessage([]byte(msg))
+	}
+
+	var got []string
+	for len(s.transcripts) > 0 {
+		got = append(got, (<-s.transcripts).Text)
+	}
+	want := []string{"what's", "what's the status?", "and"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+
+	// The transcript snapshot still has the latest interim
+	if snap := s.Transcript(); snap[len(snap)-1].Text != "and Maya" {
+		t.Errorf("latest transcript = %+v, want the newest interim", snap[len(snap)-1])
+	}
+}
+
+func TestPingGetsPong(t *testing.T) {
+	pongs := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, data, err := conn.ReadMessage(); err == nil {
+			pongs <- data
+		}
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s := newTestSession()
+	s.conn = conn
+
+	s.handleMessage(loadFixture(t, "ping.json"))
+
+	select {
+	case data := <-pongs:
+		if got, want := strings.TrimSpace(string(data)), `{"type":"pong","event_id":4}`; got != want {
+			t.Errorf("pong = %s, want %s", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong sent")
+	}
+}
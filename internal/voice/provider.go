@@ -43,7 +43,15 @@ type CallResponse struct {
 	CreatedAt time.Time
 }
 
-// ConversationHandler handles LLM integration for voice conversations
+// ConversationHandler handles LLM integration for voice conversations.
+//
+// Each call carries one new message. Calls with the same conversationID
+// belong to one conversation, and a handler should answer in light of
+// its earlier turns. Handlers shouldn't keep that state themselves: the
+// caller attaches the prior turns with ContextWithTurns (voice platforms
+// resend them with every request) and the handler reads them from
+// TurnsFromContext(ctx). Handlers must be safe for concurrent calls,
+// including calls for the same conversation.
 type ConversationHandler interface {
 	// HandleMessage processes a message and returns a response
 	HandleMessage(ctx context.Context, conversationID, userID, message string) (string, error)
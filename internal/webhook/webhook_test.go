@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Example request from Slack's "Verifying requests from Slack" guide
+const (
+	slackSecret    = "8f742231b10e8888abcd99yyyzzz85a5"
+	slackTimestamp = "1531420618"
+	slackBody      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	slackSignature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+)
+
+func at(t *testing.T, ts int64) {
+	t.Helper()
+	orig := now
+	now = func() time.Time { return time.Unix(ts, 0) }
+	t.Cleanup(func() { now = orig })
+}
+
+func TestSlackKnownVector(t *testing.T) {
+	at(t, 1531420618+30)
+
+	if got := Slack.Sign([]byte(slackBody), slackTimestamp, slackSecret); got != slackSignature {
+		t.Errorf("Sign = %s, want %s", got, slackSignature)
+	}
+	if err := Slack.Verify([]byte(slackBody), slackTimestamp, slackSignature, slackSecret, 5*time.Minute); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	at(t, 1531420618)
+	body := []byte(slackBody)
+	ok := slackSignature
+
+	tests := []struct {
+		name                         string
+		body                         []byte
+		timestamp, signature, secret string
+		want                         error
+	}{
+		{"no secret", body, slackTimestamp, ok, "", ErrNoSecret},
+		{"no signature", body, slackTimestamp, "", slackSecret, ErrMissingSignature},
+		{"no timestamp", body, "", ok, slackSecret, ErrMissingSignature},
+		{"bad timestamp", body, "yesterday", ok, slackSecret, ErrInvalidTimestamp},
+		{"replayed", body, strconv.Itoa(1531420618 - 600), ok, slackSecret, ErrStaleTimestamp},
+		{"from the future", body, strconv.Itoa(1531420618 + 600), ok, slackSecret, ErrStaleTimestamp},
+		{"tampered body", append([]byte(nil), slackBody+"&admin=1"...), slackTimestamp, ok, slackSecret, ErrSignatureMismatch},
+		{"wrong secret", body, slackTimestamp, ok, "not-the-secret", ErrSignatureMismatch},
+		{"missing prefix", body, slackTimestamp, ok[len("v0="):], slackSecret, ErrSignatureMismatch},
+	}
+	for _, tt := range tests {
+		err := Slack.Verify(tt.body, tt.timestamp, tt.signature, tt.secret, 5*time.Minute)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultScheme(t *testing.T) {
+	at(t, 1700000000)
+	body := []byte(`{"agent_id":"abc"}`)
+
+	sig := Default.Sign(body, "1700000000", "secret")
+	if err := Verify(body, "1700000000", sig, "secret", time.Minute); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+	// A Slack signature over the same request isn't valid for Default
+	if err := Verify(body, "1700000000", Slack.Sign(body, "1700000000", "secret"), "secret", time.Minute); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("cross-scheme Verify = %v, want a mismatch", err)
+	}
+}